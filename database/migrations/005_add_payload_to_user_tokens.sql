@@ -0,0 +1,2 @@
+ALTER TABLE user_tokens
+ADD COLUMN IF NOT EXISTS payload TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE spaces
+ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public';
@@ -0,0 +1,11 @@
+ALTER TABLE user_spaces
+ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'member';
+
+-- Space creators become the owners of their spaces
+UPDATE user_spaces us SET role = 'owner'
+FROM spaces s
+WHERE s.id = us.space_id AND s.created_by = us.user_id
+  AND NOT EXISTS (SELECT 1 FROM user_spaces o WHERE o.space_id = us.space_id AND o.role = 'owner');
+
+ALTER TABLE posts
+ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMP NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE spaces
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP NULL;
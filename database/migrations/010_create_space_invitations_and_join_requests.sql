@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS space_invitations (
+    id TEXT PRIMARY KEY,
+    space_id INT NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    inviter_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invitee_id INT DEFAULT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE DEFAULT NULL,
+    max_uses INT NOT NULL,
+    uses INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_space_invitations_space ON space_invitations (space_id);
+CREATE INDEX IF NOT EXISTS idx_space_invitations_invitee ON space_invitations (invitee_id);
+
+CREATE TABLE IF NOT EXISTS space_join_requests (
+    id TEXT PRIMARY KEY,
+    space_id INT NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    message TEXT NOT NULL DEFAULT '',
+    reviewed_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_space_join_requests_pending
+    ON space_join_requests (space_id, user_id) WHERE status = 'pending';
@@ -0,0 +1,8 @@
+ALTER TABLE chat_messages
+ADD COLUMN IF NOT EXISTS reply_to_id TEXT NULL REFERENCES chat_messages(id) ON DELETE SET NULL;
+
+ALTER TABLE chat_messages
+ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP NULL;
+
+ALTER TABLE chat_messages
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
@@ -0,0 +1,2 @@
+-- Message IDs are ULIDs, byte order ("C" collation) is chronological order
+CREATE INDEX IF NOT EXISTS idx_chat_messages_space_id ON chat_messages (space_id, id COLLATE "C");
@@ -0,0 +1,8 @@
+-- Last chat message each user read per space, used for read receipts and unread counts
+CREATE TABLE IF NOT EXISTS chat_read_receipts (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    space_id INT NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    last_read_message_id TEXT NOT NULL,
+    read_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, space_id)
+);
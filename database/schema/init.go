@@ -61,6 +61,75 @@ func EnsureSchema(db *sql.DB) error {
             space_id INT NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
             timestamp TIMESTAMP NOT NULL DEFAULT now()
 )`,
+		`CREATE TABLE IF NOT EXISTS refresh_tokens (
+            id TEXT PRIMARY KEY,
+            user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            family_id TEXT NOT NULL,
+            token_hash TEXT UNIQUE NOT NULL,
+            expires_at TIMESTAMP NOT NULL,
+            revoked_at TIMESTAMP DEFAULT NULL,
+            replaced_by TEXT DEFAULT NULL,
+            created_at TIMESTAMP NOT NULL DEFAULT now()
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_family ON refresh_tokens (user_id, family_id)`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP DEFAULT NULL`,
+		`CREATE TABLE IF NOT EXISTS user_tokens (
+            id TEXT PRIMARY KEY,
+            user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            purpose TEXT NOT NULL,
+            token_hash TEXT UNIQUE NOT NULL,
+            expires_at TIMESTAMP NOT NULL,
+            used_at TIMESTAMP DEFAULT NULL,
+            created_at TIMESTAMP NOT NULL DEFAULT now()
+        )`,
+		`ALTER TABLE user_tokens ADD COLUMN IF NOT EXISTS payload TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT false`,
+		`ALTER TABLE spaces ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'`,
+		`ALTER TABLE user_spaces ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'member'`,
+		`UPDATE user_spaces us SET role = 'owner'
+            FROM spaces s
+            WHERE s.id = us.space_id AND s.created_by = us.user_id
+              AND NOT EXISTS (SELECT 1 FROM user_spaces o WHERE o.space_id = us.space_id AND o.role = 'owner')`,
+		`ALTER TABLE posts ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMP NULL`,
+		`ALTER TABLE spaces ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP NULL`,
+		`CREATE TABLE IF NOT EXISTS space_invitations (
+            id TEXT PRIMARY KEY,
+            space_id INT NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+            inviter_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            invitee_id INT DEFAULT NULL REFERENCES users(id) ON DELETE CASCADE,
+            token_hash TEXT UNIQUE DEFAULT NULL,
+            max_uses INT NOT NULL,
+            uses INT NOT NULL DEFAULT 0,
+            expires_at TIMESTAMP NOT NULL,
+            revoked_at TIMESTAMP DEFAULT NULL,
+            created_at TIMESTAMP NOT NULL DEFAULT now()
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_space_invitations_space ON space_invitations (space_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_space_invitations_invitee ON space_invitations (invitee_id)`,
+		`CREATE TABLE IF NOT EXISTS space_join_requests (
+            id TEXT PRIMARY KEY,
+            space_id INT NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+            user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            status VARCHAR(20) NOT NULL DEFAULT 'pending',
+            message TEXT NOT NULL DEFAULT '',
+            reviewed_by INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+            reviewed_at TIMESTAMP DEFAULT NULL,
+            created_at TIMESTAMP NOT NULL DEFAULT now()
+        )`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_space_join_requests_pending
+            ON space_join_requests (space_id, user_id) WHERE status = 'pending'`,
+		`ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS reply_to_id TEXT NULL REFERENCES chat_messages(id) ON DELETE SET NULL`,
+		`ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP NULL`,
+		`ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_chat_messages_space_id ON chat_messages (space_id, id COLLATE "C")`,
+		`CREATE TABLE IF NOT EXISTS chat_read_receipts (
+            user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            space_id INT NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+            last_read_message_id TEXT NOT NULL,
+            read_at TIMESTAMP NOT NULL DEFAULT now(),
+            PRIMARY KEY (user_id, space_id)
+        )`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP NULL`,
 	}
 
 	for _, stmt := range stmts {
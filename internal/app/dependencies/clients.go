@@ -3,8 +3,12 @@ package dependencies
 import (
 	"context"
 	"cpi-hub-api/database/schema"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/mailer"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"time"
 
 	"database/sql"
@@ -71,6 +75,26 @@ func CloseMongoConnection(client *mongo.Client) error {
 	return client.Disconnect(ctx)
 }
 
+// NewMailer sends real emails when MAILER_DRIVER=smtp, otherwise it only logs them
+func NewMailer() domain.Mailer {
+	if os.Getenv("MAILER_DRIVER") != "smtp" {
+		return mailer.NewLogMailer()
+	}
+
+	port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil {
+		port = 587
+	}
+
+	return mailer.NewSMTPMailer(mailer.SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     port,
+		User:     os.Getenv("SMTP_USER"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	})
+}
+
 func NewPostgreSQLClient() (*sql.DB, error) {
 	config := PostgreSQLConfig{
 		Host:     "localhost",
@@ -1,8 +1,11 @@
 package dependencies
 
 import (
+	"context"
+	authUsecase "cpi-hub-api/internal/core/usecase/auth"
 	commentUsecase "cpi-hub-api/internal/core/usecase/comment"
 	eventsUsecase "cpi-hub-api/internal/core/usecase/events"
+	invitationUsecase "cpi-hub-api/internal/core/usecase/invitation"
 	messageUsecase "cpi-hub-api/internal/core/usecase/message"
 	notificationUsecase "cpi-hub-api/internal/core/usecase/notification"
 	postUsecase "cpi-hub-api/internal/core/usecase/post"
@@ -13,13 +16,19 @@ import (
 	reactionRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/mongo/reaction"
 	commentRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/comment"
 	eventsRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/events"
+	joinRequestRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/join_request"
 	messageRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/message"
 	postRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/post"
+	refreshTokenRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/refresh_token"
 	spaceRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/space"
+	spaceInvitationRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/space_invitation"
 	userRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/user"
 	userSpaceRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/user_space"
+	userTokenRepository "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/user_token"
+	authHandler "cpi-hub-api/internal/infrastructure/entrypoint/handlers/auth"
 	"cpi-hub-api/internal/infrastructure/entrypoint/handlers/comment"
 	"cpi-hub-api/internal/infrastructure/entrypoint/handlers/events"
+	invitationHandler "cpi-hub-api/internal/infrastructure/entrypoint/handlers/invitation"
 	messageHandler "cpi-hub-api/internal/infrastructure/entrypoint/handlers/message"
 	notificationHandler "cpi-hub-api/internal/infrastructure/entrypoint/handlers/notification"
 	"cpi-hub-api/internal/infrastructure/entrypoint/handlers/post"
@@ -27,9 +36,11 @@ import (
 	"cpi-hub-api/internal/infrastructure/entrypoint/handlers/space"
 	"cpi-hub-api/internal/infrastructure/entrypoint/handlers/user"
 	"log"
+	"os"
 )
 
 type Handlers struct {
+	AuthHandler         *authHandler.AuthHandler
 	UserHandler         *user.UserHandler
 	SpaceHandler        *space.SpaceHandler
 	PostHandler         *post.PostHandler
@@ -38,6 +49,7 @@ type Handlers struct {
 	MessageHandler      *messageHandler.MessageHandler
 	ReactionHandler     *reactionHandler.ReactionHandler
 	NotificationHandler *notificationHandler.NotificationHandler
+	InvitationHandler   *invitationHandler.InvitationHandler
 }
 
 func Build() *Handlers {
@@ -53,36 +65,78 @@ func Build() *Handlers {
 	}
 
 	userRepository := userRepository.NewUserRepository(sqldb)
-	spaceRepository := spaceRepository.NewSpaceRepository(sqldb)
-	userSpaceRepository := userSpaceRepository.NewUserSpaceRepository(sqldb)
+	postgresSpaceRepository := spaceRepository.NewSpaceRepository(sqldb)
+	postgresUserSpaceRepository := userSpaceRepository.NewUserSpaceRepository(sqldb)
 	postRepository := postRepository.NewPostRepository(sqldb)
 	commentRepository := commentRepository.NewCommentRepository(sqldb)
 	eventsRepo := eventsRepository.NewEventsRepository(sqldb)
 	messageRepo := messageRepository.NewMessageRepository(sqldb)
 	reactionRepo := reactionRepository.NewReactionRepository(mongodb)
 	notificationRepo := notificationRepository.NewNotificationRepository(mongodb)
-
-	userUsecase := userUsecase.NewUserUsecase(userRepository, spaceRepository, userSpaceRepository)
-	spaceUsecase := spaceUsecase.NewSpaceUsecase(spaceRepository, userRepository, userSpaceRepository, postRepository)
-	postUsecase := postUsecase.NewPostUsecase(postRepository, spaceRepository, userRepository, commentRepository, userSpaceRepository)
-	commentUsecase := commentUsecase.NewCommentUsecase(commentRepository)
-	messageUsecase := messageUsecase.NewMessageUsecase(messageRepo)
+	if err := notificationRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Error creating notification indexes: %v", err)
+	}
+	notificationPreferenceRepo := notificationRepository.NewNotificationPreferenceRepository(mongodb)
+	userTokenRepo := userTokenRepository.NewUserTokenRepository(sqldb)
+	invitationRepo := spaceInvitationRepository.NewSpaceInvitationRepository(sqldb)
+	joinRequestRepo := joinRequestRepository.NewJoinRequestRepository(sqldb)
 
 	hubManager := eventsUsecase.NewHubManager()
 	go hubManager.Run()
 
-	userConnManager := eventsUsecase.NewUserConnectionManager()
-	notificationManager := eventsUsecase.NewNotificationManager()
+	// Presence follows membership changes, so every usecase writes through the observed repositories.
+	// The hub hides the users who went invisible from the chats.
+	userConnManager := eventsUsecase.NewUserConnectionManager(userRepository, postgresUserSpaceRepository, hubManager)
+	userSpaceRepository := eventsUsecase.NewObservedUserSpaceRepository(postgresUserSpaceRepository, userConnManager)
+	spaceRepository := eventsUsecase.NewObservedSpaceRepository(postgresSpaceRepository, postgresUserSpaceRepository, userConnManager)
+
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:3000"
+	}
+
+	spaceUsecase := spaceUsecase.NewSpaceUsecase(spaceRepository, userRepository, userSpaceRepository, postRepository, commentRepository, reactionRepo, notificationRepo)
+	commentUsecase := commentUsecase.NewCommentUsecase(commentRepository, spaceRepository, userSpaceRepository)
+	messageUsecase := messageUsecase.NewMessageUsecase(messageRepo, spaceRepository, userSpaceRepository)
+
+	notificationManager := eventsUsecase.NewNotificationManager(notificationRepo)
 
-	notificationUsecase := notificationUsecase.NewNotificationUsecase(notificationRepo, notificationManager)
-	reactionUsecase := reactionUsecase.NewReactionUsecase(reactionRepo, userRepository, postRepository, commentRepository, notificationUsecase)
+	notificationDispatcher := notificationUsecase.NewNotificationDispatcher(notificationRepo, notificationManager, notificationUsecase.DefaultDispatcherConfig)
+	go notificationDispatcher.Run(context.Background())
 
-	eventsUsecase := eventsUsecase.NewEventsUsecase(hubManager, userConnManager, notificationManager, eventsRepo, userRepository, spaceRepository)
+	mailer := NewMailer()
+
+	digestConfig := notificationUsecase.DefaultDigestConfig
+	digestConfig.AppURL = appURL
+	notificationDigest := notificationUsecase.NewNotificationDigest(notificationRepo, userRepository, mailer, digestConfig)
+	go notificationDigest.Run(context.Background())
+
+	notificationUsecase := notificationUsecase.NewNotificationUsecase(notificationRepo, notificationPreferenceRepo, userRepository, notificationDispatcher)
+	postUsecase := postUsecase.NewPostUsecase(postRepository, spaceRepository, userRepository, commentRepository, userSpaceRepository, notificationUsecase)
+	reactionUsecase := reactionUsecase.NewReactionUsecase(reactionRepo, userRepository, postRepository, commentRepository, spaceRepository, userSpaceRepository, notificationUsecase)
+	invitationUsecase := invitationUsecase.NewInvitationUsecase(invitationRepo, joinRequestRepo, spaceRepository, userRepository, userSpaceRepository, notificationUsecase)
+
+	eventsUsecase := eventsUsecase.NewEventsUsecase(hubManager, userConnManager, notificationManager, notificationUsecase, eventsRepo, userRepository, spaceRepository, userSpaceRepository)
+
+	// Revoking a session closes the sockets opened with it
+	refreshTokenRepo := authUsecase.NewCachedRefreshTokenRepository(refreshTokenRepository.NewRefreshTokenRepository(sqldb), authUsecase.SessionCacheTTL, eventsUsecase)
+	requireEmailVerification := os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true"
+	authUsecase := authUsecase.NewAuthUsecase(refreshTokenRepo, userRepository, authUsecase.Config{
+		RequireEmailVerification: requireEmailVerification,
+	})
+	userUsecase := userUsecase.NewUserUsecase(userRepository, spaceRepository, userSpaceRepository, userTokenRepo, refreshTokenRepo, mailer, userUsecase.Config{
+		RequireEmailVerification: requireEmailVerification,
+		AppURL:                   appURL,
+	})
 
 	return &Handlers{
+		AuthHandler: &authHandler.AuthHandler{
+			AuthUseCase: authUsecase,
+		},
 		UserHandler: &user.UserHandler{
 			UseCase:     userUsecase,
 			PostUseCase: postUsecase,
+			AuthUseCase: authUsecase,
 		},
 		SpaceHandler: &space.SpaceHandler{
 			SpaceUseCase: spaceUsecase,
@@ -93,7 +147,7 @@ func Build() *Handlers {
 		CommentHandler: &comment.CommentHandler{
 			CommentUseCase: commentUsecase,
 		},
-		EventsHandler: events.NewEventsHandler(eventsUsecase),
+		EventsHandler: events.NewEventsHandler(eventsUsecase, authUsecase),
 		MessageHandler: &messageHandler.MessageHandler{
 			MessageUseCase: messageUsecase,
 		},
@@ -101,5 +155,8 @@ func Build() *Handlers {
 			ReactionUseCase: reactionUsecase,
 		},
 		NotificationHandler: notificationHandler.NewNotificationHandler(notificationUsecase),
+		InvitationHandler: &invitationHandler.InvitationHandler{
+			InvitationUseCase: invitationUsecase,
+		},
 	}
 }
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// RefreshToken is a server-side session. Every rotation issues a new token in
+// the same family, so a reused token can revoke the whole session.
+type RefreshToken struct {
+	ID         string
+	UserID     int
+	FamilyID   string
+	TokenHash  string
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	ReplacedBy *string
+	CreatedAt  time.Time
+}
+
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
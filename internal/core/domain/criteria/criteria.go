@@ -1,7 +1,9 @@
 package criteria
 
 type Criteria struct {
-	Filters         []Filter
+	Filters []Filter
+	// RequiredFilters are always combined with AND, whatever the LogicalOperator of Filters
+	RequiredFilters []Filter
 	Sort            Sort
 	Pagination      Pagination
 	LogicalOperator LogicalOperator
@@ -53,6 +55,7 @@ type Pagination struct {
 
 type CriteriaBuilder struct {
 	filters         []Filter
+	requiredFilters []Filter
 	sort            Sort
 	pagination      Pagination
 	logicalOperator LogicalOperator
@@ -61,6 +64,7 @@ type CriteriaBuilder struct {
 func NewCriteriaBuilder() *CriteriaBuilder {
 	return &CriteriaBuilder{
 		filters:         make([]Filter, 0),
+		requiredFilters: make([]Filter, 0),
 		sort:            Sort{},
 		pagination:      Pagination{},
 		logicalOperator: LogicalOperatorAnd,
@@ -83,6 +87,16 @@ func (b *CriteriaBuilder) WithFilterAndCondition(field string, value any, operat
 	return b
 }
 
+// WithRequiredFilter adds a filter every result must match, even when the other filters are joined with OR
+func (b *CriteriaBuilder) WithRequiredFilter(field string, value any, operator Operator) *CriteriaBuilder {
+	b.requiredFilters = append(b.requiredFilters, Filter{
+		Field:    field,
+		Value:    value,
+		Operator: operator,
+	})
+	return b
+}
+
 func (b *CriteriaBuilder) WithSort(field string, direction Direction) *CriteriaBuilder {
 	b.sort = Sort{
 		Field:         field,
@@ -107,6 +121,7 @@ func (b *CriteriaBuilder) WithLogicalOperator(operator LogicalOperator) *Criteri
 func (b *CriteriaBuilder) Build() *Criteria {
 	return &Criteria{
 		Filters:         b.filters,
+		RequiredFilters: b.requiredFilters,
 		Sort:            b.sort,
 		Pagination:      b.pagination,
 		LogicalOperator: b.logicalOperator,
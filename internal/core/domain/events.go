@@ -17,6 +17,17 @@ const (
 	MessageTypeError MessageType = "error"
 	MessageTypePing  MessageType = "ping"
 	MessageTypePong  MessageType = "pong"
+	MessageTypeAck   MessageType = "ack"
+	// Edit y Delete los envía el autor o un moderador, y se difunden al espacio con el mensaje actualizado
+	MessageTypeEdit   MessageType = "edit"
+	MessageTypeDelete MessageType = "delete"
+	// Resync avisa al cliente que no se pueden reenviar los eventos perdidos y debe recargar el historial por REST
+	MessageTypeResync MessageType = "resync"
+	// Typing se difunde al espacio sin guardarse, Read registra el último mensaje leído por el usuario
+	MessageTypeTyping MessageType = "typing"
+	MessageTypeRead   MessageType = "read"
+	// PresenceSnapshot se envía solo al cliente que se une, con los usuarios ya conectados al espacio
+	MessageTypePresenceSnapshot MessageType = "presence_snapshot"
 )
 
 // EventMessage representa un mensaje de eventos en tiempo real genérico
@@ -28,6 +39,10 @@ type EventMessage struct {
 	SpaceID   int         `json:"space_id,omitempty"`
 	Username  string      `json:"username,omitempty"`
 	Image     string      `json:"image,omitempty"`
+	// CorrelationID es enviado por el cliente y se devuelve en el ack o error de ese mensaje
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// EventID es un ULID creciente por espacio, el cliente lo envía como last_event_id al reconectarse
+	EventID string `json:"event_id,omitempty"`
 }
 
 // ChatMessage representa un mensaje de chat específico
@@ -39,6 +54,15 @@ type ChatMessage struct {
 	SpaceID   int       `json:"space_id"`
 	Timestamp time.Time `json:"timestamp"`
 	Image     string    `json:"image"`
+	// ReplyToID es el mensaje del mismo espacio al que responde
+	ReplyToID *string    `json:"reply_to_id"`
+	EditedAt  *time.Time `json:"edited_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// IsDeleted indica si el mensaje fue borrado, los mensajes borrados se conservan sin contenido
+func (m *ChatMessage) IsDeleted() bool {
+	return m.DeletedAt != nil
 }
 
 // JoinMessage representa un mensaje de unión a un espacio
@@ -55,6 +79,57 @@ type LeaveMessage struct {
 	Username string `json:"username"`
 }
 
+// ChatAckMessage confirma al emisor que su mensaje de chat fue guardado
+type ChatAckMessage struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ChatMessageRef identifica el mensaje a editar o borrar
+type ChatMessageRef struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+}
+
+// ResyncMessage indica desde qué evento el cliente perdió continuidad
+type ResyncMessage struct {
+	LastEventID string `json:"last_event_id"`
+}
+
+// PresenceUser es un usuario conectado al chat de un espacio
+type PresenceUser struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Image    string `json:"image"`
+}
+
+// PresenceSnapshotMessage lista los usuarios conectados a un espacio
+type PresenceSnapshotMessage struct {
+	SpaceID int            `json:"space_id"`
+	Users   []PresenceUser `json:"users"`
+}
+
+// TypingMessage avisa que un usuario está escribiendo en el espacio
+type TypingMessage struct {
+	SpaceID  int    `json:"space_id"`
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// ReadReceipt es el último mensaje que un usuario leyó en un espacio
+type ReadReceipt struct {
+	UserID    int       `json:"user_id"`
+	SpaceID   int       `json:"space_id"`
+	MessageID string    `json:"message_id"`
+	ReadAt    time.Time `json:"read_at"`
+}
+
+// SpaceUnreadCount es la cantidad de mensajes de otros usuarios sin leer en un espacio
+type SpaceUnreadCount struct {
+	SpaceID int
+	Count   int
+}
+
 // ErrorMessage representa un mensaje de error
 type ErrorMessage struct {
 	Code    string `json:"code"`
@@ -63,13 +138,18 @@ type ErrorMessage struct {
 
 // Client representa un cliente conectado a eventos en tiempo real
 type Client struct {
-	ID       string
-	UserID   int
-	SpaceID  int
-	Send     chan []byte
-	Hub      *Hub
-	Conn     EventConnection
-	Username string
+	ID      string
+	UserID  int
+	SpaceID int
+	// SessionID es la sesión con la que se abrió la conexión, se cierra si se revoca
+	SessionID string
+	Send      chan []byte
+	Hub       *Hub
+	Conn      EventConnection
+	Username  string
+	Image     string
+	// LastEventID es el último evento que el cliente ya recibió, los anteriores no se le reenvían
+	LastEventID string
 }
 
 // Hub mantiene el conjunto de clientes activos y los mensajes de difusión
@@ -94,6 +174,8 @@ type Hub struct {
 type SpaceMessage struct {
 	SpaceID int
 	Message []byte
+	// EventID está vacío para los mensajes que no se guardan para reenvío
+	EventID string
 }
 
 // EventConnection define la interfaz para conexiones de eventos en tiempo real
@@ -121,6 +203,33 @@ type UserStatus string
 const (
 	UserStatusOnline  UserStatus = "online"
 	UserStatusOffline UserStatus = "offline"
+	// Away, DoNotDisturb e Invisible los elige el usuario desde el socket de presencia
+	UserStatusAway         UserStatus = "away"
+	UserStatusDoNotDisturb UserStatus = "do_not_disturb"
+	// Invisible mantiene al usuario conectado pero los demás lo ven offline
+	UserStatusInvisible UserStatus = "invisible"
+)
+
+// IsSelectable indica si el usuario puede elegir este estado, offline solo se da al desconectarse
+func (s UserStatus) IsSelectable() bool {
+	switch s {
+	case UserStatusOnline, UserStatusAway, UserStatusDoNotDisturb, UserStatusInvisible:
+		return true
+	}
+	return false
+}
+
+// Public es el estado que ven los demás usuarios
+func (s UserStatus) Public() UserStatus {
+	if s == UserStatusInvisible {
+		return UserStatusOffline
+	}
+	return s
+}
+
+const (
+	UserConnectionMessageTypeStatus    = "user_status"
+	UserConnectionMessageTypeSetStatus = "set_status"
 )
 
 type UserConnectionMessage struct {
@@ -128,26 +237,69 @@ type UserConnectionMessage struct {
 	UserID    int        `json:"user_id"`
 	Status    UserStatus `json:"status"`
 	Username  string     `json:"username"`
+	Image     string     `json:"image,omitempty"`
 	Timestamp string     `json:"timestamp"`
+	// LastSeenAt se envía con el estado offline
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// SetStatusMessage lo envía el cliente para cambiar su estado
+type SetStatusMessage struct {
+	Type   string     `json:"type"`
+	Status UserStatus `json:"status"`
 }
 
 type HandleUserConnectionParams struct {
-	UserID  int
-	Writer  http.ResponseWriter
-	Request *http.Request
+	UserID    int
+	SessionID string
+	Writer    http.ResponseWriter
+	Request   *http.Request
+}
+
+// MembershipListener recibe los usuarios que entraron o salieron de algún espacio
+type MembershipListener interface {
+	MembershipsChanged(userIDs []int)
+}
+
+// SessionListener cierra las conexiones abiertas con las sesiones que se revocaron.
+// revoked indica qué sesiones del usuario ya no son válidas.
+type SessionListener interface {
+	SessionsRevoked(userID int, revoked func(sessionID string) bool)
+}
+
+// VisibilityListener oculta de la presencia de los chats a los usuarios invisibles
+type VisibilityListener interface {
+	SetInvisible(userID int, invisible bool)
 }
 
 type UserConnectionManager interface {
 	HandleConnection(params HandleUserConnectionParams) error
+	MembershipListener
+	SessionListener
 }
 
 type HandleNotificationConnectionParams struct {
-	UserID  int
-	Writer  http.ResponseWriter
-	Request *http.Request
+	UserID    int
+	SessionID string
+	Writer    http.ResponseWriter
+	Request   *http.Request
+}
+
+const NotificationMessageTypeAck = "ack"
+
+// NotificationAckMessage lo envía el cliente al recibir una notificación
+type NotificationAckMessage struct {
+	Type           string `json:"type"`
+	NotificationID string `json:"notification_id"`
 }
 
 type NotificationManager interface {
 	HandleConnection(params HandleNotificationConnectionParams) error
+	SessionListener
+	// BroadcastToUser encola la notificación en los dispositivos conectados. La entrega
+	// se confirma cuando se escribe en el socket o cuando el cliente envía el ack.
 	BroadcastToUser(userID int, notification *Notification) error
+	// BroadcastRetraction avisa a los dispositivos conectados que una notificación se
+	// retiró o cambió. No pasa por el outbox, quien no está conectado la ve al recargar.
+	BroadcastRetraction(userID int, retraction *NotificationRetraction) error
 }
@@ -0,0 +1,14 @@
+package domain
+
+import "context"
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails such as verification and password reset links
+type Mailer interface {
+	Send(ctx context.Context, message MailMessage) error
+}
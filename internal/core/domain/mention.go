@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// MaxMentions caps how many users a single post, comment or message can notify
+const MaxMentions = 20
+
+// mentionPattern matches the markup the client inserts for a mention: @[Ana Gómez](7)
+var mentionPattern = regexp.MustCompile(`@\[[^\]\n]*\]\((\d+)\)`)
+
+// ParseMentions returns the mentioned user IDs in order of appearance, without repeats
+func ParseMentions(texts ...string) []int {
+	seen := make(map[int]bool)
+	var userIDs []int
+
+	for _, text := range texts {
+		for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+			userID, err := strconv.Atoi(match[1])
+			if err != nil || userID <= 0 || seen[userID] {
+				continue
+			}
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+
+			if len(userIDs) == MaxMentions {
+				return userIDs
+			}
+		}
+	}
+
+	return userIDs
+}
@@ -14,6 +14,7 @@ import (
 	domain "cpi-hub-api/internal/core/domain"
 	criteria "cpi-hub-api/internal/core/domain/criteria"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -115,6 +116,20 @@ func (mr *MockUserRepositoryMockRecorder) Update(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepository)(nil).Update), ctx, user)
 }
 
+// UpdateLastSeen mocks base method.
+func (m *MockUserRepository) UpdateLastSeen(ctx context.Context, userID int, lastSeenAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastSeen", ctx, userID, lastSeenAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastSeen indicates an expected call of UpdateLastSeen.
+func (mr *MockUserRepositoryMockRecorder) UpdateLastSeen(ctx, userID, lastSeenAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastSeen", reflect.TypeOf((*MockUserRepository)(nil).UpdateLastSeen), ctx, userID, lastSeenAt)
+}
+
 // MockSpaceRepository is a mock of SpaceRepository interface.
 type MockSpaceRepository struct {
 	ctrl     *gomock.Controller
@@ -168,6 +183,20 @@ func (mr *MockSpaceRepositoryMockRecorder) Create(ctx, space any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSpaceRepository)(nil).Create), ctx, space)
 }
 
+// Delete mocks base method.
+func (m *MockSpaceRepository) Delete(ctx context.Context, spaceID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, spaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSpaceRepositoryMockRecorder) Delete(ctx, spaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSpaceRepository)(nil).Delete), ctx, spaceID)
+}
+
 // Find mocks base method.
 func (m *MockSpaceRepository) Find(ctx context.Context, arg1 *criteria.Criteria) (*domain.Space, error) {
 	m.ctrl.T.Helper()
@@ -213,6 +242,34 @@ func (mr *MockSpaceRepositoryMockRecorder) FindByIDs(ctx, ids any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDs", reflect.TypeOf((*MockSpaceRepository)(nil).FindByIDs), ctx, ids)
 }
 
+// SetArchived mocks base method.
+func (m *MockSpaceRepository) SetArchived(ctx context.Context, spaceID int, archivedAt *time.Time, updatedBy int, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArchived", ctx, spaceID, archivedAt, updatedBy, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetArchived indicates an expected call of SetArchived.
+func (mr *MockSpaceRepositoryMockRecorder) SetArchived(ctx, spaceID, archivedAt, updatedBy, updatedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArchived", reflect.TypeOf((*MockSpaceRepository)(nil).SetArchived), ctx, spaceID, archivedAt, updatedBy, updatedAt)
+}
+
+// Touch mocks base method.
+func (m *MockSpaceRepository) Touch(ctx context.Context, spaceID, updatedBy int, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, spaceID, updatedBy, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockSpaceRepositoryMockRecorder) Touch(ctx, spaceID, updatedBy, updatedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockSpaceRepository)(nil).Touch), ctx, spaceID, updatedBy, updatedAt)
+}
+
 // Update mocks base method.
 func (m *MockSpaceRepository) Update(ctx context.Context, space *domain.Space) error {
 	m.ctrl.T.Helper()
@@ -251,6 +308,20 @@ func (m *MockUserSpaceRepository) EXPECT() *MockUserSpaceRepositoryMockRecorder
 	return m.recorder
 }
 
+// AddMember mocks base method.
+func (m *MockUserSpaceRepository) AddMember(ctx context.Context, userID, spaceID int, role domain.SpaceRole) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", ctx, userID, spaceID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockUserSpaceRepositoryMockRecorder) AddMember(ctx, userID, spaceID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockUserSpaceRepository)(nil).AddMember), ctx, userID, spaceID, role)
+}
+
 // Count mocks base method.
 func (m *MockUserSpaceRepository) Count(ctx context.Context, arg1 *criteria.Criteria) (int, error) {
 	m.ctrl.T.Helper()
@@ -266,6 +337,21 @@ func (mr *MockUserSpaceRepositoryMockRecorder) Count(ctx, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockUserSpaceRepository)(nil).Count), ctx, arg1)
 }
 
+// CountByRole mocks base method.
+func (m *MockUserSpaceRepository) CountByRole(ctx context.Context, spaceID int) (map[domain.SpaceRole]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByRole", ctx, spaceID)
+	ret0, _ := ret[0].(map[domain.SpaceRole]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByRole indicates an expected call of CountByRole.
+func (mr *MockUserSpaceRepositoryMockRecorder) CountByRole(ctx, spaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByRole", reflect.TypeOf((*MockUserSpaceRepository)(nil).CountByRole), ctx, spaceID)
+}
+
 // Exists mocks base method.
 func (m *MockUserSpaceRepository) Exists(ctx context.Context, userId, spaceId int) (bool, error) {
 	m.ctrl.T.Helper()
@@ -281,6 +367,36 @@ func (mr *MockUserSpaceRepositoryMockRecorder) Exists(ctx, userId, spaceId any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockUserSpaceRepository)(nil).Exists), ctx, userId, spaceId)
 }
 
+// FindMembersBySpaceID mocks base method.
+func (m *MockUserSpaceRepository) FindMembersBySpaceID(ctx context.Context, spaceID int) ([]*domain.SpaceMembership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMembersBySpaceID", ctx, spaceID)
+	ret0, _ := ret[0].([]*domain.SpaceMembership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMembersBySpaceID indicates an expected call of FindMembersBySpaceID.
+func (mr *MockUserSpaceRepositoryMockRecorder) FindMembersBySpaceID(ctx, spaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMembersBySpaceID", reflect.TypeOf((*MockUserSpaceRepository)(nil).FindMembersBySpaceID), ctx, spaceID)
+}
+
+// FindRole mocks base method.
+func (m *MockUserSpaceRepository) FindRole(ctx context.Context, userID, spaceID int) (domain.SpaceRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRole", ctx, userID, spaceID)
+	ret0, _ := ret[0].(domain.SpaceRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRole indicates an expected call of FindRole.
+func (mr *MockUserSpaceRepositoryMockRecorder) FindRole(ctx, userID, spaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRole", reflect.TypeOf((*MockUserSpaceRepository)(nil).FindRole), ctx, userID, spaceID)
+}
+
 // FindSpacesIDsByUserID mocks base method.
 func (m *MockUserSpaceRepository) FindSpacesIDsByUserID(ctx context.Context, userID int) ([]int, error) {
 	m.ctrl.T.Helper()
@@ -311,6 +427,20 @@ func (mr *MockUserSpaceRepositoryMockRecorder) FindUserIDsBySpaceID(ctx, spaceID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserIDsBySpaceID", reflect.TypeOf((*MockUserSpaceRepository)(nil).FindUserIDsBySpaceID), ctx, spaceID)
 }
 
+// TransferOwnership mocks base method.
+func (m *MockUserSpaceRepository) TransferOwnership(ctx context.Context, spaceID, fromUserID, toUserID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferOwnership", ctx, spaceID, fromUserID, toUserID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferOwnership indicates an expected call of TransferOwnership.
+func (mr *MockUserSpaceRepositoryMockRecorder) TransferOwnership(ctx, spaceID, fromUserID, toUserID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferOwnership", reflect.TypeOf((*MockUserSpaceRepository)(nil).TransferOwnership), ctx, spaceID, fromUserID, toUserID)
+}
+
 // Update mocks base method.
 func (m *MockUserSpaceRepository) Update(ctx context.Context, userId int, spaceIDs []int, action string) error {
 	m.ctrl.T.Helper()
@@ -325,6 +455,20 @@ func (mr *MockUserSpaceRepositoryMockRecorder) Update(ctx, userId, spaceIDs, act
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserSpaceRepository)(nil).Update), ctx, userId, spaceIDs, action)
 }
 
+// UpdateRole mocks base method.
+func (m *MockUserSpaceRepository) UpdateRole(ctx context.Context, userID, spaceID int, role domain.SpaceRole) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, userID, spaceID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockUserSpaceRepositoryMockRecorder) UpdateRole(ctx, userID, spaceID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockUserSpaceRepository)(nil).UpdateRole), ctx, userID, spaceID, role)
+}
+
 // MockPostRepository is a mock of PostRepository interface.
 type MockPostRepository struct {
 	ctrl     *gomock.Controller
@@ -422,6 +566,34 @@ func (mr *MockPostRepositoryMockRecorder) Search(ctx, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockPostRepository)(nil).Search), ctx, arg1)
 }
 
+// SetPinned mocks base method.
+func (m *MockPostRepository) SetPinned(ctx context.Context, postID int, pinnedAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPinned", ctx, postID, pinnedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPinned indicates an expected call of SetPinned.
+func (mr *MockPostRepositoryMockRecorder) SetPinned(ctx, postID, pinnedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPinned", reflect.TypeOf((*MockPostRepository)(nil).SetPinned), ctx, postID, pinnedAt)
+}
+
+// Touch mocks base method.
+func (m *MockPostRepository) Touch(ctx context.Context, postID, updatedBy int, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, postID, updatedBy, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockPostRepositoryMockRecorder) Touch(ctx, postID, updatedBy, updatedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockPostRepository)(nil).Touch), ctx, postID, updatedBy, updatedAt)
+}
+
 // Update mocks base method.
 func (m *MockPostRepository) Update(ctx context.Context, post *domain.Post) error {
 	m.ctrl.T.Helper()
@@ -571,6 +743,36 @@ func (m *MockEventsRepository) EXPECT() *MockEventsRepositoryMockRecorder {
 	return m.recorder
 }
 
+// FindMessage mocks base method.
+func (m *MockEventsRepository) FindMessage(ctx context.Context, id string) (*domain.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMessage", ctx, id)
+	ret0, _ := ret[0].(*domain.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMessage indicates an expected call of FindMessage.
+func (mr *MockEventsRepositoryMockRecorder) FindMessage(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMessage", reflect.TypeOf((*MockEventsRepository)(nil).FindMessage), ctx, id)
+}
+
+// MarkRead mocks base method.
+func (m *MockEventsRepository) MarkRead(ctx context.Context, receipt *domain.ReadReceipt) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, receipt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockEventsRepositoryMockRecorder) MarkRead(ctx, receipt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockEventsRepository)(nil).MarkRead), ctx, receipt)
+}
+
 // SaveMessage mocks base method.
 func (m *MockEventsRepository) SaveMessage(message *domain.ChatMessage) error {
 	m.ctrl.T.Helper()
@@ -585,6 +787,20 @@ func (mr *MockEventsRepositoryMockRecorder) SaveMessage(message any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMessage", reflect.TypeOf((*MockEventsRepository)(nil).SaveMessage), message)
 }
 
+// UpdateMessage mocks base method.
+func (m *MockEventsRepository) UpdateMessage(ctx context.Context, message *domain.ChatMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMessage", ctx, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMessage indicates an expected call of UpdateMessage.
+func (mr *MockEventsRepositoryMockRecorder) UpdateMessage(ctx, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMessage", reflect.TypeOf((*MockEventsRepository)(nil).UpdateMessage), ctx, message)
+}
+
 // MockMessageRepository is a mock of MessageRepository interface.
 type MockMessageRepository struct {
 	ctrl     *gomock.Controller
@@ -609,6 +825,21 @@ func (m *MockMessageRepository) EXPECT() *MockMessageRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountUnreadBySpace mocks base method.
+func (m *MockMessageRepository) CountUnreadBySpace(ctx context.Context, userID int) ([]*domain.SpaceUnreadCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadBySpace", ctx, userID)
+	ret0, _ := ret[0].([]*domain.SpaceUnreadCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadBySpace indicates an expected call of CountUnreadBySpace.
+func (mr *MockMessageRepositoryMockRecorder) CountUnreadBySpace(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadBySpace", reflect.TypeOf((*MockMessageRepository)(nil).CountUnreadBySpace), ctx, userID)
+}
+
 // SearchMessages mocks base method.
 func (m *MockMessageRepository) SearchMessages(ctx context.Context, filters domain.SearchMessagesFilter) ([]*domain.ChatMessage, int, error) {
 	m.ctrl.T.Helper()
@@ -624,3 +855,978 @@ func (mr *MockMessageRepositoryMockRecorder) SearchMessages(ctx, filters any) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchMessages", reflect.TypeOf((*MockMessageRepository)(nil).SearchMessages), ctx, filters)
 }
+
+// SearchMessagesByCursor mocks base method.
+func (m *MockMessageRepository) SearchMessagesByCursor(ctx context.Context, filter domain.MessageCursorFilter) ([]*domain.ChatMessage, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchMessagesByCursor", ctx, filter)
+	ret0, _ := ret[0].([]*domain.ChatMessage)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchMessagesByCursor indicates an expected call of SearchMessagesByCursor.
+func (mr *MockMessageRepositoryMockRecorder) SearchMessagesByCursor(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchMessagesByCursor", reflect.TypeOf((*MockMessageRepository)(nil).SearchMessagesByCursor), ctx, filter)
+}
+
+// MockReactionRepository is a mock of ReactionRepository interface.
+type MockReactionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReactionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockReactionRepositoryMockRecorder is the mock recorder for MockReactionRepository.
+type MockReactionRepositoryMockRecorder struct {
+	mock *MockReactionRepository
+}
+
+// NewMockReactionRepository creates a new mock instance.
+func NewMockReactionRepository(ctrl *gomock.Controller) *MockReactionRepository {
+	mock := &MockReactionRepository{ctrl: ctrl}
+	mock.recorder = &MockReactionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReactionRepository) EXPECT() *MockReactionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddReaction mocks base method.
+func (m *MockReactionRepository) AddReaction(ctx context.Context, reaction *domain.Reaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReaction", ctx, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddReaction indicates an expected call of AddReaction.
+func (mr *MockReactionRepositoryMockRecorder) AddReaction(ctx, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReaction", reflect.TypeOf((*MockReactionRepository)(nil).AddReaction), ctx, reaction)
+}
+
+// CountReactions mocks base method.
+func (m *MockReactionRepository) CountReactions(ctx context.Context, arg1 *criteria.Criteria) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReactions", ctx, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReactions indicates an expected call of CountReactions.
+func (mr *MockReactionRepositoryMockRecorder) CountReactions(ctx, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReactions", reflect.TypeOf((*MockReactionRepository)(nil).CountReactions), ctx, arg1)
+}
+
+// DeleteByEntities mocks base method.
+func (m *MockReactionRepository) DeleteByEntities(ctx context.Context, entityType domain.EntityType, entityIDs []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByEntities", ctx, entityType, entityIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByEntities indicates an expected call of DeleteByEntities.
+func (mr *MockReactionRepositoryMockRecorder) DeleteByEntities(ctx, entityType, entityIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByEntities", reflect.TypeOf((*MockReactionRepository)(nil).DeleteByEntities), ctx, entityType, entityIDs)
+}
+
+// DeleteReaction mocks base method.
+func (m *MockReactionRepository) DeleteReaction(ctx context.Context, reactionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReaction", ctx, reactionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReaction indicates an expected call of DeleteReaction.
+func (mr *MockReactionRepositoryMockRecorder) DeleteReaction(ctx, reactionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReaction", reflect.TypeOf((*MockReactionRepository)(nil).DeleteReaction), ctx, reactionID)
+}
+
+// FindReaction mocks base method.
+func (m *MockReactionRepository) FindReaction(ctx context.Context, arg1 *criteria.Criteria) (*domain.Reaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReaction", ctx, arg1)
+	ret0, _ := ret[0].(*domain.Reaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReaction indicates an expected call of FindReaction.
+func (mr *MockReactionRepositoryMockRecorder) FindReaction(ctx, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReaction", reflect.TypeOf((*MockReactionRepository)(nil).FindReaction), ctx, arg1)
+}
+
+// FindReactionByID mocks base method.
+func (m *MockReactionRepository) FindReactionByID(ctx context.Context, reactionID string) (*domain.Reaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReactionByID", ctx, reactionID)
+	ret0, _ := ret[0].(*domain.Reaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReactionByID indicates an expected call of FindReactionByID.
+func (mr *MockReactionRepositoryMockRecorder) FindReactionByID(ctx, reactionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReactionByID", reflect.TypeOf((*MockReactionRepository)(nil).FindReactionByID), ctx, reactionID)
+}
+
+// UpdateReaction mocks base method.
+func (m *MockReactionRepository) UpdateReaction(ctx context.Context, reaction *domain.Reaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReaction", ctx, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReaction indicates an expected call of UpdateReaction.
+func (mr *MockReactionRepositoryMockRecorder) UpdateReaction(ctx, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReaction", reflect.TypeOf((*MockReactionRepository)(nil).UpdateReaction), ctx, reaction)
+}
+
+// MockNotificationRepository is a mock of NotificationRepository interface.
+type MockNotificationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationRepositoryMockRecorder is the mock recorder for MockNotificationRepository.
+type MockNotificationRepositoryMockRecorder struct {
+	mock *MockNotificationRepository
+}
+
+// NewMockNotificationRepository creates a new mock instance.
+func NewMockNotificationRepository(ctrl *gomock.Controller) *MockNotificationRepository {
+	mock := &MockNotificationRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationRepository) EXPECT() *MockNotificationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByEntities mocks base method.
+func (m *MockNotificationRepository) DeleteByEntities(ctx context.Context, entityType domain.EntityType, entityIDs []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByEntities", ctx, entityType, entityIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByEntities indicates an expected call of DeleteByEntities.
+func (mr *MockNotificationRepositoryMockRecorder) DeleteByEntities(ctx, entityType, entityIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByEntities", reflect.TypeOf((*MockNotificationRepository)(nil).DeleteByEntities), ctx, entityType, entityIDs)
+}
+
+// GetUnreadCount mocks base method.
+func (m *MockNotificationRepository) GetUnreadCount(ctx context.Context, userID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnreadCount", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnreadCount indicates an expected call of GetUnreadCount.
+func (mr *MockNotificationRepositoryMockRecorder) GetUnreadCount(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnreadCount", reflect.TypeOf((*MockNotificationRepository)(nil).GetUnreadCount), ctx, userID)
+}
+
+// GetUserNotifications mocks base method.
+func (m *MockNotificationRepository) GetUserNotifications(ctx context.Context, userID, limit, offset int) ([]*domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserNotifications", ctx, userID, limit, offset)
+	ret0, _ := ret[0].([]*domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserNotifications indicates an expected call of GetUserNotifications.
+func (mr *MockNotificationRepositoryMockRecorder) GetUserNotifications(ctx, userID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).GetUserNotifications), ctx, userID, limit, offset)
+}
+
+// MarkAllAsRead mocks base method.
+func (m *MockNotificationRepository) MarkAllAsRead(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllAsRead", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAllAsRead indicates an expected call of MarkAllAsRead.
+func (mr *MockNotificationRepositoryMockRecorder) MarkAllAsRead(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllAsRead", reflect.TypeOf((*MockNotificationRepository)(nil).MarkAllAsRead), ctx, userID)
+}
+
+// MarkAsRead mocks base method.
+func (m *MockNotificationRepository) MarkAsRead(ctx context.Context, userID int, notificationID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsRead", ctx, userID, notificationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsRead indicates an expected call of MarkAsRead.
+func (mr *MockNotificationRepositoryMockRecorder) MarkAsRead(ctx, userID, notificationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsRead", reflect.TypeOf((*MockNotificationRepository)(nil).MarkAsRead), ctx, userID, notificationID)
+}
+
+// RetractActor mocks base method.
+func (m *MockNotificationRepository) RetractActor(ctx context.Context, target *domain.Notification, actorID int) ([]*domain.NotificationRetraction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetractActor", ctx, target, actorID)
+	ret0, _ := ret[0].([]*domain.NotificationRetraction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetractActor indicates an expected call of RetractActor.
+func (mr *MockNotificationRepositoryMockRecorder) RetractActor(ctx, target, actorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetractActor", reflect.TypeOf((*MockNotificationRepository)(nil).RetractActor), ctx, target, actorID)
+}
+
+// SaveNotification mocks base method.
+func (m *MockNotificationRepository) SaveNotification(ctx context.Context, notification *domain.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveNotification", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveNotification indicates an expected call of SaveNotification.
+func (mr *MockNotificationRepositoryMockRecorder) SaveNotification(ctx, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNotification", reflect.TypeOf((*MockNotificationRepository)(nil).SaveNotification), ctx, notification)
+}
+
+// SaveNotifications mocks base method.
+func (m *MockNotificationRepository) SaveNotifications(ctx context.Context, notifications []*domain.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveNotifications", ctx, notifications)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveNotifications indicates an expected call of SaveNotifications.
+func (mr *MockNotificationRepositoryMockRecorder) SaveNotifications(ctx, notifications any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).SaveNotifications), ctx, notifications)
+}
+
+// MockNotificationDeliveryRepository is a mock of NotificationDeliveryRepository interface.
+type MockNotificationDeliveryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationDeliveryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationDeliveryRepositoryMockRecorder is the mock recorder for MockNotificationDeliveryRepository.
+type MockNotificationDeliveryRepositoryMockRecorder struct {
+	mock *MockNotificationDeliveryRepository
+}
+
+// NewMockNotificationDeliveryRepository creates a new mock instance.
+func NewMockNotificationDeliveryRepository(ctrl *gomock.Controller) *MockNotificationDeliveryRepository {
+	mock := &MockNotificationDeliveryRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationDeliveryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationDeliveryRepository) EXPECT() *MockNotificationDeliveryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindDueDeliveries mocks base method.
+func (m *MockNotificationDeliveryRepository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.NotificationDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDueDeliveries", ctx, now, limit)
+	ret0, _ := ret[0].([]*domain.NotificationDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDueDeliveries indicates an expected call of FindDueDeliveries.
+func (mr *MockNotificationDeliveryRepositoryMockRecorder) FindDueDeliveries(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDueDeliveries", reflect.TypeOf((*MockNotificationDeliveryRepository)(nil).FindDueDeliveries), ctx, now, limit)
+}
+
+// MarkDelivered mocks base method.
+func (m *MockNotificationDeliveryRepository) MarkDelivered(ctx context.Context, userID int, notificationID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDelivered", ctx, userID, notificationID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkDelivered indicates an expected call of MarkDelivered.
+func (mr *MockNotificationDeliveryRepositoryMockRecorder) MarkDelivered(ctx, userID, notificationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDelivered", reflect.TypeOf((*MockNotificationDeliveryRepository)(nil).MarkDelivered), ctx, userID, notificationID)
+}
+
+// RescheduleUser mocks base method.
+func (m *MockNotificationDeliveryRepository) RescheduleUser(ctx context.Context, userID int, nextAttemptAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleUser", ctx, userID, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleUser indicates an expected call of RescheduleUser.
+func (mr *MockNotificationDeliveryRepositoryMockRecorder) RescheduleUser(ctx, userID, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleUser", reflect.TypeOf((*MockNotificationDeliveryRepository)(nil).RescheduleUser), ctx, userID, nextAttemptAt)
+}
+
+// ScheduleRetry mocks base method.
+func (m *MockNotificationDeliveryRepository) ScheduleRetry(ctx context.Context, delivery *domain.NotificationDelivery, nextAttemptAt *time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleRetry", ctx, delivery, nextAttemptAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleRetry indicates an expected call of ScheduleRetry.
+func (mr *MockNotificationDeliveryRepositoryMockRecorder) ScheduleRetry(ctx, delivery, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleRetry", reflect.TypeOf((*MockNotificationDeliveryRepository)(nil).ScheduleRetry), ctx, delivery, nextAttemptAt)
+}
+
+// MockNotificationDigestRepository is a mock of NotificationDigestRepository interface.
+type MockNotificationDigestRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationDigestRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationDigestRepositoryMockRecorder is the mock recorder for MockNotificationDigestRepository.
+type MockNotificationDigestRepositoryMockRecorder struct {
+	mock *MockNotificationDigestRepository
+}
+
+// NewMockNotificationDigestRepository creates a new mock instance.
+func NewMockNotificationDigestRepository(ctrl *gomock.Controller) *MockNotificationDigestRepository {
+	mock := &MockNotificationDigestRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationDigestRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationDigestRepository) EXPECT() *MockNotificationDigestRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindDigest mocks base method.
+func (m *MockNotificationDigestRepository) FindDigest(ctx context.Context, userID, limit int) ([]*domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDigest", ctx, userID, limit)
+	ret0, _ := ret[0].([]*domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDigest indicates an expected call of FindDigest.
+func (mr *MockNotificationDigestRepositoryMockRecorder) FindDigest(ctx, userID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDigest", reflect.TypeOf((*MockNotificationDigestRepository)(nil).FindDigest), ctx, userID, limit)
+}
+
+// FindDigestUserIDs mocks base method.
+func (m *MockNotificationDigestRepository) FindDigestUserIDs(ctx context.Context, afterUserID, limit int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDigestUserIDs", ctx, afterUserID, limit)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDigestUserIDs indicates an expected call of FindDigestUserIDs.
+func (mr *MockNotificationDigestRepositoryMockRecorder) FindDigestUserIDs(ctx, afterUserID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDigestUserIDs", reflect.TypeOf((*MockNotificationDigestRepository)(nil).FindDigestUserIDs), ctx, afterUserID, limit)
+}
+
+// MarkDigested mocks base method.
+func (m *MockNotificationDigestRepository) MarkDigested(ctx context.Context, userID int, notificationIDs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDigested", ctx, userID, notificationIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDigested indicates an expected call of MarkDigested.
+func (mr *MockNotificationDigestRepositoryMockRecorder) MarkDigested(ctx, userID, notificationIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDigested", reflect.TypeOf((*MockNotificationDigestRepository)(nil).MarkDigested), ctx, userID, notificationIDs)
+}
+
+// MockNotificationPreferenceRepository is a mock of NotificationPreferenceRepository interface.
+type MockNotificationPreferenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationPreferenceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationPreferenceRepositoryMockRecorder is the mock recorder for MockNotificationPreferenceRepository.
+type MockNotificationPreferenceRepositoryMockRecorder struct {
+	mock *MockNotificationPreferenceRepository
+}
+
+// NewMockNotificationPreferenceRepository creates a new mock instance.
+func NewMockNotificationPreferenceRepository(ctrl *gomock.Controller) *MockNotificationPreferenceRepository {
+	mock := &MockNotificationPreferenceRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationPreferenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationPreferenceRepository) EXPECT() *MockNotificationPreferenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockNotificationPreferenceRepository) Delete(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockNotificationPreferenceRepositoryMockRecorder) Delete(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNotificationPreferenceRepository)(nil).Delete), ctx, userID)
+}
+
+// Find mocks base method.
+func (m *MockNotificationPreferenceRepository) Find(ctx context.Context, userID int) (*domain.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, userID)
+	ret0, _ := ret[0].(*domain.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockNotificationPreferenceRepositoryMockRecorder) Find(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockNotificationPreferenceRepository)(nil).Find), ctx, userID)
+}
+
+// FindByUserIDs mocks base method.
+func (m *MockNotificationPreferenceRepository) FindByUserIDs(ctx context.Context, userIDs []int) ([]*domain.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserIDs", ctx, userIDs)
+	ret0, _ := ret[0].([]*domain.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserIDs indicates an expected call of FindByUserIDs.
+func (mr *MockNotificationPreferenceRepositoryMockRecorder) FindByUserIDs(ctx, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserIDs", reflect.TypeOf((*MockNotificationPreferenceRepository)(nil).FindByUserIDs), ctx, userIDs)
+}
+
+// Mute mocks base method.
+func (m *MockNotificationPreferenceRepository) Mute(ctx context.Context, userID int, entityType domain.EntityType, entityID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Mute", ctx, userID, entityType, entityID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Mute indicates an expected call of Mute.
+func (mr *MockNotificationPreferenceRepositoryMockRecorder) Mute(ctx, userID, entityType, entityID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mute", reflect.TypeOf((*MockNotificationPreferenceRepository)(nil).Mute), ctx, userID, entityType, entityID)
+}
+
+// Save mocks base method.
+func (m *MockNotificationPreferenceRepository) Save(ctx context.Context, preferences *domain.NotificationPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockNotificationPreferenceRepositoryMockRecorder) Save(ctx, preferences any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockNotificationPreferenceRepository)(nil).Save), ctx, preferences)
+}
+
+// Unmute mocks base method.
+func (m *MockNotificationPreferenceRepository) Unmute(ctx context.Context, userID int, entityType domain.EntityType, entityID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unmute", ctx, userID, entityType, entityID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unmute indicates an expected call of Unmute.
+func (mr *MockNotificationPreferenceRepositoryMockRecorder) Unmute(ctx, userID, entityType, entityID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unmute", reflect.TypeOf((*MockNotificationPreferenceRepository)(nil).Unmute), ctx, userID, entityType, entityID)
+}
+
+// MockRefreshTokenRepository is a mock of RefreshTokenRepository interface.
+type MockRefreshTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshTokenRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRefreshTokenRepositoryMockRecorder is the mock recorder for MockRefreshTokenRepository.
+type MockRefreshTokenRepositoryMockRecorder struct {
+	mock *MockRefreshTokenRepository
+}
+
+// NewMockRefreshTokenRepository creates a new mock instance.
+func NewMockRefreshTokenRepository(ctrl *gomock.Controller) *MockRefreshTokenRepository {
+	mock := &MockRefreshTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefreshTokenRepository) EXPECT() *MockRefreshTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRefreshTokenRepositoryMockRecorder) Create(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRefreshTokenRepository)(nil).Create), ctx, token)
+}
+
+// FindByHash mocks base method.
+func (m *MockRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*domain.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByHash indicates an expected call of FindByHash.
+func (mr *MockRefreshTokenRepositoryMockRecorder) FindByHash(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByHash", reflect.TypeOf((*MockRefreshTokenRepository)(nil).FindByHash), ctx, tokenHash)
+}
+
+// IsFamilyActive mocks base method.
+func (m *MockRefreshTokenRepository) IsFamilyActive(ctx context.Context, userID int, familyID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsFamilyActive", ctx, userID, familyID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsFamilyActive indicates an expected call of IsFamilyActive.
+func (mr *MockRefreshTokenRepositoryMockRecorder) IsFamilyActive(ctx, userID, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsFamilyActive", reflect.TypeOf((*MockRefreshTokenRepository)(nil).IsFamilyActive), ctx, userID, familyID)
+}
+
+// MarkRotated mocks base method.
+func (m *MockRefreshTokenRepository) MarkRotated(ctx context.Context, id, replacedBy string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRotated", ctx, id, replacedBy)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRotated indicates an expected call of MarkRotated.
+func (mr *MockRefreshTokenRepositoryMockRecorder) MarkRotated(ctx, id, replacedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRotated", reflect.TypeOf((*MockRefreshTokenRepository)(nil).MarkRotated), ctx, id, replacedBy)
+}
+
+// RevokeAllByUser mocks base method.
+func (m *MockRefreshTokenRepository) RevokeAllByUser(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllByUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllByUser indicates an expected call of RevokeAllByUser.
+func (mr *MockRefreshTokenRepositoryMockRecorder) RevokeAllByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllByUser", reflect.TypeOf((*MockRefreshTokenRepository)(nil).RevokeAllByUser), ctx, userID)
+}
+
+// RevokeAllByUserExcept mocks base method.
+func (m *MockRefreshTokenRepository) RevokeAllByUserExcept(ctx context.Context, userID int, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllByUserExcept", ctx, userID, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllByUserExcept indicates an expected call of RevokeAllByUserExcept.
+func (mr *MockRefreshTokenRepositoryMockRecorder) RevokeAllByUserExcept(ctx, userID, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllByUserExcept", reflect.TypeOf((*MockRefreshTokenRepository)(nil).RevokeAllByUserExcept), ctx, userID, familyID)
+}
+
+// RevokeFamily mocks base method.
+func (m *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, userID int, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", ctx, userID, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockRefreshTokenRepositoryMockRecorder) RevokeFamily(ctx, userID, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockRefreshTokenRepository)(nil).RevokeFamily), ctx, userID, familyID)
+}
+
+// MockUserTokenRepository is a mock of UserTokenRepository interface.
+type MockUserTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserTokenRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserTokenRepositoryMockRecorder is the mock recorder for MockUserTokenRepository.
+type MockUserTokenRepositoryMockRecorder struct {
+	mock *MockUserTokenRepository
+}
+
+// NewMockUserTokenRepository creates a new mock instance.
+func NewMockUserTokenRepository(ctrl *gomock.Controller) *MockUserTokenRepository {
+	mock := &MockUserTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockUserTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserTokenRepository) EXPECT() *MockUserTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockUserTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserTokenRepositoryMockRecorder) Create(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserTokenRepository)(nil).Create), ctx, token)
+}
+
+// FindByHash mocks base method.
+func (m *MockUserTokenRepository) FindByHash(ctx context.Context, purpose domain.UserTokenPurpose, tokenHash string) (*domain.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByHash", ctx, purpose, tokenHash)
+	ret0, _ := ret[0].(*domain.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByHash indicates an expected call of FindByHash.
+func (mr *MockUserTokenRepositoryMockRecorder) FindByHash(ctx, purpose, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByHash", reflect.TypeOf((*MockUserTokenRepository)(nil).FindByHash), ctx, purpose, tokenHash)
+}
+
+// InvalidateByUser mocks base method.
+func (m *MockUserTokenRepository) InvalidateByUser(ctx context.Context, userID int, purpose domain.UserTokenPurpose) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateByUser", ctx, userID, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateByUser indicates an expected call of InvalidateByUser.
+func (mr *MockUserTokenRepositoryMockRecorder) InvalidateByUser(ctx, userID, purpose any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateByUser", reflect.TypeOf((*MockUserTokenRepository)(nil).InvalidateByUser), ctx, userID, purpose)
+}
+
+// MarkUsed mocks base method.
+func (m *MockUserTokenRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUsed indicates an expected call of MarkUsed.
+func (mr *MockUserTokenRepositoryMockRecorder) MarkUsed(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockUserTokenRepository)(nil).MarkUsed), ctx, id)
+}
+
+// MockSpaceInvitationRepository is a mock of SpaceInvitationRepository interface.
+type MockSpaceInvitationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSpaceInvitationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSpaceInvitationRepositoryMockRecorder is the mock recorder for MockSpaceInvitationRepository.
+type MockSpaceInvitationRepositoryMockRecorder struct {
+	mock *MockSpaceInvitationRepository
+}
+
+// NewMockSpaceInvitationRepository creates a new mock instance.
+func NewMockSpaceInvitationRepository(ctrl *gomock.Controller) *MockSpaceInvitationRepository {
+	mock := &MockSpaceInvitationRepository{ctrl: ctrl}
+	mock.recorder = &MockSpaceInvitationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSpaceInvitationRepository) EXPECT() *MockSpaceInvitationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSpaceInvitationRepository) Create(ctx context.Context, invitation *domain.SpaceInvitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, invitation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSpaceInvitationRepositoryMockRecorder) Create(ctx, invitation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSpaceInvitationRepository)(nil).Create), ctx, invitation)
+}
+
+// FindActiveByInviteeID mocks base method.
+func (m *MockSpaceInvitationRepository) FindActiveByInviteeID(ctx context.Context, userID int) ([]*domain.SpaceInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActiveByInviteeID", ctx, userID)
+	ret0, _ := ret[0].([]*domain.SpaceInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActiveByInviteeID indicates an expected call of FindActiveByInviteeID.
+func (mr *MockSpaceInvitationRepositoryMockRecorder) FindActiveByInviteeID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActiveByInviteeID", reflect.TypeOf((*MockSpaceInvitationRepository)(nil).FindActiveByInviteeID), ctx, userID)
+}
+
+// FindActiveBySpaceID mocks base method.
+func (m *MockSpaceInvitationRepository) FindActiveBySpaceID(ctx context.Context, spaceID int) ([]*domain.SpaceInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActiveBySpaceID", ctx, spaceID)
+	ret0, _ := ret[0].([]*domain.SpaceInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActiveBySpaceID indicates an expected call of FindActiveBySpaceID.
+func (mr *MockSpaceInvitationRepositoryMockRecorder) FindActiveBySpaceID(ctx, spaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActiveBySpaceID", reflect.TypeOf((*MockSpaceInvitationRepository)(nil).FindActiveBySpaceID), ctx, spaceID)
+}
+
+// FindByID mocks base method.
+func (m *MockSpaceInvitationRepository) FindByID(ctx context.Context, id string) (*domain.SpaceInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*domain.SpaceInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockSpaceInvitationRepositoryMockRecorder) FindByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockSpaceInvitationRepository)(nil).FindByID), ctx, id)
+}
+
+// FindByTokenHash mocks base method.
+func (m *MockSpaceInvitationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.SpaceInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*domain.SpaceInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTokenHash indicates an expected call of FindByTokenHash.
+func (mr *MockSpaceInvitationRepositoryMockRecorder) FindByTokenHash(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTokenHash", reflect.TypeOf((*MockSpaceInvitationRepository)(nil).FindByTokenHash), ctx, tokenHash)
+}
+
+// IncrementUses mocks base method.
+func (m *MockSpaceInvitationRepository) IncrementUses(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementUses", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementUses indicates an expected call of IncrementUses.
+func (mr *MockSpaceInvitationRepositoryMockRecorder) IncrementUses(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementUses", reflect.TypeOf((*MockSpaceInvitationRepository)(nil).IncrementUses), ctx, id)
+}
+
+// Revoke mocks base method.
+func (m *MockSpaceInvitationRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockSpaceInvitationRepositoryMockRecorder) Revoke(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockSpaceInvitationRepository)(nil).Revoke), ctx, id)
+}
+
+// MockJoinRequestRepository is a mock of JoinRequestRepository interface.
+type MockJoinRequestRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockJoinRequestRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockJoinRequestRepositoryMockRecorder is the mock recorder for MockJoinRequestRepository.
+type MockJoinRequestRepositoryMockRecorder struct {
+	mock *MockJoinRequestRepository
+}
+
+// NewMockJoinRequestRepository creates a new mock instance.
+func NewMockJoinRequestRepository(ctrl *gomock.Controller) *MockJoinRequestRepository {
+	mock := &MockJoinRequestRepository{ctrl: ctrl}
+	mock.recorder = &MockJoinRequestRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJoinRequestRepository) EXPECT() *MockJoinRequestRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockJoinRequestRepository) Create(ctx context.Context, request *domain.JoinRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockJoinRequestRepositoryMockRecorder) Create(ctx, request any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockJoinRequestRepository)(nil).Create), ctx, request)
+}
+
+// FindByID mocks base method.
+func (m *MockJoinRequestRepository) FindByID(ctx context.Context, id string) (*domain.JoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*domain.JoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockJoinRequestRepositoryMockRecorder) FindByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockJoinRequestRepository)(nil).FindByID), ctx, id)
+}
+
+// FindPending mocks base method.
+func (m *MockJoinRequestRepository) FindPending(ctx context.Context, spaceID, userID int) (*domain.JoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPending", ctx, spaceID, userID)
+	ret0, _ := ret[0].(*domain.JoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPending indicates an expected call of FindPending.
+func (mr *MockJoinRequestRepositoryMockRecorder) FindPending(ctx, spaceID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPending", reflect.TypeOf((*MockJoinRequestRepository)(nil).FindPending), ctx, spaceID, userID)
+}
+
+// FindPendingBySpaceID mocks base method.
+func (m *MockJoinRequestRepository) FindPendingBySpaceID(ctx context.Context, spaceID int) ([]*domain.JoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPendingBySpaceID", ctx, spaceID)
+	ret0, _ := ret[0].([]*domain.JoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPendingBySpaceID indicates an expected call of FindPendingBySpaceID.
+func (mr *MockJoinRequestRepositoryMockRecorder) FindPendingBySpaceID(ctx, spaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPendingBySpaceID", reflect.TypeOf((*MockJoinRequestRepository)(nil).FindPendingBySpaceID), ctx, spaceID)
+}
+
+// Review mocks base method.
+func (m *MockJoinRequestRepository) Review(ctx context.Context, id string, status domain.JoinRequestStatus, reviewerID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Review", ctx, id, status, reviewerID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Review indicates an expected call of Review.
+func (mr *MockJoinRequestRepositoryMockRecorder) Review(ctx, id, status, reviewerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Review", reflect.TypeOf((*MockJoinRequestRepository)(nil).Review), ctx, id, status, reviewerID)
+}
@@ -1,11 +1,25 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type NotificationType string
 
 const (
-	NotificationTypeReaction NotificationType = "reaction"
+	NotificationTypeReaction            NotificationType = "reaction"
+	NotificationTypeSpaceInvitation     NotificationType = "space_invitation"
+	NotificationTypeInvitationAccepted  NotificationType = "invitation_accepted"
+	NotificationTypeInvitationDeclined  NotificationType = "invitation_declined"
+	NotificationTypeInvitationRevoked   NotificationType = "invitation_revoked"
+	NotificationTypeJoinRequest         NotificationType = "join_request"
+	NotificationTypeJoinRequestApproved NotificationType = "join_request_approved"
+	NotificationTypeJoinRequestRejected NotificationType = "join_request_rejected"
+	NotificationTypeComment             NotificationType = "comment"
+	NotificationTypeReply               NotificationType = "reply"
+	NotificationTypeMention             NotificationType = "mention"
+	NotificationTypeNewPost             NotificationType = "new_post"
 )
 
 type Notification struct {
@@ -15,6 +29,56 @@ type Notification struct {
 	EntityID   int
 	PostID     *int
 	UserID     int
-	Read       bool
-	CreatedAt  time.Time
+	// Action is the reaction behind a reaction notification, likes and dislikes group apart
+	Action ActionType
+	// ActorID is the user whose action triggered the notification, the latest one when grouped
+	ActorID int
+	// ActorIDs are the distinct actors, most recent first. Repositories return a sample.
+	ActorIDs   []int
+	ActorCount int
+	// Actors are the profiles of ActorIDs, only loaded when listing notifications
+	Actors    []*User
+	Read      bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeliverAt is when the push is due, later during quiet hours and nil when push is off
+	DeliverAt *time.Time
+	// EmailDigest marks the notification for the user's email digest
+	EmailDigest bool
+	// DigestOnly notifications are kept for the email digest, the inbox does not list them
+	DigestOnly bool
+}
+
+// NotificationActorSample is how many actors are returned with a grouped notification
+const NotificationActorSample = 3
+
+// GroupKey identifies the unread notifications that collapse into one, such as the
+// reactions to a post or the comments on it. It is empty for types that are never grouped.
+func (n *Notification) GroupKey() string {
+	switch n.Type {
+	case NotificationTypeReaction:
+		return fmt.Sprintf("%s:%s:%s:%d", n.Type, n.Action, n.EntityType, n.EntityID)
+	case NotificationTypeComment:
+		if n.PostID != nil {
+			return fmt.Sprintf("%s:%s:%d", n.Type, EntityTypePost, *n.PostID)
+		}
+	}
+	return ""
+}
+
+// NotificationRetraction is what is left of a notification after an actor undid their
+// action. Deleted is set when no actor was left and the notification was removed.
+type NotificationRetraction struct {
+	Notification *Notification
+	Deleted      bool
+	UnreadCount  int
+}
+
+// NotificationDelivery is the pending push of a notification to the user's sockets.
+// NextAttemptAt is nil once the attempts run out, the delivery waits for the user
+// to connect again.
+type NotificationDelivery struct {
+	Notification  *Notification
+	Attempts      int
+	NextAttemptAt *time.Time
 }
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// NotificationChannels are where a type of notification reaches the user. Push
+// needs InApp, with only EmailDigest on the notification is kept for the digest.
+type NotificationChannels struct {
+	InApp       bool
+	Push        bool
+	EmailDigest bool
+}
+
+// DefaultNotificationChannels applies to every type the user never configured
+var DefaultNotificationChannels = NotificationChannels{InApp: true, Push: true}
+
+// DefaultQuietHoursTimezone is used when the user does not send one
+const DefaultQuietHoursTimezone = "America/Argentina/Buenos_Aires"
+
+// QuietHours holds pushes back between Start and End, in minutes since midnight
+// in the user's Timezone. End before Start spans midnight.
+type QuietHours struct {
+	Start    int
+	End      int
+	Timezone string
+}
+
+// Until returns when the quiet hours that contain t are over
+func (q *QuietHours) Until(t time.Time) (time.Time, bool) {
+	location, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := t.In(location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	minute := local.Hour()*60 + local.Minute()
+
+	switch {
+	case q.Start == q.End:
+		return time.Time{}, false
+	case q.Start < q.End && minute >= q.Start && minute < q.End:
+		return midnight.Add(time.Duration(q.End) * time.Minute), true
+	case q.Start > q.End && minute >= q.Start:
+		return midnight.AddDate(0, 0, 1).Add(time.Duration(q.End) * time.Minute), true
+	case q.Start > q.End && minute < q.End:
+		return midnight.Add(time.Duration(q.End) * time.Minute), true
+	}
+
+	return time.Time{}, false
+}
+
+type NotificationPreferences struct {
+	UserID        int
+	Types         map[NotificationType]NotificationChannels
+	MutedSpaceIDs []int
+	MutedPostIDs  []int
+	QuietHours    *QuietHours
+	UpdatedAt     time.Time
+}
+
+// NewNotificationPreferences returns the defaults of a user who never changed them
+func NewNotificationPreferences(userID int) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID: userID,
+		Types:  map[NotificationType]NotificationChannels{},
+	}
+}
+
+func (p *NotificationPreferences) Channels(notificationType NotificationType) NotificationChannels {
+	if channels, ok := p.Types[notificationType]; ok {
+		return channels
+	}
+	return DefaultNotificationChannels
+}
+
+// IsMuted reports whether the notification comes from a muted space or post
+func (p *NotificationPreferences) IsMuted(spaceID int, postID *int) bool {
+	if spaceID != 0 && slices.Contains(p.MutedSpaceIDs, spaceID) {
+		return true
+	}
+	return postID != nil && slices.Contains(p.MutedPostIDs, *postID)
+}
+
+// NotificationTypes are the types a user can configure
+var NotificationTypes = []NotificationType{
+	NotificationTypeReaction,
+	NotificationTypeComment,
+	NotificationTypeReply,
+	NotificationTypeMention,
+	NotificationTypeNewPost,
+	NotificationTypeSpaceInvitation,
+	NotificationTypeInvitationAccepted,
+	NotificationTypeInvitationDeclined,
+	NotificationTypeInvitationRevoked,
+	NotificationTypeJoinRequest,
+	NotificationTypeJoinRequestApproved,
+	NotificationTypeJoinRequestRejected,
+}
+
+func IsValidNotificationType(notificationType string) bool {
+	return slices.Contains(NotificationTypes, NotificationType(notificationType))
+}
@@ -12,6 +12,7 @@ type Post struct {
 	CreatedBy int
 	UpdatedBy int
 	SpaceID   int
+	PinnedAt  *time.Time
 	Comments  []Comment
 }
 
@@ -18,6 +18,8 @@ type EntityType string
 const (
 	EntityTypePost    EntityType = "post"
 	EntityTypeComment EntityType = "comment"
+	// EntityTypeSpace is only used by notifications, spaces cannot be reacted to
+	EntityTypeSpace EntityType = "space"
 )
 
 func IsValidEntityType(entityType string) bool {
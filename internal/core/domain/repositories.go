@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"cpi-hub-api/internal/core/domain/criteria"
+	"time"
 )
 
 //go:generate mockgen -package=mock -source=./repositories.go -destination=./mock/repositories_mock.go
@@ -12,6 +13,7 @@ type UserRepository interface {
 	Search(ctx context.Context, criteria *criteria.Criteria) ([]*User, error)
 	Count(ctx context.Context, criteria *criteria.Criteria) (int, error)
 	Update(ctx context.Context, user *User) error
+	UpdateLastSeen(ctx context.Context, userID int, lastSeenAt time.Time) error
 }
 
 type SpaceRepository interface {
@@ -19,8 +21,13 @@ type SpaceRepository interface {
 	Find(ctx context.Context, criteria *criteria.Criteria) (*Space, error)
 	FindByIDs(ctx context.Context, ids []int) ([]*Space, error)
 	FindAll(ctx context.Context, criteria *criteria.Criteria) ([]*Space, error)
+	// Update saves the editable fields, the archive state only changes through SetArchived
 	Update(ctx context.Context, space *Space) error
+	SetArchived(ctx context.Context, spaceID int, archivedAt *time.Time, updatedBy int, updatedAt time.Time) error
+	// Touch records activity in the space without writing back any other field
+	Touch(ctx context.Context, spaceID int, updatedBy int, updatedAt time.Time) error
 	Count(ctx context.Context, criteria *criteria.Criteria) (int, error)
+	Delete(ctx context.Context, spaceID int) error
 }
 
 type UserSpaceRepository interface {
@@ -29,12 +36,22 @@ type UserSpaceRepository interface {
 	FindUserIDsBySpaceID(ctx context.Context, spaceID int) ([]int, error)
 	Exists(ctx context.Context, userId int, spaceId int) (bool, error)
 	Count(ctx context.Context, criteria *criteria.Criteria) (int, error)
+	AddMember(ctx context.Context, userID int, spaceID int, role SpaceRole) error
+	FindRole(ctx context.Context, userID int, spaceID int) (SpaceRole, error)
+	FindMembersBySpaceID(ctx context.Context, spaceID int) ([]*SpaceMembership, error)
+	CountByRole(ctx context.Context, spaceID int) (map[SpaceRole]int, error)
+	UpdateRole(ctx context.Context, userID int, spaceID int, role SpaceRole) error
+	TransferOwnership(ctx context.Context, spaceID int, fromUserID int, toUserID int) error
 }
 
 type PostRepository interface {
 	Create(ctx context.Context, post *Post) error
 	Find(ctx context.Context, criteria *criteria.Criteria) (*Post, error)
+	// Update saves the editable fields, the pin only changes through SetPinned
 	Update(ctx context.Context, post *Post) error
+	SetPinned(ctx context.Context, postID int, pinnedAt *time.Time) error
+	// Touch records activity in the post without writing back any other field
+	Touch(ctx context.Context, postID int, updatedBy int, updatedAt time.Time) error
 	Search(ctx context.Context, criteria *criteria.Criteria) ([]*Post, error)
 	Count(ctx context.Context, criteria *criteria.Criteria) (int, error)
 	Delete(ctx context.Context, postID int) error
@@ -51,6 +68,10 @@ type CommentRepository interface {
 
 type EventsRepository interface {
 	SaveMessage(message *ChatMessage) error
+	FindMessage(ctx context.Context, id string) (*ChatMessage, error)
+	UpdateMessage(ctx context.Context, message *ChatMessage) error
+	// MarkRead only moves the read receipt forward, it reports whether it changed
+	MarkRead(ctx context.Context, receipt *ReadReceipt) (bool, error)
 }
 
 type SearchMessagesFilter struct {
@@ -61,23 +82,109 @@ type SearchMessagesFilter struct {
 	SortDirection string
 }
 
+// MessageCursorFilter pages through the chat history by message ID, ULIDs sort by time.
+// Before and After are exclusive, when neither is set the newest messages are returned.
+type MessageCursorFilter struct {
+	SpaceID int
+	Before  string
+	After   string
+	Limit   int
+}
+
 type MessageRepository interface {
 	SearchMessages(ctx context.Context, filters SearchMessagesFilter) ([]*ChatMessage, int, error)
+	// SearchMessagesByCursor returns the page in chronological order and whether more messages follow it
+	SearchMessagesByCursor(ctx context.Context, filter MessageCursorFilter) ([]*ChatMessage, bool, error)
+	// CountUnreadBySpace returns one entry per space the user belongs to
+	CountUnreadBySpace(ctx context.Context, userID int) ([]*SpaceUnreadCount, error)
 }
 
 type ReactionRepository interface {
 	AddReaction(ctx context.Context, reaction *Reaction) error
 	FindReaction(ctx context.Context, criteria *criteria.Criteria) (*Reaction, error)
+	FindReactionByID(ctx context.Context, reactionID string) (*Reaction, error)
 	DeleteReaction(ctx context.Context, reactionID string) error
 	UpdateReaction(ctx context.Context, reaction *Reaction) error
 	CountReactions(ctx context.Context, criteria *criteria.Criteria) (int, error)
+	DeleteByEntities(ctx context.Context, entityType EntityType, entityIDs []int) error
 	// GetReactions(ctx context.Context, criteria *criteria.Criteria) ([]*Reaction, error)
 }
 
 type NotificationRepository interface {
 	SaveNotification(ctx context.Context, notification *Notification) error
+	// SaveNotifications inserts ungrouped notifications in one batch, with their pending deliveries
+	SaveNotifications(ctx context.Context, notifications []*Notification) error
 	GetUserNotifications(ctx context.Context, userID int, limit, offset int) ([]*Notification, error)
-	MarkAsRead(ctx context.Context, notificationID string) error
+	// RetractActor removes the actor from the recipient's notifications matching the target and deletes the ones left without actors
+	RetractActor(ctx context.Context, target *Notification, actorID int) ([]*NotificationRetraction, error)
+	MarkAsRead(ctx context.Context, userID int, notificationID string) error
 	MarkAllAsRead(ctx context.Context, userID int) error
 	GetUnreadCount(ctx context.Context, userID int) (int, error)
+	DeleteByEntities(ctx context.Context, entityType EntityType, entityIDs []int) error
+}
+
+// NotificationDeliveryRepository is the outbox of notifications still to be pushed.
+// SaveNotification writes the pending delivery in the same document.
+type NotificationDeliveryRepository interface {
+	FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*NotificationDelivery, error)
+	// ScheduleRetry claims the attempt, it fails when another dispatcher already took it
+	ScheduleRetry(ctx context.Context, delivery *NotificationDelivery, nextAttemptAt *time.Time) (bool, error)
+	MarkDelivered(ctx context.Context, userID int, notificationID string) (bool, error)
+	// RescheduleUser makes the user's deliveries that were already attempted due again from the first attempt
+	RescheduleUser(ctx context.Context, userID int, nextAttemptAt time.Time) error
+}
+
+// NotificationDigestRepository holds the notifications waiting for the user's email digest
+type NotificationDigestRepository interface {
+	// FindDigestUserIDs returns, in ascending order, the users after afterUserID with notifications waiting
+	FindDigestUserIDs(ctx context.Context, afterUserID int, limit int) ([]int, error)
+	FindDigest(ctx context.Context, userID int, limit int) ([]*Notification, error)
+	MarkDigested(ctx context.Context, userID int, notificationIDs []string) error
+}
+
+// NotificationPreferenceRepository returns nil preferences for users who never set them
+type NotificationPreferenceRepository interface {
+	Find(ctx context.Context, userID int) (*NotificationPreferences, error)
+	// FindByUserIDs leaves out the users who never set their preferences
+	FindByUserIDs(ctx context.Context, userIDs []int) ([]*NotificationPreferences, error)
+	Save(ctx context.Context, preferences *NotificationPreferences) error
+	Delete(ctx context.Context, userID int) error
+	Mute(ctx context.Context, userID int, entityType EntityType, entityID int) error
+	Unmute(ctx context.Context, userID int, entityType EntityType, entityID int) (bool, error)
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *RefreshToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	MarkRotated(ctx context.Context, id string, replacedBy string) (bool, error)
+	RevokeFamily(ctx context.Context, userID int, familyID string) error
+	RevokeAllByUser(ctx context.Context, userID int) error
+	RevokeAllByUserExcept(ctx context.Context, userID int, familyID string) error
+	// IsFamilyActive reports whether the session still has a refresh token that is neither revoked nor expired
+	IsFamilyActive(ctx context.Context, userID int, familyID string) (bool, error)
+}
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *UserToken) error
+	FindByHash(ctx context.Context, purpose UserTokenPurpose, tokenHash string) (*UserToken, error)
+	MarkUsed(ctx context.Context, id string) (bool, error)
+	InvalidateByUser(ctx context.Context, userID int, purpose UserTokenPurpose) error
+}
+
+type SpaceInvitationRepository interface {
+	Create(ctx context.Context, invitation *SpaceInvitation) error
+	FindByID(ctx context.Context, id string) (*SpaceInvitation, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*SpaceInvitation, error)
+	FindActiveBySpaceID(ctx context.Context, spaceID int) ([]*SpaceInvitation, error)
+	FindActiveByInviteeID(ctx context.Context, userID int) ([]*SpaceInvitation, error)
+	IncrementUses(ctx context.Context, id string) (bool, error)
+	Revoke(ctx context.Context, id string) (bool, error)
+}
+
+type JoinRequestRepository interface {
+	Create(ctx context.Context, request *JoinRequest) error
+	FindByID(ctx context.Context, id string) (*JoinRequest, error)
+	FindPending(ctx context.Context, spaceID int, userID int) (*JoinRequest, error)
+	FindPendingBySpaceID(ctx context.Context, spaceID int) ([]*JoinRequest, error)
+	Review(ctx context.Context, id string, status JoinRequestStatus, reviewerID int) (bool, error)
 }
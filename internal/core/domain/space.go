@@ -2,16 +2,46 @@ package domain
 
 import "time"
 
+const (
+	SpaceVisibilityPublic  = "public"
+	SpaceVisibilityPrivate = "private"
+)
+
+type SpaceRole string
+
+const (
+	SpaceRoleOwner     SpaceRole = "owner"
+	SpaceRoleModerator SpaceRole = "moderator"
+	SpaceRoleMember    SpaceRole = "member"
+)
+
+// CanModerate reports whether the role may pin posts, delete content and kick members
+func (r SpaceRole) CanModerate() bool {
+	return r == SpaceRoleOwner || r == SpaceRoleModerator
+}
+
 type Space struct {
 	ID          int
 	Name        string
 	Description string
+	Visibility  string
+	ArchivedAt  *time.Time
 	CreatedAt   time.Time
 	CreatedBy   int
 	UpdatedAt   time.Time
 	UpdatedBy   int
 }
 
+// IsPrivate reports whether only members can see the space
+func (s *Space) IsPrivate() bool {
+	return s.Visibility == SpaceVisibilityPrivate
+}
+
+// IsArchived reports whether the space is read-only
+func (s *Space) IsArchived() bool {
+	return s.ArchivedAt != nil
+}
+
 type SpaceWithUserAndCounts struct {
 	Space       *Space
 	User        *User
@@ -19,18 +49,34 @@ type SpaceWithUserAndCounts struct {
 }
 
 type SpaceCounts struct {
-	Users int
-	Posts int
+	Users      int
+	Owners     int
+	Moderators int
+	Members    int
+	Posts      int
+}
+
+type SpaceMembership struct {
+	UserID  int
+	SpaceID int
+	Role    SpaceRole
+}
+
+type SpaceMember struct {
+	User *User
+	Role SpaceRole
 }
 
 type SpaceSearchCriteria struct {
-	Name          *string
-	CreatedBy     *int
-	OrderBy       string
-	Page          int
-	PageSize      int
-	SortDirection string
-	Query         string
+	Name            *string
+	CreatedBy       *int
+	OrderBy         string
+	Page            int
+	PageSize        int
+	SortDirection   string
+	Query           string
+	ViewerID        int
+	IncludeArchived bool
 }
 
 type SearchResult struct {
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+const (
+	DefaultInvitationTTL      = 7 * 24 * time.Hour
+	DefaultInvitationLinkUses = 25
+)
+
+// SpaceInvitation lets a user join a space without going through a join request.
+// Direct invitations target a single user, link invitations are redeemed with a
+// token shared by the inviter. Only the hash of the link token is stored.
+type SpaceInvitation struct {
+	ID        string
+	SpaceID   int
+	InviterID int
+	InviteeID *int
+	TokenHash string
+	MaxUses   int
+	Uses      int
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func (i *SpaceInvitation) IsDirect() bool {
+	return i.InviteeID != nil
+}
+
+// IsUsable reports whether the invitation can still be redeemed at the given time
+func (i *SpaceInvitation) IsUsable(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt) && i.Uses < i.MaxUses
+}
+
+type JoinRequestStatus string
+
+const (
+	JoinRequestPending  JoinRequestStatus = "pending"
+	JoinRequestApproved JoinRequestStatus = "approved"
+	JoinRequestRejected JoinRequestStatus = "rejected"
+)
+
+// JoinRequest is a request from a user to join a private space, reviewed by its owners and moderators
+type JoinRequest struct {
+	ID         string
+	SpaceID    int
+	UserID     int
+	Status     JoinRequestStatus
+	Message    string
+	ReviewedBy *int
+	ReviewedAt *time.Time
+	CreatedAt  time.Time
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 )
 
@@ -18,6 +19,16 @@ type User struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Image     string
+	// EmailVerifiedAt is nil until the user confirms their email address
+	EmailVerifiedAt *time.Time
+	IsAdmin         bool
+	// LastSeenAt is set when the user's last presence connection closes
+	LastSeenAt *time.Time
+}
+
+// FullName is the display name shown in chat and presence
+func (u *User) FullName() string {
+	return strings.TrimSpace(u.Name + " " + u.LastName)
 }
 
 type UserWithSpaces struct {
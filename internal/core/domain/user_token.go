@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+type UserTokenPurpose string
+
+const (
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenEmailChange       UserTokenPurpose = "email_change"
+
+	EmailVerificationTTL = 24 * time.Hour
+	PasswordResetTTL     = time.Hour
+)
+
+// UserToken is a single-use, expiring token sent to the user by email.
+// Only the hash of the token is stored.
+type UserToken struct {
+	ID        string
+	UserID    int
+	Purpose   UserTokenPurpose
+	TokenHash string
+	// Payload carries purpose specific data, such as the pending email of an email change
+	Payload   string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -1,6 +1,31 @@
 package dto
 
+import (
+	"cpi-hub-api/internal/core/domain"
+	"time"
+)
+
 type AuthResponse struct {
-	User  UserDTO `json:"user"`
-	Token string  `json:"token"`
+	User         UserDTO   `json:"user"`
+	Token        string    `json:"token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitzero"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokensResponse struct {
+	Token        string    `json:"token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitzero"`
+}
+
+func ToTokensResponse(tokens *domain.AuthTokens) TokensResponse {
+	return TokensResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}
 }
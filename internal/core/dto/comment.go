@@ -9,7 +9,7 @@ type CreateComment struct {
 	PostID          int     `json:"post_id"`
 	Content         string  `json:"content" binding:"required"`
 	Image           *string `json:"image"`
-	CreatedBy       int     `json:"created_by" binding:"required"`
+	CreatedBy       int     `json:"-"`
 	ParentCommentID *int    `json:"parent_comment_id,omitempty"`
 }
 
@@ -55,6 +55,7 @@ type SearchCommentsParams struct {
 	SortDirection string
 	UserID        *int
 	PostID        *int
+	ViewerID      int // private spaces the viewer does not belong to are left out
 }
 
 type PaginatedCommentsResponse struct {
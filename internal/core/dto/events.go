@@ -7,25 +7,77 @@ import (
 )
 
 type EventsConnectionParams struct {
-	UserID   int
-	SpaceID  int
-	Writer   http.ResponseWriter
-	Request  *http.Request
-	Username string
+	UserID    int
+	SessionID string
+	SpaceID   int
+	// LastEventID is the last event the client received before reconnecting
+	LastEventID string
+	Writer      http.ResponseWriter
+	Request     *http.Request
 }
 
 type EventsBroadcastParams struct {
 	SpaceID  int    `json:"space_id" binding:"required"`
-	UserID   int    `json:"user_id" binding:"required"`
+	UserID   int    `json:"-"`
 	Message  string `json:"message" binding:"required"`
-	Username string `json:"username" binding:"required"`
+	Username string `json:"-"`
 	Image    string `json:"image"`
+	// ReplyToID is optional and must reference a message of the same space
+	ReplyToID *string `json:"reply_to_id"`
+}
+
+type EditChatMessageParams struct {
+	MessageID string `json:"-"`
+	UserID    int    `json:"-"`
+	Content   string `json:"content" binding:"required"`
+}
+
+type DeleteChatMessageParams struct {
+	MessageID string
+	UserID    int
+}
+
+type MarkReadParams struct {
+	SpaceID   int
+	UserID    int
+	MessageID string
+}
+
+type PresenceUserDTO struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Image    string `json:"image"`
+}
+
+type SpacePresenceDTO struct {
+	SpaceID     int               `json:"space_id"`
+	OnlineCount int               `json:"online_count"`
+	Users       []PresenceUserDTO `json:"users"`
+}
+
+func ToSpacePresenceDTO(spaceID int, users []domain.PresenceUser) SpacePresenceDTO {
+	userDTOs := make([]PresenceUserDTO, 0, len(users))
+	for _, user := range users {
+		userDTOs = append(userDTOs, PresenceUserDTO{UserID: user.UserID, Username: user.Username, Image: user.Image})
+	}
+
+	return SpacePresenceDTO{
+		SpaceID:     spaceID,
+		OnlineCount: len(userDTOs),
+		Users:       userDTOs,
+	}
+}
+
+type WebSocketTicketDTO struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type HandleUserConnectionParams struct {
-	UserID  int
-	Writer  http.ResponseWriter
-	Request *http.Request
+	UserID    int
+	SessionID string
+	Writer    http.ResponseWriter
+	Request   *http.Request
 }
 
 type NotificationMessageDTO struct {
@@ -41,3 +93,35 @@ func ToNotificationMessageDTO(notification *domain.Notification) NotificationMes
 		Timestamp: notification.CreatedAt,
 	}
 }
+
+// NotificationRetractedDTO carries the unread count so clients can fix their badge
+type NotificationRetractedDTO struct {
+	NotificationID string           `json:"notification_id"`
+	Deleted        bool             `json:"deleted"`
+	Notification   *NotificationDTO `json:"notification,omitempty"`
+	UnreadCount    int              `json:"unread_count"`
+}
+
+type NotificationRetractedMessageDTO struct {
+	Type      string                   `json:"type"`
+	Data      NotificationRetractedDTO `json:"data"`
+	Timestamp time.Time                `json:"timestamp"`
+}
+
+func ToNotificationRetractedMessageDTO(retraction *domain.NotificationRetraction, timestamp time.Time) NotificationRetractedMessageDTO {
+	data := NotificationRetractedDTO{
+		NotificationID: retraction.Notification.ID,
+		Deleted:        retraction.Deleted,
+		UnreadCount:    retraction.UnreadCount,
+	}
+	if !retraction.Deleted {
+		notification := ToNotificationDTO(retraction.Notification)
+		data.Notification = &notification
+	}
+
+	return NotificationRetractedMessageDTO{
+		Type:      "notification_retracted",
+		Data:      data,
+		Timestamp: timestamp,
+	}
+}
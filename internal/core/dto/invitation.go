@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"time"
+)
+
+type CreateInvitationDTO struct {
+	// InviteeID makes a direct invitation, without it a shareable link is created
+	InviteeID      *int `json:"invitee_id"`
+	MaxUses        int  `json:"max_uses" binding:"omitempty,min=1,max=1000"`
+	ExpiresInHours int  `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
+
+type AcceptInvitationLinkDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type CreateJoinRequestDTO struct {
+	Message string `json:"message" binding:"max=500"`
+}
+
+type InvitationDTO struct {
+	ID        string  `json:"id"`
+	SpaceID   int     `json:"space_id"`
+	InviterID int     `json:"inviter_id"`
+	InviteeID *int    `json:"invitee_id,omitempty"`
+	Token     string  `json:"token,omitempty"` // Token is only returned when a link invitation is created
+	MaxUses   int     `json:"max_uses"`
+	Uses      int     `json:"uses"`
+	ExpiresAt string  `json:"expires_at"`
+	RevokedAt *string `json:"revoked_at"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type JoinRequestDTO struct {
+	ID         string  `json:"id"`
+	SpaceID    int     `json:"space_id"`
+	UserID     int     `json:"user_id"`
+	Status     string  `json:"status"`
+	Message    string  `json:"message"`
+	ReviewedBy *int    `json:"reviewed_by"`
+	ReviewedAt *string `json:"reviewed_at"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+func ToInvitationDTO(invitation *domain.SpaceInvitation) InvitationDTO {
+	return InvitationDTO{
+		ID:        invitation.ID,
+		SpaceID:   invitation.SpaceID,
+		InviterID: invitation.InviterID,
+		InviteeID: invitation.InviteeID,
+		MaxUses:   invitation.MaxUses,
+		Uses:      invitation.Uses,
+		ExpiresAt: invitation.ExpiresAt.Format(time.RFC3339),
+		RevokedAt: formatOptionalTime(invitation.RevokedAt),
+		CreatedAt: invitation.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToInvitationDTOs(invitations []*domain.SpaceInvitation) []InvitationDTO {
+	invitationDTOs := make([]InvitationDTO, 0, len(invitations))
+	for _, invitation := range invitations {
+		invitationDTOs = append(invitationDTOs, ToInvitationDTO(invitation))
+	}
+	return invitationDTOs
+}
+
+func ToJoinRequestDTO(request *domain.JoinRequest) JoinRequestDTO {
+	return JoinRequestDTO{
+		ID:         request.ID,
+		SpaceID:    request.SpaceID,
+		UserID:     request.UserID,
+		Status:     string(request.Status),
+		Message:    request.Message,
+		ReviewedBy: request.ReviewedBy,
+		ReviewedAt: formatOptionalTime(request.ReviewedAt),
+		CreatedAt:  request.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToJoinRequestDTOs(requests []*domain.JoinRequest) []JoinRequestDTO {
+	requestDTOs := make([]JoinRequestDTO, 0, len(requests))
+	for _, request := range requests {
+		requestDTOs = append(requestDTOs, ToJoinRequestDTO(request))
+	}
+	return requestDTOs
+}
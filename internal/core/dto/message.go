@@ -11,6 +11,37 @@ type SearchMessagesParams struct {
 	OrderBy       string
 	SortDirection string
 	SpaceID       int
+	UserID        int
+}
+
+type MessageHistoryParams struct {
+	SpaceID int
+	UserID  int
+	Before  string
+	After   string
+	Limit   int
+}
+
+type CursorMessagesResponse struct {
+	Data []MessageDTO `json:"data"`
+	// NextCursor continues in the same direction, it is null when there are no more messages
+	NextCursor *string `json:"next_cursor"`
+	HasMore    bool    `json:"has_more"`
+}
+
+type SpaceUnreadCountDTO struct {
+	SpaceID     int `json:"space_id"`
+	UnreadCount int `json:"unread_count"`
+}
+
+func ToSpaceUnreadCountDTOs(counts []*domain.SpaceUnreadCount) []SpaceUnreadCountDTO {
+	countDTOs := make([]SpaceUnreadCountDTO, 0, len(counts))
+
+	for _, count := range counts {
+		countDTOs = append(countDTOs, SpaceUnreadCountDTO{SpaceID: count.SpaceID, UnreadCount: count.Count})
+	}
+
+	return countDTOs
 }
 
 type PaginatedMessagesResponse struct {
@@ -21,13 +52,16 @@ type PaginatedMessagesResponse struct {
 }
 
 type MessageDTO struct {
-	ID        string    `json:"id"`
-	Content   string    `json:"content"`
-	UserID    int       `json:"user_id"`
-	Username  string    `json:"username"`
-	SpaceID   int       `json:"space_id"`
-	CreatedAt time.Time `json:"created_at"`
-	Image     string    `json:"image"`
+	ID        string     `json:"id"`
+	Content   string     `json:"content"`
+	UserID    int        `json:"user_id"`
+	Username  string     `json:"username"`
+	SpaceID   int        `json:"space_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Image     string     `json:"image"`
+	ReplyToID *string    `json:"reply_to_id"`
+	EditedAt  *time.Time `json:"edited_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
 }
 
 func ToMessageDTO(message *domain.ChatMessage) MessageDTO {
@@ -39,6 +73,9 @@ func ToMessageDTO(message *domain.ChatMessage) MessageDTO {
 		SpaceID:   message.SpaceID,
 		CreatedAt: message.Timestamp,
 		Image:     message.Image,
+		ReplyToID: message.ReplyToID,
+		EditedAt:  message.EditedAt,
+		DeletedAt: message.DeletedAt,
 	}
 }
 
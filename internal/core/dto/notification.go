@@ -2,14 +2,16 @@ package dto
 
 import (
 	"cpi-hub-api/internal/core/domain"
+	"fmt"
 	"net/http"
 	"time"
 )
 
 type HandleNotificationConnectionParams struct {
-	UserID  int
-	Writer  http.ResponseWriter
-	Request *http.Request
+	UserID    int
+	SessionID string
+	Writer    http.ResponseWriter
+	Request   *http.Request
 }
 
 type CreateNotificationParams struct {
@@ -18,17 +20,70 @@ type CreateNotificationParams struct {
 	EntityID         int
 	PostID           *int
 	OwnerUserID      int
+	ActorUserID      int
+	// SpaceID is where the notification comes from, used to honor muted spaces
+	SpaceID int
+	// Action is the reaction behind a reaction notification
+	Action domain.ActionType
+}
+
+// FanOutNotificationParams send the same notification to many users, like the members of a space
+type FanOutNotificationParams struct {
+	NotificationType domain.NotificationType
+	EntityType       domain.EntityType
+	EntityID         int
+	PostID           *int
+	OwnerUserIDs     []int
+	ActorUserID      int
+	SpaceID          int
+}
+
+// For returns the params of the notification of one of the owners
+func (p FanOutNotificationParams) For(ownerUserID int) CreateNotificationParams {
+	return CreateNotificationParams{
+		NotificationType: p.NotificationType,
+		EntityType:       p.EntityType,
+		EntityID:         p.EntityID,
+		PostID:           p.PostID,
+		OwnerUserID:      ownerUserID,
+		ActorUserID:      p.ActorUserID,
+		SpaceID:          p.SpaceID,
+	}
+}
+
+// RetractNotificationParams identify the grouped notification an actor is taken out of
+type RetractNotificationParams struct {
+	NotificationType domain.NotificationType
+	EntityType       domain.EntityType
+	EntityID         int
+	PostID           *int
+	Action           domain.ActionType
+	OwnerUserID      int
+	ActorUserID      int
 }
 
 type NotificationDTO struct {
-	ID         string    `json:"id"`
-	Type       string    `json:"type"`
-	EntityType string    `json:"entity_type"`
-	EntityID   int       `json:"entity_id"`
-	PostID     *int      `json:"post_id,omitempty"` // PostID is set when EntityType is comment
-	UserID     int       `json:"user_id"`
-	Read       bool      `json:"read"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   int                    `json:"entity_id"`
+	PostID     *int                   `json:"post_id,omitempty"` // PostID is set when EntityType is comment
+	UserID     int                    `json:"user_id"`
+	Action     string                 `json:"action,omitempty"`
+	ActorID    int                    `json:"actor_id,omitempty"`
+	ActorCount int                    `json:"actor_count"`
+	ActorIDs   []int                  `json:"actor_ids"` // ActorIDs and Actors are a sample of the most recent actors
+	Actors     []NotificationActorDTO `json:"actors,omitempty"`
+	Read       bool                   `json:"read"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+type NotificationActorDTO struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	LastName string `json:"last_name"`
+	Image    string `json:"image"`
 }
 
 func ToNotificationDTO(notification *domain.Notification) NotificationDTO {
@@ -39,7 +94,102 @@ func ToNotificationDTO(notification *domain.Notification) NotificationDTO {
 		EntityID:   notification.EntityID,
 		PostID:     notification.PostID,
 		UserID:     notification.UserID,
+		Action:     string(notification.Action),
+		ActorID:    notification.ActorID,
+		ActorCount: notification.ActorCount,
+		ActorIDs:   notification.ActorIDs,
+		Actors:     toNotificationActorDTOs(notification.Actors),
 		Read:       notification.Read,
 		CreatedAt:  notification.CreatedAt,
+		UpdatedAt:  notification.UpdatedAt,
+	}
+}
+
+func toNotificationActorDTOs(users []*domain.User) []NotificationActorDTO {
+	if len(users) == 0 {
+		return nil
+	}
+
+	actors := make([]NotificationActorDTO, len(users))
+	for i, user := range users {
+		actors[i] = NotificationActorDTO{
+			ID:       user.ID,
+			Name:     user.Name,
+			LastName: user.LastName,
+			Image:    user.Image,
+		}
 	}
+	return actors
+}
+
+type NotificationChannelsDTO struct {
+	InApp       bool `json:"in_app"`
+	Push        bool `json:"push"`
+	EmailDigest bool `json:"email_digest"`
+}
+
+// QuietHoursDTO times are "HH:MM", the timezone defaults to Buenos Aires
+type QuietHoursDTO struct {
+	Start    string `json:"start" binding:"required"`
+	End      string `json:"end" binding:"required"`
+	Timezone string `json:"timezone"`
+}
+
+// UpdateNotificationPreferencesDTO replaces the preferences, missing types go back to the defaults
+type UpdateNotificationPreferencesDTO struct {
+	Types      map[string]NotificationChannelsDTO `json:"types"`
+	QuietHours *QuietHoursDTO                     `json:"quiet_hours"`
+}
+
+type MuteNotificationsDTO struct {
+	EntityType string `json:"entity_type" binding:"required"`
+	EntityID   int    `json:"entity_id" binding:"required"`
+}
+
+type NotificationPreferencesDTO struct {
+	Types         map[string]NotificationChannelsDTO `json:"types"`
+	QuietHours    *QuietHoursDTO                     `json:"quiet_hours"`
+	MutedSpaceIDs []int                              `json:"muted_space_ids"`
+	MutedPostIDs  []int                              `json:"muted_post_ids"`
+}
+
+// ToNotificationPreferencesDTO lists every type with the channels that apply to it
+func ToNotificationPreferencesDTO(preferences *domain.NotificationPreferences) NotificationPreferencesDTO {
+	types := make(map[string]NotificationChannelsDTO, len(domain.NotificationTypes))
+	for _, notificationType := range domain.NotificationTypes {
+		channels := preferences.Channels(notificationType)
+		types[string(notificationType)] = NotificationChannelsDTO{
+			InApp:       channels.InApp,
+			Push:        channels.Push,
+			EmailDigest: channels.EmailDigest,
+		}
+	}
+
+	var quietHours *QuietHoursDTO
+	if preferences.QuietHours != nil {
+		quietHours = &QuietHoursDTO{
+			Start:    formatClock(preferences.QuietHours.Start),
+			End:      formatClock(preferences.QuietHours.End),
+			Timezone: preferences.QuietHours.Timezone,
+		}
+	}
+
+	mutedSpaceIDs, mutedPostIDs := preferences.MutedSpaceIDs, preferences.MutedPostIDs
+	if mutedSpaceIDs == nil {
+		mutedSpaceIDs = []int{}
+	}
+	if mutedPostIDs == nil {
+		mutedPostIDs = []int{}
+	}
+
+	return NotificationPreferencesDTO{
+		Types:         types,
+		QuietHours:    quietHours,
+		MutedSpaceIDs: mutedSpaceIDs,
+		MutedPostIDs:  mutedPostIDs,
+	}
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
 }
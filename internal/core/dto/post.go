@@ -9,7 +9,7 @@ type CreatePost struct {
 	Title     string  `json:"title" binding:"required"`
 	Content   string  `json:"content" binding:"required"`
 	Image     *string `json:"image"`
-	CreatedBy int     `json:"created_by" binding:"required"`
+	CreatedBy int     `json:"-"`
 	SpaceID   int     `json:"space_id" binding:"required"`
 }
 
@@ -27,6 +27,7 @@ type SearchPostsParams struct {
 	SpaceID       int
 	UserID        int
 	Query         string
+	ViewerID      int // private spaces the viewer does not belong to are left out
 }
 
 type InterestedPostsParams struct {
@@ -35,6 +36,7 @@ type InterestedPostsParams struct {
 	OrderBy       string
 	SortDirection string
 	UserID        int // required for interested posts
+	ViewerID      int
 }
 
 type PaginatedPostsResponse struct {
@@ -52,6 +54,7 @@ type PostExtendedDTO struct {
 	CreatedAt time.Time            `json:"created_at"`
 	UpdatedAt time.Time            `json:"updated_at"`
 	UpdatedBy int                  `json:"updated_by"`
+	PinnedAt  *time.Time           `json:"pinned_at"`
 	CreatedBy UserDTO              `json:"created_by"`
 	Space     SimpleSpaceDto       `json:"space"`
 	Comments  []CommentWithUserDTO `json:"comments"`
@@ -79,6 +82,7 @@ func ToPostExtendedDTO(post *domain.ExtendedPost) PostExtendedDTO {
 		CreatedAt: post.Post.CreatedAt,
 		UpdatedAt: post.Post.UpdatedAt,
 		UpdatedBy: post.Post.UpdatedBy,
+		PinnedAt:  post.Post.PinnedAt,
 		CreatedBy: UserDTO{
 			ID:       post.User.ID,
 			Name:     post.User.Name,
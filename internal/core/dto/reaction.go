@@ -5,7 +5,7 @@ import (
 )
 
 type NewReaction struct {
-	UserID     int    `json:"user_id" binding:"required"`
+	UserID     int    `json:"-"`
 	EntityType string `json:"entity_type" binding:"required"`
 	EntityID   int    `json:"entity_id" binding:"required"`
 	Action     string `json:"action" binding:"required"`
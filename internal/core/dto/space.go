@@ -8,31 +8,59 @@ import (
 type CreateSpace struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description" binding:"required"`
-	CreatedBy   int    `json:"created_by" binding:"required"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=public private"`
+	CreatedBy   int    `json:"-"`
 }
 
 type SpaceDTO struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
 	CreatedBy   int    `json:"created_by"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
 	UpdatedBy   int    `json:"updated_by"`
 }
 
+type UpdateSpaceDTO struct {
+	Name        *string `json:"name" binding:"omitempty,min=1"`
+	Description *string `json:"description"`
+	Visibility  *string `json:"visibility" binding:"omitempty,oneof=public private"`
+}
+
+type UpdateMemberRoleDTO struct {
+	Role string `json:"role" binding:"required,oneof=moderator member"`
+}
+
+type TransferOwnershipDTO struct {
+	UserID int `json:"user_id" binding:"required"`
+}
+
+type SpaceMemberDTO struct {
+	UserDTO
+	Role string `json:"role"`
+}
+
+type SpaceRoleCountsDTO struct {
+	Owners     int `json:"owners"`
+	Moderators int `json:"moderators"`
+	Members    int `json:"members"`
+}
+
 type SimpleSpaceDto struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 }
 
 type SearchSpacesDTO struct {
-	Name          *string `form:"name" json:"name"`
-	CreatedBy     *int    `form:"created_by" json:"created_by"`
-	OrderBy       string  `form:"order_by" json:"order_by"`
-	Page          int     `form:"page" json:"page"`
-	PageSize      int     `form:"page_size" json:"page_size"`
-	SortDirection string  `form:"sort_direction" json:"sort_direction"`
+	Name            *string `form:"name" json:"name"`
+	CreatedBy       *int    `form:"created_by" json:"created_by"`
+	OrderBy         string  `form:"order_by" json:"order_by"`
+	Page            int     `form:"page" json:"page"`
+	PageSize        int     `form:"page_size" json:"page_size"`
+	SortDirection   string  `form:"sort_direction" json:"sort_direction"`
+	IncludeArchived bool    `form:"include_archived" json:"include_archived"`
 }
 
 type SearchSpacesResponseDTO struct {
@@ -43,20 +71,24 @@ type SearchSpacesResponseDTO struct {
 }
 
 type SpaceWithUserAndCountDTO struct {
-	ID          int     `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Users       int     `json:"users"`
-	Posts       int     `json:"posts"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
-	CreatedBy   UserDTO `json:"created_by"`
+	ID          int                `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Visibility  string             `json:"visibility"`
+	ArchivedAt  *string            `json:"archived_at"`
+	Users       int                `json:"users"`
+	Roles       SpaceRoleCountsDTO `json:"roles"`
+	Posts       int                `json:"posts"`
+	CreatedAt   string             `json:"created_at"`
+	UpdatedAt   string             `json:"updated_at"`
+	CreatedBy   UserDTO            `json:"created_by"`
 }
 
 func (c *CreateSpace) ToDomain() *domain.Space {
 	return &domain.Space{
 		Name:        c.Name,
 		Description: c.Description,
+		Visibility:  c.Visibility,
 		CreatedBy:   c.CreatedBy,
 	}
 }
@@ -66,10 +98,17 @@ func ToSpaceWithUserDTO(space *domain.SpaceWithUserAndCounts) SpaceWithUserAndCo
 		ID:          space.Space.ID,
 		Name:        space.Space.Name,
 		Description: space.Space.Description,
+		Visibility:  space.Space.Visibility,
+		ArchivedAt:  formatOptionalTime(space.Space.ArchivedAt),
 		Users:       space.SpaceCounts.Users,
-		Posts:       space.SpaceCounts.Posts,
-		CreatedAt:   space.Space.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   space.Space.UpdatedAt.Format(time.RFC3339),
+		Roles: SpaceRoleCountsDTO{
+			Owners:     space.SpaceCounts.Owners,
+			Moderators: space.SpaceCounts.Moderators,
+			Members:    space.SpaceCounts.Members,
+		},
+		Posts:     space.SpaceCounts.Posts,
+		CreatedAt: space.Space.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: space.Space.UpdatedAt.Format(time.RFC3339),
 		CreatedBy: UserDTO{
 			ID:       space.User.ID,
 			Name:     space.User.Name,
@@ -85,6 +124,7 @@ func ToSpaceDTO(space *domain.Space) SpaceDTO {
 		ID:          space.ID,
 		Name:        space.Name,
 		Description: space.Description,
+		Visibility:  space.Visibility,
 		CreatedBy:   space.CreatedBy,
 		CreatedAt:   space.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   space.UpdatedAt.Format(time.RFC3339),
@@ -100,3 +140,24 @@ func ToSpaceWithUserDTOs(spaces []*domain.SpaceWithUserAndCounts) []SpaceWithUse
 	}
 	return spacesWithUserDTOs
 }
+
+func ToSpaceMemberDTOs(members []*domain.SpaceMember) []SpaceMemberDTO {
+	memberDTOs := make([]SpaceMemberDTO, 0, len(members))
+
+	for _, member := range members {
+		memberDTOs = append(memberDTOs, SpaceMemberDTO{
+			UserDTO: ToUserDTO(member.User),
+			Role:    string(member.Role),
+		})
+	}
+	return memberDTOs
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
@@ -14,20 +14,23 @@ type CreateUser struct {
 }
 
 type UserDTO struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	LastName string `json:"last_name"`
-	Email    string `json:"email"`
-	Image    string `json:"image"`
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	LastName      string `json:"last_name"`
+	Email         string `json:"email"`
+	Image         string `json:"image"`
+	EmailVerified bool   `json:"email_verified"`
 }
 
 type UserDTOWithSpaces struct {
-	ID       int        `json:"id"`
-	Name     string     `json:"name"`
-	LastName string     `json:"last_name"`
-	Email    string     `json:"email"`
-	Image    string     `json:"image"`
-	Spaces   []SpaceDTO `json:"spaces"`
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	LastName      string     `json:"last_name"`
+	Email         string     `json:"email"`
+	Image         string     `json:"image"`
+	Spaces        []SpaceDTO `json:"spaces"`
+	EmailVerified bool       `json:"email_verified"`
+	LastSeenAt    *time.Time `json:"last_seen_at"`
 }
 
 func (c *CreateUser) ToDomain() *domain.User {
@@ -42,11 +45,12 @@ func (c *CreateUser) ToDomain() *domain.User {
 
 func ToUserDTO(user *domain.User) UserDTO {
 	return UserDTO{
-		ID:       user.ID,
-		Name:     user.Name,
-		LastName: user.LastName,
-		Email:    user.Email,
-		Image:    user.Image,
+		ID:            user.ID,
+		Name:          user.Name,
+		LastName:      user.LastName,
+		Email:         user.Email,
+		Image:         user.Image,
+		EmailVerified: user.EmailVerifiedAt != nil,
 	}
 }
 
@@ -66,12 +70,14 @@ func ToUserDTOWithSpaces(user *domain.UserWithSpaces) UserDTOWithSpaces {
 	}
 
 	return UserDTOWithSpaces{
-		ID:       user.User.ID,
-		Name:     user.User.Name,
-		LastName: user.User.LastName,
-		Email:    user.User.Email,
-		Image:    user.User.Image,
-		Spaces:   spaceDTOs,
+		ID:            user.User.ID,
+		Name:          user.User.Name,
+		LastName:      user.User.LastName,
+		Email:         user.User.Email,
+		Image:         user.User.Image,
+		Spaces:        spaceDTOs,
+		EmailVerified: user.User.EmailVerifiedAt != nil,
+		LastSeenAt:    user.User.LastSeenAt,
 	}
 }
 
@@ -81,9 +87,36 @@ type UpdateUserSpacesDTO struct {
 	Action   string
 }
 
+type VerifyEmail struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=5"`
+}
+
+type ChangePassword struct {
+	UserID          int    `json:"-"`
+	SessionID       string `json:"-"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=5"`
+}
+
+type ChangeEmail struct {
+	UserID          int    `json:"-"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+	Email           string `json:"email" binding:"required,email"`
+}
+
 type LoginUser struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	IP       string `json:"-"`
 }
 
 type SearchUsersParams struct {
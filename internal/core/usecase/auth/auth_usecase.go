@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/criteria"
+	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
+)
+
+type AuthUseCase interface {
+	IssueTokens(ctx context.Context, user *domain.User) (*domain.AuthTokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*domain.AuthTokens, error)
+	Logout(ctx context.Context, userID int, sessionID string) error
+	LogoutAll(ctx context.Context, userID int) error
+	ValidateSession(ctx context.Context, userID int, sessionID string) error
+	// RequiresEmailVerification reports whether the user must verify their email before getting tokens
+	RequiresEmailVerification(user *domain.User) bool
+}
+
+// Config holds the session settings read from the environment
+type Config struct {
+	// RequireEmailVerification refuses tokens to accounts that never verified their email
+	RequireEmailVerification bool
+}
+
+type authUseCase struct {
+	refreshTokenRepository domain.RefreshTokenRepository
+	userRepository         domain.UserRepository
+	config                 Config
+}
+
+func NewAuthUsecase(refreshTokenRepo domain.RefreshTokenRepository, userRepo domain.UserRepository, config Config) AuthUseCase {
+	return &authUseCase{
+		refreshTokenRepository: refreshTokenRepo,
+		userRepository:         userRepo,
+		config:                 config,
+	}
+}
+
+// IssueTokens starts a new session for the user
+func (a *authUseCase) IssueTokens(ctx context.Context, user *domain.User) (*domain.AuthTokens, error) {
+	if a.RequiresEmailVerification(user) {
+		return nil, apperror.NewForbidden("Email address not verified", nil, "auth_usecase.go:IssueTokens")
+	}
+
+	return a.issue(ctx, user, helpers.NewULID(), helpers.NewULID())
+}
+
+func (a *authUseCase) RequiresEmailVerification(user *domain.User) bool {
+	return a.config.RequireEmailVerification && user.EmailVerifiedAt == nil
+}
+
+// Refresh rotates the refresh token. Presenting a token that was already
+// rotated or revoked is treated as theft and revokes the whole session.
+func (a *authUseCase) Refresh(ctx context.Context, refreshToken string) (*domain.AuthTokens, error) {
+	stored, err := a.refreshTokenRepository.FindByHash(ctx, helpers.HashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if stored == nil {
+		return nil, apperror.NewUnauthorized("Invalid refresh token", nil, "auth_usecase.go:Refresh")
+	}
+
+	if stored.RevokedAt != nil {
+		if err := a.refreshTokenRepository.RevokeFamily(ctx, stored.UserID, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, apperror.NewUnauthorized("Refresh token reuse detected", nil, "auth_usecase.go:Refresh")
+	}
+
+	if helpers.GetTime().After(stored.ExpiresAt) {
+		return nil, apperror.NewUnauthorized("Refresh token expired", nil, "auth_usecase.go:Refresh")
+	}
+
+	user, err := a.userRepository.Find(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("id", stored.UserID, criteria.OperatorEqual).
+		Build())
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, apperror.NewUnauthorized("Invalid refresh token", nil, "auth_usecase.go:Refresh")
+	}
+
+	if a.RequiresEmailVerification(user) {
+		return nil, apperror.NewForbidden("Email address not verified", nil, "auth_usecase.go:Refresh")
+	}
+
+	newTokenID := helpers.NewULID()
+	rotated, err := a.refreshTokenRepository.MarkRotated(ctx, stored.ID, newTokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rotated {
+		if err := a.refreshTokenRepository.RevokeFamily(ctx, stored.UserID, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, apperror.NewUnauthorized("Refresh token reuse detected", nil, "auth_usecase.go:Refresh")
+	}
+
+	return a.issue(ctx, user, newTokenID, stored.FamilyID)
+}
+
+func (a *authUseCase) Logout(ctx context.Context, userID int, sessionID string) error {
+	if sessionID == "" {
+		return apperror.NewInvalidData("Token is not bound to a session", nil, "auth_usecase.go:Logout")
+	}
+
+	return a.refreshTokenRepository.RevokeFamily(ctx, userID, sessionID)
+}
+
+func (a *authUseCase) LogoutAll(ctx context.Context, userID int) error {
+	return a.refreshTokenRepository.RevokeAllByUser(ctx, userID)
+}
+
+// ValidateSession rejects access tokens whose session was logged out. Tokens issued
+// before sessions existed carry no sid and are rejected too.
+func (a *authUseCase) ValidateSession(ctx context.Context, userID int, sessionID string) error {
+	if sessionID == "" {
+		return apperror.NewUnauthorized("Token is not bound to a session", nil, "auth_usecase.go:ValidateSession")
+	}
+
+	active, err := a.refreshTokenRepository.IsFamilyActive(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if !active {
+		return apperror.NewUnauthorized("Session has been revoked", nil, "auth_usecase.go:ValidateSession")
+	}
+
+	return nil
+}
+
+func (a *authUseCase) issue(ctx context.Context, user *domain.User, tokenID string, familyID string) (*domain.AuthTokens, error) {
+	refreshToken, err := helpers.NewOpaqueToken()
+	if err != nil {
+		return nil, apperror.NewInternalServer("Error generating refresh token", err, "auth_usecase.go:issue")
+	}
+
+	now := helpers.GetTime()
+	if err := a.refreshTokenRepository.Create(ctx, &domain.RefreshToken{
+		ID:        tokenID,
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: helpers.HashToken(refreshToken),
+		ExpiresAt: now.Add(domain.RefreshTokenTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := helpers.CreateToken(user.Email, user.ID, familyID, domain.AccessTokenTTL)
+	if err != nil {
+		return nil, apperror.NewInternalServer("Error generating access token", err, "auth_usecase.go:issue")
+	}
+
+	return &domain.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    now.Add(domain.AccessTokenTTL),
+	}, nil
+}
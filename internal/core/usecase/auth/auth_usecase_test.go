@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRefreshTokenRepository := mock.NewMockRefreshTokenRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+
+	authUseCase := NewAuthUsecase(mockRefreshTokenRepository, mockUserRepository, Config{RequireEmailVerification: true})
+
+	type args struct {
+		context      context.Context
+		refreshToken string
+	}
+
+	type want struct {
+		err error
+	}
+
+	revokedAt := helpers.GetTime().Add(-time.Minute)
+
+	verifiedAt := helpers.GetTime().Add(-time.Hour)
+
+	givenUser := &domain.User{
+		ID:              1,
+		Email:           "test@test.com",
+		EmailVerifiedAt: &verifiedAt,
+	}
+
+	unverifiedUser := &domain.User{
+		ID:    1,
+		Email: "test@test.com",
+	}
+
+	activeToken := &domain.RefreshToken{
+		ID:        "token-1",
+		UserID:    1,
+		FamilyID:  "family-1",
+		ExpiresAt: helpers.GetTime().Add(time.Hour),
+	}
+
+	revokedToken := &domain.RefreshToken{
+		ID:        "token-1",
+		UserID:    1,
+		FamilyID:  "family-1",
+		ExpiresAt: helpers.GetTime().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}
+
+	expiredToken := &domain.RefreshToken{
+		ID:        "token-1",
+		UserID:    1,
+		FamilyID:  "family-1",
+		ExpiresAt: helpers.GetTime().Add(-time.Hour),
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		want  want
+		calls []*gomock.Call
+	}{
+		{
+			name: "success",
+			args: args{
+				context:      context.Background(),
+				refreshToken: "refresh",
+			},
+			want: want{},
+			calls: []*gomock.Call{
+				mockRefreshTokenRepository.EXPECT().FindByHash(gomock.Any(), helpers.HashToken("refresh")).Return(activeToken, nil),
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
+				mockRefreshTokenRepository.EXPECT().MarkRotated(gomock.Any(), "token-1", gomock.Any()).Return(true, nil),
+				mockRefreshTokenRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil),
+			},
+		},
+		{
+			name: "error token not found",
+			args: args{
+				context:      context.Background(),
+				refreshToken: "refresh",
+			},
+			want: want{
+				err: apperror.NewUnauthorized("Invalid refresh token", nil, "auth_usecase.go:Refresh"),
+			},
+			calls: []*gomock.Call{
+				mockRefreshTokenRepository.EXPECT().FindByHash(gomock.Any(), gomock.Any()).Return(nil, nil),
+			},
+		},
+		{
+			name: "error reused token revokes family",
+			args: args{
+				context:      context.Background(),
+				refreshToken: "refresh",
+			},
+			want: want{
+				err: apperror.NewUnauthorized("Refresh token reuse detected", nil, "auth_usecase.go:Refresh"),
+			},
+			calls: []*gomock.Call{
+				mockRefreshTokenRepository.EXPECT().FindByHash(gomock.Any(), gomock.Any()).Return(revokedToken, nil),
+				mockRefreshTokenRepository.EXPECT().RevokeFamily(gomock.Any(), 1, "family-1").Return(nil),
+			},
+		},
+		{
+			name: "error token expired",
+			args: args{
+				context:      context.Background(),
+				refreshToken: "refresh",
+			},
+			want: want{
+				err: apperror.NewUnauthorized("Refresh token expired", nil, "auth_usecase.go:Refresh"),
+			},
+			calls: []*gomock.Call{
+				mockRefreshTokenRepository.EXPECT().FindByHash(gomock.Any(), gomock.Any()).Return(expiredToken, nil),
+			},
+		},
+		{
+			name: "error email not verified",
+			args: args{
+				context:      context.Background(),
+				refreshToken: "refresh",
+			},
+			want: want{
+				err: apperror.NewForbidden("Email address not verified", nil, "auth_usecase.go:Refresh"),
+			},
+			calls: []*gomock.Call{
+				mockRefreshTokenRepository.EXPECT().FindByHash(gomock.Any(), gomock.Any()).Return(activeToken, nil),
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(unverifiedUser, nil),
+			},
+		},
+		{
+			name: "error concurrent rotation revokes family",
+			args: args{
+				context:      context.Background(),
+				refreshToken: "refresh",
+			},
+			want: want{
+				err: apperror.NewUnauthorized("Refresh token reuse detected", nil, "auth_usecase.go:Refresh"),
+			},
+			calls: []*gomock.Call{
+				mockRefreshTokenRepository.EXPECT().FindByHash(gomock.Any(), gomock.Any()).Return(activeToken, nil),
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
+				mockRefreshTokenRepository.EXPECT().MarkRotated(gomock.Any(), "token-1", gomock.Any()).Return(false, nil),
+				mockRefreshTokenRepository.EXPECT().RevokeFamily(gomock.Any(), 1, "family-1").Return(nil),
+			},
+		},
+		{
+			name: "error finding token",
+			args: args{
+				context:      context.Background(),
+				refreshToken: "refresh",
+			},
+			want: want{
+				err: errors.New("unexpected error"),
+			},
+			calls: []*gomock.Call{
+				mockRefreshTokenRepository.EXPECT().FindByHash(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error")),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		calls := make([]interface{}, len(test.calls))
+		for i, c := range test.calls {
+			calls[i] = c
+		}
+
+		gomock.InOrder(calls...)
+
+		got, gotErr := authUseCase.Refresh(test.args.context, test.args.refreshToken)
+
+		assert.Equal(t, test.want.err, gotErr)
+		if test.want.err == nil {
+			assert.NotEmpty(t, got.AccessToken)
+			assert.NotEqual(t, test.args.refreshToken, got.RefreshToken)
+		}
+	}
+}
+
+func TestIssueTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRefreshTokenRepository := mock.NewMockRefreshTokenRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+
+	authUseCase := NewAuthUsecase(mockRefreshTokenRepository, mockUserRepository, Config{RequireEmailVerification: true})
+
+	verifiedAt := helpers.GetTime()
+	mockRefreshTokenRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	tokens, err := authUseCase.IssueTokens(context.Background(), &domain.User{ID: 1, Email: "test@test.com", EmailVerifiedAt: &verifiedAt})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	_, err = authUseCase.IssueTokens(context.Background(), &domain.User{ID: 1, Email: "test@test.com"})
+	assert.Equal(t, apperror.NewForbidden("Email address not verified", nil, "auth_usecase.go:IssueTokens"), err)
+}
+
+func TestLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRefreshTokenRepository := mock.NewMockRefreshTokenRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+
+	authUseCase := NewAuthUsecase(mockRefreshTokenRepository, mockUserRepository, Config{RequireEmailVerification: true})
+
+	mockRefreshTokenRepository.EXPECT().RevokeFamily(gomock.Any(), 1, "family-1").Return(nil)
+	assert.Nil(t, authUseCase.Logout(context.Background(), 1, "family-1"))
+
+	assert.Equal(t,
+		apperror.NewInvalidData("Token is not bound to a session", nil, "auth_usecase.go:Logout"),
+		authUseCase.Logout(context.Background(), 1, ""),
+	)
+}
+
+func TestValidateSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRefreshTokenRepository := mock.NewMockRefreshTokenRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+
+	authUseCase := NewAuthUsecase(mockRefreshTokenRepository, mockUserRepository, Config{RequireEmailVerification: true})
+
+	mockRefreshTokenRepository.EXPECT().IsFamilyActive(gomock.Any(), 1, "family-1").Return(true, nil)
+	assert.Nil(t, authUseCase.ValidateSession(context.Background(), 1, "family-1"))
+
+	mockRefreshTokenRepository.EXPECT().IsFamilyActive(gomock.Any(), 1, "family-2").Return(false, nil)
+	assert.Equal(t,
+		apperror.NewUnauthorized("Session has been revoked", nil, "auth_usecase.go:ValidateSession"),
+		authUseCase.ValidateSession(context.Background(), 1, "family-2"),
+	)
+
+	assert.Equal(t,
+		apperror.NewUnauthorized("Token is not bound to a session", nil, "auth_usecase.go:ValidateSession"),
+		authUseCase.ValidateSession(context.Background(), 1, ""),
+	)
+}
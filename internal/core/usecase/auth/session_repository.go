@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"sync"
+	"time"
+)
+
+// SessionCacheTTL is how long an active session is trusted without asking the
+// database. A logout made on another instance takes up to this long to apply there.
+const SessionCacheTTL = 30 * time.Second
+
+// maxCachedSessions bounds the memory used by the cache
+const maxCachedSessions = 10000
+
+// cachedRefreshTokenRepository remembers the active sessions so the access token
+// check does not query the database on every request. Only active sessions are
+// cached, and revoking through the repository forgets them right away and tells
+// the listener, which closes the sockets opened with them.
+type cachedRefreshTokenRepository struct {
+	domain.RefreshTokenRepository
+	listener domain.SessionListener
+	mutex    sync.Mutex
+	sessions map[int]map[string]time.Time
+	size     int
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+func NewCachedRefreshTokenRepository(repository domain.RefreshTokenRepository, ttl time.Duration, listener domain.SessionListener) domain.RefreshTokenRepository {
+	return &cachedRefreshTokenRepository{
+		RefreshTokenRepository: repository,
+		listener:               listener,
+		sessions:               make(map[int]map[string]time.Time),
+		ttl:                    ttl,
+		now:                    time.Now,
+	}
+}
+
+func (r *cachedRefreshTokenRepository) IsFamilyActive(ctx context.Context, userID int, familyID string) (bool, error) {
+	if r.cached(userID, familyID) {
+		return true, nil
+	}
+
+	active, err := r.RefreshTokenRepository.IsFamilyActive(ctx, userID, familyID)
+	if err != nil || !active {
+		return active, err
+	}
+
+	r.remember(userID, familyID)
+	return true, nil
+}
+
+func (r *cachedRefreshTokenRepository) RevokeFamily(ctx context.Context, userID int, familyID string) error {
+	err := r.RefreshTokenRepository.RevokeFamily(ctx, userID, familyID)
+	r.revoked(userID, err, func(sessionID string) bool { return sessionID == familyID })
+	return err
+}
+
+func (r *cachedRefreshTokenRepository) RevokeAllByUser(ctx context.Context, userID int) error {
+	err := r.RefreshTokenRepository.RevokeAllByUser(ctx, userID)
+	r.revoked(userID, err, func(string) bool { return true })
+	return err
+}
+
+func (r *cachedRefreshTokenRepository) RevokeAllByUserExcept(ctx context.Context, userID int, familyID string) error {
+	err := r.RefreshTokenRepository.RevokeAllByUserExcept(ctx, userID, familyID)
+	r.revoked(userID, err, func(sessionID string) bool { return sessionID != familyID })
+	return err
+}
+
+func (r *cachedRefreshTokenRepository) cached(userID int, familyID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	expiresAt, ok := r.sessions[userID][familyID]
+	return ok && r.now().Before(expiresAt)
+}
+
+func (r *cachedRefreshTokenRepository) remember(userID int, familyID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.now()
+	if r.size >= maxCachedSessions {
+		r.removeExpired(now)
+	}
+	if r.size >= maxCachedSessions {
+		return
+	}
+
+	if r.sessions[userID] == nil {
+		r.sessions[userID] = make(map[string]time.Time)
+	}
+	if _, ok := r.sessions[userID][familyID]; !ok {
+		r.size++
+	}
+	r.sessions[userID][familyID] = now.Add(r.ttl)
+}
+
+// revoked runs after the revocation, so the next check reads it from the database.
+// The sockets are only closed once the revocation was saved.
+func (r *cachedRefreshTokenRepository) revoked(userID int, err error, revoked func(sessionID string) bool) {
+	r.forget(userID, revoked)
+	if err == nil {
+		r.listener.SessionsRevoked(userID, revoked)
+	}
+}
+
+func (r *cachedRefreshTokenRepository) forget(userID int, revoked func(sessionID string) bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for sessionID := range r.sessions[userID] {
+		if revoked(sessionID) {
+			delete(r.sessions[userID], sessionID)
+			r.size--
+		}
+	}
+	if len(r.sessions[userID]) == 0 {
+		delete(r.sessions, userID)
+	}
+}
+
+func (r *cachedRefreshTokenRepository) removeExpired(now time.Time) {
+	for userID, sessions := range r.sessions {
+		for sessionID, expiresAt := range sessions {
+			if !now.Before(expiresAt) {
+				delete(sessions, sessionID)
+				r.size--
+			}
+		}
+		if len(sessions) == 0 {
+			delete(r.sessions, userID)
+		}
+	}
+}
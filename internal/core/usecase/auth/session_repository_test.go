@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain/mock"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type stubSessionListener struct {
+	revoked []bool
+}
+
+func (l *stubSessionListener) SessionsRevoked(userID int, revoked func(sessionID string) bool) {
+	l.revoked = append(l.revoked, revoked("family-1"), revoked("family-4"))
+}
+
+func TestCachedRefreshTokenRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	refreshTokenRepository := mock.NewMockRefreshTokenRepository(ctrl)
+	listener := &stubSessionListener{}
+	repository := NewCachedRefreshTokenRepository(refreshTokenRepository, 30*time.Second, listener).(*cachedRefreshTokenRepository)
+	repository.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	refreshTokenRepository.EXPECT().IsFamilyActive(gomock.Any(), 1, "family-1").Return(true, nil).Times(1)
+	for range 3 {
+		active, err := repository.IsFamilyActive(ctx, 1, "family-1")
+		assert.NoError(t, err)
+		assert.True(t, active)
+	}
+
+	refreshTokenRepository.EXPECT().IsFamilyActive(gomock.Any(), 1, "family-2").Return(false, nil).Times(2)
+	for range 2 {
+		active, _ := repository.IsFamilyActive(ctx, 1, "family-2")
+		assert.False(t, active, "revoked sessions are not cached")
+	}
+
+	refreshTokenRepository.EXPECT().RevokeFamily(gomock.Any(), 1, "family-1").Return(nil)
+	assert.NoError(t, repository.RevokeFamily(ctx, 1, "family-1"))
+
+	refreshTokenRepository.EXPECT().IsFamilyActive(gomock.Any(), 1, "family-1").Return(false, nil)
+	active, _ := repository.IsFamilyActive(ctx, 1, "family-1")
+	assert.False(t, active, "a logout applies right away")
+
+	refreshTokenRepository.EXPECT().IsFamilyActive(gomock.Any(), 1, "family-3").Return(true, nil).Times(2)
+	repository.IsFamilyActive(ctx, 1, "family-3")
+	now = now.Add(time.Minute)
+	active, _ = repository.IsFamilyActive(ctx, 1, "family-3")
+	assert.True(t, active, "expired entries are read again")
+
+	refreshTokenRepository.EXPECT().RevokeAllByUserExcept(gomock.Any(), 1, "family-4").Return(nil)
+	assert.NoError(t, repository.RevokeAllByUserExcept(ctx, 1, "family-4"))
+	assert.Empty(t, repository.sessions)
+
+	refreshTokenRepository.EXPECT().RevokeAllByUser(gomock.Any(), 1).Return(errors.New("db down"))
+	assert.Error(t, repository.RevokeAllByUser(ctx, 1))
+
+	// the logout closes family-1 sockets, the password change keeps family-4 ones, the failure closes none
+	assert.Equal(t, []bool{true, false, true, false}, listener.revoked)
+}
@@ -0,0 +1,113 @@
+package authorization
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/criteria"
+	"cpi-hub-api/pkg/apperror"
+)
+
+// MembershipPolicy decides what a user may do in a space based on whether they belong to it
+type MembershipPolicy interface {
+	RequireMember(ctx context.Context, spaceID int, userID int) error
+	CanView(ctx context.Context, space *domain.Space, userID int) (bool, error)
+	RequireView(ctx context.Context, space *domain.Space, userID int) error
+	HiddenSpaceIDs(ctx context.Context, userID int) ([]int, error)
+}
+
+type membershipPolicy struct {
+	spaceRepository     domain.SpaceRepository
+	userSpaceRepository domain.UserSpaceRepository
+}
+
+func NewMembershipPolicy(spaceRepo domain.SpaceRepository, userSpaceRepo domain.UserSpaceRepository) MembershipPolicy {
+	return &membershipPolicy{
+		spaceRepository:     spaceRepo,
+		userSpaceRepository: userSpaceRepo,
+	}
+}
+
+// RequireMember fails with Forbidden unless the user belongs to the space
+func (p *membershipPolicy) RequireMember(ctx context.Context, spaceID int, userID int) error {
+	isMember, err := p.userSpaceRepository.Exists(ctx, userID, spaceID)
+	if err != nil {
+		return err
+	}
+
+	if !isMember {
+		return apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireMember")
+	}
+
+	return nil
+}
+
+// CanView reports whether the user may see the space, private spaces are only visible to their members
+func (p *membershipPolicy) CanView(ctx context.Context, space *domain.Space, userID int) (bool, error) {
+	if !space.IsPrivate() {
+		return true, nil
+	}
+
+	return p.userSpaceRepository.Exists(ctx, userID, space.ID)
+}
+
+// RequireView fails with Forbidden when the user cannot see the space, used for the content inside it
+func (p *membershipPolicy) RequireView(ctx context.Context, space *domain.Space, userID int) error {
+	canView, err := p.CanView(ctx, space, userID)
+	if err != nil {
+		return err
+	}
+
+	if !canView {
+		return apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireView")
+	}
+
+	return nil
+}
+
+// HiddenSpaceIDs returns the private spaces the user does not belong to
+func (p *membershipPolicy) HiddenSpaceIDs(ctx context.Context, userID int) ([]int, error) {
+	privateSpaces, err := p.spaceRepository.FindAll(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("visibility", domain.SpaceVisibilityPrivate, criteria.OperatorEqual).
+		Build())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(privateSpaces) == 0 {
+		return []int{}, nil
+	}
+
+	memberSpaceIDs, err := p.userSpaceRepository.FindSpacesIDsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember := make(map[int]bool, len(memberSpaceIDs))
+	for _, id := range memberSpaceIDs {
+		isMember[id] = true
+	}
+
+	hidden := make([]int, 0, len(privateSpaces))
+	for _, space := range privateSpaces {
+		if !isMember[space.ID] {
+			hidden = append(hidden, space.ID)
+		}
+	}
+
+	return hidden, nil
+}
+
+// ExcludeSpaces keeps the content of the spaces out of a search. The filter is required so
+// an OR search cannot bring it back.
+func ExcludeSpaces(builder *criteria.CriteriaBuilder, field string, spaceIDs []int) *criteria.CriteriaBuilder {
+	if len(spaceIDs) == 0 {
+		return builder
+	}
+
+	ids := make([]interface{}, len(spaceIDs))
+	for i, id := range spaceIDs {
+		ids[i] = id
+	}
+
+	return builder.WithRequiredFilter(field, ids, criteria.OperatorNotIn)
+}
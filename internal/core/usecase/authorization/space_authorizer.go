@@ -0,0 +1,47 @@
+package authorization
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/pkg/apperror"
+)
+
+// SpaceAuthorizer answers permission questions about a user inside a space
+type SpaceAuthorizer interface {
+	Role(ctx context.Context, spaceID int, userID int) (domain.SpaceRole, error)
+	CanModerate(ctx context.Context, spaceID int, userID int) (bool, error)
+}
+
+type spaceAuthorizer struct {
+	userSpaceRepository domain.UserSpaceRepository
+}
+
+func NewSpaceAuthorizer(userSpaceRepo domain.UserSpaceRepository) SpaceAuthorizer {
+	return &spaceAuthorizer{
+		userSpaceRepository: userSpaceRepo,
+	}
+}
+
+// Role returns the role of the user in the space, empty when they are not a member
+func (a *spaceAuthorizer) Role(ctx context.Context, spaceID int, userID int) (domain.SpaceRole, error) {
+	return a.userSpaceRepository.FindRole(ctx, userID, spaceID)
+}
+
+// CanModerate reports whether the user may moderate the content of the space
+func (a *spaceAuthorizer) CanModerate(ctx context.Context, spaceID int, userID int) (bool, error) {
+	role, err := a.Role(ctx, spaceID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return role.CanModerate(), nil
+}
+
+// RequireWritable fails when the space is archived, archived spaces are read-only
+func RequireWritable(space *domain.Space) error {
+	if space.IsArchived() {
+		return apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable")
+	}
+
+	return nil
+}
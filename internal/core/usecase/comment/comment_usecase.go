@@ -5,6 +5,8 @@ import (
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/domain/criteria"
 	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/authorization"
+	pghelpers "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/helpers"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
 )
@@ -16,17 +18,23 @@ type SearchResult struct {
 
 type CommentUseCase interface {
 	Search(ctx context.Context, params dto.SearchCommentsParams) (*SearchResult, error)
-	Update(ctx context.Context, params dto.UpdateCommentDTO) error
-	Delete(ctx context.Context, commentID int) error
+	Update(ctx context.Context, userID int, params dto.UpdateCommentDTO) error
+	Delete(ctx context.Context, userID int, commentID int) error
 }
 
 type commentUseCase struct {
 	commentRepository domain.CommentRepository
+	spaceRepository   domain.SpaceRepository
+	spaceAuthorizer   authorization.SpaceAuthorizer
+	membershipPolicy  authorization.MembershipPolicy
 }
 
-func NewCommentUsecase(commentRepo domain.CommentRepository) CommentUseCase {
+func NewCommentUsecase(commentRepo domain.CommentRepository, spaceRepo domain.SpaceRepository, userSpaceRepo domain.UserSpaceRepository) CommentUseCase {
 	return &commentUseCase{
 		commentRepository: commentRepo,
+		spaceRepository:   spaceRepo,
+		spaceAuthorizer:   authorization.NewSpaceAuthorizer(userSpaceRepo),
+		membershipPolicy:  authorization.NewMembershipPolicy(spaceRepo, userSpaceRepo),
 	}
 }
 
@@ -36,7 +44,12 @@ func (c *commentUseCase) Search(ctx context.Context, params dto.SearchCommentsPa
 		sortDirection = criteria.OrderDirectionAsc
 	}
 
-	builder := criteria.NewCriteriaBuilder()
+	hiddenSpaceIDs, err := c.membershipPolicy.HiddenSpaceIDs(ctx, params.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := authorization.ExcludeSpaces(criteria.NewCriteriaBuilder(), "space_id", hiddenSpaceIDs)
 
 	if params.UserID != nil && *params.UserID > 0 {
 		builder.WithFilter("created_by", *params.UserID, criteria.OperatorEqual)
@@ -66,7 +79,7 @@ func (c *commentUseCase) Search(ctx context.Context, params dto.SearchCommentsPa
 	}, nil
 }
 
-func (c *commentUseCase) Update(ctx context.Context, params dto.UpdateCommentDTO) error {
+func (c *commentUseCase) Update(ctx context.Context, userID int, params dto.UpdateCommentDTO) error {
 
 	searchCriteria := criteria.NewCriteriaBuilder().
 		WithFilter("id", params.CommentID, criteria.OperatorEqual).
@@ -81,6 +94,14 @@ func (c *commentUseCase) Update(ctx context.Context, params dto.UpdateCommentDTO
 		return apperror.NewNotFound("comment not found", nil, "comment_usecase.go:Update")
 	}
 
+	if err := c.requireWritableSpace(ctx, existingComment); err != nil {
+		return err
+	}
+
+	if existingComment.Comment.CreatedBy != userID {
+		return apperror.NewForbidden("You can only edit your own comments", nil, "comment_usecase.go:Update")
+	}
+
 	existingComment.Comment.Content = params.Content
 	existingComment.Comment.UpdatedAt = helpers.GetTime()
 
@@ -91,7 +112,7 @@ func (c *commentUseCase) Update(ctx context.Context, params dto.UpdateCommentDTO
 	return nil
 }
 
-func (c *commentUseCase) Delete(ctx context.Context, commentID int) error {
+func (c *commentUseCase) Delete(ctx context.Context, userID int, commentID int) error {
 	searchCriteria := criteria.NewCriteriaBuilder().
 		WithFilter("id", commentID, criteria.OperatorEqual).
 		Build()
@@ -104,9 +125,34 @@ func (c *commentUseCase) Delete(ctx context.Context, commentID int) error {
 		return apperror.NewNotFound("comment not found", nil, "comment_usecase.go:Delete")
 	}
 
+	if err := c.requireWritableSpace(ctx, existingComment); err != nil {
+		return err
+	}
+
+	if existingComment.Comment.CreatedBy != userID {
+		canModerate, err := c.spaceAuthorizer.CanModerate(ctx, existingComment.Space.ID, userID)
+		if err != nil {
+			return err
+		}
+		if !canModerate {
+			return apperror.NewForbidden("You can only delete your own comments", nil, "comment_usecase.go:Delete")
+		}
+	}
+
 	if err := c.commentRepository.Delete(ctx, commentID); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// requireWritableSpace fails when the space of the comment is archived. The space joined by the
+// comment repository has no archived_at, so it is loaded again.
+func (c *commentUseCase) requireWritableSpace(ctx context.Context, comment *domain.CommentWithInfo) error {
+	space, err := pghelpers.FindEntity(ctx, c.spaceRepository, "id", comment.Space.ID, "Space not found")
+	if err != nil {
+		return err
+	}
+
+	return authorization.RequireWritable(space)
+}
@@ -0,0 +1,329 @@
+package comment
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/criteria"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/pkg/apperror"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSearch(t *testing.T) {
+	privateSpace := &domain.Space{ID: 5, Visibility: domain.SpaceVisibilityPrivate}
+	hiddenFilter := criteria.Filter{Field: "space_id", Value: []interface{}{5}, Operator: criteria.OperatorNotIn}
+
+	tests := []struct {
+		name            string
+		viewerID        int
+		memberSpaceIDs  []int
+		wantRequiredLen int
+	}{
+		{
+			name:            "non member does not see comments of private spaces",
+			viewerID:        2,
+			memberSpaceIDs:  []int{},
+			wantRequiredLen: 1,
+		},
+		{
+			name:            "member sees comments of their private spaces",
+			viewerID:        1,
+			memberSpaceIDs:  []int{5},
+			wantRequiredLen: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+			mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+			mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+
+			commentUseCase := NewCommentUsecase(mockCommentRepository, mockSpaceRepository, mockUserSpaceRepository)
+
+			assertHidden := func(c *criteria.Criteria) {
+				assert.Len(t, c.RequiredFilters, tt.wantRequiredLen)
+				if tt.wantRequiredLen > 0 {
+					assert.Equal(t, hiddenFilter, c.RequiredFilters[0])
+				}
+			}
+
+			mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{privateSpace}, nil)
+			mockUserSpaceRepository.EXPECT().FindSpacesIDsByUserID(gomock.Any(), tt.viewerID).Return(tt.memberSpaceIDs, nil)
+			mockCommentRepository.EXPECT().Count(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *criteria.Criteria) (int, error) {
+				assertHidden(c)
+				return 0, nil
+			})
+			mockCommentRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *criteria.Criteria) ([]*domain.CommentWithInfo, error) {
+				assertHidden(c)
+				return []*domain.CommentWithInfo{}, nil
+			})
+
+			postID := 10
+			result, err := commentUseCase.Search(context.Background(), dto.SearchCommentsParams{PostID: &postID, ViewerID: tt.viewerID})
+
+			assert.NoError(t, err)
+			assert.Empty(t, result.Comments)
+			assert.Equal(t, 0, result.Total)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+
+	commentUseCase := NewCommentUsecase(mockCommentRepository, mockSpaceRepository, mockUserSpaceRepository)
+
+	type args struct {
+		context context.Context
+		userID  int
+		params  dto.UpdateCommentDTO
+	}
+
+	type want struct {
+		err error
+	}
+
+	givenComment := &domain.CommentWithInfo{
+		Comment: &domain.Comment{ID: 1, PostID: 1, Content: "Test Comment", CreatedBy: 1},
+		Space:   &domain.Space{ID: 1},
+	}
+
+	archivedAt := time.Now()
+	givenSpace := &domain.Space{ID: 1}
+	archivedSpace := &domain.Space{ID: 1, ArchivedAt: &archivedAt}
+
+	tests := []struct {
+		name  string
+		args  args
+		want  want
+		calls []*gomock.Call
+	}{
+		{
+			name: "success",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				params:  dto.UpdateCommentDTO{CommentID: 1, Content: "Edited"},
+			},
+			want: want{},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenComment, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockCommentRepository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil),
+			},
+		},
+		{
+			name: "error comment not found",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				params:  dto.UpdateCommentDTO{CommentID: 1, Content: "Edited"},
+			},
+			want: want{
+				err: apperror.NewNotFound("comment not found", nil, "comment_usecase.go:Update"),
+			},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil),
+			},
+		},
+		{
+			name: "error user is not the author",
+			args: args{
+				context: context.Background(),
+				userID:  2,
+				params:  dto.UpdateCommentDTO{CommentID: 1, Content: "Edited"},
+			},
+			want: want{
+				err: apperror.NewForbidden("You can only edit your own comments", nil, "comment_usecase.go:Update"),
+			},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenComment, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+			},
+		},
+		{
+			name: "error updating comment",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				params:  dto.UpdateCommentDTO{CommentID: 1, Content: "Edited"},
+			},
+			want: want{
+				err: errors.New("unexpected error"),
+			},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenComment, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockCommentRepository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("unexpected error")),
+			},
+		},
+		{
+			name: "error space is archived",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				params:  dto.UpdateCommentDTO{CommentID: 1, Content: "Edited"},
+			},
+			want: want{
+				err: apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable"),
+			},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenComment, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(archivedSpace, nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		calls := make([]interface{}, len(test.calls))
+		for i, c := range test.calls {
+			calls[i] = c
+		}
+
+		gomock.InOrder(calls...)
+
+		gotErr := commentUseCase.Update(test.args.context, test.args.userID, test.args.params)
+
+		assert.Equal(t, test.want.err, gotErr)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+
+	commentUseCase := NewCommentUsecase(mockCommentRepository, mockSpaceRepository, mockUserSpaceRepository)
+
+	type args struct {
+		context   context.Context
+		userID    int
+		commentID int
+	}
+
+	type want struct {
+		err error
+	}
+
+	givenComment := &domain.CommentWithInfo{
+		Comment: &domain.Comment{ID: 1, PostID: 1, Content: "Test Comment", CreatedBy: 1},
+		Space:   &domain.Space{ID: 1},
+	}
+
+	archivedAt := time.Now()
+	givenSpace := &domain.Space{ID: 1}
+	archivedSpace := &domain.Space{ID: 1, ArchivedAt: &archivedAt}
+
+	tests := []struct {
+		name  string
+		args  args
+		want  want
+		calls []*gomock.Call
+	}{
+		{
+			name: "success author deletes own comment",
+			args: args{
+				context:   context.Background(),
+				userID:    1,
+				commentID: 1,
+			},
+			want: want{},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenComment, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockCommentRepository.EXPECT().Delete(gomock.Any(), 1).Return(nil),
+			},
+		},
+		{
+			name: "success moderator deletes comment",
+			args: args{
+				context:   context.Background(),
+				userID:    3,
+				commentID: 1,
+			},
+			want: want{},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenComment, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 3, 1).Return(domain.SpaceRoleModerator, nil),
+				mockCommentRepository.EXPECT().Delete(gomock.Any(), 1).Return(nil),
+			},
+		},
+		{
+			name: "error user cannot moderate space",
+			args: args{
+				context:   context.Background(),
+				userID:    2,
+				commentID: 1,
+			},
+			want: want{
+				err: apperror.NewForbidden("You can only delete your own comments", nil, "comment_usecase.go:Delete"),
+			},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenComment, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 1).Return(domain.SpaceRoleMember, nil),
+			},
+		},
+		{
+			name: "error comment not found",
+			args: args{
+				context:   context.Background(),
+				userID:    1,
+				commentID: 1,
+			},
+			want: want{
+				err: apperror.NewNotFound("comment not found", nil, "comment_usecase.go:Delete"),
+			},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil),
+			},
+		},
+		{
+			name: "error space is archived",
+			args: args{
+				context:   context.Background(),
+				userID:    1,
+				commentID: 1,
+			},
+			want: want{
+				err: apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable"),
+			},
+			calls: []*gomock.Call{
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenComment, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(archivedSpace, nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		calls := make([]interface{}, len(test.calls))
+		for i, c := range test.calls {
+			calls[i] = c
+		}
+
+		gomock.InOrder(calls...)
+
+		gotErr := commentUseCase.Delete(test.args.context, test.args.userID, test.args.commentID)
+
+		assert.Equal(t, test.want.err, gotErr)
+	}
+}
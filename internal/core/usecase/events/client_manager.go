@@ -2,6 +2,8 @@ package events
 
 import (
 	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
 	"encoding/json"
 	"log"
@@ -10,16 +12,31 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// ChatMessageSender valida, guarda y difunde los mensajes de chat y sus cambios,
+// es el mismo camino que usan los endpoints REST. También registra las lecturas y
+// difunde los avisos de escritura.
+type ChatMessageSender interface {
+	SendChatMessage(params dto.EventsBroadcastParams) (*domain.ChatMessage, error)
+	EditMessage(params dto.EditChatMessageParams) (*domain.ChatMessage, error)
+	DeleteMessage(params dto.DeleteChatMessageParams) (*domain.ChatMessage, error)
+	MarkRead(params dto.MarkReadParams) (*domain.ReadReceipt, error)
+	SendTyping(typing domain.TypingMessage)
+}
+
 // ClientManager maneja las operaciones del cliente
 type ClientManager struct {
 	client *domain.Client
+	chat   ChatMessageSender
 	config *WebSocketConfig
+	// lastTypingAt es el último aviso de escritura difundido, los siguientes se descartan durante TypingThrottle
+	lastTypingAt time.Time
 }
 
 // NewClientManager crea una nueva instancia del ClientManager
-func NewClientManager(client *domain.Client) *ClientManager {
+func NewClientManager(client *domain.Client, chat ChatMessageSender) *ClientManager {
 	return &ClientManager{
 		client: client,
+		chat:   chat,
 		config: DefaultWebSocketConfig(),
 	}
 }
@@ -101,7 +118,7 @@ func (cm *ClientManager) handleMessage(messageBytes []byte) {
 	var wsMsg domain.EventMessage
 	if err := json.Unmarshal(messageBytes, &wsMsg); err != nil {
 		log.Printf("Error unmarshaling message: %v", err)
-		cm.sendError("invalid_message_format", "Invalid message format")
+		cm.sendError("", "invalid_message_format", "Invalid message format")
 		return
 	}
 
@@ -114,56 +131,162 @@ func (cm *ClientManager) handleMessage(messageBytes []byte) {
 	switch wsMsg.Type {
 	case domain.MessageTypeChat:
 		cm.handleChatMessage(wsMsg)
+	case domain.MessageTypeEdit:
+		cm.handleEditMessage(wsMsg)
+	case domain.MessageTypeDelete:
+		cm.handleDeleteMessage(wsMsg)
+	case domain.MessageTypeTyping:
+		cm.handleTyping()
+	case domain.MessageTypeRead:
+		cm.handleRead(wsMsg)
 	case domain.MessageTypePing:
 		cm.handlePing(wsMsg)
 	default:
 		log.Printf("Unknown message type: %s", wsMsg.Type)
-		cm.sendError("unknown_message_type", "Unknown message type")
+		cm.sendError(wsMsg.CorrelationID, "unknown_message_type", "Unknown message type")
 	}
 }
 
-// handleChatMessage procesa mensajes de chat
+// handleChatMessage guarda y difunde un mensaje de chat, y confirma al emisor
+// con el ID asignado por el servidor
 func (cm *ClientManager) handleChatMessage(wsMsg domain.EventMessage) {
-	// Convertir data a ChatMessage
-	dataBytes, err := json.Marshal(wsMsg.Data)
-	if err != nil {
-		log.Printf("Error marshaling chat data: %v", err)
-		cm.sendError("invalid_chat_data", "Invalid chat data")
+	var chatMsg domain.ChatMessage
+	if !cm.decodeData(wsMsg, &chatMsg) {
 		return
 	}
 
-	var chatMsg domain.ChatMessage
-	if err := json.Unmarshal(dataBytes, &chatMsg); err != nil {
-		log.Printf("Error unmarshaling chat message: %v", err)
-		cm.sendError("invalid_chat_message", "Invalid chat message")
+	// La identidad y el espacio salen de la conexión, nunca del mensaje
+	savedMsg, err := cm.chat.SendChatMessage(dto.EventsBroadcastParams{
+		SpaceID:   cm.client.SpaceID,
+		UserID:    cm.client.UserID,
+		Message:   chatMsg.Content,
+		Image:     cm.client.Image,
+		ReplyToID: chatMsg.ReplyToID,
+	})
+	cm.acknowledge(wsMsg.CorrelationID, savedMsg, err)
+}
+
+// handleEditMessage edita un mensaje propio o, si es moderador, de otro usuario
+func (cm *ClientManager) handleEditMessage(wsMsg domain.EventMessage) {
+	var ref domain.ChatMessageRef
+	if !cm.decodeData(wsMsg, &ref) {
+		return
+	}
+
+	editedMsg, err := cm.chat.EditMessage(dto.EditChatMessageParams{
+		MessageID: ref.ID,
+		UserID:    cm.client.UserID,
+		Content:   ref.Content,
+	})
+	cm.acknowledge(wsMsg.CorrelationID, editedMsg, err)
+}
+
+// handleDeleteMessage borra un mensaje propio o, si es moderador, de otro usuario
+func (cm *ClientManager) handleDeleteMessage(wsMsg domain.EventMessage) {
+	var ref domain.ChatMessageRef
+	if !cm.decodeData(wsMsg, &ref) {
+		return
+	}
+
+	deletedMsg, err := cm.chat.DeleteMessage(dto.DeleteChatMessageParams{
+		MessageID: ref.ID,
+		UserID:    cm.client.UserID,
+	})
+	cm.acknowledge(wsMsg.CorrelationID, deletedMsg, err)
+}
+
+// handleTyping difunde que el cliente está escribiendo, como mucho una vez por TypingThrottle
+func (cm *ClientManager) handleTyping() {
+	now := helpers.GetTime()
+	if now.Sub(cm.lastTypingAt) < cm.config.TypingThrottle {
 		return
 	}
+	cm.lastTypingAt = now
 
-	// Validar el mensaje
-	if err := cm.validateChatMessage(&chatMsg); err != nil {
-		cm.sendError("validation_error", err.Error())
+	cm.chat.SendTyping(domain.TypingMessage{
+		SpaceID:  cm.client.SpaceID,
+		UserID:   cm.client.UserID,
+		Username: cm.client.Username,
+	})
+}
+
+// handleRead registra el último mensaje leído por el cliente en su espacio
+func (cm *ClientManager) handleRead(wsMsg domain.EventMessage) {
+	var ref domain.ChatMessageRef
+	if !cm.decodeData(wsMsg, &ref) {
+		return
+	}
+
+	receipt, err := cm.chat.MarkRead(dto.MarkReadParams{
+		SpaceID:   cm.client.SpaceID,
+		UserID:    cm.client.UserID,
+		MessageID: ref.ID,
+	})
+	if err != nil {
+		cm.sendChatError(wsMsg.CorrelationID, err)
 		return
 	}
 
-	// Establecer información del cliente
-	chatMsg.UserID = cm.client.UserID
-	chatMsg.SpaceID = cm.client.SpaceID
-	chatMsg.Timestamp = helpers.GetTime()
-	chatMsg.ID = helpers.NewULID()
+	cm.sendMessage(domain.EventMessage{
+		Type:          domain.MessageTypeAck,
+		Data:          domain.ChatAckMessage{ID: receipt.MessageID, Timestamp: receipt.ReadAt},
+		Timestamp:     helpers.GetTime(),
+		UserID:        cm.client.UserID,
+		SpaceID:       cm.client.SpaceID,
+		CorrelationID: wsMsg.CorrelationID,
+	})
+}
+
+// decodeData convierte el campo data del mensaje, avisando al cliente si no es válido
+func (cm *ClientManager) decodeData(wsMsg domain.EventMessage, target interface{}) bool {
+	dataBytes, err := json.Marshal(wsMsg.Data)
+	if err != nil {
+		log.Printf("Error marshaling chat data: %v", err)
+		cm.sendError(wsMsg.CorrelationID, "invalid_chat_data", "Invalid chat data")
+		return false
+	}
+
+	if err := json.Unmarshal(dataBytes, target); err != nil {
+		log.Printf("Error unmarshaling chat message: %v", err)
+		cm.sendError(wsMsg.CorrelationID, "invalid_chat_message", "Invalid chat message")
+		return false
+	}
 
-	// Difundir el mensaje al espacio
-	cm.broadcastChatMessage(&chatMsg)
+	return true
 }
 
-// validateChatMessage valida un mensaje de chat
-func (cm *ClientManager) validateChatMessage(chatMsg *domain.ChatMessage) error {
-	if chatMsg.Content == "" {
-		return domain.ErrEmptyMessage
+// acknowledge confirma al emisor con el ID del mensaje o le envía el error
+func (cm *ClientManager) acknowledge(correlationID string, chatMsg *domain.ChatMessage, err error) {
+	if err != nil {
+		cm.sendChatError(correlationID, err)
+		return
 	}
-	if len(chatMsg.Content) > 1000 {
-		return domain.ErrMessageTooLong
+
+	cm.sendMessage(domain.EventMessage{
+		Type:          domain.MessageTypeAck,
+		Data:          domain.ChatAckMessage{ID: chatMsg.ID, Timestamp: chatMsg.Timestamp},
+		Timestamp:     helpers.GetTime(),
+		UserID:        cm.client.UserID,
+		SpaceID:       cm.client.SpaceID,
+		CorrelationID: correlationID,
+	})
+}
+
+// sendChatError traduce el error de guardar un mensaje a un error para el cliente
+func (cm *ClientManager) sendChatError(correlationID string, err error) {
+	switch {
+	case err == domain.ErrEmptyMessage || err == domain.ErrMessageTooLong:
+		cm.sendError(correlationID, "validation_error", err.Error())
+	case apperror.Is(err, apperror.Forbidden):
+		cm.sendError(correlationID, "forbidden", "You are not allowed to do this in this space")
+	case apperror.Is(err, apperror.NotFound):
+		cm.sendError(correlationID, "not_found", "Message not found")
+	case apperror.Is(err, apperror.InvalidData):
+		cm.sendError(correlationID, "invalid_data", "Invalid chat message")
+	default:
+		log.Printf("Error saving chat message: %v", err)
+		cm.sendError(correlationID, "message_not_saved", "The message could not be saved")
 	}
-	return nil
 }
 
 // handlePing procesa mensajes ping
@@ -180,49 +303,19 @@ func (cm *ClientManager) handlePing(wsMsg domain.EventMessage) {
 }
 
 // sendError envía un mensaje de error al cliente
-func (cm *ClientManager) sendError(code, message string) {
+func (cm *ClientManager) sendError(correlationID, code, message string) {
 	errorMsg := domain.EventMessage{
-		Type:      domain.MessageTypeError,
-		Data:      domain.ErrorMessage{Code: code, Message: message},
-		Timestamp: helpers.GetTime(),
-		UserID:    cm.client.UserID,
-		SpaceID:   cm.client.SpaceID,
+		Type:          domain.MessageTypeError,
+		Data:          domain.ErrorMessage{Code: code, Message: message},
+		Timestamp:     helpers.GetTime(),
+		UserID:        cm.client.UserID,
+		SpaceID:       cm.client.SpaceID,
+		CorrelationID: correlationID,
 	}
 
 	cm.sendMessage(errorMsg)
 }
 
-// broadcastChatMessage difunde un mensaje de chat al espacio
-func (cm *ClientManager) broadcastChatMessage(chatMsg *domain.ChatMessage) {
-	wsMsg := domain.EventMessage{
-		Type:      domain.MessageTypeChat,
-		Data:      chatMsg,
-		Timestamp: helpers.GetTime(),
-		UserID:    chatMsg.UserID,
-		SpaceID:   chatMsg.SpaceID,
-	}
-
-	messageBytes, err := json.Marshal(wsMsg)
-	if err != nil {
-		log.Printf("Error marshaling chat message: %v", err)
-		return
-	}
-
-	// Enviar mensaje a todos los clientes del mismo espacio
-	spaceMsg := domain.SpaceMessage{
-		SpaceID: chatMsg.SpaceID,
-		Message: messageBytes,
-	}
-
-	select {
-	case cm.client.Hub.SpaceBroadcast <- spaceMsg:
-		log.Printf("Chat message broadcasted: user %d in space %d: %s",
-			chatMsg.UserID, chatMsg.SpaceID, chatMsg.Content)
-	default:
-		log.Printf("Could not broadcast message to space %d", chatMsg.SpaceID)
-	}
-}
-
 // sendMessage envía un mensaje al cliente
 func (cm *ClientManager) sendMessage(wsMsg domain.EventMessage) {
 	messageBytes, err := json.Marshal(wsMsg)
@@ -0,0 +1,189 @@
+package events
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/pkg/apperror"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubChatSender struct {
+	params     dto.EventsBroadcastParams
+	editParams dto.EditChatMessageParams
+	deletedID  string
+	readParams dto.MarkReadParams
+	typing     []domain.TypingMessage
+	err        error
+}
+
+func (s *stubChatSender) SendChatMessage(params dto.EventsBroadcastParams) (*domain.ChatMessage, error) {
+	s.params = params
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &domain.ChatMessage{ID: "01HSERVER", Content: params.Message, UserID: params.UserID, SpaceID: params.SpaceID, Timestamp: time.Now()}, nil
+}
+
+func (s *stubChatSender) EditMessage(params dto.EditChatMessageParams) (*domain.ChatMessage, error) {
+	s.editParams = params
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &domain.ChatMessage{ID: params.MessageID, Content: params.Content}, nil
+}
+
+func (s *stubChatSender) DeleteMessage(params dto.DeleteChatMessageParams) (*domain.ChatMessage, error) {
+	s.deletedID = params.MessageID
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &domain.ChatMessage{ID: params.MessageID}, nil
+}
+
+func (s *stubChatSender) MarkRead(params dto.MarkReadParams) (*domain.ReadReceipt, error) {
+	s.readParams = params
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &domain.ReadReceipt{UserID: params.UserID, SpaceID: params.SpaceID, MessageID: params.MessageID, ReadAt: time.Now()}, nil
+}
+
+func (s *stubChatSender) SendTyping(typing domain.TypingMessage) {
+	s.typing = append(s.typing, typing)
+}
+
+type receivedEvent struct {
+	Type          domain.MessageType `json:"type"`
+	Data          json.RawMessage    `json:"data"`
+	CorrelationID string             `json:"correlation_id"`
+}
+
+func newTestClientManager(sender ChatMessageSender) *ClientManager {
+	client := &domain.Client{UserID: 7, SpaceID: 3, Image: "avatar.png", Send: make(chan []byte, 4)}
+	return NewClientManager(client, sender)
+}
+
+func readEvent(t *testing.T, cm *ClientManager) receivedEvent {
+	var event receivedEvent
+	select {
+	case raw := <-cm.client.Send:
+		assert.NoError(t, json.Unmarshal(raw, &event))
+	default:
+		t.Fatal("expected a message for the sender")
+	}
+	return event
+}
+
+func TestHandleChatMessage(t *testing.T) {
+	t.Run("persists through the sender and acknowledges with the server ID", func(t *testing.T) {
+		sender := &stubChatSender{}
+		cm := newTestClientManager(sender)
+
+		cm.handleMessage([]byte(`{"type":"chat","correlation_id":"c-1","user_id":99,"data":{"content":"hola","user_id":99,"space_id":42}}`))
+
+		assert.Equal(t, dto.EventsBroadcastParams{SpaceID: 3, UserID: 7, Message: "hola", Image: "avatar.png"}, sender.params)
+
+		event := readEvent(t, cm)
+		assert.Equal(t, domain.MessageTypeAck, event.Type)
+		assert.Equal(t, "c-1", event.CorrelationID)
+
+		var ack domain.ChatAckMessage
+		assert.NoError(t, json.Unmarshal(event.Data, &ack))
+		assert.Equal(t, "01HSERVER", ack.ID)
+	})
+
+	t.Run("forwards the reply target", func(t *testing.T) {
+		sender := &stubChatSender{}
+		cm := newTestClientManager(sender)
+
+		cm.handleMessage([]byte(`{"type":"chat","data":{"content":"yes","reply_to_id":"01HPARENT"}}`))
+
+		if assert.NotNil(t, sender.params.ReplyToID) {
+			assert.Equal(t, "01HPARENT", *sender.params.ReplyToID)
+		}
+		assert.Equal(t, domain.MessageTypeAck, readEvent(t, cm).Type)
+	})
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{name: "validation error", err: domain.ErrEmptyMessage, wantCode: "validation_error"},
+		{name: "not a member", err: apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireMember"), wantCode: "forbidden"},
+		{name: "message not found", err: apperror.NewNotFound("Message not found", nil, "events_usecase.go:findModifiableMessage"), wantCode: "not_found"},
+		{name: "storage failure", err: errors.New("db down"), wantCode: "message_not_saved"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cm := newTestClientManager(&stubChatSender{err: test.err})
+
+			cm.handleMessage([]byte(`{"type":"chat","correlation_id":"c-2","data":{"content":"hola"}}`))
+
+			event := readEvent(t, cm)
+			assert.Equal(t, domain.MessageTypeError, event.Type)
+			assert.Equal(t, "c-2", event.CorrelationID)
+
+			var errorMsg domain.ErrorMessage
+			assert.NoError(t, json.Unmarshal(event.Data, &errorMsg))
+			assert.Equal(t, test.wantCode, errorMsg.Code)
+		})
+	}
+}
+
+func TestHandleEditAndDeleteMessage(t *testing.T) {
+	sender := &stubChatSender{}
+	cm := newTestClientManager(sender)
+
+	cm.handleMessage([]byte(`{"type":"edit","correlation_id":"e-1","data":{"id":"01HMSG","content":"fixed"}}`))
+
+	assert.Equal(t, dto.EditChatMessageParams{MessageID: "01HMSG", UserID: 7, Content: "fixed"}, sender.editParams)
+	event := readEvent(t, cm)
+	assert.Equal(t, domain.MessageTypeAck, event.Type)
+	assert.Equal(t, "e-1", event.CorrelationID)
+
+	cm.handleMessage([]byte(`{"type":"delete","correlation_id":"d-1","data":{"id":"01HMSG"}}`))
+
+	assert.Equal(t, "01HMSG", sender.deletedID)
+	event = readEvent(t, cm)
+	assert.Equal(t, domain.MessageTypeAck, event.Type)
+	assert.Equal(t, "d-1", event.CorrelationID)
+}
+
+func TestHandleTyping(t *testing.T) {
+	sender := &stubChatSender{}
+	cm := newTestClientManager(sender)
+
+	cm.handleMessage([]byte(`{"type":"typing"}`))
+	cm.handleMessage([]byte(`{"type":"typing"}`))
+
+	assert.Equal(t, []domain.TypingMessage{{SpaceID: 3, UserID: 7}}, sender.typing)
+	assert.Empty(t, cm.client.Send, "typing is not acknowledged")
+
+	cm.lastTypingAt = time.Now().Add(-cm.config.TypingThrottle)
+	cm.handleMessage([]byte(`{"type":"typing"}`))
+
+	assert.Len(t, sender.typing, 2)
+}
+
+func TestHandleRead(t *testing.T) {
+	sender := &stubChatSender{}
+	cm := newTestClientManager(sender)
+
+	cm.handleMessage([]byte(`{"type":"read","correlation_id":"r-1","data":{"id":"01HMSG"}}`))
+
+	assert.Equal(t, dto.MarkReadParams{SpaceID: 3, UserID: 7, MessageID: "01HMSG"}, sender.readParams)
+	event := readEvent(t, cm)
+	assert.Equal(t, domain.MessageTypeAck, event.Type)
+	assert.Equal(t, "r-1", event.CorrelationID)
+}
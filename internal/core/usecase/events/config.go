@@ -15,8 +15,16 @@ type WebSocketConfig struct {
 
 	// Connection limits
 	MaxConnections int
+
+	// TypingThrottle es el tiempo mínimo entre dos avisos de escritura del mismo cliente
+	TypingThrottle time.Duration
 }
 
+// websocketSubprotocols son los subprotocolos aceptados en el handshake. Un
+// cliente que envía "bearer, <jwt>" en Sec-WebSocket-Protocol recibe "bearer"
+// como subprotocolo elegido, el token nunca se refleja en la respuesta.
+var websocketSubprotocols = []string{"bearer"}
+
 // DefaultWebSocketConfig retorna la configuración por defecto para WebSocket
 func DefaultWebSocketConfig() *WebSocketConfig {
 	return &WebSocketConfig{
@@ -26,6 +34,7 @@ func DefaultWebSocketConfig() *WebSocketConfig {
 		MaxMessageSize: 512,
 		SendBufferSize: 256,
 		MaxConnections: 50,
+		TypingThrottle: 3 * time.Second,
 	}
 }
 
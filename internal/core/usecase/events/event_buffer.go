@@ -0,0 +1,109 @@
+package events
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"crypto/rand"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// DefaultReplayBufferSize es la cantidad de eventos recientes que se guardan por espacio.
+// Debe ser menor que SendBufferSize para que el reenvío entre en el canal del cliente,
+// TestReplayFitsInSendBuffer lo verifica.
+const DefaultReplayBufferSize = 100
+
+type bufferedEvent struct {
+	id      string
+	payload []byte
+}
+
+type spaceEvents struct {
+	events []bufferedEvent
+	// evictedID es el último evento descartado, un cliente anterior a él perdió eventos
+	evictedID string
+}
+
+// EventBuffer asigna IDs crecientes a los eventos de cada espacio y guarda los
+// más recientes para reenviarlos a los clientes que se reconectan
+type EventBuffer struct {
+	spaces  map[int]*spaceEvents
+	size    int
+	mutex   sync.Mutex
+	entropy *ulid.MonotonicEntropy
+	now     func() time.Time
+	// startID marca el arranque del servidor, los eventos anteriores ya no se conocen
+	startID string
+}
+
+func NewEventBuffer(size int) *EventBuffer {
+	buffer := &EventBuffer{
+		spaces:  make(map[int]*spaceEvents),
+		size:    size,
+		entropy: ulid.Monotonic(rand.Reader, 0),
+		now:     time.Now,
+	}
+	buffer.startID = buffer.nextID()
+
+	return buffer
+}
+
+// Append asigna un EventID al mensaje, lo guarda y retorna el mensaje serializado.
+// send se ejecuta con el lock tomado para que los eventos salgan en el orden de sus IDs.
+func (b *EventBuffer) Append(spaceID int, message domain.EventMessage, send func(eventID string, payload []byte)) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	message.EventID = b.nextID()
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	space := b.space(spaceID)
+	space.events = append(space.events, bufferedEvent{id: message.EventID, payload: payload})
+	if len(space.events) > b.size {
+		space.evictedID = space.events[0].id
+		space.events = space.events[1:]
+	}
+
+	send(message.EventID, payload)
+	return nil
+}
+
+// Since retorna los eventos del espacio posteriores a lastEventID y el ID del último
+// evento conocido. ok es false cuando hay eventos perdidos que ya no están en el buffer.
+func (b *EventBuffer) Since(spaceID int, lastEventID string) (missed [][]byte, latestID string, ok bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	space := b.space(spaceID)
+	if lastEventID < b.startID || lastEventID < space.evictedID {
+		return nil, "", false
+	}
+
+	latestID = lastEventID
+	for _, event := range space.events {
+		if event.id > lastEventID {
+			missed = append(missed, event.payload)
+			latestID = event.id
+		}
+	}
+
+	return missed, latestID, true
+}
+
+func (b *EventBuffer) space(spaceID int) *spaceEvents {
+	space, exists := b.spaces[spaceID]
+	if !exists {
+		space = &spaceEvents{}
+		b.spaces[spaceID] = space
+	}
+	return space
+}
+
+func (b *EventBuffer) nextID() string {
+	return ulid.MustNew(ulid.Timestamp(b.now()), b.entropy).String()
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func appendEvents(t *testing.T, buffer *EventBuffer, spaceID, count int) []string {
+	ids := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		err := buffer.Append(spaceID, domain.EventMessage{Type: domain.MessageTypeChat, SpaceID: spaceID}, func(eventID string, _ []byte) {
+			ids = append(ids, eventID)
+		})
+		assert.NoError(t, err)
+	}
+	return ids
+}
+
+func TestEventBufferSince(t *testing.T) {
+	buffer := NewEventBuffer(3)
+	ids := appendEvents(t, buffer, 1, 2)
+	appendEvents(t, buffer, 2, 1)
+
+	assert.True(t, ids[0] < ids[1], "event IDs must increase")
+
+	t.Run("returns only the events after the cursor", func(t *testing.T) {
+		missed, latestID, ok := buffer.Since(1, ids[0])
+
+		assert.True(t, ok)
+		assert.Equal(t, ids[1], latestID)
+		if assert.Len(t, missed, 1) {
+			var event domain.EventMessage
+			assert.NoError(t, json.Unmarshal(missed[0], &event))
+			assert.Equal(t, ids[1], event.EventID)
+		}
+	})
+
+	t.Run("nothing missed keeps the cursor", func(t *testing.T) {
+		missed, latestID, ok := buffer.Since(1, ids[1])
+
+		assert.True(t, ok)
+		assert.Empty(t, missed)
+		assert.Equal(t, ids[1], latestID)
+	})
+
+	t.Run("cursor from before the server started", func(t *testing.T) {
+		_, _, ok := buffer.Since(1, "01A00000000000000000000000")
+
+		assert.False(t, ok)
+	})
+
+	t.Run("cursor older than the buffer", func(t *testing.T) {
+		appendEvents(t, buffer, 1, 3)
+
+		_, _, ok := buffer.Since(1, ids[0])
+
+		assert.False(t, ok)
+	})
+}
+
+func TestReplayMissedEvents(t *testing.T) {
+	hm := NewHubManager()
+	ids := appendEvents(t, hm.events, 3, 2)
+
+	t.Run("replays missed events", func(t *testing.T) {
+		client := &domain.Client{SpaceID: 3, LastEventID: ids[0], Send: make(chan []byte, 4)}
+
+		hm.replayMissedEvents(client)
+
+		assert.Len(t, client.Send, 1)
+		assert.Equal(t, ids[1], client.LastEventID)
+	})
+
+	t.Run("asks to resync when the gap is too large", func(t *testing.T) {
+		client := &domain.Client{SpaceID: 3, LastEventID: "01A00000000000000000000000", Send: make(chan []byte, 4)}
+
+		hm.replayMissedEvents(client)
+
+		var event domain.EventMessage
+		assert.NoError(t, json.Unmarshal(<-client.Send, &event))
+		assert.Equal(t, domain.MessageTypeResync, event.Type)
+		assert.Empty(t, client.LastEventID)
+	})
+
+	t.Run("closes the client instead of blocking the hub", func(t *testing.T) {
+		client := &domain.Client{SpaceID: 3, LastEventID: ids[0], Send: make(chan []byte)}
+
+		assert.False(t, hm.replayMissedEvents(client))
+
+		_, open := <-client.Send
+		assert.False(t, open)
+	})
+}
+
+func TestReplayFitsInSendBuffer(t *testing.T) {
+	assert.Less(t, DefaultReplayBufferSize, DefaultWebSocketConfig().SendBufferSize)
+}
@@ -1,12 +1,18 @@
 package events
 
 import (
+	"context"
 	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/criteria"
 	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/authorization"
+	"cpi-hub-api/internal/core/usecase/notification"
+	pghelpers "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/helpers"
 	websocketAdapter "cpi-hub-api/internal/infrastructure/adapters/websocket"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gorilla/websocket"
@@ -16,9 +22,14 @@ type EventsUsecase struct {
 	hubManager          *HubManager
 	userConnManager     domain.UserConnectionManager
 	notificationManager domain.NotificationManager
+	notificationUsecase notification.NotificationUsecase
 	repository          domain.EventsRepository
 	userRepository      domain.UserRepository
 	spaceRepository     domain.SpaceRepository
+	userSpaceRepository domain.UserSpaceRepository
+	membershipPolicy    authorization.MembershipPolicy
+	spaceAuthorizer     authorization.SpaceAuthorizer
+	tickets             *TicketStore
 	config              *WebSocketConfig
 }
 
@@ -26,25 +37,100 @@ func NewEventsUsecase(
 	hubManager *HubManager,
 	userConnManager domain.UserConnectionManager,
 	notificationManager domain.NotificationManager,
+	notificationUsecase notification.NotificationUsecase,
 	repository domain.EventsRepository,
 	userRepository domain.UserRepository,
 	spaceRepository domain.SpaceRepository,
+	userSpaceRepository domain.UserSpaceRepository,
 ) *EventsUsecase {
 	return &EventsUsecase{
 		hubManager:          hubManager,
 		userConnManager:     userConnManager,
 		notificationManager: notificationManager,
+		notificationUsecase: notificationUsecase,
 		repository:          repository,
 		userRepository:      userRepository,
 		spaceRepository:     spaceRepository,
+		userSpaceRepository: userSpaceRepository,
+		membershipPolicy:    authorization.NewMembershipPolicy(spaceRepository, userSpaceRepository),
+		spaceAuthorizer:     authorization.NewSpaceAuthorizer(userSpaceRepository),
+		tickets:             NewTicketStore(DefaultTicketTTL),
 		config:              DefaultWebSocketConfig(),
 	}
 }
 
+// IssueTicket emite un ticket de un solo uso para abrir un WebSocket
+func (u *EventsUsecase) IssueTicket(userID int, sessionID string) (*dto.WebSocketTicketDTO, error) {
+	ticket, expiresAt, err := u.tickets.Issue(userID, sessionID)
+	if err != nil {
+		return nil, apperror.NewInternalServer("Error generating ticket", err, "events_usecase.go:IssueTicket")
+	}
+
+	return &dto.WebSocketTicketDTO{
+		Ticket:    ticket,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// RedeemTicket consume un ticket y retorna el usuario autenticado y su sesión
+func (u *EventsUsecase) RedeemTicket(ticket string) (int, string, error) {
+	userID, sessionID, ok := u.tickets.Redeem(ticket)
+	if !ok {
+		return 0, "", apperror.NewUnauthorized("Invalid or expired ticket", nil, "events_usecase.go:RedeemTicket")
+	}
+
+	return userID, sessionID, nil
+}
+
+// findUser obtiene el usuario autenticado, que es la única fuente de su identidad
+func (u *EventsUsecase) findUser(ctx context.Context, userID int) (*domain.User, error) {
+	user, err := u.userRepository.Find(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("id", userID, criteria.OperatorEqual).
+		Build())
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, apperror.NewUnauthorized("User not found", nil, "events_usecase.go:findUser")
+	}
+
+	return user, nil
+}
+
+// requireWritableSpace verifica que el espacio no esté archivado y que el usuario sea miembro,
+// solo los miembros pueden unirse al chat y enviar mensajes
+func (u *EventsUsecase) requireWritableSpace(ctx context.Context, spaceID, userID int) error {
+	space, err := pghelpers.FindEntity(ctx, u.spaceRepository, "id", spaceID, "Space not found")
+	if err != nil {
+		return err
+	}
+
+	if err := authorization.RequireWritable(space); err != nil {
+		return err
+	}
+
+	return u.membershipPolicy.RequireMember(ctx, space.ID, userID)
+}
+
 func (u *EventsUsecase) HandleConnection(params dto.EventsConnectionParams) error {
+	if params.LastEventID != "" && !helpers.IsULID(params.LastEventID) {
+		return apperror.NewInvalidData("Invalid last_event_id", nil, "events_usecase.go:HandleConnection")
+	}
+
+	user, err := u.findUser(params.Request.Context(), params.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.requireWritableSpace(params.Request.Context(), params.SpaceID, user.ID); err != nil {
+		return err
+	}
+
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  int(u.config.MaxMessageSize),
 		WriteBufferSize: int(u.config.MaxMessageSize),
+		Subprotocols:    websocketSubprotocols,
 		CheckOrigin: func(r *http.Request) bool {
 			return true
 		},
@@ -58,11 +144,14 @@ func (u *EventsUsecase) HandleConnection(params dto.EventsConnectionParams) erro
 
 	wsConn := websocketAdapter.NewWebSocketWrapper(conn)
 
-	client := u.CreateClient(params.UserID, params.SpaceID, params.Username, wsConn)
+	client := u.CreateClient(user.ID, params.SpaceID, user.FullName(), wsConn)
+	client.Image = user.Image
+	client.LastEventID = params.LastEventID
+	client.SessionID = params.SessionID
 
 	u.RegisterClient(client)
 
-	clientManager := NewClientManager(client)
+	clientManager := NewClientManager(client, u)
 	go clientManager.WritePump()
 	go clientManager.ReadPump()
 
@@ -93,11 +182,32 @@ func (u *EventsUsecase) BroadcastToSpace(dto dto.EventsBroadcastParams) (*domain
 	return u.broadcastMessage(dto)
 }
 
+// SendChatMessage implementa ChatMessageSender para los mensajes recibidos por WebSocket
+func (u *EventsUsecase) SendChatMessage(params dto.EventsBroadcastParams) (*domain.ChatMessage, error) {
+	return u.broadcastMessage(params)
+}
+
 func (u *EventsUsecase) broadcastMessage(dto dto.EventsBroadcastParams) (*domain.ChatMessage, error) {
 	if err := u.validateMessageContent(dto.Message); err != nil {
 		return nil, err
 	}
 
+	user, err := u.findUser(context.Background(), dto.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.requireWritableSpace(context.Background(), dto.SpaceID, user.ID); err != nil {
+		return nil, err
+	}
+	dto.Username = user.FullName()
+
+	if dto.ReplyToID != nil {
+		if err := u.validateReplyTarget(context.Background(), dto.SpaceID, *dto.ReplyToID); err != nil {
+			return nil, err
+		}
+	}
+
 	chatMsg := &domain.ChatMessage{
 		ID:        helpers.NewULID(),
 		Content:   dto.Message,
@@ -106,6 +216,7 @@ func (u *EventsUsecase) broadcastMessage(dto dto.EventsBroadcastParams) (*domain
 		SpaceID:   dto.SpaceID,
 		Image:     dto.Image,
 		Timestamp: helpers.GetTime(),
+		ReplyToID: dto.ReplyToID,
 	}
 
 	if err := u.repository.SaveMessage(chatMsg); err != nil {
@@ -113,6 +224,181 @@ func (u *EventsUsecase) broadcastMessage(dto dto.EventsBroadcastParams) (*domain
 	}
 
 	u.hubManager.BroadcastChatMessage(chatMsg)
+	u.notifyMentions(context.Background(), chatMsg)
+	return chatMsg, nil
+}
+
+// notifyMentions avisa a los miembros del espacio mencionados en el mensaje, es best effort
+func (u *EventsUsecase) notifyMentions(ctx context.Context, chatMsg *domain.ChatMessage) {
+	for _, userID := range domain.ParseMentions(chatMsg.Content) {
+		if userID == chatMsg.UserID {
+			continue
+		}
+
+		isMember, err := u.userSpaceRepository.Exists(ctx, userID, chatMsg.SpaceID)
+		if err != nil {
+			log.Printf("Error checking membership of user %d in space %d: %v", userID, chatMsg.SpaceID, err)
+			continue
+		}
+		if !isMember {
+			continue
+		}
+
+		err = u.notificationUsecase.CreateNotification(ctx, dto.CreateNotificationParams{
+			NotificationType: domain.NotificationTypeMention,
+			EntityType:       domain.EntityTypeSpace,
+			EntityID:         chatMsg.SpaceID,
+			OwnerUserID:      userID,
+			ActorUserID:      chatMsg.UserID,
+			SpaceID:          chatMsg.SpaceID,
+		})
+		if err != nil {
+			log.Printf("Error creating mention notification for user %d: %v", userID, err)
+		}
+	}
+}
+
+// validateReplyTarget verifica que el mensaje respondido exista en el mismo espacio
+func (u *EventsUsecase) validateReplyTarget(ctx context.Context, spaceID int, replyToID string) error {
+	target, err := u.repository.FindMessage(ctx, replyToID)
+	if err != nil {
+		return err
+	}
+
+	if target == nil || target.SpaceID != spaceID {
+		return apperror.NewInvalidData("Reply target not found", nil, "events_usecase.go:validateReplyTarget")
+	}
+
+	return nil
+}
+
+// EditMessage cambia el contenido de un mensaje y difunde la edición al espacio
+func (u *EventsUsecase) EditMessage(params dto.EditChatMessageParams) (*domain.ChatMessage, error) {
+	if err := u.validateMessageContent(params.Content); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	chatMsg, err := u.findModifiableMessage(ctx, params.MessageID, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := helpers.GetTime()
+	chatMsg.Content = params.Content
+	chatMsg.EditedAt = &now
+
+	if err := u.repository.UpdateMessage(ctx, chatMsg); err != nil {
+		return nil, err
+	}
+
+	u.hubManager.BroadcastChatEvent(domain.MessageTypeEdit, chatMsg)
+	return chatMsg, nil
+}
+
+// DeleteMessage borra el contenido de un mensaje, el mensaje se conserva para no romper las respuestas
+func (u *EventsUsecase) DeleteMessage(params dto.DeleteChatMessageParams) (*domain.ChatMessage, error) {
+	ctx := context.Background()
+	chatMsg, err := u.findModifiableMessage(ctx, params.MessageID, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := helpers.GetTime()
+	chatMsg.Content = ""
+	chatMsg.DeletedAt = &now
+
+	if err := u.repository.UpdateMessage(ctx, chatMsg); err != nil {
+		return nil, err
+	}
+
+	u.hubManager.BroadcastChatEvent(domain.MessageTypeDelete, chatMsg)
+	return chatMsg, nil
+}
+
+// MarkRead registra el último mensaje leído por el usuario en el espacio y lo difunde
+// si avanzó, marcar un mensaje más viejo no tiene efecto
+func (u *EventsUsecase) MarkRead(params dto.MarkReadParams) (*domain.ReadReceipt, error) {
+	ctx := context.Background()
+	chatMsg, err := u.repository.FindMessage(ctx, params.MessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if chatMsg == nil || chatMsg.SpaceID != params.SpaceID {
+		return nil, apperror.NewNotFound("Message not found", nil, "events_usecase.go:MarkRead")
+	}
+
+	if err := u.membershipPolicy.RequireMember(ctx, params.SpaceID, params.UserID); err != nil {
+		return nil, err
+	}
+
+	receipt := &domain.ReadReceipt{
+		UserID:    params.UserID,
+		SpaceID:   params.SpaceID,
+		MessageID: chatMsg.ID,
+		ReadAt:    helpers.GetTime(),
+	}
+
+	advanced, err := u.repository.MarkRead(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	if advanced {
+		u.hubManager.BroadcastReadReceipt(receipt)
+	}
+	return receipt, nil
+}
+
+// Presence retorna los usuarios conectados al chat del espacio, solo los miembros pueden verlos
+func (u *EventsUsecase) Presence(ctx context.Context, spaceID, userID int) ([]domain.PresenceUser, error) {
+	space, err := pghelpers.FindEntity(ctx, u.spaceRepository, "id", spaceID, "Space not found")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.membershipPolicy.RequireMember(ctx, space.ID, userID); err != nil {
+		return nil, err
+	}
+
+	return u.hubManager.Presence(space.ID), nil
+}
+
+// SendTyping implementa ChatMessageSender, el aviso no se guarda
+func (u *EventsUsecase) SendTyping(typing domain.TypingMessage) {
+	u.hubManager.BroadcastTyping(typing)
+}
+
+// findModifiableMessage obtiene un mensaje que el usuario puede editar o borrar:
+// debe ser su autor o moderador del espacio
+func (u *EventsUsecase) findModifiableMessage(ctx context.Context, messageID string, userID int) (*domain.ChatMessage, error) {
+	chatMsg, err := u.repository.FindMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if chatMsg == nil || chatMsg.IsDeleted() {
+		return nil, apperror.NewNotFound("Message not found", nil, "events_usecase.go:findModifiableMessage")
+	}
+
+	if err := u.requireWritableSpace(ctx, chatMsg.SpaceID, userID); err != nil {
+		return nil, err
+	}
+
+	if chatMsg.UserID == userID {
+		return chatMsg, nil
+	}
+
+	canModerate, err := u.spaceAuthorizer.CanModerate(ctx, chatMsg.SpaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModerate {
+		return nil, apperror.NewForbidden("Only the author or a moderator can change this message", nil, "events_usecase.go:findModifiableMessage")
+	}
+
 	return chatMsg, nil
 }
 
@@ -133,21 +419,39 @@ func (u *EventsUsecase) generateClientID(userID, spaceID int) string {
 }
 
 func (u *EventsUsecase) HandleUserConnection(params dto.HandleUserConnectionParams) error {
+	if _, err := u.findUser(params.Request.Context(), params.UserID); err != nil {
+		return err
+	}
+
 	handleUserConnectionParams := domain.HandleUserConnectionParams{
-		UserID:  params.UserID,
-		Writer:  params.Writer,
-		Request: params.Request,
+		UserID:    params.UserID,
+		SessionID: params.SessionID,
+		Writer:    params.Writer,
+		Request:   params.Request,
 	}
 
 	return u.userConnManager.HandleConnection(handleUserConnectionParams)
 }
 
 func (u *EventsUsecase) HandleNotificationConnection(params dto.HandleNotificationConnectionParams) error {
+	if _, err := u.findUser(params.Request.Context(), params.UserID); err != nil {
+		return err
+	}
+
 	handleNotificationConnectionParams := domain.HandleNotificationConnectionParams{
-		UserID:  params.UserID,
-		Writer:  params.Writer,
-		Request: params.Request,
+		UserID:    params.UserID,
+		SessionID: params.SessionID,
+		Writer:    params.Writer,
+		Request:   params.Request,
 	}
 
 	return u.notificationManager.HandleConnection(handleNotificationConnectionParams)
 }
+
+// SessionsRevoked cierra los sockets abiertos con sesiones revocadas, como los de
+// las otras sesiones cuando el usuario cambia su contraseña
+func (u *EventsUsecase) SessionsRevoked(userID int, revoked func(sessionID string) bool) {
+	u.hubManager.CloseSessions(userID, revoked)
+	u.userConnManager.SessionsRevoked(userID, revoked)
+	u.notificationManager.SessionsRevoked(userID, revoked)
+}
@@ -0,0 +1,244 @@
+package events
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
+	mocks "cpi-hub-api/internal/core/usecase/notification/mock"
+	"cpi-hub-api/pkg/apperror"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type eventsUsecaseMocks struct {
+	eventsRepository    *mock.MockEventsRepository
+	spaceRepository     *mock.MockSpaceRepository
+	userSpaceRepository *mock.MockUserSpaceRepository
+	notificationUsecase *mocks.MockNotificationUsecase
+}
+
+func newTestEventsUsecase(ctrl *gomock.Controller) (*EventsUsecase, eventsUsecaseMocks) {
+	m := eventsUsecaseMocks{
+		eventsRepository:    mock.NewMockEventsRepository(ctrl),
+		spaceRepository:     mock.NewMockSpaceRepository(ctrl),
+		userSpaceRepository: mock.NewMockUserSpaceRepository(ctrl),
+		notificationUsecase: mocks.NewMockNotificationUsecase(ctrl),
+	}
+
+	usecase := NewEventsUsecase(NewHubManager(), nil, nil, m.notificationUsecase, m.eventsRepository, mock.NewMockUserRepository(ctrl), m.spaceRepository, m.userSpaceRepository)
+	return usecase, m
+}
+
+func TestEditMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newTestEventsUsecase(ctrl)
+
+	space := &domain.Space{ID: 3}
+	storedMessage := func() *domain.ChatMessage {
+		return &domain.ChatMessage{ID: "01HMSG", Content: "hola", UserID: 7, SpaceID: 3, Timestamp: time.Now()}
+	}
+
+	tests := []struct {
+		name   string
+		params dto.EditChatMessageParams
+		want   error
+		calls  func() []*gomock.Call
+	}{
+		{
+			name:   "success author edits",
+			params: dto.EditChatMessageParams{MessageID: "01HMSG", UserID: 7, Content: "hello"},
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.eventsRepository.EXPECT().FindMessage(gomock.Any(), "01HMSG").Return(storedMessage(), nil),
+					m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+					m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 7, 3).Return(true, nil),
+					m.eventsRepository.EXPECT().UpdateMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ interface{}, message *domain.ChatMessage) error {
+							assert.Equal(t, "hello", message.Content)
+							assert.NotNil(t, message.EditedAt)
+							return nil
+						}),
+				}
+			},
+		},
+		{
+			name:   "success moderator edits",
+			params: dto.EditChatMessageParams{MessageID: "01HMSG", UserID: 2, Content: "hello"},
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.eventsRepository.EXPECT().FindMessage(gomock.Any(), "01HMSG").Return(storedMessage(), nil),
+					m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+					m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 2, 3).Return(true, nil),
+					m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 3).Return(domain.SpaceRoleModerator, nil),
+					m.eventsRepository.EXPECT().UpdateMessage(gomock.Any(), gomock.Any()).Return(nil),
+				}
+			},
+		},
+		{
+			name:   "error other member cannot edit",
+			params: dto.EditChatMessageParams{MessageID: "01HMSG", UserID: 9, Content: "hello"},
+			want:   apperror.NewForbidden("Only the author or a moderator can change this message", nil, "events_usecase.go:findModifiableMessage"),
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.eventsRepository.EXPECT().FindMessage(gomock.Any(), "01HMSG").Return(storedMessage(), nil),
+					m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+					m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 9, 3).Return(true, nil),
+					m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 9, 3).Return(domain.SpaceRoleMember, nil),
+				}
+			},
+		},
+		{
+			name:   "error deleted message",
+			params: dto.EditChatMessageParams{MessageID: "01HMSG", UserID: 7, Content: "hello"},
+			want:   apperror.NewNotFound("Message not found", nil, "events_usecase.go:findModifiableMessage"),
+			calls: func() []*gomock.Call {
+				deleted := storedMessage()
+				deletedAt := time.Now()
+				deleted.DeletedAt = &deletedAt
+				return []*gomock.Call{
+					m.eventsRepository.EXPECT().FindMessage(gomock.Any(), "01HMSG").Return(deleted, nil),
+				}
+			},
+		},
+		{
+			name:   "error empty content",
+			params: dto.EditChatMessageParams{MessageID: "01HMSG", UserID: 7},
+			want:   domain.ErrEmptyMessage,
+			calls:  func() []*gomock.Call { return nil },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := test.calls()
+			ordered := make([]interface{}, len(calls))
+			for i, c := range calls {
+				ordered[i] = c
+			}
+			gomock.InOrder(ordered...)
+
+			_, gotErr := usecase.EditMessage(test.params)
+
+			assert.Equal(t, test.want, gotErr)
+		})
+	}
+}
+
+func TestDeleteMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newTestEventsUsecase(ctrl)
+
+	gomock.InOrder(
+		m.eventsRepository.EXPECT().FindMessage(gomock.Any(), "01HMSG").Return(&domain.ChatMessage{ID: "01HMSG", Content: "hola", UserID: 7, SpaceID: 3}, nil),
+		m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 3}, nil),
+		m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 7, 3).Return(true, nil),
+		m.eventsRepository.EXPECT().UpdateMessage(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	deleted, err := usecase.DeleteMessage(dto.DeleteChatMessageParams{MessageID: "01HMSG", UserID: 7})
+
+	assert.NoError(t, err)
+	assert.Empty(t, deleted.Content)
+	assert.True(t, deleted.IsDeleted())
+}
+
+func TestMarkRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newTestEventsUsecase(ctrl)
+
+	tests := []struct {
+		name   string
+		params dto.MarkReadParams
+		want   error
+		calls  func() []*gomock.Call
+	}{
+		{
+			name:   "success moves the receipt forward",
+			params: dto.MarkReadParams{SpaceID: 3, UserID: 7, MessageID: "01HMSG"},
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.eventsRepository.EXPECT().FindMessage(gomock.Any(), "01HMSG").Return(&domain.ChatMessage{ID: "01HMSG", SpaceID: 3}, nil),
+					m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 7, 3).Return(true, nil),
+					m.eventsRepository.EXPECT().MarkRead(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ interface{}, receipt *domain.ReadReceipt) (bool, error) {
+							assert.Equal(t, "01HMSG", receipt.MessageID)
+							assert.Equal(t, 7, receipt.UserID)
+							return true, nil
+						}),
+				}
+			},
+		},
+		{
+			name:   "error message from another space",
+			params: dto.MarkReadParams{SpaceID: 4, UserID: 7, MessageID: "01HMSG"},
+			want:   apperror.NewNotFound("Message not found", nil, "events_usecase.go:MarkRead"),
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.eventsRepository.EXPECT().FindMessage(gomock.Any(), "01HMSG").Return(&domain.ChatMessage{ID: "01HMSG", SpaceID: 3}, nil),
+				}
+			},
+		},
+		{
+			name:   "error not a member",
+			params: dto.MarkReadParams{SpaceID: 3, UserID: 9, MessageID: "01HMSG"},
+			want:   apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireMember"),
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.eventsRepository.EXPECT().FindMessage(gomock.Any(), "01HMSG").Return(&domain.ChatMessage{ID: "01HMSG", SpaceID: 3}, nil),
+					m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 9, 3).Return(false, nil),
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := test.calls()
+			ordered := make([]interface{}, len(calls))
+			for i, c := range calls {
+				ordered[i] = c
+			}
+			gomock.InOrder(ordered...)
+
+			_, gotErr := usecase.MarkRead(test.params)
+
+			assert.Equal(t, test.want, gotErr)
+		})
+	}
+}
+
+func TestNotifyMentions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newTestEventsUsecase(ctrl)
+
+	gomock.InOrder(
+		m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 8, 3).Return(true, nil),
+		m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), dto.CreateNotificationParams{
+			NotificationType: domain.NotificationTypeMention,
+			EntityType:       domain.EntityTypeSpace,
+			EntityID:         3,
+			OwnerUserID:      8,
+			ActorUserID:      7,
+			SpaceID:          3,
+		}).Return(nil),
+		m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 9, 3).Return(false, nil),
+	)
+
+	usecase.notifyMentions(context.Background(), &domain.ChatMessage{
+		UserID:  7,
+		SpaceID: 3,
+		Content: "@[Yo](7) @[Ana](8) @[Ana](8) @[Eve](9)",
+	})
+}
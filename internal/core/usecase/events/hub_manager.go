@@ -9,7 +9,16 @@ import (
 
 // HubManager maneja las operaciones del hub
 type HubManager struct {
-	hub *domain.Hub
+	hub         *domain.Hub
+	events      *EventBuffer
+	presence    *PresenceTracker
+	revocations chan sessionRevocation
+}
+
+// sessionRevocation pide cerrar los clientes del usuario abiertos con sesiones revocadas
+type sessionRevocation struct {
+	userID  int
+	revoked func(sessionID string) bool
 }
 
 // NewHubManager crea una nueva instancia del HubManager
@@ -22,6 +31,9 @@ func NewHubManager() *HubManager {
 			Broadcast:      make(chan []byte),
 			SpaceBroadcast: make(chan domain.SpaceMessage, 100), // buffer de 100
 		},
+		events:      NewEventBuffer(DefaultReplayBufferSize),
+		presence:    NewPresenceTracker(),
+		revocations: make(chan sessionRevocation),
 	}
 }
 
@@ -35,35 +47,40 @@ func (hm *HubManager) Run() {
 	for {
 		select {
 		case client := <-hm.hub.Register:
+			if !hm.replayMissedEvents(client) {
+				continue
+			}
 			hm.hub.Clients[client] = true
+			firstConnection := hm.presence.Add(client)
 			log.Printf("Client %s (ID %d) connected to space %d", client.Username, client.UserID, client.SpaceID)
 
-			// Enviar mensaje de bienvenida
-			welcomeMsg := domain.EventMessage{
-				Type:      domain.MessageTypeJoin,
-				Data:      domain.JoinMessage{SpaceID: client.SpaceID, UserID: client.UserID},
-				Timestamp: helpers.GetTime(),
-				UserID:    client.UserID,
-				SpaceID:   client.SpaceID,
-				Username:  client.Username,
+			hm.sendPresenceSnapshot(client)
+
+			// Enviar mensaje de bienvenida solo si el usuario no tenía otra pestaña abierta y no es invisible
+			if firstConnection {
+				welcomeMsg := domain.EventMessage{
+					Type:      domain.MessageTypeJoin,
+					Data:      domain.JoinMessage{SpaceID: client.SpaceID, UserID: client.UserID},
+					Timestamp: helpers.GetTime(),
+					UserID:    client.UserID,
+					SpaceID:   client.SpaceID,
+					Username:  client.Username,
+				}
+				hm.broadcastToSpace(client.SpaceID, welcomeMsg)
 			}
-			hm.broadcastToSpace(client.SpaceID, welcomeMsg)
 
 		case client := <-hm.hub.Unregister:
 			if _, ok := hm.hub.Clients[client]; ok {
-				delete(hm.hub.Clients, client)
-				close(client.Send)
+				hm.removeClient(client)
 				log.Printf("Client %d disconnected from space %d", client.UserID, client.SpaceID)
+			}
 
-				// Enviar mensaje de despedida
-				leaveMsg := domain.EventMessage{
-					Type:      domain.MessageTypeLeave,
-					Data:      domain.LeaveMessage{SpaceID: client.SpaceID, UserID: client.UserID},
-					Timestamp: helpers.GetTime(),
-					UserID:    client.UserID,
-					SpaceID:   client.SpaceID,
+		case revocation := <-hm.revocations:
+			// Cerrar la conexión termina el ReadPump, que desregistra al cliente
+			for client := range hm.hub.Clients {
+				if client.UserID == revocation.userID && revocation.revoked(client.SessionID) {
+					client.Conn.Close()
 				}
-				hm.broadcastToSpace(client.SpaceID, leaveMsg)
 			}
 
 		case message := <-hm.hub.Broadcast:
@@ -71,19 +88,21 @@ func (hm *HubManager) Run() {
 				select {
 				case client.Send <- message:
 				default:
-					close(client.Send)
-					delete(hm.hub.Clients, client)
+					hm.removeClient(client)
 				}
 			}
 
 		case spaceMsg := <-hm.hub.SpaceBroadcast:
 			for client := range hm.hub.Clients {
 				if client.SpaceID == spaceMsg.SpaceID {
+					// el cliente ya recibió este evento al reenviarle lo que perdió
+					if spaceMsg.EventID != "" && spaceMsg.EventID <= client.LastEventID {
+						continue
+					}
 					select {
 					case client.Send <- spaceMsg.Message:
 					default:
-						close(client.Send)
-						delete(hm.hub.Clients, client)
+						hm.removeClient(client)
 					}
 				}
 			}
@@ -91,30 +110,173 @@ func (hm *HubManager) Run() {
 	}
 }
 
+// removeClient desconecta al cliente del hub y avisa al espacio si era la última
+// conexión de un usuario visible
+func (hm *HubManager) removeClient(client *domain.Client) {
+	delete(hm.hub.Clients, client)
+	close(client.Send)
+
+	if !hm.presence.Remove(client) {
+		return
+	}
+
+	// Enviar mensaje de despedida
+	leaveMsg := domain.EventMessage{
+		Type:      domain.MessageTypeLeave,
+		Data:      domain.LeaveMessage{SpaceID: client.SpaceID, UserID: client.UserID},
+		Timestamp: helpers.GetTime(),
+		UserID:    client.UserID,
+		SpaceID:   client.SpaceID,
+	}
+	hm.broadcastToSpace(client.SpaceID, leaveMsg)
+}
+
+// SetInvisible anuncia que el usuario se fue de los espacios donde está conectado
+// cuando pasa a invisible, y que entró cuando vuelve a ser visible
+func (hm *HubManager) SetInvisible(userID int, invisible bool) {
+	for spaceID, user := range hm.presence.SetInvisible(userID, invisible) {
+		if invisible {
+			hm.broadcastToSpace(spaceID, domain.EventMessage{
+				Type:      domain.MessageTypeLeave,
+				Data:      domain.LeaveMessage{SpaceID: spaceID, UserID: userID},
+				Timestamp: helpers.GetTime(),
+				UserID:    userID,
+				SpaceID:   spaceID,
+			})
+			continue
+		}
+
+		hm.broadcastToSpace(spaceID, domain.EventMessage{
+			Type:      domain.MessageTypeJoin,
+			Data:      domain.JoinMessage{SpaceID: spaceID, UserID: userID},
+			Timestamp: helpers.GetTime(),
+			UserID:    userID,
+			SpaceID:   spaceID,
+			Username:  user.Username,
+		})
+	}
+}
+
+// CloseSessions cierra los clientes del usuario abiertos con sesiones revocadas
+func (hm *HubManager) CloseSessions(userID int, revoked func(sessionID string) bool) {
+	hm.revocations <- sessionRevocation{userID: userID, revoked: revoked}
+}
+
+// Presence retorna los usuarios conectados al chat del espacio
+func (hm *HubManager) Presence(spaceID int) []domain.PresenceUser {
+	return hm.presence.Users(spaceID)
+}
+
+// sendPresenceSnapshot envía al cliente que se une los usuarios conectados al espacio
+func (hm *HubManager) sendPresenceSnapshot(client *domain.Client) {
+	snapshotMsg := domain.EventMessage{
+		Type:      domain.MessageTypePresenceSnapshot,
+		Data:      domain.PresenceSnapshotMessage{SpaceID: client.SpaceID, Users: hm.presence.Users(client.SpaceID)},
+		Timestamp: helpers.GetTime(),
+		SpaceID:   client.SpaceID,
+	}
+
+	messageBytes, err := json.Marshal(snapshotMsg)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
+	select {
+	case client.Send <- messageBytes:
+	default:
+		log.Printf("Could not send presence snapshot to client %s", client.ID)
+	}
+}
+
+// replayMissedEvents reenvía al cliente que se reconecta los eventos posteriores a su
+// LastEventID, o le pide recargar el historial si ya no están en el buffer. Si el canal
+// del cliente se llena lo cierra sin registrarlo y devuelve false, igual que un broadcast.
+func (hm *HubManager) replayMissedEvents(client *domain.Client) bool {
+	if client.LastEventID == "" {
+		return true
+	}
+
+	missed, latestID, ok := hm.events.Since(client.SpaceID, client.LastEventID)
+	if !ok {
+		resyncMsg := domain.EventMessage{
+			Type:      domain.MessageTypeResync,
+			Data:      domain.ResyncMessage{LastEventID: client.LastEventID},
+			Timestamp: helpers.GetTime(),
+			SpaceID:   client.SpaceID,
+		}
+		client.LastEventID = ""
+		if messageBytes, err := json.Marshal(resyncMsg); err == nil {
+			return hm.sendOrClose(client, messageBytes)
+		}
+		return true
+	}
+
+	for _, message := range missed {
+		if !hm.sendOrClose(client, message) {
+			return false
+		}
+	}
+	client.LastEventID = latestID
+	return true
+}
+
+// sendOrClose encola el mensaje sin bloquear el hub, un cliente que todavía no está
+// registrado y no tiene lugar en su canal se desconecta
+func (hm *HubManager) sendOrClose(client *domain.Client, message []byte) bool {
+	select {
+	case client.Send <- message:
+		return true
+	default:
+		log.Printf("Client %s could not receive the missed events of space %d", client.ID, client.SpaceID)
+		close(client.Send)
+		return false
+	}
+}
+
 // broadcastToSpace envía un mensaje a todos los clientes de un espacio específico
+// y lo guarda para reenviarlo a los clientes que se reconecten
 func (hm *HubManager) broadcastToSpace(spaceID int, message domain.EventMessage) {
+	err := hm.events.Append(spaceID, message, func(eventID string, messageBytes []byte) {
+		hm.sendToSpace(domain.SpaceMessage{
+			SpaceID: spaceID,
+			Message: messageBytes,
+			EventID: eventID,
+		})
+	})
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+	}
+}
+
+// broadcastEphemeral envía un mensaje al espacio sin guardarlo para reenvío
+func (hm *HubManager) broadcastEphemeral(spaceID int, message domain.EventMessage) {
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
 
-	spaceMsg := domain.SpaceMessage{
-		SpaceID: spaceID,
-		Message: messageBytes,
-	}
+	hm.sendToSpace(domain.SpaceMessage{SpaceID: spaceID, Message: messageBytes})
+}
 
+func (hm *HubManager) sendToSpace(spaceMsg domain.SpaceMessage) {
 	select {
 	case hm.hub.SpaceBroadcast <- spaceMsg:
 	default:
-		log.Printf("Could not send message to space %d", spaceID)
+		log.Printf("Could not send message to space %d", spaceMsg.SpaceID)
 	}
 }
 
 // BroadcastChatMessage envía un mensaje de chat a un espacio específico
 func (hm *HubManager) BroadcastChatMessage(chatMsg *domain.ChatMessage) {
+	hm.BroadcastChatEvent(domain.MessageTypeChat, chatMsg)
+}
+
+// BroadcastChatEvent difunde un mensaje nuevo, editado o borrado para que los clientes lo actualicen
+func (hm *HubManager) BroadcastChatEvent(messageType domain.MessageType, chatMsg *domain.ChatMessage) {
 	wsMsg := domain.EventMessage{
-		Type:      domain.MessageTypeChat,
+		Type:      messageType,
 		Data:      chatMsg,
 		Timestamp: helpers.GetTime(),
 		UserID:    chatMsg.UserID,
@@ -124,3 +286,26 @@ func (hm *HubManager) BroadcastChatMessage(chatMsg *domain.ChatMessage) {
 
 	hm.broadcastToSpace(chatMsg.SpaceID, wsMsg)
 }
+
+// BroadcastTyping avisa al espacio que un usuario está escribiendo
+func (hm *HubManager) BroadcastTyping(typing domain.TypingMessage) {
+	hm.broadcastEphemeral(typing.SpaceID, domain.EventMessage{
+		Type:      domain.MessageTypeTyping,
+		Data:      typing,
+		Timestamp: helpers.GetTime(),
+		UserID:    typing.UserID,
+		SpaceID:   typing.SpaceID,
+		Username:  typing.Username,
+	})
+}
+
+// BroadcastReadReceipt difunde hasta qué mensaje leyó un usuario
+func (hm *HubManager) BroadcastReadReceipt(receipt *domain.ReadReceipt) {
+	hm.broadcastToSpace(receipt.SpaceID, domain.EventMessage{
+		Type:      domain.MessageTypeRead,
+		Data:      receipt,
+		Timestamp: helpers.GetTime(),
+		UserID:    receipt.UserID,
+		SpaceID:   receipt.SpaceID,
+	})
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"log"
+)
+
+// observedUserSpaceRepository avisa al listener cuando cambian las membresías, así la
+// presencia sigue a los usuarios que entran o salen de un espacio sin reconectarse
+type observedUserSpaceRepository struct {
+	domain.UserSpaceRepository
+	listener domain.MembershipListener
+}
+
+func NewObservedUserSpaceRepository(repository domain.UserSpaceRepository, listener domain.MembershipListener) domain.UserSpaceRepository {
+	return &observedUserSpaceRepository{
+		UserSpaceRepository: repository,
+		listener:            listener,
+	}
+}
+
+func (r *observedUserSpaceRepository) Update(ctx context.Context, userID int, spaceIDs []int, action string) error {
+	if err := r.UserSpaceRepository.Update(ctx, userID, spaceIDs, action); err != nil {
+		return err
+	}
+
+	r.listener.MembershipsChanged([]int{userID})
+	return nil
+}
+
+func (r *observedUserSpaceRepository) AddMember(ctx context.Context, userID int, spaceID int, role domain.SpaceRole) error {
+	if err := r.UserSpaceRepository.AddMember(ctx, userID, spaceID, role); err != nil {
+		return err
+	}
+
+	r.listener.MembershipsChanged([]int{userID})
+	return nil
+}
+
+// observedSpaceRepository avisa al listener de los miembros de un espacio borrado
+type observedSpaceRepository struct {
+	domain.SpaceRepository
+	userSpaceRepository domain.UserSpaceRepository
+	listener            domain.MembershipListener
+}
+
+func NewObservedSpaceRepository(repository domain.SpaceRepository, userSpaceRepository domain.UserSpaceRepository, listener domain.MembershipListener) domain.SpaceRepository {
+	return &observedSpaceRepository{
+		SpaceRepository:     repository,
+		userSpaceRepository: userSpaceRepository,
+		listener:            listener,
+	}
+}
+
+func (r *observedSpaceRepository) Delete(ctx context.Context, spaceID int) error {
+	// Los miembros se buscan antes de que el borrado en cascada los elimine
+	memberIDs, err := r.userSpaceRepository.FindUserIDsBySpaceID(ctx, spaceID)
+	if err != nil {
+		log.Printf("Error finding members of space %d: %v", spaceID, err)
+	}
+
+	if err := r.SpaceRepository.Delete(ctx, spaceID); err != nil {
+		return err
+	}
+
+	r.listener.MembershipsChanged(memberIDs)
+	return nil
+}
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type stubMembershipListener struct {
+	changed [][]int
+}
+
+func (l *stubMembershipListener) MembershipsChanged(userIDs []int) {
+	l.changed = append(l.changed, userIDs)
+}
+
+func TestObservedUserSpaceRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	listener := &stubMembershipListener{}
+	repository := NewObservedUserSpaceRepository(userSpaceRepository, listener)
+
+	userSpaceRepository.EXPECT().AddMember(gomock.Any(), 7, 3, domain.SpaceRoleMember).Return(nil)
+	userSpaceRepository.EXPECT().Update(gomock.Any(), 8, []int{3}, domain.RemoveUserFromSpace).Return(errors.New("db down"))
+
+	assert.NoError(t, repository.AddMember(context.Background(), 7, 3, domain.SpaceRoleMember))
+	assert.Error(t, repository.Update(context.Background(), 8, []int{3}, domain.RemoveUserFromSpace))
+
+	assert.Equal(t, [][]int{{7}}, listener.changed, "failed changes are not announced")
+}
+
+func TestObservedSpaceRepositoryDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	spaceRepository := mock.NewMockSpaceRepository(ctrl)
+	userSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	listener := &stubMembershipListener{}
+	repository := NewObservedSpaceRepository(spaceRepository, userSpaceRepository, listener)
+
+	gomock.InOrder(
+		userSpaceRepository.EXPECT().FindUserIDsBySpaceID(gomock.Any(), 3).Return([]int{7, 8}, nil),
+		spaceRepository.EXPECT().Delete(gomock.Any(), 3).Return(nil),
+	)
+
+	assert.NoError(t, repository.Delete(context.Background(), 3))
+	assert.Equal(t, [][]int{{7, 8}}, listener.changed)
+}
@@ -1,33 +1,37 @@
 package events
 
 import (
+	"context"
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/dto"
 	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
 	"encoding/json"
 	"log"
 	"net/http"
 	"sync"
-	"time"
 
 	"github.com/gorilla/websocket"
 )
 
 // NotificationManager implementa la interfaz NotificationManager del dominio
 type NotificationManager struct {
-	connections map[int]*websocket.Conn // user_id -> connection
-	mutex       sync.RWMutex
-	upgrader    websocket.Upgrader
-	config      *WebSocketConfig
+	connections        map[int]map[*socketConnection]bool // user_id -> conexiones de cada dispositivo
+	mutex              sync.RWMutex
+	upgrader           websocket.Upgrader
+	config             *WebSocketConfig
+	deliveryRepository domain.NotificationDeliveryRepository
 }
 
-func NewNotificationManager() domain.NotificationManager {
+func NewNotificationManager(deliveryRepository domain.NotificationDeliveryRepository) domain.NotificationManager {
 	return &NotificationManager{
-		connections: make(map[int]*websocket.Conn),
-		config:      DefaultWebSocketConfig(),
+		connections:        make(map[int]map[*socketConnection]bool),
+		config:             DefaultWebSocketConfig(),
+		deliveryRepository: deliveryRepository,
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  int(DefaultWebSocketConfig().MaxMessageSize),
 			WriteBufferSize: int(DefaultWebSocketConfig().MaxMessageSize),
+			Subprotocols:    websocketSubprotocols,
 			CheckOrigin: func(r *http.Request) bool {
 				return true
 			},
@@ -37,7 +41,10 @@ func NewNotificationManager() domain.NotificationManager {
 
 func (nm *NotificationManager) HandleConnection(params domain.HandleNotificationConnectionParams) error {
 	nm.mutex.RLock()
-	currentConnections := len(nm.connections)
+	currentConnections := 0
+	for _, devices := range nm.connections {
+		currentConnections += len(devices)
+	}
 	nm.mutex.RUnlock()
 
 	if currentConnections >= nm.config.MaxConnections {
@@ -51,76 +58,79 @@ func (nm *NotificationManager) HandleConnection(params domain.HandleNotification
 		return apperror.NewInternalServer("error upgrading connection", err, "notification_manager.go:HandleConnection")
 	}
 
-	// Cerrar conexión existente si existe
-	nm.mutex.Lock()
-	if existingConn, exists := nm.connections[params.UserID]; exists {
-		existingConn.Close()
+	// Cada pestaña o dispositivo mantiene su propia conexión
+	connection := newSocketConnection(conn, params.SessionID, nm.config)
+	nm.addConnection(params.UserID, connection)
+
+	// Lo que quedó pendiente mientras el usuario estaba desconectado se reintenta ya
+	if err := nm.deliveryRepository.RescheduleUser(context.Background(), params.UserID, helpers.GetTime()); err != nil {
+		log.Printf("NotificationManager: Error rescheduling deliveries for user %d: %v", params.UserID, err)
 	}
-	nm.connections[params.UserID] = conn
-	nm.mutex.Unlock()
 
-	go nm.handleMessages(params.UserID, conn)
+	go connection.writePump()
+	go nm.handleMessages(params.UserID, connection)
 
 	return nil
 }
 
-// handleMessages maneja los mensajes entrantes de una conexión
-func (nm *NotificationManager) handleMessages(userID int, conn *websocket.Conn) {
-	defer func() {
-		conn.Close()
-		nm.removeConnection(userID)
-	}()
+// handleMessages lee la conexión hasta que se cierra, el cliente solo envía acks
+func (nm *NotificationManager) handleMessages(userID int, connection *socketConnection) {
+	defer nm.removeConnection(userID, connection)
 
-	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(nm.config.PongWait))
-		return nil
+	connection.readPump(func(messageBytes []byte) {
+		nm.handleMessage(userID, messageBytes)
 	})
+}
 
-	ticker := time.NewTicker(nm.config.GetPingPeriod())
-	defer ticker.Stop()
-
-	go func() {
-		for range ticker.C {
-			nm.mutex.RLock()
-			conn, exists := nm.connections[userID]
-			nm.mutex.RUnlock()
+func (nm *NotificationManager) handleMessage(userID int, messageBytes []byte) {
+	var message domain.NotificationAckMessage
+	if err := json.Unmarshal(messageBytes, &message); err != nil || message.Type != domain.NotificationMessageTypeAck {
+		return
+	}
 
-			if !exists {
-				return
-			}
+	nm.markDelivered(userID, message.NotificationID)
+}
 
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
-			}
-		}
-	}()
+// markDelivered saca la notificación del outbox, el dispatcher deja de reintentarla
+func (nm *NotificationManager) markDelivered(userID int, notificationID string) {
+	if _, err := nm.deliveryRepository.MarkDelivered(context.Background(), userID, notificationID); err != nil {
+		log.Printf("NotificationManager: Error marking notification %s as delivered: %v", notificationID, err)
+	}
+}
 
-	conn.SetReadDeadline(time.Now().Add(nm.config.PongWait))
+func (nm *NotificationManager) addConnection(userID int, connection *socketConnection) {
+	nm.mutex.Lock()
+	defer nm.mutex.Unlock()
 
-	for {
-		_, _, err := conn.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-			}
-			break
-		}
-		conn.SetReadDeadline(time.Now().Add(nm.config.PongWait))
+	if nm.connections[userID] == nil {
+		nm.connections[userID] = make(map[*socketConnection]bool)
 	}
+	nm.connections[userID][connection] = true
 }
 
-func (nm *NotificationManager) removeConnection(userID int) {
+func (nm *NotificationManager) removeConnection(userID int, connection *socketConnection) {
 	nm.mutex.Lock()
-	delete(nm.connections, userID)
-	nm.mutex.Unlock()
+	defer nm.mutex.Unlock()
+
+	delete(nm.connections[userID], connection)
+	if len(nm.connections[userID]) == 0 {
+		delete(nm.connections, userID)
+	}
 }
 
-// BroadcastToUser envía una notificación a un usuario específico si está conectado
-func (nm *NotificationManager) BroadcastToUser(userID int, notification *domain.Notification) error {
-	nm.mutex.RLock()
-	conn, exists := nm.connections[userID]
-	nm.mutex.RUnlock()
+// SessionsRevoked cierra los dispositivos del usuario conectados con sesiones revocadas
+func (nm *NotificationManager) SessionsRevoked(userID int, revoked func(sessionID string) bool) {
+	for _, connection := range nm.userDevices(userID) {
+		if revoked(connection.sessionID) {
+			connection.Close()
+		}
+	}
+}
 
-	if !exists {
+// BroadcastToUser envía una notificación a todos los dispositivos conectados del usuario
+func (nm *NotificationManager) BroadcastToUser(userID int, notification *domain.Notification) error {
+	devices := nm.userDevices(userID)
+	if len(devices) == 0 {
 		return nil
 	}
 
@@ -131,11 +141,52 @@ func (nm *NotificationManager) BroadcastToUser(userID int, notification *domain.
 		return apperror.NewInternalServer("error marshaling notification", err, "notification_manager.go:BroadcastToUser")
 	}
 
-	conn.SetWriteDeadline(time.Now().Add(nm.config.WriteWait))
-	if err := conn.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
-		nm.removeConnection(userID)
-		return apperror.NewInternalServer("error writing notification", err, "notification_manager.go:BroadcastToUser")
+	// Basta con que un dispositivo la reciba para darla por entregada
+	var delivered sync.Once
+	written := func() {
+		delivered.Do(func() {
+			go nm.markDelivered(userID, notification.ID)
+		})
+	}
+
+	for _, connection := range devices {
+		// Un dispositivo que no consume sus mensajes se desconecta, los demás siguen recibiendo
+		if !connection.SendWithCallback(messageBytes, written) {
+			connection.Close()
+		}
 	}
 
 	return nil
 }
+
+// BroadcastRetraction avisa a todos los dispositivos conectados del usuario que la notificación se retiró
+func (nm *NotificationManager) BroadcastRetraction(userID int, retraction *domain.NotificationRetraction) error {
+	devices := nm.userDevices(userID)
+	if len(devices) == 0 {
+		return nil
+	}
+
+	messageBytes, err := json.Marshal(dto.ToNotificationRetractedMessageDTO(retraction, helpers.GetTime()))
+	if err != nil {
+		return apperror.NewInternalServer("error marshaling notification retraction", err, "notification_manager.go:BroadcastRetraction")
+	}
+
+	for _, connection := range devices {
+		if !connection.Send(messageBytes) {
+			connection.Close()
+		}
+	}
+
+	return nil
+}
+
+func (nm *NotificationManager) userDevices(userID int) []*socketConnection {
+	nm.mutex.RLock()
+	defer nm.mutex.RUnlock()
+
+	devices := make([]*socketConnection, 0, len(nm.connections[userID]))
+	for connection := range nm.connections[userID] {
+		devices = append(devices, connection)
+	}
+	return devices
+}
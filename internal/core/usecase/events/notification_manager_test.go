@@ -0,0 +1,97 @@
+package events
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestSocketConnection() *socketConnection {
+	return newSocketConnection(nil, "", DefaultWebSocketConfig())
+}
+
+func TestBroadcastToUserReachesEveryDevice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nm := NewNotificationManager(mock.NewMockNotificationDeliveryRepository(ctrl)).(*NotificationManager)
+	phone, laptop, other := newTestSocketConnection(), newTestSocketConnection(), newTestSocketConnection()
+	nm.addConnection(7, phone)
+	nm.addConnection(7, laptop)
+	nm.addConnection(8, other)
+
+	assert.NoError(t, nm.BroadcastToUser(7, &domain.Notification{ID: "n-1", UserID: 7}))
+
+	assert.Len(t, phone.send, 1)
+	assert.Len(t, laptop.send, 1)
+	assert.Empty(t, other.send)
+
+	nm.removeConnection(7, phone)
+	assert.Len(t, nm.connections[7], 1)
+	nm.removeConnection(7, laptop)
+	assert.NotContains(t, nm.connections, 7)
+}
+
+func TestBroadcastRetraction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nm := NewNotificationManager(mock.NewMockNotificationDeliveryRepository(ctrl)).(*NotificationManager)
+	phone := newTestSocketConnection()
+	nm.addConnection(7, phone)
+
+	assert.NoError(t, nm.BroadcastRetraction(7, &domain.NotificationRetraction{
+		Notification: &domain.Notification{ID: "n-1", UserID: 7},
+		Deleted:      true,
+		UnreadCount:  2,
+	}))
+
+	var message dto.NotificationRetractedMessageDTO
+	assert.NoError(t, json.Unmarshal((<-phone.send).data, &message))
+	assert.Equal(t, "notification_retracted", message.Type)
+	assert.Equal(t, dto.NotificationRetractedDTO{NotificationID: "n-1", Deleted: true, UnreadCount: 2}, message.Data)
+}
+
+func TestNotificationAck(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		setup   func(deliveryRepository *mock.MockNotificationDeliveryRepository)
+	}{
+		{
+			name:    "ack marks the notification as delivered",
+			message: `{"type":"ack","notification_id":"n-1"}`,
+			setup: func(deliveryRepository *mock.MockNotificationDeliveryRepository) {
+				deliveryRepository.EXPECT().MarkDelivered(gomock.Any(), 7, "n-1").Return(true, nil)
+			},
+		},
+		{
+			name:    "other messages are ignored",
+			message: `{"type":"set_status","status":"away"}`,
+			setup:   func(deliveryRepository *mock.MockNotificationDeliveryRepository) {},
+		},
+		{
+			name:    "invalid json is ignored",
+			message: `ack`,
+			setup:   func(deliveryRepository *mock.MockNotificationDeliveryRepository) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			deliveryRepository := mock.NewMockNotificationDeliveryRepository(ctrl)
+			tt.setup(deliveryRepository)
+
+			nm := NewNotificationManager(deliveryRepository).(*NotificationManager)
+			nm.handleMessage(7, []byte(tt.message))
+		})
+	}
+}
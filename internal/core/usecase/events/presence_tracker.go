@@ -0,0 +1,114 @@
+package events
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"sort"
+	"sync"
+)
+
+type spacePresence struct {
+	user        domain.PresenceUser
+	connections int
+}
+
+// PresenceTracker cuenta las conexiones de cada usuario por espacio, un usuario con
+// varias pestañas abiertas aparece una sola vez y uno invisible no aparece. El hub
+// lo actualiza y los endpoints REST lo consultan, por eso tiene su propio lock.
+type PresenceTracker struct {
+	spaces    map[int]map[int]*spacePresence
+	invisible map[int]bool
+	mutex     sync.RWMutex
+}
+
+func NewPresenceTracker() *PresenceTracker {
+	return &PresenceTracker{
+		spaces:    make(map[int]map[int]*spacePresence),
+		invisible: make(map[int]bool),
+	}
+}
+
+// Add registra una conexión y retorna true si es la primera del usuario en el espacio
+// y el usuario es visible, es decir si hay que anunciar que entró
+func (p *PresenceTracker) Add(client *domain.Client) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	users, exists := p.spaces[client.SpaceID]
+	if !exists {
+		users = make(map[int]*spacePresence)
+		p.spaces[client.SpaceID] = users
+	}
+
+	presence, exists := users[client.UserID]
+	if !exists {
+		presence = &spacePresence{user: domain.PresenceUser{UserID: client.UserID, Username: client.Username, Image: client.Image}}
+		users[client.UserID] = presence
+	}
+	presence.connections++
+
+	return presence.connections == 1 && !p.invisible[client.UserID]
+}
+
+// Remove quita una conexión y retorna true si era la última del usuario en el espacio
+// y el usuario es visible, es decir si hay que anunciar que se fue
+func (p *PresenceTracker) Remove(client *domain.Client) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	users := p.spaces[client.SpaceID]
+	presence, exists := users[client.UserID]
+	if !exists {
+		return false
+	}
+
+	presence.connections--
+	if presence.connections > 0 {
+		return false
+	}
+
+	delete(users, client.UserID)
+	if len(users) == 0 {
+		delete(p.spaces, client.SpaceID)
+	}
+	return !p.invisible[client.UserID]
+}
+
+// SetInvisible cambia la visibilidad del usuario y retorna su presencia en los
+// espacios donde está conectado, que ahora lo ven entrar o irse
+func (p *PresenceTracker) SetInvisible(userID int, invisible bool) map[int]domain.PresenceUser {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.invisible[userID] == invisible {
+		return nil
+	}
+	if invisible {
+		p.invisible[userID] = true
+	} else {
+		delete(p.invisible, userID)
+	}
+
+	changed := make(map[int]domain.PresenceUser)
+	for spaceID, users := range p.spaces {
+		if presence, exists := users[userID]; exists {
+			changed[spaceID] = presence.user
+		}
+	}
+	return changed
+}
+
+// Users retorna los usuarios visibles conectados al espacio ordenados por ID
+func (p *PresenceTracker) Users(spaceID int) []domain.PresenceUser {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	users := make([]domain.PresenceUser, 0, len(p.spaces[spaceID]))
+	for userID, presence := range p.spaces[spaceID] {
+		if !p.invisible[userID] {
+			users = append(users, presence.user)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	return users
+}
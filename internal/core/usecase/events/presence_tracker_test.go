@@ -0,0 +1,122 @@
+package events
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresenceTracker(t *testing.T) {
+	tracker := NewPresenceTracker()
+	firstTab := &domain.Client{UserID: 7, SpaceID: 3, Username: "Ana"}
+	secondTab := &domain.Client{UserID: 7, SpaceID: 3, Username: "Ana"}
+	other := &domain.Client{UserID: 2, SpaceID: 3, Username: "Beto"}
+
+	assert.True(t, tracker.Add(firstTab))
+	assert.False(t, tracker.Add(secondTab), "a second tab is not a new user")
+	assert.True(t, tracker.Add(other))
+
+	assert.Equal(t, []domain.PresenceUser{{UserID: 2, Username: "Beto"}, {UserID: 7, Username: "Ana"}}, tracker.Users(3))
+	assert.Empty(t, tracker.Users(4))
+
+	assert.False(t, tracker.Remove(firstTab), "the user still has a tab open")
+	assert.True(t, tracker.Remove(secondTab))
+	assert.Equal(t, []domain.PresenceUser{{UserID: 2, Username: "Beto"}}, tracker.Users(3))
+}
+
+func TestPresenceTrackerHidesInvisibleUsers(t *testing.T) {
+	tracker := NewPresenceTracker()
+	ana := &domain.Client{UserID: 7, SpaceID: 3, Username: "Ana"}
+	beto := &domain.Client{UserID: 2, SpaceID: 3, Username: "Beto"}
+
+	assert.True(t, tracker.Add(ana))
+	assert.Equal(t, map[int]domain.PresenceUser{3: {UserID: 7, Username: "Ana"}}, tracker.SetInvisible(7, true))
+	assert.Nil(t, tracker.SetInvisible(7, true), "nothing changes when the user already was invisible")
+	assert.Empty(t, tracker.Users(3))
+
+	tracker.SetInvisible(2, true)
+	assert.False(t, tracker.Add(beto), "an invisible user joins without being announced")
+	assert.False(t, tracker.Remove(beto), "and leaves without being announced")
+
+	assert.Equal(t, map[int]domain.PresenceUser{3: {UserID: 7, Username: "Ana"}}, tracker.SetInvisible(7, false))
+	assert.Equal(t, []domain.PresenceUser{{UserID: 7, Username: "Ana"}}, tracker.Users(3))
+}
+
+func TestSetInvisibleAnnouncesLeaveAndJoin(t *testing.T) {
+	hm := NewHubManager()
+	hm.presence.Add(&domain.Client{UserID: 7, SpaceID: 3, Username: "Ana"})
+
+	hm.SetInvisible(7, true)
+	leave := <-hm.hub.SpaceBroadcast
+	assert.Contains(t, string(leave.Message), `"type":"leave"`)
+
+	hm.SetInvisible(7, false)
+	join := <-hm.hub.SpaceBroadcast
+	assert.Contains(t, string(join.Message), `"type":"join"`)
+
+	hm.SetInvisible(8, true)
+	assert.Empty(t, hm.hub.SpaceBroadcast, "a user without chats open has nothing to announce")
+}
+
+func TestRemoveClientAnnouncesLastConnection(t *testing.T) {
+	hm := NewHubManager()
+	firstTab := &domain.Client{UserID: 7, SpaceID: 3, Send: make(chan []byte, 1)}
+	secondTab := &domain.Client{UserID: 7, SpaceID: 3, Send: make(chan []byte, 1)}
+	for _, client := range []*domain.Client{firstTab, secondTab} {
+		hm.hub.Clients[client] = true
+		hm.presence.Add(client)
+	}
+
+	hm.removeClient(firstTab)
+	assert.Empty(t, hm.hub.SpaceBroadcast)
+
+	hm.removeClient(secondTab)
+	assert.Len(t, hm.hub.SpaceBroadcast, 1)
+	assert.Empty(t, hm.Presence(3))
+}
+
+// closeRecorder es una conexión que solo registra cuándo se cierra
+type closeRecorder struct {
+	domain.EventConnection
+	closed chan struct{}
+}
+
+func (c *closeRecorder) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func (c *closeRecorder) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestCloseSessions(t *testing.T) {
+	hm := NewHubManager()
+	revoked := &closeRecorder{closed: make(chan struct{})}
+	current := &closeRecorder{closed: make(chan struct{})}
+	otherUser := &closeRecorder{closed: make(chan struct{})}
+	hm.hub.Clients[&domain.Client{UserID: 7, SpaceID: 3, SessionID: "family-1", Conn: revoked}] = true
+	hm.hub.Clients[&domain.Client{UserID: 7, SpaceID: 3, SessionID: "family-2", Conn: current}] = true
+	hm.hub.Clients[&domain.Client{UserID: 8, SpaceID: 3, SessionID: "family-1", Conn: otherUser}] = true
+	go hm.Run()
+
+	hm.CloseSessions(7, func(sessionID string) bool { return sessionID != "family-2" })
+
+	select {
+	case <-revoked.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the revoked session was not closed")
+	}
+
+	// una segunda revocación solo se recibe cuando terminó la anterior
+	hm.CloseSessions(9, func(string) bool { return true })
+	assert.False(t, current.isClosed(), "the current session stays open")
+	assert.False(t, otherUser.isClosed(), "other users are not affected")
+}
@@ -0,0 +1,136 @@
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// socketConnection es una conexión de un dispositivo del usuario. Gorilla no permite
+// escrituras concurrentes sobre la misma conexión, así que todo lo que se envía pasa
+// por el canal send y lo escribe una sola goroutine.
+type socketConnection struct {
+	conn *websocket.Conn
+	// sessionID es la sesión con la que se abrió, la conexión se cierra si se revoca
+	sessionID string
+	send      chan outgoingMessage
+	done      chan struct{}
+	closeOnce sync.Once
+	config    *WebSocketConfig
+}
+
+// outgoingMessage es un mensaje encolado, written se llama cuando se escribió en el socket
+type outgoingMessage struct {
+	data    []byte
+	written func()
+}
+
+func newSocketConnection(conn *websocket.Conn, sessionID string, config *WebSocketConfig) *socketConnection {
+	return &socketConnection{
+		conn:      conn,
+		sessionID: sessionID,
+		send:      make(chan outgoingMessage, config.SendBufferSize),
+		done:      make(chan struct{}),
+		config:    config,
+	}
+}
+
+// Send encola el mensaje sin bloquear, retorna false si la conexión está cerrada o saturada
+func (c *socketConnection) Send(message []byte) bool {
+	return c.SendWithCallback(message, nil)
+}
+
+// SendWithCallback encola el mensaje y llama a written cuando se escribe en el socket
+func (c *socketConnection) SendWithCallback(message []byte, written func()) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- outgoingMessage{data: message, written: written}:
+		return true
+	default:
+		return false
+	}
+}
+
+// SendJSON serializa el mensaje y lo encola
+func (c *socketConnection) SendJSON(message interface{}) bool {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return false
+	}
+
+	return c.Send(messageBytes)
+}
+
+// Close cierra la conexión, la goroutine de lectura termina con un error y la quita del manager
+func (c *socketConnection) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// writePump escribe los mensajes encolados y los pings, es el único que escribe en la conexión
+func (c *socketConnection) writePump() {
+	ticker := time.NewTicker(c.config.GetPingPeriod())
+	defer func() {
+		ticker.Stop()
+		c.Close()
+	}()
+
+	for {
+		select {
+		case message := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message.data); err != nil {
+				return
+			}
+			if message.written != nil {
+				message.written()
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// readPump lee los mensajes del cliente hasta que la conexión se cierra
+func (c *socketConnection) readPump(handle func(message []byte)) {
+	defer c.Close()
+
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(c.config.PongWait))
+		return nil
+	})
+	c.conn.SetReadDeadline(time.Now().Add(c.config.PongWait))
+
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("Error de WebSocket: %v", err)
+			}
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(c.config.PongWait))
+
+		if handle != nil {
+			handle(message)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"cpi-hub-api/pkg/helpers"
+	"sync"
+	"time"
+)
+
+// DefaultTicketTTL es la vida útil de un ticket de conexión
+const DefaultTicketTTL = 30 * time.Second
+
+type wsTicket struct {
+	userID    int
+	sessionID string
+	expiresAt time.Time
+}
+
+// TicketStore emite tickets de un solo uso para autenticar el handshake
+// WebSocket, ya que el navegador no permite enviar el header Authorization
+type TicketStore struct {
+	tickets map[string]wsTicket
+	mutex   sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+func NewTicketStore(ttl time.Duration) *TicketStore {
+	return &TicketStore{
+		tickets: make(map[string]wsTicket),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Issue crea un ticket para el usuario, ligado a la sesión con la que lo pidió
+func (s *TicketStore) Issue(userID int, sessionID string) (string, time.Time, error) {
+	ticket, err := helpers.NewOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	s.removeExpired(now)
+
+	expiresAt := now.Add(s.ttl)
+	s.tickets[ticket] = wsTicket{userID: userID, sessionID: sessionID, expiresAt: expiresAt}
+
+	return ticket, expiresAt, nil
+}
+
+// Redeem consume el ticket y retorna el usuario y la sesión a los que pertenece
+func (s *TicketStore) Redeem(ticket string) (int, string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, exists := s.tickets[ticket]
+	if !exists {
+		return 0, "", false
+	}
+	delete(s.tickets, ticket)
+
+	if s.now().After(stored.expiresAt) {
+		return 0, "", false
+	}
+
+	return stored.userID, stored.sessionID, true
+}
+
+func (s *TicketStore) removeExpired(now time.Time) {
+	for ticket, stored := range s.tickets {
+		if now.After(stored.expiresAt) {
+			delete(s.tickets, ticket)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTicketStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewTicketStore(30 * time.Second)
+	store.now = func() time.Time { return now }
+
+	ticket, _, err := store.Issue(7, "family-1")
+	assert.Nil(t, err)
+
+	userID, sessionID, ok := store.Redeem(ticket)
+	assert.True(t, ok)
+	assert.Equal(t, 7, userID)
+	assert.Equal(t, "family-1", sessionID)
+
+	_, _, ok = store.Redeem(ticket)
+	assert.False(t, ok, "a ticket can only be used once")
+
+	expired, _, _ := store.Issue(7, "family-1")
+	now = now.Add(time.Minute)
+	_, _, ok = store.Redeem(expired)
+	assert.False(t, ok, "expired tickets are rejected")
+
+	_, _, ok = store.Redeem("unknown")
+	assert.False(t, ok)
+}
@@ -1,8 +1,13 @@
 package events
 
 import (
+	"context"
 	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/criteria"
 	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
+	"encoding/json"
+	"log"
 	"net/http"
 	"strconv"
 	"sync"
@@ -11,21 +16,44 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// userProfileCacheTTL es cuánto se reutiliza el nombre y avatar de un usuario antes de volver a buscarlo
+const userProfileCacheTTL = 5 * time.Minute
+
+type userProfile struct {
+	username  string
+	image     string
+	expiresAt time.Time
+}
+
 // UserConnectionManager implementa la interfaz UserConnectionManager del dominio
 type UserConnectionManager struct {
-	connections map[int]*websocket.Conn // user_id -> connection
-	userStatus  map[int]bool            // user_id -> online/offline
-	mutex       sync.RWMutex
-	upgrader    websocket.Upgrader
-	config      *WebSocketConfig
+	connections map[int]map[*socketConnection]bool // user_id -> conexiones de cada dispositivo
+	userStatus  map[int]domain.UserStatus          // user_id -> estado elegido, los desconectados no están
+	profiles    map[int]userProfile                // user_id -> nombre y avatar cacheados
+	// userSpaces son los espacios de cada usuario conectado, solo quienes comparten
+	// un espacio ven el estado del otro
+	userSpaces          map[int]map[int]bool
+	mutex               sync.RWMutex
+	profileMutex        sync.Mutex
+	userRepository      domain.UserRepository
+	userSpaceRepository domain.UserSpaceRepository
+	visibility          domain.VisibilityListener
+	upgrader            websocket.Upgrader
+	config              *WebSocketConfig
 }
 
-func NewUserConnectionManager() domain.UserConnectionManager {
+func NewUserConnectionManager(userRepository domain.UserRepository, userSpaceRepository domain.UserSpaceRepository, visibility domain.VisibilityListener) domain.UserConnectionManager {
 	return &UserConnectionManager{
-		connections: make(map[int]*websocket.Conn),
-		userStatus:  make(map[int]bool),
-		config:      DefaultWebSocketConfig(),
+		connections:         make(map[int]map[*socketConnection]bool),
+		userStatus:          make(map[int]domain.UserStatus),
+		profiles:            make(map[int]userProfile),
+		userSpaces:          make(map[int]map[int]bool),
+		userRepository:      userRepository,
+		userSpaceRepository: userSpaceRepository,
+		visibility:          visibility,
+		config:              DefaultWebSocketConfig(),
 		upgrader: websocket.Upgrader{
+			Subprotocols: websocketSubprotocols,
 			CheckOrigin: func(r *http.Request) bool {
 				return true
 			},
@@ -33,179 +61,343 @@ func NewUserConnectionManager() domain.UserConnectionManager {
 	}
 }
 
-// HandleConnection maneja una nueva conexión de usuario
+// HandleConnection maneja una nueva conexión de usuario, un usuario puede tener varias
 func (ucm *UserConnectionManager) HandleConnection(params domain.HandleUserConnectionParams) error {
-	if len(ucm.connections) >= ucm.config.MaxConnections {
+	ucm.mutex.RLock()
+	currentConnections := 0
+	for _, devices := range ucm.connections {
+		currentConnections += len(devices)
+	}
+	ucm.mutex.RUnlock()
+
+	if currentConnections >= ucm.config.MaxConnections {
 		return apperror.NewInternalServer("maximum connections reached", nil, "user_connection_manager.go:HandleConnection")
 	}
 
+	spaceIDs, err := ucm.userSpaceRepository.FindSpacesIDsByUserID(params.Request.Context(), params.UserID)
+	if err != nil {
+		return err
+	}
+
 	conn, err := ucm.upgrader.Upgrade(params.Writer, params.Request, nil)
 	if err != nil {
 		return apperror.NewInternalServer("error upgrading connection", err, "user_connection_manager.go:HandleConnection")
 	}
 
-	ucm.mutex.Lock()
-	// Si el usuario ya está conectado, cerrar la conexión anterior
-	if existingConn, exists := ucm.connections[params.UserID]; exists {
-		existingConn.Close()
-	}
-	ucm.connections[params.UserID] = conn
-	ucm.userStatus[params.UserID] = true
-	ucm.mutex.Unlock()
+	connection := newSocketConnection(conn, params.SessionID, ucm.config)
+	firstConnection := ucm.addConnection(params.UserID, connection, spaceIDs)
+	go connection.writePump()
 
 	// Enviar mensaje inicial al usuario que se conecta
-	ucm.sendInitialStatusMessage(params.UserID, conn)
+	ucm.sendInitialStatusMessage(params.UserID, connection)
 
 	// Enviar lista de usuarios ya conectados al nuevo usuario
-	ucm.sendConnectedUsersList(params.UserID, conn)
+	ucm.sendConnectedUsersList(params.UserID, connection)
 
-	// Notificar a otros usuarios que este usuario está online
-	ucm.broadcastUserStatusToOthers(params.UserID, domain.UserStatusOnline)
+	// Notificar a otros usuarios que este usuario está online, solo con su primer dispositivo
+	if firstConnection {
+		ucm.broadcastUserStatusToOthers(params.UserID, domain.UserStatusOnline)
+	}
 
-	go ucm.handleMessages(params.UserID, conn)
+	go ucm.handleMessages(params.UserID, connection)
 
 	return nil
 }
 
+// addConnection registra la conexión y retorna true si es la primera del usuario
+func (ucm *UserConnectionManager) addConnection(userID int, connection *socketConnection, spaceIDs []int) bool {
+	ucm.mutex.Lock()
+	defer ucm.mutex.Unlock()
+
+	firstConnection := len(ucm.connections[userID]) == 0
+	if firstConnection {
+		ucm.connections[userID] = make(map[*socketConnection]bool)
+		ucm.userStatus[userID] = domain.UserStatusOnline
+	}
+	ucm.connections[userID][connection] = true
+	ucm.userSpaces[userID] = toSpaceSet(spaceIDs)
+
+	return firstConnection
+}
+
 // handleMessages maneja los mensajes entrantes de una conexión
-func (ucm *UserConnectionManager) handleMessages(userID int, conn *websocket.Conn) {
-	defer func() {
-		conn.Close()
-		ucm.removeConnection(userID)
-	}()
-
-	// Configurar ping/pong para detectar conexiones muertas
-	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(ucm.config.PongWait))
-		return nil
+func (ucm *UserConnectionManager) handleMessages(userID int, connection *socketConnection) {
+	defer ucm.removeConnection(userID, connection)
+
+	connection.readPump(func(messageBytes []byte) {
+		ucm.handleMessage(userID, messageBytes)
 	})
+}
 
-	// Enviar ping periódico para mantener la conexión viva
-	ticker := time.NewTicker(ucm.config.GetPingPeriod())
-	defer ticker.Stop()
+// handleMessage procesa los cambios de estado enviados por el usuario, el estado
+// es del usuario y no de la conexión
+func (ucm *UserConnectionManager) handleMessage(userID int, messageBytes []byte) {
+	var message domain.SetStatusMessage
+	if err := json.Unmarshal(messageBytes, &message); err != nil || message.Type != domain.UserConnectionMessageTypeSetStatus {
+		return
+	}
 
-	go func() {
-		for range ticker.C {
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
-			}
-		}
-	}()
+	if !message.Status.IsSelectable() {
+		log.Printf("Invalid status %q from user %d", message.Status, userID)
+		return
+	}
 
-	// Configurar timeout de lectura
-	conn.SetReadDeadline(time.Now().Add(ucm.config.PongWait))
+	ucm.mutex.Lock()
+	previous := ucm.userStatus[userID]
+	ucm.userStatus[userID] = message.Status
+	devices := ucm.userConnectionsLocked(userID)
+	ucm.mutex.Unlock()
 
-	for {
-		_, _, err := conn.ReadMessage()
-		if err != nil {
-			// Detectar diferentes tipos de errores de conexión
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				// Conexión cerrada inesperadamente
-			}
-			break
-		}
-		// Resetear el deadline después de cada mensaje
-		conn.SetReadDeadline(time.Now().Add(ucm.config.PongWait))
+	// Confirmar el estado en todos los dispositivos del usuario, incluso si es invisible
+	ucm.sendToConnections(ucm.statusMessage(userID, message.Status), devices)
+
+	if previous.Public() != message.Status.Public() {
+		ucm.broadcastUserStatusToOthers(userID, message.Status.Public())
+	}
+
+	// En los chats también deja de figurar mientras es invisible
+	if invisible := message.Status == domain.UserStatusInvisible; invisible != (previous == domain.UserStatusInvisible) {
+		ucm.visibility.SetInvisible(userID, invisible)
 	}
 }
 
-// removeConnection elimina una conexión de usuario
-func (ucm *UserConnectionManager) removeConnection(userID int) {
+// removeConnection elimina una conexión del usuario. Cuando cierra la última se
+// guarda cuándo se lo vio por última vez y se lo anuncia offline. Un usuario
+// invisible ya figuraba offline, su desconexión no se anuncia ni se registra.
+func (ucm *UserConnectionManager) removeConnection(userID int, connection *socketConnection) {
 	ucm.mutex.Lock()
+	delete(ucm.connections[userID], connection)
+	if len(ucm.connections[userID]) > 0 {
+		ucm.mutex.Unlock()
+		return
+	}
+
+	// Los destinatarios se calculan antes de olvidar los espacios del usuario
+	recipients := ucm.visibleConnectionsLocked(userID)
 	delete(ucm.connections, userID)
-	ucm.userStatus[userID] = false
+	status := ucm.userStatus[userID]
+	delete(ucm.userStatus, userID)
+	delete(ucm.userSpaces, userID)
 	ucm.mutex.Unlock()
 
-	ucm.broadcastUserStatusToOthers(userID, domain.UserStatusOffline)
+	// El estado se olvida con la última conexión, al volver se conecta visible
+	if status == domain.UserStatusInvisible {
+		ucm.visibility.SetInvisible(userID, false)
+		return
+	}
+
+	lastSeenAt := helpers.GetTime()
+	if err := ucm.userRepository.UpdateLastSeen(context.Background(), userID, lastSeenAt); err != nil {
+		log.Printf("Error saving last seen for user %d: %v", userID, err)
+	}
+
+	message := ucm.statusMessage(userID, domain.UserStatusOffline)
+	message.LastSeenAt = &lastSeenAt
+	ucm.sendToConnections(message, flattenConnections(recipients))
 }
 
 // sendInitialStatusMessage envía un mensaje inicial al usuario que se conecta
-func (ucm *UserConnectionManager) sendInitialStatusMessage(userID int, conn *websocket.Conn) {
-	message := domain.UserConnectionMessage{
-		Type:      "user_status",
-		UserID:    userID,
-		Status:    domain.UserStatusOnline,
-		Username:  ucm.getUsername(userID),
-		Timestamp: time.Now().Format(time.RFC3339),
+func (ucm *UserConnectionManager) sendInitialStatusMessage(userID int, connection *socketConnection) {
+	ucm.mutex.RLock()
+	status := ucm.userStatus[userID]
+	ucm.mutex.RUnlock()
+
+	ucm.sendToConnections(ucm.statusMessage(userID, status), []*socketConnection{connection})
+}
+
+// broadcastUserStatusToOthers difunde el estado de un usuario a los conectados que comparten un espacio con él
+func (ucm *UserConnectionManager) broadcastUserStatusToOthers(userID int, status domain.UserStatus) {
+	message := ucm.statusMessage(userID, status)
+
+	ucm.mutex.RLock()
+	connectionsToNotify := ucm.visibleConnectionsLocked(userID)
+	ucm.mutex.RUnlock()
+
+	ucm.sendToConnections(message, flattenConnections(connectionsToNotify))
+}
+
+// sendToConnections encola el mensaje en cada conexión sin bloquear, las que
+// están saturadas se cierran y su goroutine de lectura las quita
+func (ucm *UserConnectionManager) sendToConnections(message domain.UserConnectionMessage, connections []*socketConnection) {
+	for _, connection := range connections {
+		if !connection.SendJSON(message) {
+			connection.Close()
+		}
 	}
+}
 
-	err := conn.WriteJSON(message)
-	if err != nil {
-		// Si no se puede enviar el mensaje inicial, cerrar la conexión
-		conn.Close()
-		ucm.mutex.Lock()
-		delete(ucm.connections, userID)
-		ucm.mutex.Unlock()
+// userConnectionsLocked retorna los dispositivos conectados del usuario, se llama con el mutex tomado
+func (ucm *UserConnectionManager) userConnectionsLocked(userID int) []*socketConnection {
+	connections := make([]*socketConnection, 0, len(ucm.connections[userID]))
+	for connection := range ucm.connections[userID] {
+		connections = append(connections, connection)
 	}
+	return connections
 }
 
-// broadcastUserStatusToOthers difunde el estado de un usuario a todos los demás conectados
-func (ucm *UserConnectionManager) broadcastUserStatusToOthers(userID int, status domain.UserStatus) {
-	message := domain.UserConnectionMessage{
-		Type:      "user_status",
-		UserID:    userID,
-		Status:    status,
-		Username:  ucm.getUsername(userID),
-		Timestamp: time.Now().Format(time.RFC3339),
+// visibleConnectionsLocked retorna las conexiones de los demás usuarios que comparten
+// un espacio con userID, se llama con el mutex tomado
+func (ucm *UserConnectionManager) visibleConnectionsLocked(userID int) map[int][]*socketConnection {
+	connections := make(map[int][]*socketConnection)
+	for id := range ucm.connections {
+		if id != userID && ucm.sharesSpaceLocked(userID, id) {
+			connections[id] = ucm.userConnectionsLocked(id)
+		}
 	}
+	return connections
+}
 
-	// Crear una copia de las conexiones para evitar bloqueos largos
-	ucm.mutex.RLock()
-	connectionsToNotify := make(map[int]*websocket.Conn, len(ucm.connections))
-	for id, conn := range ucm.connections {
-		if id != userID {
-			connectionsToNotify[id] = conn
+func (ucm *UserConnectionManager) sharesSpaceLocked(userID, otherID int) bool {
+	for spaceID := range ucm.userSpaces[userID] {
+		if ucm.userSpaces[otherID][spaceID] {
+			return true
 		}
 	}
-	ucm.mutex.RUnlock()
+	return false
+}
+
+// MembershipsChanged recarga los espacios de los usuarios conectados. Quienes empiezan
+// a compartir un espacio intercambian su estado, quienes dejan de compartir se ven offline.
+func (ucm *UserConnectionManager) MembershipsChanged(userIDs []int) {
+	for _, userID := range userIDs {
+		ucm.mutex.RLock()
+		_, connected := ucm.connections[userID]
+		ucm.mutex.RUnlock()
+		if !connected {
+			continue
+		}
 
-	// Enviar mensajes de forma asíncrona para evitar bloqueos
-	go func() {
-		for id, conn := range connectionsToNotify {
-			err := conn.WriteJSON(message)
-			if err != nil {
-				// Si hay error, remover la conexión
-				ucm.mutex.Lock()
-				delete(ucm.connections, id)
-				ucm.mutex.Unlock()
+		spaceIDs, err := ucm.userSpaceRepository.FindSpacesIDsByUserID(context.Background(), userID)
+		if err != nil {
+			log.Printf("Error reloading spaces of user %d: %v", userID, err)
+			continue
+		}
+
+		ucm.mutex.Lock()
+		if _, connected := ucm.connections[userID]; !connected {
+			ucm.mutex.Unlock()
+			continue
+		}
+		devices := ucm.userConnectionsLocked(userID)
+		before := ucm.visibleConnectionsLocked(userID)
+		ucm.userSpaces[userID] = toSpaceSet(spaceIDs)
+		after := ucm.visibleConnectionsLocked(userID)
+		statuses := make(map[int]domain.UserStatus, len(ucm.userStatus))
+		for id, status := range ucm.userStatus {
+			statuses[id] = status.Public()
+		}
+		ucm.mutex.Unlock()
+
+		for otherID, otherDevices := range after {
+			if _, visible := before[otherID]; !visible {
+				ucm.exchangeStatus(userID, devices, statuses[userID], otherID, otherDevices, statuses[otherID])
+			}
+		}
+		for otherID, otherDevices := range before {
+			if _, visible := after[otherID]; !visible {
+				ucm.exchangeStatus(userID, devices, domain.UserStatusOffline, otherID, otherDevices, domain.UserStatusOffline)
 			}
 		}
-	}()
+	}
+}
+
+// SessionsRevoked cierra las conexiones del usuario abiertas con sesiones revocadas,
+// handleMessages las quita y anuncia la desconexión
+func (ucm *UserConnectionManager) SessionsRevoked(userID int, revoked func(sessionID string) bool) {
+	for _, connection := range ucm.userConnections(userID) {
+		if revoked(connection.sessionID) {
+			connection.Close()
+		}
+	}
+}
+
+func (ucm *UserConnectionManager) userConnections(userID int) []*socketConnection {
+	ucm.mutex.RLock()
+	defer ucm.mutex.RUnlock()
+
+	return ucm.userConnectionsLocked(userID)
+}
+
+// exchangeStatus envía a cada usuario el estado del otro
+func (ucm *UserConnectionManager) exchangeStatus(userID int, devices []*socketConnection, status domain.UserStatus, otherID int, otherDevices []*socketConnection, otherStatus domain.UserStatus) {
+	ucm.sendToConnections(ucm.statusMessage(userID, status), otherDevices)
+	ucm.sendToConnections(ucm.statusMessage(otherID, otherStatus), devices)
 }
 
-// sendConnectedUsersList envía la lista de usuarios ya conectados al nuevo usuario
-func (ucm *UserConnectionManager) sendConnectedUsersList(newUserID int, conn *websocket.Conn) {
+// sendConnectedUsersList envía la lista de usuarios ya conectados al nuevo dispositivo
+func (ucm *UserConnectionManager) sendConnectedUsersList(newUserID int, connection *socketConnection) {
 	ucm.mutex.RLock()
-	connectedUsers := make([]domain.UserConnectionMessage, 0)
-	for userID := range ucm.connections {
-		if userID != newUserID { // No incluir al usuario que se acaba de conectar
-			connectedUsers = append(connectedUsers, domain.UserConnectionMessage{
-				Type:      "user_status",
-				UserID:    userID,
-				Status:    domain.UserStatusOnline,
-				Username:  ucm.getUsername(userID),
-				Timestamp: time.Now().Format(time.RFC3339),
-			})
+	connectedStatus := make(map[int]domain.UserStatus)
+	for userID := range ucm.visibleConnectionsLocked(newUserID) {
+		// No incluir a los invisibles
+		if status := ucm.userStatus[userID].Public(); status != domain.UserStatusOffline {
+			connectedStatus[userID] = status
 		}
 	}
 	ucm.mutex.RUnlock()
 
-	// Enviar cada usuario conectado como un mensaje separado
-	for _, userMessage := range connectedUsers {
-		err := conn.WriteJSON(userMessage)
+	// Enviar cada usuario conectado como un mensaje separado, los perfiles se
+	// buscan sin el lock porque pueden requerir ir a la base
+	for userID, status := range connectedStatus {
+		ucm.sendToConnections(ucm.statusMessage(userID, status), []*socketConnection{connection})
+	}
+}
+
+func flattenConnections(connections map[int][]*socketConnection) []*socketConnection {
+	flattened := make([]*socketConnection, 0, len(connections))
+	for _, devices := range connections {
+		flattened = append(flattened, devices...)
+	}
+	return flattened
+}
+
+// statusMessage arma el mensaje de estado con el nombre y avatar del usuario
+func (ucm *UserConnectionManager) statusMessage(userID int, status domain.UserStatus) domain.UserConnectionMessage {
+	profile := ucm.getProfile(userID)
+
+	return domain.UserConnectionMessage{
+		Type:      domain.UserConnectionMessageTypeStatus,
+		UserID:    userID,
+		Status:    status,
+		Username:  profile.username,
+		Image:     profile.image,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+// getProfile obtiene el nombre y avatar del usuario, cacheados por userProfileCacheTTL
+func (ucm *UserConnectionManager) getProfile(userID int) userProfile {
+	ucm.profileMutex.Lock()
+	defer ucm.profileMutex.Unlock()
+
+	if profile, exists := ucm.profiles[userID]; exists && time.Now().Before(profile.expiresAt) {
+		return profile
+	}
+
+	user, err := ucm.userRepository.Find(context.Background(), criteria.NewCriteriaBuilder().
+		WithFilter("id", userID, criteria.OperatorEqual).
+		Build())
+	if err != nil || user == nil {
 		if err != nil {
-			// Si no se puede enviar, cerrar la conexión
-			conn.Close()
-			ucm.mutex.Lock()
-			delete(ucm.connections, newUserID)
-			ucm.mutex.Unlock()
-			break
+			log.Printf("Error finding user %d for presence: %v", userID, err)
 		}
+		// Sin cachear, el próximo mensaje vuelve a intentar
+		return userProfile{username: "User" + strconv.Itoa(userID)}
 	}
+
+	profile := userProfile{
+		username:  user.FullName(),
+		image:     user.Image,
+		expiresAt: time.Now().Add(userProfileCacheTTL),
+	}
+	ucm.profiles[userID] = profile
+	return profile
 }
 
-// getUsername obtiene el nombre de usuario (placeholder - implementar según lógica de negocio)
-func (ucm *UserConnectionManager) getUsername(userID int) string {
-	// TODO: Implementar lógica para obtener el username del userID
-	return "User" + strconv.Itoa(userID)
+func toSpaceSet(spaceIDs []int) map[int]bool {
+	spaces := make(map[int]bool, len(spaceIDs))
+	for _, spaceID := range spaceIDs {
+		spaces[spaceID] = true
+	}
+	return spaces
 }
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mock.NewMockUserRepository(ctrl)
+	ucm := NewUserConnectionManager(userRepository, mock.NewMockUserSpaceRepository(ctrl), NewHubManager()).(*UserConnectionManager)
+
+	userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 7, Name: "Ana", LastName: "Gómez", Image: "ana.png"}, nil).Times(1)
+
+	for i := 0; i < 2; i++ {
+		message := ucm.statusMessage(7, domain.UserStatusAway)
+
+		assert.Equal(t, "Ana Gómez", message.Username)
+		assert.Equal(t, "ana.png", message.Image)
+		assert.Equal(t, domain.UserStatusAway, message.Status)
+	}
+}
+
+func TestRemoveConnectionSavesLastSeen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mock.NewMockUserRepository(ctrl)
+	ucm := NewUserConnectionManager(userRepository, mock.NewMockUserSpaceRepository(ctrl), NewHubManager()).(*UserConnectionManager)
+
+	t.Run("visible user goes offline with the last device", func(t *testing.T) {
+		phone, laptop := newTestSocketConnection(), newTestSocketConnection()
+		ucm.addConnection(7, phone, []int{3})
+		ucm.addConnection(7, laptop, []int{3})
+		ucm.userStatus[7] = domain.UserStatusDoNotDisturb
+
+		ucm.removeConnection(7, phone)
+		assert.Equal(t, domain.UserStatusDoNotDisturb, ucm.userStatus[7], "the laptop is still connected")
+
+		userRepository.EXPECT().UpdateLastSeen(gomock.Any(), 7, gomock.Any()).Return(nil)
+		userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 7, Name: "Ana"}, nil)
+
+		ucm.removeConnection(7, laptop)
+
+		assert.NotContains(t, ucm.userStatus, 7)
+		assert.NotContains(t, ucm.connections, 7)
+	})
+
+	t.Run("invisible user is not revealed", func(t *testing.T) {
+		connection := newTestSocketConnection()
+		ucm.addConnection(8, connection, []int{3})
+		ucm.userStatus[8] = domain.UserStatusInvisible
+
+		ucm.removeConnection(8, connection)
+
+		assert.NotContains(t, ucm.userStatus, 8)
+	})
+}
+
+func TestUserStatus(t *testing.T) {
+	assert.True(t, domain.UserStatusDoNotDisturb.IsSelectable())
+	assert.False(t, domain.UserStatusOffline.IsSelectable())
+	assert.Equal(t, domain.UserStatusOffline, domain.UserStatusInvisible.Public())
+	assert.Equal(t, domain.UserStatusAway, domain.UserStatusAway.Public())
+}
+
+func TestVisibleConnectionsShareASpace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	ucm := NewUserConnectionManager(mock.NewMockUserRepository(ctrl), userSpaceRepository, NewHubManager()).(*UserConnectionManager)
+
+	second := newTestSocketConnection()
+	ucm.addConnection(1, newTestSocketConnection(), []int{10, 11})
+	ucm.addConnection(2, second, []int{11})
+	ucm.addConnection(3, newTestSocketConnection(), []int{12})
+
+	assert.Equal(t, map[int][]*socketConnection{2: {second}}, ucm.visibleConnectionsLocked(1))
+	assert.Empty(t, ucm.visibleConnectionsLocked(3))
+
+	t.Run("reloads the spaces of connected users only", func(t *testing.T) {
+		userSpaceRepository.EXPECT().FindSpacesIDsByUserID(gomock.Any(), 3).Return([]int{12, 13}, nil)
+
+		ucm.MembershipsChanged([]int{3, 4})
+
+		assert.Equal(t, toSpaceSet([]int{12, 13}), ucm.userSpaces[3])
+		assert.NotContains(t, ucm.userSpaces, 4)
+	})
+}
+
+func TestStatusChangeReachesEveryDevice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepository := mock.NewMockUserRepository(ctrl)
+	ucm := NewUserConnectionManager(userRepository, mock.NewMockUserSpaceRepository(ctrl), NewHubManager()).(*UserConnectionManager)
+	userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 7, Name: "Ana"}, nil).AnyTimes()
+
+	phone, laptop := newTestSocketConnection(), newTestSocketConnection()
+	ucm.addConnection(7, phone, []int{3})
+	ucm.addConnection(7, laptop, []int{3})
+	other := newTestSocketConnection()
+	ucm.addConnection(2, other, []int{3})
+
+	ucm.handleMessage(7, []byte(`{"type":"set_status","status":"away"}`))
+
+	for _, connection := range []*socketConnection{phone, laptop, other} {
+		if assert.Len(t, connection.send, 1) {
+			var message domain.UserConnectionMessage
+			assert.NoError(t, json.Unmarshal((<-connection.send).data, &message))
+			assert.Equal(t, domain.UserStatusAway, message.Status)
+		}
+	}
+}
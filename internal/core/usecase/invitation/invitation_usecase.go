@@ -0,0 +1,465 @@
+package invitation
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/authorization"
+	"cpi-hub-api/internal/core/usecase/notification"
+	pghelpers "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/helpers"
+	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
+	"log"
+	"time"
+)
+
+type InvitationUseCase interface {
+	CreateInvitation(ctx context.Context, actorID int, spaceID int, params dto.CreateInvitationDTO) (*domain.SpaceInvitation, string, error)
+	ListInvitations(ctx context.Context, actorID int, spaceID int) ([]*domain.SpaceInvitation, error)
+	ListUserInvitations(ctx context.Context, userID int) ([]*domain.SpaceInvitation, error)
+	RevokeInvitation(ctx context.Context, actorID int, invitationID string) error
+	AcceptInvitation(ctx context.Context, userID int, invitationID string) (*domain.SpaceInvitation, error)
+	AcceptInvitationLink(ctx context.Context, userID int, token string) (*domain.SpaceInvitation, error)
+	DeclineInvitation(ctx context.Context, userID int, invitationID string) error
+	RequestToJoin(ctx context.Context, userID int, spaceID int, message string) (*domain.JoinRequest, error)
+	ListJoinRequests(ctx context.Context, actorID int, spaceID int) ([]*domain.JoinRequest, error)
+	ReviewJoinRequest(ctx context.Context, actorID int, requestID string, approve bool) (*domain.JoinRequest, error)
+}
+
+type invitationUseCase struct {
+	invitationRepository  domain.SpaceInvitationRepository
+	joinRequestRepository domain.JoinRequestRepository
+	spaceRepository       domain.SpaceRepository
+	userRepository        domain.UserRepository
+	userSpaceRepository   domain.UserSpaceRepository
+	notificationUsecase   notification.NotificationUsecase
+	spaceAuthorizer       authorization.SpaceAuthorizer
+}
+
+func NewInvitationUsecase(
+	invitationRepository domain.SpaceInvitationRepository,
+	joinRequestRepository domain.JoinRequestRepository,
+	spaceRepository domain.SpaceRepository,
+	userRepository domain.UserRepository,
+	userSpaceRepository domain.UserSpaceRepository,
+	notificationUsecase notification.NotificationUsecase,
+) InvitationUseCase {
+	return &invitationUseCase{
+		invitationRepository:  invitationRepository,
+		joinRequestRepository: joinRequestRepository,
+		spaceRepository:       spaceRepository,
+		userRepository:        userRepository,
+		userSpaceRepository:   userSpaceRepository,
+		notificationUsecase:   notificationUsecase,
+		spaceAuthorizer:       authorization.NewSpaceAuthorizer(userSpaceRepository),
+	}
+}
+
+// CreateInvitation creates a direct invitation when an invitee is given, otherwise a
+// shareable link. The link token is only returned here, the database keeps its hash.
+func (u *invitationUseCase) CreateInvitation(ctx context.Context, actorID int, spaceID int, params dto.CreateInvitationDTO) (*domain.SpaceInvitation, string, error) {
+	space, err := u.findModeratedSpace(ctx, spaceID, actorID, "Only owners and moderators can invite users")
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := authorization.RequireWritable(space); err != nil {
+		return nil, "", err
+	}
+
+	ttl := domain.DefaultInvitationTTL
+	if params.ExpiresInHours > 0 {
+		ttl = time.Duration(params.ExpiresInHours) * time.Hour
+	}
+
+	now := helpers.GetTime()
+	invitation := &domain.SpaceInvitation{
+		ID:        helpers.NewULID(),
+		SpaceID:   space.ID,
+		InviterID: actorID,
+		InviteeID: params.InviteeID,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	var token string
+	if params.InviteeID != nil {
+		if err := u.ensureInvitable(ctx, space.ID, *params.InviteeID); err != nil {
+			return nil, "", err
+		}
+		invitation.MaxUses = 1
+	} else {
+		token, err = helpers.NewOpaqueToken()
+		if err != nil {
+			return nil, "", apperror.NewInternalServer("Error generating invitation token", err, "invitation_usecase.go:CreateInvitation")
+		}
+		invitation.TokenHash = helpers.HashToken(token)
+		invitation.MaxUses = domain.DefaultInvitationLinkUses
+		if params.MaxUses > 0 {
+			invitation.MaxUses = params.MaxUses
+		}
+	}
+
+	if err := u.invitationRepository.Create(ctx, invitation); err != nil {
+		return nil, "", err
+	}
+
+	if invitation.IsDirect() {
+		u.notify(ctx, domain.NotificationTypeSpaceInvitation, space.ID, actorID, *invitation.InviteeID)
+	}
+
+	return invitation, token, nil
+}
+
+func (u *invitationUseCase) ListInvitations(ctx context.Context, actorID int, spaceID int) ([]*domain.SpaceInvitation, error) {
+	if _, err := u.findModeratedSpace(ctx, spaceID, actorID, "Only owners and moderators can list invitations"); err != nil {
+		return nil, err
+	}
+
+	return u.invitationRepository.FindActiveBySpaceID(ctx, spaceID)
+}
+
+func (u *invitationUseCase) ListUserInvitations(ctx context.Context, userID int) ([]*domain.SpaceInvitation, error) {
+	return u.invitationRepository.FindActiveByInviteeID(ctx, userID)
+}
+
+func (u *invitationUseCase) RevokeInvitation(ctx context.Context, actorID int, invitationID string) error {
+	invitation, err := u.findInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.findModeratedSpace(ctx, invitation.SpaceID, actorID, "Only owners and moderators can revoke invitations"); err != nil {
+		return err
+	}
+
+	revoked, err := u.invitationRepository.Revoke(ctx, invitation.ID)
+	if err != nil {
+		return err
+	}
+
+	if !revoked {
+		return apperror.NewInvalidData("Invitation already revoked", nil, "invitation_usecase.go:RevokeInvitation")
+	}
+
+	if invitation.IsDirect() {
+		u.notify(ctx, domain.NotificationTypeInvitationRevoked, invitation.SpaceID, actorID, *invitation.InviteeID)
+	}
+
+	return nil
+}
+
+// AcceptInvitation redeems a direct invitation, only its invitee can accept it
+func (u *invitationUseCase) AcceptInvitation(ctx context.Context, userID int, invitationID string) (*domain.SpaceInvitation, error) {
+	invitation, err := u.findDirectInvitation(ctx, userID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.redeem(ctx, userID, invitation); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (u *invitationUseCase) AcceptInvitationLink(ctx context.Context, userID int, token string) (*domain.SpaceInvitation, error) {
+	invitation, err := u.invitationRepository.FindByTokenHash(ctx, helpers.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil || invitation.IsDirect() {
+		return nil, apperror.NewNotFound("Invitation not found", nil, "invitation_usecase.go:AcceptInvitationLink")
+	}
+
+	if err := u.redeem(ctx, userID, invitation); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (u *invitationUseCase) DeclineInvitation(ctx context.Context, userID int, invitationID string) error {
+	invitation, err := u.findDirectInvitation(ctx, userID, invitationID)
+	if err != nil {
+		return err
+	}
+
+	if !invitation.IsUsable(helpers.GetTime()) {
+		return apperror.NewInvalidData("Invitation is no longer valid", nil, "invitation_usecase.go:DeclineInvitation")
+	}
+
+	if _, err := u.invitationRepository.Revoke(ctx, invitation.ID); err != nil {
+		return err
+	}
+
+	u.notify(ctx, domain.NotificationTypeInvitationDeclined, invitation.SpaceID, userID, invitation.InviterID)
+
+	return nil
+}
+
+// RequestToJoin asks the moderators of a private space to let the user in,
+// public spaces are joined directly
+func (u *invitationUseCase) RequestToJoin(ctx context.Context, userID int, spaceID int, message string) (*domain.JoinRequest, error) {
+	space, err := pghelpers.FindEntity(ctx, u.spaceRepository, "id", spaceID, "Space not found")
+	if err != nil {
+		return nil, err
+	}
+
+	if !space.IsPrivate() {
+		return nil, apperror.NewInvalidData("Public spaces can be joined directly", nil, "invitation_usecase.go:RequestToJoin")
+	}
+
+	if err := authorization.RequireWritable(space); err != nil {
+		return nil, err
+	}
+
+	if err := u.ensureNotMember(ctx, space.ID, userID); err != nil {
+		return nil, err
+	}
+
+	pending, err := u.joinRequestRepository.FindPending(ctx, space.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending != nil {
+		return nil, apperror.NewInvalidData("You already have a pending request for this space", nil, "invitation_usecase.go:RequestToJoin")
+	}
+
+	request := &domain.JoinRequest{
+		ID:        helpers.NewULID(),
+		SpaceID:   space.ID,
+		UserID:    userID,
+		Status:    domain.JoinRequestPending,
+		Message:   message,
+		CreatedAt: helpers.GetTime(),
+	}
+
+	if err := u.joinRequestRepository.Create(ctx, request); err != nil {
+		return nil, err
+	}
+
+	u.notifyModerators(ctx, space.ID, userID)
+
+	return request, nil
+}
+
+func (u *invitationUseCase) ListJoinRequests(ctx context.Context, actorID int, spaceID int) ([]*domain.JoinRequest, error) {
+	if _, err := u.findModeratedSpace(ctx, spaceID, actorID, "Only owners and moderators can review join requests"); err != nil {
+		return nil, err
+	}
+
+	return u.joinRequestRepository.FindPendingBySpaceID(ctx, spaceID)
+}
+
+func (u *invitationUseCase) ReviewJoinRequest(ctx context.Context, actorID int, requestID string, approve bool) (*domain.JoinRequest, error) {
+	request, err := u.joinRequestRepository.FindByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request == nil {
+		return nil, apperror.NewNotFound("Join request not found", nil, "invitation_usecase.go:ReviewJoinRequest")
+	}
+
+	space, err := u.findModeratedSpace(ctx, request.SpaceID, actorID, "Only owners and moderators can review join requests")
+	if err != nil {
+		return nil, err
+	}
+
+	if approve {
+		if err := authorization.RequireWritable(space); err != nil {
+			return nil, err
+		}
+	}
+
+	status := domain.JoinRequestRejected
+	notificationType := domain.NotificationTypeJoinRequestRejected
+	if approve {
+		status = domain.JoinRequestApproved
+		notificationType = domain.NotificationTypeJoinRequestApproved
+	}
+
+	reviewed, err := u.joinRequestRepository.Review(ctx, request.ID, status, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reviewed {
+		return nil, apperror.NewInvalidData("Join request was already reviewed", nil, "invitation_usecase.go:ReviewJoinRequest")
+	}
+
+	if approve {
+		if err := u.addMember(ctx, space.ID, request.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := helpers.GetTime()
+	request.Status = status
+	request.ReviewedBy = &actorID
+	request.ReviewedAt = &now
+
+	u.notify(ctx, notificationType, space.ID, actorID, request.UserID)
+
+	return request, nil
+}
+
+// redeem consumes one use of the invitation and adds the user to its space
+func (u *invitationUseCase) redeem(ctx context.Context, userID int, invitation *domain.SpaceInvitation) error {
+	if !invitation.IsUsable(helpers.GetTime()) {
+		return apperror.NewInvalidData("Invitation is no longer valid", nil, "invitation_usecase.go:redeem")
+	}
+
+	space, err := pghelpers.FindEntity(ctx, u.spaceRepository, "id", invitation.SpaceID, "Space not found")
+	if err != nil {
+		return err
+	}
+
+	if err := authorization.RequireWritable(space); err != nil {
+		return err
+	}
+
+	if err := u.ensureNotMember(ctx, space.ID, userID); err != nil {
+		return err
+	}
+
+	consumed, err := u.invitationRepository.IncrementUses(ctx, invitation.ID)
+	if err != nil {
+		return err
+	}
+
+	if !consumed {
+		return apperror.NewInvalidData("Invitation is no longer valid", nil, "invitation_usecase.go:redeem")
+	}
+
+	if err := u.userSpaceRepository.AddMember(ctx, userID, space.ID, domain.SpaceRoleMember); err != nil {
+		return err
+	}
+
+	invitation.Uses++
+
+	u.notify(ctx, domain.NotificationTypeInvitationAccepted, space.ID, userID, invitation.InviterID)
+
+	return nil
+}
+
+// addMember is a no-op when the user already joined, e.g. through an invitation
+func (u *invitationUseCase) addMember(ctx context.Context, spaceID int, userID int) error {
+	exists, err := u.userSpaceRepository.Exists(ctx, userID, spaceID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	return u.userSpaceRepository.AddMember(ctx, userID, spaceID, domain.SpaceRoleMember)
+}
+
+func (u *invitationUseCase) findModeratedSpace(ctx context.Context, spaceID int, actorID int, message string) (*domain.Space, error) {
+	space, err := pghelpers.FindEntity(ctx, u.spaceRepository, "id", spaceID, "Space not found")
+	if err != nil {
+		return nil, err
+	}
+
+	canModerate, err := u.spaceAuthorizer.CanModerate(ctx, space.ID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModerate {
+		return nil, apperror.NewForbidden(message, nil, "invitation_usecase.go:findModeratedSpace")
+	}
+
+	return space, nil
+}
+
+func (u *invitationUseCase) findInvitation(ctx context.Context, invitationID string) (*domain.SpaceInvitation, error) {
+	invitation, err := u.invitationRepository.FindByID(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil {
+		return nil, apperror.NewNotFound("Invitation not found", nil, "invitation_usecase.go:findInvitation")
+	}
+
+	return invitation, nil
+}
+
+// findDirectInvitation hides invitations addressed to other users
+func (u *invitationUseCase) findDirectInvitation(ctx context.Context, userID int, invitationID string) (*domain.SpaceInvitation, error) {
+	invitation, err := u.findInvitation(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !invitation.IsDirect() || *invitation.InviteeID != userID {
+		return nil, apperror.NewNotFound("Invitation not found", nil, "invitation_usecase.go:findDirectInvitation")
+	}
+
+	return invitation, nil
+}
+
+func (u *invitationUseCase) ensureInvitable(ctx context.Context, spaceID int, inviteeID int) error {
+	if _, err := pghelpers.FindEntity(ctx, u.userRepository, "id", inviteeID, "User not found"); err != nil {
+		return err
+	}
+
+	exists, err := u.userSpaceRepository.Exists(ctx, inviteeID, spaceID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return apperror.NewInvalidData("User is already a member of this space", nil, "invitation_usecase.go:ensureInvitable")
+	}
+
+	return nil
+}
+
+func (u *invitationUseCase) ensureNotMember(ctx context.Context, spaceID int, userID int) error {
+	exists, err := u.userSpaceRepository.Exists(ctx, userID, spaceID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return apperror.NewInvalidData("You are already a member of this space", nil, "invitation_usecase.go:ensureNotMember")
+	}
+
+	return nil
+}
+
+func (u *invitationUseCase) notifyModerators(ctx context.Context, spaceID int, actorID int) {
+	members, err := u.userSpaceRepository.FindMembersBySpaceID(ctx, spaceID)
+	if err != nil {
+		log.Printf("Error finding moderators of space %d: %v", spaceID, err)
+		return
+	}
+
+	for _, member := range members {
+		if member.Role.CanModerate() {
+			u.notify(ctx, domain.NotificationTypeJoinRequest, spaceID, actorID, member.UserID)
+		}
+	}
+}
+
+// notify is best effort, the state change already happened
+func (u *invitationUseCase) notify(ctx context.Context, notificationType domain.NotificationType, spaceID int, actorID int, userID int) {
+	err := u.notificationUsecase.CreateNotification(ctx, dto.CreateNotificationParams{
+		NotificationType: notificationType,
+		EntityType:       domain.EntityTypeSpace,
+		EntityID:         spaceID,
+		OwnerUserID:      userID,
+		ActorUserID:      actorID,
+		SpaceID:          spaceID,
+	})
+	if err != nil {
+		log.Printf("Error creating %s notification for user %d: %v", notificationType, userID, err)
+	}
+}
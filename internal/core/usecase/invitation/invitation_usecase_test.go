@@ -0,0 +1,363 @@
+package invitation
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
+	mocks "cpi-hub-api/internal/core/usecase/notification/mock"
+	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type testMocks struct {
+	invitationRepository  *mock.MockSpaceInvitationRepository
+	joinRequestRepository *mock.MockJoinRequestRepository
+	spaceRepository       *mock.MockSpaceRepository
+	userRepository        *mock.MockUserRepository
+	userSpaceRepository   *mock.MockUserSpaceRepository
+	notificationUsecase   *mocks.MockNotificationUsecase
+}
+
+func newTestUsecase(ctrl *gomock.Controller) (InvitationUseCase, testMocks) {
+	m := testMocks{
+		invitationRepository:  mock.NewMockSpaceInvitationRepository(ctrl),
+		joinRequestRepository: mock.NewMockJoinRequestRepository(ctrl),
+		spaceRepository:       mock.NewMockSpaceRepository(ctrl),
+		userRepository:        mock.NewMockUserRepository(ctrl),
+		userSpaceRepository:   mock.NewMockUserSpaceRepository(ctrl),
+		notificationUsecase:   mocks.NewMockNotificationUsecase(ctrl),
+	}
+
+	return NewInvitationUsecase(m.invitationRepository, m.joinRequestRepository, m.spaceRepository, m.userRepository, m.userSpaceRepository, m.notificationUsecase), m
+}
+
+func notificationFor(notificationType domain.NotificationType, spaceID int, actorID int, userID int) dto.CreateNotificationParams {
+	return dto.CreateNotificationParams{
+		NotificationType: notificationType,
+		EntityType:       domain.EntityTypeSpace,
+		EntityID:         spaceID,
+		OwnerUserID:      userID,
+		ActorUserID:      actorID,
+		SpaceID:          spaceID,
+	}
+}
+
+func TestCreateInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invitationUseCase, m := newTestUsecase(ctrl)
+
+	space := &domain.Space{ID: 10, Visibility: domain.SpaceVisibilityPrivate}
+	inviteeID := 2
+
+	t.Run("direct invitation notifies the invitee", func(t *testing.T) {
+		gomock.InOrder(
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+			m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleModerator, nil),
+			m.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: inviteeID}, nil),
+			m.userSpaceRepository.EXPECT().Exists(gomock.Any(), inviteeID, 10).Return(false, nil),
+			m.invitationRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, invitation *domain.SpaceInvitation) error {
+					assert.Equal(t, 1, invitation.MaxUses)
+					assert.Empty(t, invitation.TokenHash)
+					return nil
+				}),
+			m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), notificationFor(domain.NotificationTypeSpaceInvitation, 10, 1, inviteeID)).Return(nil),
+		)
+
+		invitation, token, err := invitationUseCase.CreateInvitation(context.Background(), 1, 10, dto.CreateInvitationDTO{InviteeID: &inviteeID})
+
+		assert.NoError(t, err)
+		assert.Empty(t, token)
+		assert.True(t, invitation.IsDirect())
+	})
+
+	t.Run("link invitation stores the token hash", func(t *testing.T) {
+		var storedHash string
+		gomock.InOrder(
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+			m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+			m.invitationRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, invitation *domain.SpaceInvitation) error {
+					assert.Equal(t, 5, invitation.MaxUses)
+					storedHash = invitation.TokenHash
+					return nil
+				}),
+		)
+
+		_, token, err := invitationUseCase.CreateInvitation(context.Background(), 1, 10, dto.CreateInvitationDTO{MaxUses: 5, ExpiresInHours: 2})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, helpers.HashToken(token), storedHash)
+	})
+
+	t.Run("error member cannot invite", func(t *testing.T) {
+		gomock.InOrder(
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+			m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 3, 10).Return(domain.SpaceRoleMember, nil),
+		)
+
+		_, _, err := invitationUseCase.CreateInvitation(context.Background(), 3, 10, dto.CreateInvitationDTO{})
+
+		assert.Equal(t, apperror.NewForbidden("Only owners and moderators can invite users", nil, "invitation_usecase.go:findModeratedSpace"), err)
+	})
+
+	t.Run("error invitee already a member", func(t *testing.T) {
+		gomock.InOrder(
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+			m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+			m.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: inviteeID}, nil),
+			m.userSpaceRepository.EXPECT().Exists(gomock.Any(), inviteeID, 10).Return(true, nil),
+		)
+
+		_, _, err := invitationUseCase.CreateInvitation(context.Background(), 1, 10, dto.CreateInvitationDTO{InviteeID: &inviteeID})
+
+		assert.Equal(t, apperror.NewInvalidData("User is already a member of this space", nil, "invitation_usecase.go:ensureInvitable"), err)
+	})
+}
+
+func TestAcceptInvitationLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invitationUseCase, m := newTestUsecase(ctrl)
+
+	space := &domain.Space{ID: 10, Visibility: domain.SpaceVisibilityPrivate}
+	validInvitation := func() *domain.SpaceInvitation {
+		return &domain.SpaceInvitation{ID: "inv", SpaceID: 10, InviterID: 1, MaxUses: 2, Uses: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+
+	tests := []struct {
+		name  string
+		want  error
+		calls func() []*gomock.Call
+	}{
+		{
+			name: "success adds member and notifies inviter",
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.invitationRepository.EXPECT().FindByTokenHash(gomock.Any(), helpers.HashToken("token")).Return(validInvitation(), nil),
+					m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+					m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 5, 10).Return(false, nil),
+					m.invitationRepository.EXPECT().IncrementUses(gomock.Any(), "inv").Return(true, nil),
+					m.userSpaceRepository.EXPECT().AddMember(gomock.Any(), 5, 10, domain.SpaceRoleMember).Return(nil),
+					m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), notificationFor(domain.NotificationTypeInvitationAccepted, 10, 5, 1)).Return(errors.New("mongo down")),
+				}
+			},
+		},
+		{
+			name: "error unknown token",
+			want: apperror.NewNotFound("Invitation not found", nil, "invitation_usecase.go:AcceptInvitationLink"),
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.invitationRepository.EXPECT().FindByTokenHash(gomock.Any(), gomock.Any()).Return(nil, nil),
+				}
+			},
+		},
+		{
+			name: "error expired invitation",
+			want: apperror.NewInvalidData("Invitation is no longer valid", nil, "invitation_usecase.go:redeem"),
+			calls: func() []*gomock.Call {
+				expired := validInvitation()
+				expired.ExpiresAt = time.Now().Add(-time.Hour)
+				return []*gomock.Call{
+					m.invitationRepository.EXPECT().FindByTokenHash(gomock.Any(), gomock.Any()).Return(expired, nil),
+				}
+			},
+		},
+		{
+			name: "error last use taken concurrently",
+			want: apperror.NewInvalidData("Invitation is no longer valid", nil, "invitation_usecase.go:redeem"),
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.invitationRepository.EXPECT().FindByTokenHash(gomock.Any(), gomock.Any()).Return(validInvitation(), nil),
+					m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+					m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 5, 10).Return(false, nil),
+					m.invitationRepository.EXPECT().IncrementUses(gomock.Any(), "inv").Return(false, nil),
+				}
+			},
+		},
+		{
+			name: "error already a member",
+			want: apperror.NewInvalidData("You are already a member of this space", nil, "invitation_usecase.go:ensureNotMember"),
+			calls: func() []*gomock.Call {
+				return []*gomock.Call{
+					m.invitationRepository.EXPECT().FindByTokenHash(gomock.Any(), gomock.Any()).Return(validInvitation(), nil),
+					m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+					m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 5, 10).Return(true, nil),
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := test.calls()
+			ordered := make([]interface{}, len(calls))
+			for i, c := range calls {
+				ordered[i] = c
+			}
+			gomock.InOrder(ordered...)
+
+			_, gotErr := invitationUseCase.AcceptInvitationLink(context.Background(), 5, "token")
+
+			assert.Equal(t, test.want, gotErr)
+		})
+	}
+}
+
+func TestDeclineInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invitationUseCase, m := newTestUsecase(ctrl)
+
+	inviteeID := 5
+	invitation := &domain.SpaceInvitation{ID: "inv", SpaceID: 10, InviterID: 1, InviteeID: &inviteeID, MaxUses: 1, ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("success notifies inviter", func(t *testing.T) {
+		gomock.InOrder(
+			m.invitationRepository.EXPECT().FindByID(gomock.Any(), "inv").Return(invitation, nil),
+			m.invitationRepository.EXPECT().Revoke(gomock.Any(), "inv").Return(true, nil),
+			m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), notificationFor(domain.NotificationTypeInvitationDeclined, 10, inviteeID, 1)).Return(nil),
+		)
+
+		assert.NoError(t, invitationUseCase.DeclineInvitation(context.Background(), inviteeID, "inv"))
+	})
+
+	t.Run("error invitation addressed to another user", func(t *testing.T) {
+		m.invitationRepository.EXPECT().FindByID(gomock.Any(), "inv").Return(invitation, nil)
+
+		err := invitationUseCase.DeclineInvitation(context.Background(), 9, "inv")
+
+		assert.Equal(t, apperror.NewNotFound("Invitation not found", nil, "invitation_usecase.go:findDirectInvitation"), err)
+	})
+}
+
+func TestRequestToJoin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invitationUseCase, m := newTestUsecase(ctrl)
+
+	privateSpace := &domain.Space{ID: 10, Visibility: domain.SpaceVisibilityPrivate}
+
+	t.Run("success notifies owners and moderators", func(t *testing.T) {
+		gomock.InOrder(
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(privateSpace, nil),
+			m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 5, 10).Return(false, nil),
+			m.joinRequestRepository.EXPECT().FindPending(gomock.Any(), 10, 5).Return(nil, nil),
+			m.joinRequestRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil),
+			m.userSpaceRepository.EXPECT().FindMembersBySpaceID(gomock.Any(), 10).Return([]*domain.SpaceMembership{
+				{UserID: 1, SpaceID: 10, Role: domain.SpaceRoleOwner},
+				{UserID: 2, SpaceID: 10, Role: domain.SpaceRoleModerator},
+				{UserID: 3, SpaceID: 10, Role: domain.SpaceRoleMember},
+			}, nil),
+			m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), notificationFor(domain.NotificationTypeJoinRequest, 10, 5, 1)).Return(nil),
+			m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), notificationFor(domain.NotificationTypeJoinRequest, 10, 5, 2)).Return(nil),
+		)
+
+		request, err := invitationUseCase.RequestToJoin(context.Background(), 5, 10, "hi")
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.JoinRequestPending, request.Status)
+	})
+
+	t.Run("error public space", func(t *testing.T) {
+		m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 11, Visibility: domain.SpaceVisibilityPublic}, nil)
+
+		_, err := invitationUseCase.RequestToJoin(context.Background(), 5, 11, "")
+
+		assert.Equal(t, apperror.NewInvalidData("Public spaces can be joined directly", nil, "invitation_usecase.go:RequestToJoin"), err)
+	})
+
+	t.Run("error pending request exists", func(t *testing.T) {
+		gomock.InOrder(
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(privateSpace, nil),
+			m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 5, 10).Return(false, nil),
+			m.joinRequestRepository.EXPECT().FindPending(gomock.Any(), 10, 5).Return(&domain.JoinRequest{ID: "req"}, nil),
+		)
+
+		_, err := invitationUseCase.RequestToJoin(context.Background(), 5, 10, "")
+
+		assert.Equal(t, apperror.NewInvalidData("You already have a pending request for this space", nil, "invitation_usecase.go:RequestToJoin"), err)
+	})
+}
+
+func TestReviewJoinRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invitationUseCase, m := newTestUsecase(ctrl)
+
+	space := &domain.Space{ID: 10, Visibility: domain.SpaceVisibilityPrivate}
+	pendingRequest := func() *domain.JoinRequest {
+		return &domain.JoinRequest{ID: "req", SpaceID: 10, UserID: 5, Status: domain.JoinRequestPending}
+	}
+
+	t.Run("approve adds member and notifies requester", func(t *testing.T) {
+		gomock.InOrder(
+			m.joinRequestRepository.EXPECT().FindByID(gomock.Any(), "req").Return(pendingRequest(), nil),
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+			m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRoleModerator, nil),
+			m.joinRequestRepository.EXPECT().Review(gomock.Any(), "req", domain.JoinRequestApproved, 2).Return(true, nil),
+			m.userSpaceRepository.EXPECT().Exists(gomock.Any(), 5, 10).Return(false, nil),
+			m.userSpaceRepository.EXPECT().AddMember(gomock.Any(), 5, 10, domain.SpaceRoleMember).Return(nil),
+			m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), notificationFor(domain.NotificationTypeJoinRequestApproved, 10, 2, 5)).Return(nil),
+		)
+
+		request, err := invitationUseCase.ReviewJoinRequest(context.Background(), 2, "req", true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.JoinRequestApproved, request.Status)
+	})
+
+	t.Run("reject notifies requester", func(t *testing.T) {
+		gomock.InOrder(
+			m.joinRequestRepository.EXPECT().FindByID(gomock.Any(), "req").Return(pendingRequest(), nil),
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+			m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+			m.joinRequestRepository.EXPECT().Review(gomock.Any(), "req", domain.JoinRequestRejected, 1).Return(true, nil),
+			m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), notificationFor(domain.NotificationTypeJoinRequestRejected, 10, 1, 5)).Return(nil),
+		)
+
+		request, err := invitationUseCase.ReviewJoinRequest(context.Background(), 1, "req", false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.JoinRequestRejected, request.Status)
+	})
+
+	t.Run("error already reviewed", func(t *testing.T) {
+		gomock.InOrder(
+			m.joinRequestRepository.EXPECT().FindByID(gomock.Any(), "req").Return(pendingRequest(), nil),
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+			m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+			m.joinRequestRepository.EXPECT().Review(gomock.Any(), "req", domain.JoinRequestRejected, 1).Return(false, nil),
+		)
+
+		_, err := invitationUseCase.ReviewJoinRequest(context.Background(), 1, "req", false)
+
+		assert.Equal(t, apperror.NewInvalidData("Join request was already reviewed", nil, "invitation_usecase.go:ReviewJoinRequest"), err)
+	})
+
+	t.Run("error member cannot review", func(t *testing.T) {
+		gomock.InOrder(
+			m.joinRequestRepository.EXPECT().FindByID(gomock.Any(), "req").Return(pendingRequest(), nil),
+			m.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil),
+			m.userSpaceRepository.EXPECT().FindRole(gomock.Any(), 3, 10).Return(domain.SpaceRoleMember, nil),
+		)
+
+		_, err := invitationUseCase.ReviewJoinRequest(context.Background(), 3, "req", true)
+
+		assert.Equal(t, apperror.NewForbidden("Only owners and moderators can review join requests", nil, "invitation_usecase.go:findModeratedSpace"), err)
+	})
+}
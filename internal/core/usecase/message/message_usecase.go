@@ -4,6 +4,15 @@ import (
 	"context"
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/authorization"
+	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
+	"strings"
+)
+
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 100
 )
 
 type SearchResult struct {
@@ -11,21 +20,35 @@ type SearchResult struct {
 	Total    int
 }
 
+type HistoryResult struct {
+	Messages   []*domain.ChatMessage
+	NextCursor *string
+	HasMore    bool
+}
+
 type MessageUseCase interface {
 	Search(ctx context.Context, params dto.SearchMessagesParams) (*SearchResult, error)
+	History(ctx context.Context, params dto.MessageHistoryParams) (*HistoryResult, error)
+	UnreadCounts(ctx context.Context, userID int) ([]*domain.SpaceUnreadCount, error)
 }
 
 type messageUseCase struct {
 	messageRepository domain.MessageRepository
+	membershipPolicy  authorization.MembershipPolicy
 }
 
-func NewMessageUsecase(messageRepo domain.MessageRepository) MessageUseCase {
+func NewMessageUsecase(messageRepo domain.MessageRepository, spaceRepo domain.SpaceRepository, userSpaceRepo domain.UserSpaceRepository) MessageUseCase {
 	return &messageUseCase{
 		messageRepository: messageRepo,
+		membershipPolicy:  authorization.NewMembershipPolicy(spaceRepo, userSpaceRepo),
 	}
 }
 
 func (m *messageUseCase) Search(ctx context.Context, params dto.SearchMessagesParams) (*SearchResult, error) {
+	if err := m.membershipPolicy.RequireMember(ctx, params.SpaceID, params.UserID); err != nil {
+		return nil, err
+	}
+
 	filters := domain.SearchMessagesFilter{
 		SpaceID:       params.SpaceID,
 		Page:          params.Page,
@@ -47,3 +70,59 @@ func (m *messageUseCase) Search(ctx context.Context, params dto.SearchMessagesPa
 		Total:    total,
 	}, nil
 }
+
+// History pages through the chat by message ID. Scrolling back uses before (or no cursor
+// for the newest page), catching up after a reconnect uses after.
+func (m *messageUseCase) History(ctx context.Context, params dto.MessageHistoryParams) (*HistoryResult, error) {
+	if params.Before != "" && params.After != "" {
+		return nil, apperror.NewInvalidData("Use either before or after, not both", nil, "message_usecase.go:History")
+	}
+
+	for _, cursor := range []string{params.Before, params.After} {
+		if cursor != "" && !helpers.IsULID(cursor) {
+			return nil, apperror.NewInvalidData("Invalid cursor", nil, "message_usecase.go:History")
+		}
+	}
+
+	// ULIDs are parsed in any case but stored uppercase, and the IDs are compared byte by byte
+	before, after := strings.ToUpper(params.Before), strings.ToUpper(params.After)
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if limit > MaxHistoryLimit {
+		limit = MaxHistoryLimit
+	}
+
+	if err := m.membershipPolicy.RequireMember(ctx, params.SpaceID, params.UserID); err != nil {
+		return nil, err
+	}
+
+	messages, hasMore, err := m.messageRepository.SearchMessagesByCursor(ctx, domain.MessageCursorFilter{
+		SpaceID: params.SpaceID,
+		Before:  before,
+		After:   after,
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistoryResult{Messages: messages, HasMore: hasMore}
+	if hasMore && len(messages) > 0 {
+		// Backwards the next page ends at the oldest message, forwards it starts at the newest
+		cursor := messages[0].ID
+		if after != "" {
+			cursor = messages[len(messages)-1].ID
+		}
+		result.NextCursor = &cursor
+	}
+
+	return result, nil
+}
+
+// UnreadCounts returns the unread chat messages of every space the user belongs to
+func (m *messageUseCase) UnreadCounts(ctx context.Context, userID int) ([]*domain.SpaceUnreadCount, error) {
+	return m.messageRepository.CountUnreadBySpace(ctx, userID)
+}
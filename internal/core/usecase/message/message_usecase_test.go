@@ -0,0 +1,115 @@
+package message
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/pkg/apperror"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMessageRepository := mock.NewMockMessageRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+
+	messageUseCase := NewMessageUsecase(mockMessageRepository, mock.NewMockSpaceRepository(ctrl), mockUserSpaceRepository)
+
+	page := []*domain.ChatMessage{
+		{ID: "01HV0000000000000000000001"},
+		{ID: "01HV0000000000000000000002"},
+	}
+	cursor := "01HV0000000000000000000009"
+
+	type want struct {
+		nextCursor *string
+		hasMore    bool
+		err        error
+	}
+
+	tests := []struct {
+		name   string
+		params dto.MessageHistoryParams
+		want   want
+		calls  []*gomock.Call
+	}{
+		{
+			name:   "newest page points back to the oldest message",
+			params: dto.MessageHistoryParams{SpaceID: 3, UserID: 7},
+			want:   want{nextCursor: &page[0].ID, hasMore: true},
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 7, 3).Return(true, nil),
+				mockMessageRepository.EXPECT().SearchMessagesByCursor(gomock.Any(), domain.MessageCursorFilter{SpaceID: 3, Limit: DefaultHistoryLimit}).Return(page, true, nil),
+			},
+		},
+		{
+			name:   "lowercase cursors are compared uppercase",
+			params: dto.MessageHistoryParams{SpaceID: 3, UserID: 7, Before: "01hv0000000000000000000009"},
+			want:   want{hasMore: false},
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 7, 3).Return(true, nil),
+				mockMessageRepository.EXPECT().SearchMessagesByCursor(gomock.Any(), domain.MessageCursorFilter{SpaceID: 3, Before: cursor, Limit: DefaultHistoryLimit}).Return(page, false, nil),
+			},
+		},
+		{
+			name:   "catching up points forward to the newest message",
+			params: dto.MessageHistoryParams{SpaceID: 3, UserID: 7, After: cursor, Limit: 2},
+			want:   want{nextCursor: &page[1].ID, hasMore: true},
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 7, 3).Return(true, nil),
+				mockMessageRepository.EXPECT().SearchMessagesByCursor(gomock.Any(), domain.MessageCursorFilter{SpaceID: 3, After: cursor, Limit: 2}).Return(page, true, nil),
+			},
+		},
+		{
+			name:   "last page has no cursor and limit is capped",
+			params: dto.MessageHistoryParams{SpaceID: 3, UserID: 7, Before: cursor, Limit: 500},
+			want:   want{},
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 7, 3).Return(true, nil),
+				mockMessageRepository.EXPECT().SearchMessagesByCursor(gomock.Any(), domain.MessageCursorFilter{SpaceID: 3, Before: cursor, Limit: MaxHistoryLimit}).Return(page, false, nil),
+			},
+		},
+		{
+			name:   "error both cursors",
+			params: dto.MessageHistoryParams{SpaceID: 3, UserID: 7, Before: cursor, After: cursor},
+			want:   want{err: apperror.NewInvalidData("Use either before or after, not both", nil, "message_usecase.go:History")},
+		},
+		{
+			name:   "error malformed cursor",
+			params: dto.MessageHistoryParams{SpaceID: 3, UserID: 7, Before: "42"},
+			want:   want{err: apperror.NewInvalidData("Invalid cursor", nil, "message_usecase.go:History")},
+		},
+		{
+			name:   "error not a member",
+			params: dto.MessageHistoryParams{SpaceID: 3, UserID: 8},
+			want:   want{err: apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireMember")},
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 8, 3).Return(false, nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := make([]interface{}, len(test.calls))
+			for i, c := range test.calls {
+				calls[i] = c
+			}
+			gomock.InOrder(calls...)
+
+			result, gotErr := messageUseCase.History(context.Background(), test.params)
+
+			assert.Equal(t, test.want.err, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, test.want.nextCursor, result.NextCursor)
+				assert.Equal(t, test.want.hasMore, result.HasMore)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cpi-hub-api/internal/core/usecase/notification (interfaces: NotificationUsecase)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mock/notification_usecase_mock.go -package=mocks . NotificationUsecase
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	domain "cpi-hub-api/internal/core/domain"
+	dto "cpi-hub-api/internal/core/dto"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationUsecase is a mock of NotificationUsecase interface.
+type MockNotificationUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationUsecaseMockRecorder is the mock recorder for MockNotificationUsecase.
+type MockNotificationUsecaseMockRecorder struct {
+	mock *MockNotificationUsecase
+}
+
+// NewMockNotificationUsecase creates a new mock instance.
+func NewMockNotificationUsecase(ctrl *gomock.Controller) *MockNotificationUsecase {
+	mock := &MockNotificationUsecase{ctrl: ctrl}
+	mock.recorder = &MockNotificationUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationUsecase) EXPECT() *MockNotificationUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreateNotification mocks base method.
+func (m *MockNotificationUsecase) CreateNotification(ctx context.Context, params dto.CreateNotificationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockNotificationUsecaseMockRecorder) CreateNotification(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockNotificationUsecase)(nil).CreateNotification), ctx, params)
+}
+
+// FanOutNotification mocks base method.
+func (m *MockNotificationUsecase) FanOutNotification(ctx context.Context, params dto.FanOutNotificationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FanOutNotification", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FanOutNotification indicates an expected call of FanOutNotification.
+func (mr *MockNotificationUsecaseMockRecorder) FanOutNotification(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FanOutNotification", reflect.TypeOf((*MockNotificationUsecase)(nil).FanOutNotification), ctx, params)
+}
+
+// GetPreferences mocks base method.
+func (m *MockNotificationUsecase) GetPreferences(ctx context.Context, userID int) (*domain.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, userID)
+	ret0, _ := ret[0].(*domain.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockNotificationUsecaseMockRecorder) GetPreferences(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockNotificationUsecase)(nil).GetPreferences), ctx, userID)
+}
+
+// GetUnreadCount mocks base method.
+func (m *MockNotificationUsecase) GetUnreadCount(ctx context.Context, userID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnreadCount", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnreadCount indicates an expected call of GetUnreadCount.
+func (mr *MockNotificationUsecaseMockRecorder) GetUnreadCount(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnreadCount", reflect.TypeOf((*MockNotificationUsecase)(nil).GetUnreadCount), ctx, userID)
+}
+
+// GetUserNotifications mocks base method.
+func (m *MockNotificationUsecase) GetUserNotifications(ctx context.Context, userID, limit, offset int) ([]*domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserNotifications", ctx, userID, limit, offset)
+	ret0, _ := ret[0].([]*domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserNotifications indicates an expected call of GetUserNotifications.
+func (mr *MockNotificationUsecaseMockRecorder) GetUserNotifications(ctx, userID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserNotifications", reflect.TypeOf((*MockNotificationUsecase)(nil).GetUserNotifications), ctx, userID, limit, offset)
+}
+
+// MarkAllAsRead mocks base method.
+func (m *MockNotificationUsecase) MarkAllAsRead(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllAsRead", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAllAsRead indicates an expected call of MarkAllAsRead.
+func (mr *MockNotificationUsecaseMockRecorder) MarkAllAsRead(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllAsRead", reflect.TypeOf((*MockNotificationUsecase)(nil).MarkAllAsRead), ctx, userID)
+}
+
+// MarkAsRead mocks base method.
+func (m *MockNotificationUsecase) MarkAsRead(ctx context.Context, userID int, notificationID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsRead", ctx, userID, notificationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsRead indicates an expected call of MarkAsRead.
+func (mr *MockNotificationUsecaseMockRecorder) MarkAsRead(ctx, userID, notificationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsRead", reflect.TypeOf((*MockNotificationUsecase)(nil).MarkAsRead), ctx, userID, notificationID)
+}
+
+// Mute mocks base method.
+func (m *MockNotificationUsecase) Mute(ctx context.Context, userID int, params dto.MuteNotificationsDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Mute", ctx, userID, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Mute indicates an expected call of Mute.
+func (mr *MockNotificationUsecaseMockRecorder) Mute(ctx, userID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mute", reflect.TypeOf((*MockNotificationUsecase)(nil).Mute), ctx, userID, params)
+}
+
+// ResetPreferences mocks base method.
+func (m *MockNotificationUsecase) ResetPreferences(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPreferences", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPreferences indicates an expected call of ResetPreferences.
+func (mr *MockNotificationUsecaseMockRecorder) ResetPreferences(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPreferences", reflect.TypeOf((*MockNotificationUsecase)(nil).ResetPreferences), ctx, userID)
+}
+
+// RetractNotification mocks base method.
+func (m *MockNotificationUsecase) RetractNotification(ctx context.Context, params dto.RetractNotificationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetractNotification", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetractNotification indicates an expected call of RetractNotification.
+func (mr *MockNotificationUsecaseMockRecorder) RetractNotification(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetractNotification", reflect.TypeOf((*MockNotificationUsecase)(nil).RetractNotification), ctx, params)
+}
+
+// Unmute mocks base method.
+func (m *MockNotificationUsecase) Unmute(ctx context.Context, userID int, entityType string, entityID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unmute", ctx, userID, entityType, entityID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unmute indicates an expected call of Unmute.
+func (mr *MockNotificationUsecaseMockRecorder) Unmute(ctx, userID, entityType, entityID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unmute", reflect.TypeOf((*MockNotificationUsecase)(nil).Unmute), ctx, userID, entityType, entityID)
+}
+
+// UpdatePreferences mocks base method.
+func (m *MockNotificationUsecase) UpdatePreferences(ctx context.Context, userID int, params dto.UpdateNotificationPreferencesDTO) (*domain.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", ctx, userID, params)
+	ret0, _ := ret[0].(*domain.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences.
+func (mr *MockNotificationUsecaseMockRecorder) UpdatePreferences(ctx, userID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockNotificationUsecase)(nil).UpdatePreferences), ctx, userID, params)
+}
@@ -0,0 +1,150 @@
+package notification
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/criteria"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DigestConfig sets how often the email digest goes out. Every user with
+// notifications waiting gets one email listing up to MaxItems of them, the
+// rest go in the next digest.
+type DigestConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	MaxItems  int
+	// AppURL is the frontend base URL linked from the email
+	AppURL string
+}
+
+var DefaultDigestConfig = DigestConfig{
+	Interval:  24 * time.Hour,
+	BatchSize: 100,
+	MaxItems:  50,
+}
+
+// NotificationDigest emails the notifications whose type has the email digest
+// channel on and marks them as digested
+type NotificationDigest struct {
+	digestRepository domain.NotificationDigestRepository
+	userRepository   domain.UserRepository
+	mailer           domain.Mailer
+	config           DigestConfig
+}
+
+func NewNotificationDigest(
+	digestRepository domain.NotificationDigestRepository,
+	userRepository domain.UserRepository,
+	mailer domain.Mailer,
+	config DigestConfig,
+) *NotificationDigest {
+	return &NotificationDigest{
+		digestRepository: digestRepository,
+		userRepository:   userRepository,
+		mailer:           mailer,
+		config:           config,
+	}
+}
+
+// Run sends the pending digests every Interval
+func (d *NotificationDigest) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := d.SendPending(ctx); err != nil {
+			log.Printf("Error sending notification digests: %v", err)
+		}
+	}
+}
+
+// SendPending sends one digest to every user with notifications waiting. A user
+// whose email fails is skipped and gets the same notifications next time.
+func (d *NotificationDigest) SendPending(ctx context.Context) error {
+	afterUserID := 0
+	for {
+		userIDs, err := d.digestRepository.FindDigestUserIDs(ctx, afterUserID, d.config.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			if err := d.send(ctx, userID); err != nil {
+				log.Printf("Error sending notification digest to user %d: %v", userID, err)
+			}
+		}
+
+		if len(userIDs) < d.config.BatchSize {
+			return nil
+		}
+		afterUserID = userIDs[len(userIDs)-1]
+	}
+}
+
+func (d *NotificationDigest) send(ctx context.Context, userID int) error {
+	notifications, err := d.digestRepository.FindDigest(ctx, userID, d.config.MaxItems)
+	if err != nil || len(notifications) == 0 {
+		return err
+	}
+
+	user, err := d.userRepository.Find(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("id", userID, criteria.OperatorEqual).
+		Build())
+	if err != nil {
+		return err
+	}
+
+	notificationIDs := make([]string, len(notifications))
+	for i, notification := range notifications {
+		notificationIDs[i] = notification.ID
+	}
+
+	// Nobody left to email, the notifications are dropped from the digest
+	if user == nil {
+		return d.digestRepository.MarkDigested(ctx, userID, notificationIDs)
+	}
+
+	if err := d.mailer.Send(ctx, domain.MailMessage{
+		To:      user.Email,
+		Subject: "Your notification digest",
+		Body:    d.digestBody(notifications),
+	}); err != nil {
+		return err
+	}
+
+	return d.digestRepository.MarkDigested(ctx, userID, notificationIDs)
+}
+
+// digestBody counts the notifications by type, the frontend shows the details
+func (d *NotificationDigest) digestBody(notifications []*domain.Notification) string {
+	counts := make(map[domain.NotificationType]int)
+	for _, notification := range notifications {
+		counts[notification.Type]++
+	}
+
+	types := make([]string, 0, len(counts))
+	for notificationType := range counts {
+		types = append(types, string(notificationType))
+	}
+	sort.Strings(types)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "You have %d new notifications:\n\n", len(notifications))
+	for _, notificationType := range types {
+		fmt.Fprintf(&body, "- %d %s\n", counts[domain.NotificationType(notificationType)], strings.ReplaceAll(notificationType, "_", " "))
+	}
+	fmt.Fprintf(&body, "\n%s/notifications", d.config.AppURL)
+
+	return body.String()
+}
@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/infrastructure/adapters/mailer"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationDigestSendPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	digestRepository := mock.NewMockNotificationDigestRepository(ctrl)
+	userRepository := mock.NewMockUserRepository(ctrl)
+	inMemoryMailer := mailer.NewInMemoryMailer()
+
+	config := DigestConfig{BatchSize: 2, MaxItems: 10, AppURL: "http://app"}
+	digest := NewNotificationDigest(digestRepository, userRepository, inMemoryMailer, config)
+
+	gomock.InOrder(
+		digestRepository.EXPECT().FindDigestUserIDs(gomock.Any(), 0, 2).Return([]int{1, 2}, nil),
+		digestRepository.EXPECT().FindDigest(gomock.Any(), 1, 10).Return([]*domain.Notification{
+			{ID: "a", Type: domain.NotificationTypeReaction},
+			{ID: "b", Type: domain.NotificationTypeReaction},
+			{ID: "c", Type: domain.NotificationTypeNewPost},
+		}, nil),
+		userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1, Email: "one@test.com"}, nil),
+		digestRepository.EXPECT().MarkDigested(gomock.Any(), 1, []string{"a", "b", "c"}).Return(nil),
+		digestRepository.EXPECT().FindDigest(gomock.Any(), 2, 10).Return(nil, errors.New("unexpected error")),
+		digestRepository.EXPECT().FindDigestUserIDs(gomock.Any(), 2, 2).Return([]int{3}, nil),
+		digestRepository.EXPECT().FindDigest(gomock.Any(), 3, 10).Return([]*domain.Notification{{ID: "d", Type: domain.NotificationTypeComment}}, nil),
+		userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil),
+		digestRepository.EXPECT().MarkDigested(gomock.Any(), 3, []string{"d"}).Return(nil),
+	)
+
+	assert.NoError(t, digest.SendPending(context.Background()))
+
+	sent := inMemoryMailer.Sent()
+	assert.Len(t, sent, 1, "a failed user is skipped and a deleted one gets no email")
+	assert.Equal(t, "one@test.com", sent[0].To)
+	assert.Equal(t, "You have 3 new notifications:\n\n- 1 new post\n- 2 reaction\n\nhttp://app/notifications", sent[0].Body)
+}
@@ -0,0 +1,132 @@
+package notification
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/pkg/helpers"
+	"log"
+	"time"
+)
+
+// DispatcherConfig sets how pending notifications are pushed. A delivery is
+// retried after BaseBackoff, doubled on each attempt up to MaxBackoff. After
+// MaxAttempts it waits until the user connects again.
+type DispatcherConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	MaxAttempts  int
+}
+
+var DefaultDispatcherConfig = DispatcherConfig{
+	PollInterval: time.Second,
+	BatchSize:    100,
+	BaseBackoff:  2 * time.Second,
+	MaxBackoff:   5 * time.Minute,
+	MaxAttempts:  8,
+}
+
+// NotificationDispatcher pushes the notifications in the outbox until a socket
+// write or a client ack marks them as delivered
+type NotificationDispatcher struct {
+	deliveryRepository domain.NotificationDeliveryRepository
+	notificationMgr    domain.NotificationManager
+	config             DispatcherConfig
+	wake               chan struct{}
+	now                func() time.Time
+}
+
+func NewNotificationDispatcher(
+	deliveryRepository domain.NotificationDeliveryRepository,
+	notificationMgr domain.NotificationManager,
+	config DispatcherConfig,
+) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		deliveryRepository: deliveryRepository,
+		notificationMgr:    notificationMgr,
+		config:             config,
+		wake:               make(chan struct{}, 1),
+		now:                helpers.GetTime,
+	}
+}
+
+// Run dispatches due deliveries every PollInterval, or right away after Notify
+func (d *NotificationDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+
+		if err := d.DispatchPending(ctx); err != nil {
+			log.Printf("Error dispatching notifications: %v", err)
+		}
+	}
+}
+
+// Notify wakes the dispatcher after a new notification is saved
+func (d *NotificationDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// DispatchPending pushes one batch of due deliveries. Every attempt is
+// scheduled before the push, so a crash or a lost write is retried later.
+func (d *NotificationDispatcher) DispatchPending(ctx context.Context) error {
+	deliveries, err := d.deliveryRepository.FindDueDeliveries(ctx, d.now(), d.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		claimed, err := d.deliveryRepository.ScheduleRetry(ctx, delivery, d.nextAttemptAt(delivery.Attempts+1))
+		if err != nil {
+			log.Printf("Error scheduling notification %s: %v", delivery.Notification.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := d.notificationMgr.BroadcastToUser(delivery.Notification.UserID, delivery.Notification); err != nil {
+			log.Printf("Error broadcasting notification to user %d: %v", delivery.Notification.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+// PushRetraction tells the user's connected devices about a retracted notification.
+// It skips the outbox, devices that are offline load the inbox as it is.
+func (d *NotificationDispatcher) PushRetraction(retraction *domain.NotificationRetraction) {
+	userID := retraction.Notification.UserID
+	if err := d.notificationMgr.BroadcastRetraction(userID, retraction); err != nil {
+		log.Printf("Error broadcasting notification retraction to user %d: %v", userID, err)
+	}
+}
+
+// nextAttemptAt returns when to retry after the given attempt, nil once they run out
+func (d *NotificationDispatcher) nextAttemptAt(attempt int) *time.Time {
+	if attempt >= d.config.MaxAttempts {
+		return nil
+	}
+
+	backoff := d.config.BaseBackoff
+	for i := 1; i < attempt && backoff < d.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.config.MaxBackoff {
+		backoff = d.config.MaxBackoff
+	}
+
+	next := d.now().Add(backoff)
+	return &next
+}
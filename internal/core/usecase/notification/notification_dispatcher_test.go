@@ -0,0 +1,200 @@
+package notification
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryDeliveryRepository keeps the outbox in memory with the same rules as Mongo
+type memoryDeliveryRepository struct {
+	mu         sync.Mutex
+	deliveries map[string]*domain.NotificationDelivery
+	delivered  map[string]bool
+}
+
+func newMemoryDeliveryRepository(notifications ...*domain.Notification) *memoryDeliveryRepository {
+	r := &memoryDeliveryRepository{
+		deliveries: make(map[string]*domain.NotificationDelivery),
+		delivered:  make(map[string]bool),
+	}
+	for _, notification := range notifications {
+		next := notification.CreatedAt
+		r.deliveries[notification.ID] = &domain.NotificationDelivery{Notification: notification, NextAttemptAt: &next}
+	}
+	return r
+}
+
+func (r *memoryDeliveryRepository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.NotificationDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*domain.NotificationDelivery
+	for id, delivery := range r.deliveries {
+		if r.delivered[id] || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		copied := *delivery
+		due = append(due, &copied)
+		if len(due) == limit {
+			break
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryDeliveryRepository) ScheduleRetry(ctx context.Context, delivery *domain.NotificationDelivery, nextAttemptAt *time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.deliveries[delivery.Notification.ID]
+	if r.delivered[delivery.Notification.ID] || stored.Attempts != delivery.Attempts {
+		return false, nil
+	}
+	stored.Attempts++
+	stored.NextAttemptAt = nextAttemptAt
+	return true, nil
+}
+
+func (r *memoryDeliveryRepository) MarkDelivered(ctx context.Context, userID int, notificationID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.deliveries[notificationID]
+	if !ok || stored.Notification.UserID != userID || r.delivered[notificationID] {
+		return false, nil
+	}
+	r.delivered[notificationID] = true
+	return true, nil
+}
+
+func (r *memoryDeliveryRepository) RescheduleUser(ctx context.Context, userID int, nextAttemptAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, delivery := range r.deliveries {
+		if delivery.Notification.UserID == userID && !r.delivered[id] && delivery.Attempts > 0 {
+			next := nextAttemptAt
+			delivery.Attempts = 0
+			delivery.NextAttemptAt = &next
+		}
+	}
+	return nil
+}
+
+// stubNotificationManager simulates the sockets, online users get the write confirmed
+type stubNotificationManager struct {
+	deliveryRepository domain.NotificationDeliveryRepository
+	online             map[int]bool
+	broadcasts         []string
+	retractions        []*domain.NotificationRetraction
+}
+
+func (m *stubNotificationManager) HandleConnection(params domain.HandleNotificationConnectionParams) error {
+	return nil
+}
+
+func (m *stubNotificationManager) SessionsRevoked(userID int, revoked func(sessionID string) bool) {}
+
+func (m *stubNotificationManager) BroadcastToUser(userID int, notification *domain.Notification) error {
+	m.broadcasts = append(m.broadcasts, notification.ID)
+	if m.online[userID] {
+		m.deliveryRepository.MarkDelivered(context.Background(), userID, notification.ID)
+	}
+	return nil
+}
+
+func (m *stubNotificationManager) BroadcastRetraction(userID int, retraction *domain.NotificationRetraction) error {
+	m.retractions = append(m.retractions, retraction)
+	return nil
+}
+
+func newTestDispatcher(notifications ...*domain.Notification) (*NotificationDispatcher, *memoryDeliveryRepository, *stubNotificationManager, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, notification := range notifications {
+		notification.CreatedAt = now
+	}
+
+	repository := newMemoryDeliveryRepository(notifications...)
+	manager := &stubNotificationManager{deliveryRepository: repository, online: map[int]bool{}}
+	dispatcher := NewNotificationDispatcher(repository, manager, DispatcherConfig{
+		BatchSize:   10,
+		BaseBackoff: time.Second,
+		MaxBackoff:  4 * time.Second,
+		MaxAttempts: 4,
+	})
+	dispatcher.now = func() time.Time { return now }
+
+	return dispatcher, repository, manager, &now
+}
+
+func TestDispatchPending(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("online user gets the notification once", func(t *testing.T) {
+		dispatcher, repository, manager, now := newTestDispatcher(&domain.Notification{ID: "n-1", UserID: 7})
+		manager.online[7] = true
+
+		assert.NoError(t, dispatcher.DispatchPending(ctx))
+		*now = now.Add(time.Minute)
+		assert.NoError(t, dispatcher.DispatchPending(ctx))
+
+		assert.Equal(t, []string{"n-1"}, manager.broadcasts)
+		assert.True(t, repository.delivered["n-1"])
+	})
+
+	t.Run("offline user is retried with backoff until the attempts run out", func(t *testing.T) {
+		dispatcher, repository, manager, now := newTestDispatcher(&domain.Notification{ID: "n-1", UserID: 7})
+
+		var waits []time.Duration
+		for i := 0; i < 6; i++ {
+			assert.NoError(t, dispatcher.DispatchPending(ctx))
+			next := repository.deliveries["n-1"].NextAttemptAt
+			if next == nil {
+				break
+			}
+			waits = append(waits, next.Sub(*now))
+			*now = *next
+		}
+
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, waits)
+		assert.Len(t, manager.broadcasts, 4)
+
+		t.Run("and is delivered when the user connects again", func(t *testing.T) {
+			manager.online[7] = true
+			assert.NoError(t, repository.RescheduleUser(ctx, 7, *now))
+
+			assert.NoError(t, dispatcher.DispatchPending(ctx))
+
+			assert.Len(t, manager.broadcasts, 5)
+			assert.True(t, repository.delivered["n-1"])
+		})
+	})
+
+	t.Run("attempt claimed by another dispatcher is skipped", func(t *testing.T) {
+		dispatcher, repository, manager, _ := newTestDispatcher(&domain.Notification{ID: "n-1", UserID: 7})
+		dispatcher.deliveryRepository = racingDeliveryRepository{repository}
+
+		assert.NoError(t, dispatcher.DispatchPending(ctx))
+
+		assert.Empty(t, manager.broadcasts)
+		assert.Equal(t, 1, repository.deliveries["n-1"].Attempts)
+	})
+}
+
+// racingDeliveryRepository lets another dispatcher claim every delivery right after it is read
+type racingDeliveryRepository struct {
+	*memoryDeliveryRepository
+}
+
+func (r racingDeliveryRepository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.NotificationDelivery, error) {
+	due, err := r.memoryDeliveryRepository.FindDueDeliveries(ctx, now, limit)
+	for _, delivery := range due {
+		r.memoryDeliveryRepository.ScheduleRetry(ctx, delivery, delivery.NextAttemptAt)
+	}
+	return due, err
+}
@@ -0,0 +1,133 @@
+package notification
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
+	"fmt"
+	"time"
+)
+
+// GetPreferences returns the defaults when the user never changed them
+func (u *notificationUsecase) GetPreferences(ctx context.Context, userID int) (*domain.NotificationPreferences, error) {
+	preferences, err := u.preferenceRepo.Find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if preferences == nil {
+		return domain.NewNotificationPreferences(userID), nil
+	}
+
+	return preferences, nil
+}
+
+func (u *notificationUsecase) UpdatePreferences(ctx context.Context, userID int, params dto.UpdateNotificationPreferencesDTO) (*domain.NotificationPreferences, error) {
+	preferences := domain.NewNotificationPreferences(userID)
+
+	for notificationType, channels := range params.Types {
+		if !domain.IsValidNotificationType(notificationType) {
+			return nil, apperror.NewInvalidData(fmt.Sprintf("Invalid notification type %q", notificationType), nil, "notification_preferences.go:UpdatePreferences")
+		}
+		preferences.Types[domain.NotificationType(notificationType)] = domain.NotificationChannels{
+			InApp:       channels.InApp,
+			Push:        channels.Push,
+			EmailDigest: channels.EmailDigest,
+		}
+	}
+
+	if params.QuietHours != nil {
+		quietHours, err := toQuietHours(params.QuietHours)
+		if err != nil {
+			return nil, err
+		}
+		preferences.QuietHours = quietHours
+	}
+
+	preferences.UpdatedAt = helpers.GetTime()
+
+	if err := u.preferenceRepo.Save(ctx, preferences); err != nil {
+		return nil, err
+	}
+
+	return u.GetPreferences(ctx, userID)
+}
+
+// ResetPreferences goes back to the defaults, mutes included
+func (u *notificationUsecase) ResetPreferences(ctx context.Context, userID int) error {
+	return u.preferenceRepo.Delete(ctx, userID)
+}
+
+func (u *notificationUsecase) Mute(ctx context.Context, userID int, params dto.MuteNotificationsDTO) error {
+	entityType, err := toMutableEntityType(params.EntityType, params.EntityID)
+	if err != nil {
+		return err
+	}
+
+	return u.preferenceRepo.Mute(ctx, userID, entityType, params.EntityID)
+}
+
+func (u *notificationUsecase) Unmute(ctx context.Context, userID int, entityType string, entityID int) error {
+	mutableType, err := toMutableEntityType(entityType, entityID)
+	if err != nil {
+		return err
+	}
+
+	unmuted, err := u.preferenceRepo.Unmute(ctx, userID, mutableType, entityID)
+	if err != nil {
+		return err
+	}
+
+	if !unmuted {
+		return apperror.NewNotFound("Mute not found", nil, "notification_preferences.go:Unmute")
+	}
+
+	return nil
+}
+
+func toMutableEntityType(entityType string, entityID int) (domain.EntityType, error) {
+	if entityID <= 0 {
+		return "", apperror.NewInvalidData("Invalid entity_id", nil, "notification_preferences.go:toMutableEntityType")
+	}
+
+	switch domain.EntityType(entityType) {
+	case domain.EntityTypeSpace, domain.EntityTypePost:
+		return domain.EntityType(entityType), nil
+	}
+
+	return "", apperror.NewInvalidData("Only spaces and posts can be muted", nil, "notification_preferences.go:toMutableEntityType")
+}
+
+func toQuietHours(params *dto.QuietHoursDTO) (*domain.QuietHours, error) {
+	start, err := parseClock(params.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := parseClock(params.End)
+	if err != nil {
+		return nil, err
+	}
+
+	timezone := params.Timezone
+	if timezone == "" {
+		timezone = domain.DefaultQuietHoursTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, apperror.NewInvalidData("Invalid quiet hours timezone", err, "notification_preferences.go:toQuietHours")
+	}
+
+	return &domain.QuietHours{Start: start, End: end, Timezone: timezone}, nil
+}
+
+// parseClock turns "HH:MM" into minutes since midnight
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, apperror.NewInvalidData("Quiet hours must be HH:MM", err, "notification_preferences.go:parseClock")
+	}
+
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -3,64 +3,287 @@ package notification
 import (
 	"context"
 	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/criteria"
 	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
-	"log"
 )
 
+//go:generate mockgen -destination=mock/notification_usecase_mock.go -package=mocks . NotificationUsecase
 type NotificationUsecase interface {
 	CreateNotification(ctx context.Context, params dto.CreateNotificationParams) error
+	FanOutNotification(ctx context.Context, params dto.FanOutNotificationParams) error
+	RetractNotification(ctx context.Context, params dto.RetractNotificationParams) error
 	GetUserNotifications(ctx context.Context, userID int, limit, offset int) ([]*domain.Notification, error)
-	MarkAsRead(ctx context.Context, notificationID string) error
+	MarkAsRead(ctx context.Context, userID int, notificationID string) error
 	MarkAllAsRead(ctx context.Context, userID int) error
 	GetUnreadCount(ctx context.Context, userID int) (int, error)
+	GetPreferences(ctx context.Context, userID int) (*domain.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID int, params dto.UpdateNotificationPreferencesDTO) (*domain.NotificationPreferences, error)
+	ResetPreferences(ctx context.Context, userID int) error
+	Mute(ctx context.Context, userID int, params dto.MuteNotificationsDTO) error
+	Unmute(ctx context.Context, userID int, entityType string, entityID int) error
 }
 
 type notificationUsecase struct {
 	notificationRepo domain.NotificationRepository
-	notificationMgr  domain.NotificationManager
+	preferenceRepo   domain.NotificationPreferenceRepository
+	userRepository   domain.UserRepository
+	dispatcher       *NotificationDispatcher
 }
 
 func NewNotificationUsecase(
 	notificationRepo domain.NotificationRepository,
-	notificationMgr domain.NotificationManager,
+	preferenceRepo domain.NotificationPreferenceRepository,
+	userRepository domain.UserRepository,
+	dispatcher *NotificationDispatcher,
 ) NotificationUsecase {
 	return &notificationUsecase{
 		notificationRepo: notificationRepo,
-		notificationMgr:  notificationMgr,
+		preferenceRepo:   preferenceRepo,
+		userRepository:   userRepository,
+		dispatcher:       dispatcher,
 	}
 }
 
+// fanOutBatchSize bounds the notifications written by a single insert
+const fanOutBatchSize = 500
+
+// CreateNotification follows the recipient's preferences: muted spaces and posts and
+// types without in-app are dropped, push is skipped or held until quiet hours end
 func (u *notificationUsecase) CreateNotification(ctx context.Context, params dto.CreateNotificationParams) error {
-	notification := &domain.Notification{
+	preferences, err := u.GetPreferences(ctx, params.OwnerUserID)
+	if err != nil {
+		return err
+	}
+
+	notification, pushNow := buildNotification(params, preferences)
+	if notification == nil {
+		return nil
+	}
+
+	// The pending delivery is saved with the notification, the dispatcher pushes it
+	err = u.notificationRepo.SaveNotification(ctx, notification)
+	if err != nil {
+		return err
+	}
+
+	if pushNow {
+		u.dispatcher.Notify()
+	}
+
+	return nil
+}
+
+// FanOutNotification creates the notification of every owner with the same preference
+// rules as CreateNotification. Preferences are read and notifications written in batches,
+// the dispatcher is woken once at the end. Grouped types must use CreateNotification.
+func (u *notificationUsecase) FanOutNotification(ctx context.Context, params dto.FanOutNotificationParams) error {
+	grouped := (&domain.Notification{
+		Type:       params.NotificationType,
+		EntityType: params.EntityType,
+		EntityID:   params.EntityID,
+		PostID:     params.PostID,
+	}).GroupKey() != ""
+	if grouped {
+		return apperror.NewInvalidData("Grouped notifications cannot be fanned out", nil, "notification_usecase.go:FanOutNotification")
+	}
+
+	pushNow := false
+
+	for start := 0; start < len(params.OwnerUserIDs); start += fanOutBatchSize {
+		end := min(start+fanOutBatchSize, len(params.OwnerUserIDs))
+		ownerUserIDs := params.OwnerUserIDs[start:end]
+
+		saved, err := u.preferenceRepo.FindByUserIDs(ctx, ownerUserIDs)
+		if err != nil {
+			return err
+		}
+
+		preferencesByUser := make(map[int]*domain.NotificationPreferences, len(saved))
+		for _, preferences := range saved {
+			preferencesByUser[preferences.UserID] = preferences
+		}
+
+		notifications := make([]*domain.Notification, 0, len(ownerUserIDs))
+		for _, ownerUserID := range ownerUserIDs {
+			preferences, ok := preferencesByUser[ownerUserID]
+			if !ok {
+				preferences = domain.NewNotificationPreferences(ownerUserID)
+			}
+
+			notification, push := buildNotification(params.For(ownerUserID), preferences)
+			if notification == nil {
+				continue
+			}
+			notifications = append(notifications, notification)
+			pushNow = pushNow || push
+		}
+
+		if err := u.notificationRepo.SaveNotifications(ctx, notifications); err != nil {
+			return err
+		}
+	}
+
+	if pushNow {
+		u.dispatcher.Notify()
+	}
+
+	return nil
+}
+
+// buildNotification returns nil when the preferences drop the notification. pushNow is
+// set when it has a delivery due right away, not held by quiet hours.
+func buildNotification(params dto.CreateNotificationParams, preferences *domain.NotificationPreferences) (notification *domain.Notification, pushNow bool) {
+	if preferences.IsMuted(params.SpaceID, params.PostID) {
+		return nil, false
+	}
+
+	channels := preferences.Channels(params.NotificationType)
+	if !channels.InApp && !channels.EmailDigest {
+		return nil, false
+	}
+
+	notification = &domain.Notification{
+		Type:        params.NotificationType,
+		EntityType:  params.EntityType,
+		EntityID:    params.EntityID,
+		PostID:      params.PostID,
+		UserID:      params.OwnerUserID,
+		ActorID:     params.ActorUserID,
+		Action:      params.Action,
+		Read:        false,
+		CreatedAt:   helpers.GetTime(),
+		EmailDigest: channels.EmailDigest,
+	}
+	if params.ActorUserID != 0 {
+		notification.ActorIDs = []int{params.ActorUserID}
+		notification.ActorCount = 1
+	}
+
+	// Without in-app the notification only waits for the digest, it is never pushed nor unread
+	if !channels.InApp {
+		notification.DigestOnly = true
+		notification.Read = true
+		return notification, false
+	}
+
+	if !channels.Push {
+		return notification, false
+	}
+
+	deliverAt := notification.CreatedAt
+	deferred := false
+	if preferences.QuietHours != nil {
+		if until, quiet := preferences.QuietHours.Until(deliverAt); quiet {
+			deliverAt, deferred = until, true
+		}
+	}
+	notification.DeliverAt = &deliverAt
+
+	return notification, !deferred
+}
+
+// RetractNotification undoes the actor's part in a grouped notification, such as a
+// reaction that was removed, and pushes what is left to the owner's devices
+func (u *notificationUsecase) RetractNotification(ctx context.Context, params dto.RetractNotificationParams) error {
+	target := &domain.Notification{
 		Type:       params.NotificationType,
 		EntityType: params.EntityType,
 		EntityID:   params.EntityID,
 		PostID:     params.PostID,
 		UserID:     params.OwnerUserID,
-		Read:       false,
-		CreatedAt:  helpers.GetTime(),
+		Action:     params.Action,
+	}
+	if target.GroupKey() == "" {
+		return apperror.NewInvalidData("Only grouped notifications can be retracted", nil, "notification_usecase.go:RetractNotification")
 	}
 
-	err := u.notificationRepo.SaveNotification(ctx, notification)
+	retractions, err := u.notificationRepo.RetractActor(ctx, target, params.ActorUserID)
 	if err != nil {
 		return err
 	}
 
-	err = u.notificationMgr.BroadcastToUser(params.OwnerUserID, notification)
-	if err != nil {
-		log.Printf("Error broadcasting notification to user %d: %v", params.OwnerUserID, err)
+	for _, retraction := range retractions {
+		// The devices never showed a digest only notification
+		if retraction.Notification.DigestOnly {
+			continue
+		}
+
+		retraction.UnreadCount, err = u.notificationRepo.GetUnreadCount(ctx, retraction.Notification.UserID)
+		if err != nil {
+			return err
+		}
+
+		if !retraction.Deleted {
+			if err := u.loadActors(ctx, []*domain.Notification{retraction.Notification}); err != nil {
+				return err
+			}
+		}
+
+		u.dispatcher.PushRetraction(retraction)
 	}
 
 	return nil
 }
 
 func (u *notificationUsecase) GetUserNotifications(ctx context.Context, userID int, limit, offset int) ([]*domain.Notification, error) {
-	return u.notificationRepo.GetUserNotifications(ctx, userID, limit, offset)
+	notifications, err := u.notificationRepo.GetUserNotifications(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.loadActors(ctx, notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// loadActors fetches the sampled actors of the whole page in a single query
+func (u *notificationUsecase) loadActors(ctx context.Context, notifications []*domain.Notification) error {
+	seen := make(map[int]bool)
+	var actorIDs []int
+	for _, notification := range notifications {
+		for _, actorID := range notification.ActorIDs {
+			if !seen[actorID] {
+				seen[actorID] = true
+				actorIDs = append(actorIDs, actorID)
+			}
+		}
+	}
+
+	if len(actorIDs) == 0 {
+		return nil
+	}
+
+	users, err := u.userRepository.Search(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("id", actorIDs, criteria.OperatorIn).
+		Build())
+	if err != nil {
+		return err
+	}
+
+	usersByID := make(map[int]*domain.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	// Deleted users are left out of the sample but still counted
+	for _, notification := range notifications {
+		notification.Actors = make([]*domain.User, 0, len(notification.ActorIDs))
+		for _, actorID := range notification.ActorIDs {
+			if user, ok := usersByID[actorID]; ok {
+				notification.Actors = append(notification.Actors, user)
+			}
+		}
+	}
+
+	return nil
 }
 
-func (u *notificationUsecase) MarkAsRead(ctx context.Context, notificationID string) error {
-	return u.notificationRepo.MarkAsRead(ctx, notificationID)
+func (u *notificationUsecase) MarkAsRead(ctx context.Context, userID int, notificationID string) error {
+	return u.notificationRepo.MarkAsRead(ctx, userID, notificationID)
 }
 
 func (u *notificationUsecase) MarkAllAsRead(ctx context.Context, userID int) error {
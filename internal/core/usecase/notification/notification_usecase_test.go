@@ -0,0 +1,371 @@
+package notification
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateNotification(t *testing.T) {
+	postID := 20
+	quietAllDay := &domain.QuietHours{Start: 0, End: 24*60 - 1, Timezone: "UTC"}
+
+	tests := []struct {
+		name         string
+		preferences  *domain.NotificationPreferences
+		wantSaved    bool
+		wantPush     bool
+		wantDigest   bool
+		wantHidden   bool
+		wantDeferred bool
+	}{
+		{
+			name:      "defaults save and push right away",
+			wantSaved: true,
+			wantPush:  true,
+		},
+		{
+			name:        "muted space drops the notification",
+			preferences: &domain.NotificationPreferences{UserID: 1, MutedSpaceIDs: []int{3}},
+		},
+		{
+			name:        "muted post drops the notification",
+			preferences: &domain.NotificationPreferences{UserID: 1, MutedPostIDs: []int{20}},
+		},
+		{
+			name: "type without in-app is not saved",
+			preferences: &domain.NotificationPreferences{UserID: 1, Types: map[domain.NotificationType]domain.NotificationChannels{
+				domain.NotificationTypeReaction: {Push: true},
+			}},
+		},
+		{
+			name: "type without push is saved for the email digest only",
+			preferences: &domain.NotificationPreferences{UserID: 1, Types: map[domain.NotificationType]domain.NotificationChannels{
+				domain.NotificationTypeReaction: {InApp: true, EmailDigest: true},
+			}},
+			wantSaved:  true,
+			wantDigest: true,
+		},
+		{
+			name: "type with only the email digest is kept out of the inbox",
+			preferences: &domain.NotificationPreferences{UserID: 1, Types: map[domain.NotificationType]domain.NotificationChannels{
+				domain.NotificationTypeReaction: {Push: true, EmailDigest: true},
+			}},
+			wantSaved:  true,
+			wantDigest: true,
+			wantHidden: true,
+		},
+		{
+			name:         "push is held until quiet hours end",
+			preferences:  &domain.NotificationPreferences{UserID: 1, QuietHours: quietAllDay},
+			wantSaved:    true,
+			wantPush:     true,
+			wantDeferred: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notificationRepo := mock.NewMockNotificationRepository(ctrl)
+			preferenceRepo := mock.NewMockNotificationPreferenceRepository(ctrl)
+			dispatcher := NewNotificationDispatcher(nil, nil, DefaultDispatcherConfig)
+			usecase := NewNotificationUsecase(notificationRepo, preferenceRepo, mock.NewMockUserRepository(ctrl), dispatcher)
+
+			preferenceRepo.EXPECT().Find(gomock.Any(), 1).Return(tt.preferences, nil)
+			if tt.wantSaved {
+				notificationRepo.EXPECT().SaveNotification(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, notification *domain.Notification) error {
+					assert.Equal(t, 4, notification.ActorID)
+					assert.Equal(t, []int{4}, notification.ActorIDs)
+					assert.Equal(t, 1, notification.ActorCount)
+					assert.Equal(t, "reaction:like:post:20", notification.GroupKey())
+					assert.Equal(t, tt.wantDigest, notification.EmailDigest)
+					assert.Equal(t, tt.wantHidden, notification.DigestOnly)
+					assert.Equal(t, tt.wantHidden, notification.Read)
+					assert.Equal(t, tt.wantPush, notification.DeliverAt != nil)
+					if tt.wantPush {
+						assert.Equal(t, tt.wantDeferred, notification.DeliverAt.After(notification.CreatedAt))
+					}
+					return nil
+				})
+			}
+
+			err := usecase.CreateNotification(context.Background(), dto.CreateNotificationParams{
+				NotificationType: domain.NotificationTypeReaction,
+				EntityType:       domain.EntityTypePost,
+				EntityID:         20,
+				PostID:           &postID,
+				OwnerUserID:      1,
+				ActorUserID:      4,
+				SpaceID:          3,
+				Action:           domain.ActionTypeLike,
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPush && !tt.wantDeferred, len(dispatcher.wake) == 1)
+		})
+	}
+}
+
+func TestFanOutNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := mock.NewMockNotificationRepository(ctrl)
+	preferenceRepo := mock.NewMockNotificationPreferenceRepository(ctrl)
+	dispatcher := NewNotificationDispatcher(nil, nil, DefaultDispatcherConfig)
+	usecase := NewNotificationUsecase(notificationRepo, preferenceRepo, mock.NewMockUserRepository(ctrl), dispatcher)
+
+	postID := 20
+	ownerUserIDs := make([]int, fanOutBatchSize+2)
+	for i := range ownerUserIDs {
+		ownerUserIDs[i] = i + 1
+	}
+
+	gomock.InOrder(
+		preferenceRepo.EXPECT().FindByUserIDs(gomock.Any(), ownerUserIDs[:fanOutBatchSize]).Return([]*domain.NotificationPreferences{
+			{UserID: 1, MutedSpaceIDs: []int{3}},
+		}, nil),
+		notificationRepo.EXPECT().SaveNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, notifications []*domain.Notification) error {
+			assert.Len(t, notifications, fanOutBatchSize-1)
+			assert.Equal(t, 2, notifications[0].UserID)
+			assert.Equal(t, domain.NotificationTypeNewPost, notifications[0].Type)
+			assert.Equal(t, 4, notifications[0].ActorID)
+			assert.NotNil(t, notifications[0].DeliverAt)
+			return nil
+		}),
+		preferenceRepo.EXPECT().FindByUserIDs(gomock.Any(), ownerUserIDs[fanOutBatchSize:]).Return([]*domain.NotificationPreferences{}, nil),
+		notificationRepo.EXPECT().SaveNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, notifications []*domain.Notification) error {
+			assert.Len(t, notifications, 2)
+			return nil
+		}),
+	)
+
+	err := usecase.FanOutNotification(context.Background(), dto.FanOutNotificationParams{
+		NotificationType: domain.NotificationTypeNewPost,
+		EntityType:       domain.EntityTypePost,
+		EntityID:         20,
+		PostID:           &postID,
+		OwnerUserIDs:     ownerUserIDs,
+		ActorUserID:      4,
+		SpaceID:          3,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, dispatcher.wake, 1)
+}
+
+func TestFanOutNotificationRejectsGroupedTypes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dispatcher := NewNotificationDispatcher(nil, nil, DefaultDispatcherConfig)
+	usecase := NewNotificationUsecase(mock.NewMockNotificationRepository(ctrl), mock.NewMockNotificationPreferenceRepository(ctrl), mock.NewMockUserRepository(ctrl), dispatcher)
+
+	postID := 20
+	err := usecase.FanOutNotification(context.Background(), dto.FanOutNotificationParams{
+		NotificationType: domain.NotificationTypeComment,
+		EntityType:       domain.EntityTypeComment,
+		EntityID:         40,
+		PostID:           &postID,
+		OwnerUserIDs:     []int{1, 2},
+	})
+
+	assert.Error(t, err)
+}
+
+func TestGetUserNotifications(t *testing.T) {
+	ana := &domain.User{ID: 4, Name: "Ana"}
+	bob := &domain.User{ID: 5, Name: "Bob"}
+
+	tests := []struct {
+		name       string
+		stored     []*domain.Notification
+		setup      func(userRepository *mock.MockUserRepository)
+		wantActors [][]*domain.User
+		wantErr    error
+	}{
+		{
+			name: "grouped notifications get a sample of actors",
+			stored: []*domain.Notification{
+				{ID: "n-1", Type: domain.NotificationTypeReaction, ActorIDs: []int{5, 4, 9}, ActorCount: 10},
+				{ID: "n-2", Type: domain.NotificationTypeComment, ActorIDs: []int{4}, ActorCount: 1},
+			},
+			setup: func(userRepository *mock.MockUserRepository) {
+				userRepository.EXPECT().Search(gomock.Any(), gomock.Any()).Return([]*domain.User{ana, bob}, nil)
+			},
+			wantActors: [][]*domain.User{{bob, ana}, {ana}},
+		},
+		{
+			name:       "notifications without actors skip the lookup",
+			stored:     []*domain.Notification{{ID: "n-1", Type: domain.NotificationTypeSpaceInvitation}},
+			setup:      func(userRepository *mock.MockUserRepository) {},
+			wantActors: [][]*domain.User{nil},
+		},
+		{
+			name:   "error loading actors",
+			stored: []*domain.Notification{{ID: "n-1", ActorIDs: []int{4}, ActorCount: 1}},
+			setup: func(userRepository *mock.MockUserRepository) {
+				userRepository.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+			},
+			wantErr: errors.New("db down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notificationRepo := mock.NewMockNotificationRepository(ctrl)
+			userRepository := mock.NewMockUserRepository(ctrl)
+			usecase := NewNotificationUsecase(notificationRepo, mock.NewMockNotificationPreferenceRepository(ctrl), userRepository, nil)
+
+			notificationRepo.EXPECT().GetUserNotifications(gomock.Any(), 1, 10, 0).Return(tt.stored, nil)
+			tt.setup(userRepository)
+
+			notifications, err := usecase.GetUserNotifications(context.Background(), 1, 10, 0)
+
+			assert.Equal(t, tt.wantErr, err)
+			for i, want := range tt.wantActors {
+				if want == nil {
+					assert.Empty(t, notifications[i].Actors)
+					continue
+				}
+				assert.Equal(t, want, notifications[i].Actors)
+			}
+		})
+	}
+}
+
+func TestUpdatePreferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  dto.UpdateNotificationPreferencesDTO
+		setup   func(preferenceRepo *mock.MockNotificationPreferenceRepository)
+		wantErr bool
+	}{
+		{
+			name: "saves the types and quiet hours",
+			params: dto.UpdateNotificationPreferencesDTO{
+				Types:      map[string]dto.NotificationChannelsDTO{"reaction": {InApp: true}},
+				QuietHours: &dto.QuietHoursDTO{Start: "22:30", End: "07:00"},
+			},
+			setup: func(preferenceRepo *mock.MockNotificationPreferenceRepository) {
+				preferenceRepo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, preferences *domain.NotificationPreferences) error {
+					assert.Equal(t, domain.NotificationChannels{InApp: true}, preferences.Channels(domain.NotificationTypeReaction))
+					assert.Equal(t, &domain.QuietHours{Start: 22*60 + 30, End: 7 * 60, Timezone: domain.DefaultQuietHoursTimezone}, preferences.QuietHours)
+					return nil
+				})
+				preferenceRepo.EXPECT().Find(gomock.Any(), 1).Return(nil, nil)
+			},
+		},
+		{
+			name:    "unknown type",
+			params:  dto.UpdateNotificationPreferencesDTO{Types: map[string]dto.NotificationChannelsDTO{"poke": {InApp: true}}},
+			setup:   func(preferenceRepo *mock.MockNotificationPreferenceRepository) {},
+			wantErr: true,
+		},
+		{
+			name:    "invalid quiet hours",
+			params:  dto.UpdateNotificationPreferencesDTO{QuietHours: &dto.QuietHoursDTO{Start: "25:00", End: "07:00"}},
+			setup:   func(preferenceRepo *mock.MockNotificationPreferenceRepository) {},
+			wantErr: true,
+		},
+		{
+			name:    "invalid timezone",
+			params:  dto.UpdateNotificationPreferencesDTO{QuietHours: &dto.QuietHoursDTO{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"}},
+			setup:   func(preferenceRepo *mock.MockNotificationPreferenceRepository) {},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			preferenceRepo := mock.NewMockNotificationPreferenceRepository(ctrl)
+			usecase := NewNotificationUsecase(mock.NewMockNotificationRepository(ctrl), preferenceRepo, mock.NewMockUserRepository(ctrl), nil)
+			tt.setup(preferenceRepo)
+
+			_, err := usecase.UpdatePreferences(context.Background(), 1, tt.params)
+
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestUnmute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	preferenceRepo := mock.NewMockNotificationPreferenceRepository(ctrl)
+	usecase := NewNotificationUsecase(mock.NewMockNotificationRepository(ctrl), preferenceRepo, mock.NewMockUserRepository(ctrl), nil)
+
+	preferenceRepo.EXPECT().Unmute(gomock.Any(), 1, domain.EntityTypeSpace, 3).Return(false, nil)
+
+	err := usecase.Unmute(context.Background(), 1, "space", 3)
+	assert.Error(t, err)
+
+	err = usecase.Unmute(context.Background(), 1, "comment", 3)
+	assert.Error(t, err)
+}
+
+func TestRetractNotification(t *testing.T) {
+	ana := &domain.User{ID: 5, Name: "Ana"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := mock.NewMockNotificationRepository(ctrl)
+	userRepository := mock.NewMockUserRepository(ctrl)
+	manager := &stubNotificationManager{}
+	usecase := NewNotificationUsecase(notificationRepo, mock.NewMockNotificationPreferenceRepository(ctrl), userRepository, NewNotificationDispatcher(nil, manager, DefaultDispatcherConfig))
+
+	notificationRepo.EXPECT().RetractActor(gomock.Any(), gomock.Any(), 4).DoAndReturn(func(_ context.Context, target *domain.Notification, _ int) ([]*domain.NotificationRetraction, error) {
+		assert.Equal(t, 1, target.UserID)
+		assert.Equal(t, "reaction:like:post:20", target.GroupKey())
+		return []*domain.NotificationRetraction{
+			{Notification: &domain.Notification{ID: "n-1", UserID: 1, ActorIDs: []int{5}, ActorCount: 1}},
+			{Notification: &domain.Notification{ID: "n-2", UserID: 1}, Deleted: true},
+		}, nil
+	})
+	notificationRepo.EXPECT().GetUnreadCount(gomock.Any(), 1).Return(2, nil)
+	notificationRepo.EXPECT().GetUnreadCount(gomock.Any(), 1).Return(1, nil)
+	userRepository.EXPECT().Search(gomock.Any(), gomock.Any()).Return([]*domain.User{ana}, nil)
+
+	err := usecase.RetractNotification(context.Background(), dto.RetractNotificationParams{
+		NotificationType: domain.NotificationTypeReaction,
+		EntityType:       domain.EntityTypePost,
+		EntityID:         20,
+		Action:           domain.ActionTypeLike,
+		OwnerUserID:      1,
+		ActorUserID:      4,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, manager.retractions, 2)
+	assert.Equal(t, []*domain.User{ana}, manager.retractions[0].Notification.Actors)
+	assert.Equal(t, 2, manager.retractions[0].UnreadCount)
+	assert.True(t, manager.retractions[1].Deleted)
+	assert.Equal(t, 1, manager.retractions[1].UnreadCount)
+
+	t.Run("ungrouped notifications cannot be retracted", func(t *testing.T) {
+		err := usecase.RetractNotification(context.Background(), dto.RetractNotificationParams{
+			NotificationType: domain.NotificationTypeMention,
+			EntityType:       domain.EntityTypePost,
+			EntityID:         20,
+			ActorUserID:      4,
+		})
+		assert.Error(t, err)
+	})
+}
@@ -5,10 +5,14 @@ import (
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/domain/criteria"
 	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/authorization"
+	"cpi-hub-api/internal/core/usecase/notification"
 	pghelpers "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/helpers"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
+	"log"
 	"strings"
+	"time"
 )
 
 type SearchResult struct {
@@ -18,12 +22,13 @@ type SearchResult struct {
 
 type PostUseCase interface {
 	Create(ctx context.Context, post *domain.Post) (*domain.ExtendedPost, error)
-	Get(ctx context.Context, id int) (*domain.ExtendedPost, error)
+	Get(ctx context.Context, viewerID int, id int) (*domain.ExtendedPost, error)
 	Search(ctx context.Context, params dto.SearchPostsParams) (*SearchResult, error)
 	GetInterestedPosts(ctx context.Context, params dto.InterestedPostsParams) (*SearchResult, error)
 	AddComment(ctx context.Context, commentDTO dto.CreateComment) (*domain.CommentWithInfo, error)
-	Update(ctx context.Context, updatePostDTO *dto.UpdatePost) error
-	Delete(ctx context.Context, postID int) error
+	Update(ctx context.Context, userID int, updatePostDTO *dto.UpdatePost) error
+	Delete(ctx context.Context, userID int, postID int) error
+	Pin(ctx context.Context, userID int, postID int, pinned bool) error
 }
 
 type postUseCase struct {
@@ -32,6 +37,11 @@ type postUseCase struct {
 	userRepository      domain.UserRepository
 	commentRepository   domain.CommentRepository
 	userSpaceRepository domain.UserSpaceRepository
+	notificationUsecase notification.NotificationUsecase
+	spaceAuthorizer     authorization.SpaceAuthorizer
+	membershipPolicy    authorization.MembershipPolicy
+	// async runs work that must not hold the request, like notifying a whole space
+	async func(task func())
 }
 
 func NewPostUsecase(
@@ -40,6 +50,7 @@ func NewPostUsecase(
 	userRepo domain.UserRepository,
 	commentRepo domain.CommentRepository,
 	userSpaceRepo domain.UserSpaceRepository,
+	notificationUsecase notification.NotificationUsecase,
 ) PostUseCase {
 	return &postUseCase{
 		postRepository:      postRepo,
@@ -47,6 +58,10 @@ func NewPostUsecase(
 		userRepository:      userRepo,
 		commentRepository:   commentRepo,
 		userSpaceRepository: userSpaceRepo,
+		notificationUsecase: notificationUsecase,
+		spaceAuthorizer:     authorization.NewSpaceAuthorizer(userSpaceRepo),
+		membershipPolicy:    authorization.NewMembershipPolicy(spaceRepo, userSpaceRepo),
+		async:               func(task func()) { go task() },
 	}
 }
 
@@ -126,6 +141,14 @@ func (p *postUseCase) Create(ctx context.Context, post *domain.Post) (*domain.Ex
 		return nil, err
 	}
 
+	if err := authorization.RequireWritable(existingSpace); err != nil {
+		return nil, err
+	}
+
+	if err := p.membershipPolicy.RequireMember(ctx, existingSpace.ID, existingUser.ID); err != nil {
+		return nil, err
+	}
+
 	post.CreatedAt, post.UpdatedAt = helpers.GetTime(), helpers.GetTime()
 	post.UpdatedBy = post.CreatedBy
 
@@ -133,12 +156,14 @@ func (p *postUseCase) Create(ctx context.Context, post *domain.Post) (*domain.Ex
 		return nil, err
 	}
 
-	existingSpace.UpdatedAt = helpers.GetTime()
-	existingSpace.UpdatedBy = post.CreatedBy
-	if err := p.spaceRepository.Update(ctx, existingSpace); err != nil {
+	if err := p.spaceRepository.Touch(ctx, existingSpace.ID, post.CreatedBy, helpers.GetTime()); err != nil {
 		return nil, err
 	}
 
+	// The request may be done before the members are notified
+	notifyCtx := context.WithoutCancel(ctx)
+	p.async(func() { p.notifyNewPost(notifyCtx, post) })
+
 	return &domain.ExtendedPost{
 		Post:     post,
 		Space:    existingSpace,
@@ -147,11 +172,21 @@ func (p *postUseCase) Create(ctx context.Context, post *domain.Post) (*domain.Ex
 	}, nil
 }
 
-func (p *postUseCase) Get(ctx context.Context, id int) (*domain.ExtendedPost, error) {
+func (p *postUseCase) Get(ctx context.Context, viewerID int, id int) (*domain.ExtendedPost, error) {
 	post, err := pghelpers.FindEntity(ctx, p.postRepository, "id", id, "Post not found")
 	if err != nil {
 		return nil, err
 	}
+
+	space, err := pghelpers.FindEntity(ctx, p.spaceRepository, "id", post.SpaceID, "Space not found")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.membershipPolicy.RequireView(ctx, space, viewerID); err != nil {
+		return nil, err
+	}
+
 	extendedPosts, err := p.buildExtendedPosts(ctx, []*domain.Post{post})
 	if err != nil {
 		return nil, err
@@ -167,10 +202,29 @@ func (p *postUseCase) AddComment(ctx context.Context, commentDTO dto.CreateComme
 		return nil, err
 	}
 
+	post, err := pghelpers.FindEntity(ctx, p.postRepository, "id", comment.PostID, "Post not found")
+	if err != nil {
+		return nil, err
+	}
+
+	space, err := pghelpers.FindEntity(ctx, p.spaceRepository, "id", post.SpaceID, "Space not found")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorization.RequireWritable(space); err != nil {
+		return nil, err
+	}
+
+	if err := p.membershipPolicy.RequireMember(ctx, space.ID, user.ID); err != nil {
+		return nil, err
+	}
+
 	comment.CreatedAt, comment.UpdatedAt = helpers.GetTime(), helpers.GetTime()
 
+	var parentComment *domain.CommentWithInfo
 	if comment.ParentID != nil && *comment.ParentID > 0 {
-		parentComment, err := pghelpers.FindEntity(ctx, p.commentRepository, "id", *comment.ParentID, "Parent comment not found")
+		parentComment, err = pghelpers.FindEntity(ctx, p.commentRepository, "id", *comment.ParentID, "Parent comment not found")
 		if err != nil {
 			return nil, err
 		}
@@ -183,26 +237,20 @@ func (p *postUseCase) AddComment(ctx context.Context, commentDTO dto.CreateComme
 		return nil, err
 	}
 
-	post, err := pghelpers.FindEntity(ctx, p.postRepository, "id", comment.PostID, "Post not found")
-	if err != nil {
-		return nil, err
-	}
 	post.UpdatedAt = helpers.GetTime()
 	post.UpdatedBy = comment.CreatedBy
-	if err := p.postRepository.Update(ctx, post); err != nil {
+	if err := p.postRepository.Touch(ctx, post.ID, post.UpdatedBy, post.UpdatedAt); err != nil {
 		return nil, err
 	}
 
-	space, err := pghelpers.FindEntity(ctx, p.spaceRepository, "id", post.SpaceID, "Space not found")
-	if err != nil {
-		return nil, err
-	}
 	space.UpdatedAt = helpers.GetTime()
 	space.UpdatedBy = comment.CreatedBy
-	if err := p.spaceRepository.Update(ctx, space); err != nil {
+	if err := p.spaceRepository.Touch(ctx, space.ID, space.UpdatedBy, space.UpdatedAt); err != nil {
 		return nil, err
 	}
 
+	p.notifyComment(ctx, post, comment, parentComment)
+
 	return &domain.CommentWithInfo{
 		Comment: comment,
 		User:    user,
@@ -240,22 +288,27 @@ func (p *postUseCase) Search(ctx context.Context, params dto.SearchPostsParams)
 		logicalOp = criteria.LogicalOperatorAnd
 	}
 
-	searchCriteria := criteria.NewCriteriaBuilder().
+	hiddenSpaceIDs, err := p.membershipPolicy.HiddenSpaceIDs(ctx, params.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	searchCriteria := authorization.ExcludeSpaces(criteria.NewCriteriaBuilder().
 		WithFilterAndCondition("title", searchQuery, criteria.OperatorILike, len(params.Query) > 0).
 		WithFilterAndCondition("content", searchQuery, criteria.OperatorILike, len(params.Query) > 0).
 		WithFilterAndCondition("space_id", spaceID, criteria.OperatorEqual, spaceID > 0).
 		WithFilterAndCondition("created_by", userID, criteria.OperatorEqual, userID > 0).
 		WithLogicalOperator(logicalOp).
 		WithPagination(params.Page, params.PageSize).
-		WithSort(params.OrderBy, sortDirection).
+		WithSort(params.OrderBy, sortDirection), "space_id", hiddenSpaceIDs).
 		Build()
 
-	countCriteria := criteria.NewCriteriaBuilder().
+	countCriteria := authorization.ExcludeSpaces(criteria.NewCriteriaBuilder().
 		WithFilterAndCondition("title", searchQuery, criteria.OperatorILike, len(params.Query) > 0).
 		WithFilterAndCondition("content", searchQuery, criteria.OperatorILike, len(params.Query) > 0).
 		WithFilterAndCondition("space_id", spaceID, criteria.OperatorEqual, spaceID > 0).
 		WithFilterAndCondition("created_by", userID, criteria.OperatorEqual, userID > 0).
-		WithLogicalOperator(logicalOp).
+		WithLogicalOperator(logicalOp), "space_id", hiddenSpaceIDs).
 		Build()
 
 	total, err := p.postRepository.Count(ctx, countCriteria)
@@ -290,14 +343,19 @@ func (p *postUseCase) GetInterestedPosts(ctx context.Context, params dto.Interes
 		return nil, err
 	}
 
-	searchCriteria := criteria.NewCriteriaBuilder().
+	hiddenSpaceIDs, err := p.membershipPolicy.HiddenSpaceIDs(ctx, params.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	searchCriteria := authorization.ExcludeSpaces(criteria.NewCriteriaBuilder().
 		WithFilter("space_id", spaceIDs, criteria.OperatorIn).
 		WithPagination(params.Page, params.PageSize).
-		WithSort(params.OrderBy, sortDirection).
+		WithSort(params.OrderBy, sortDirection), "space_id", hiddenSpaceIDs).
 		Build()
 
-	countCriteria := criteria.NewCriteriaBuilder().
-		WithFilter("space_id", spaceIDs, criteria.OperatorIn).
+	countCriteria := authorization.ExcludeSpaces(criteria.NewCriteriaBuilder().
+		WithFilter("space_id", spaceIDs, criteria.OperatorIn), "space_id", hiddenSpaceIDs).
 		Build()
 
 	total, err := p.postRepository.Count(ctx, countCriteria)
@@ -321,12 +379,20 @@ func (p *postUseCase) GetInterestedPosts(ctx context.Context, params dto.Interes
 	}, nil
 }
 
-func (p *postUseCase) Update(ctx context.Context, updatePostDTO *dto.UpdatePost) error {
+func (p *postUseCase) Update(ctx context.Context, userID int, updatePostDTO *dto.UpdatePost) error {
 	existingPost, err := pghelpers.FindEntity(ctx, p.postRepository, "id", updatePostDTO.PostID, "Post not found")
 	if err != nil {
 		return err
 	}
 
+	if _, err := p.findWritableSpace(ctx, existingPost); err != nil {
+		return err
+	}
+
+	if existingPost.CreatedBy != userID {
+		return apperror.NewForbidden("You can only edit your own posts", nil, "post_usecase.go:Update")
+	}
+
 	if updatePostDTO.Title != "" {
 		existingPost.Title = updatePostDTO.Title
 	}
@@ -334,6 +400,7 @@ func (p *postUseCase) Update(ctx context.Context, updatePostDTO *dto.UpdatePost)
 		existingPost.Content = updatePostDTO.Content
 	}
 	existingPost.UpdatedAt = helpers.GetTime()
+	existingPost.UpdatedBy = userID
 
 	if err := p.postRepository.Update(ctx, existingPost); err != nil {
 		return err
@@ -342,19 +409,25 @@ func (p *postUseCase) Update(ctx context.Context, updatePostDTO *dto.UpdatePost)
 	return nil
 }
 
-func (p *postUseCase) Delete(ctx context.Context, postID int) error {
+func (p *postUseCase) Delete(ctx context.Context, userID int, postID int) error {
 	existingPost, err := pghelpers.FindEntity(ctx, p.postRepository, "id", postID, "Post not found")
 	if err != nil {
 		return err
 	}
-	if existingPost == nil {
-		return apperror.NewNotFound("post not found", nil, "post_usecase.go:Delete")
-	}
-	extendedPosts, err := p.buildExtendedPosts(ctx, []*domain.Post{existingPost})
-	if err != nil {
+
+	if _, err := p.findWritableSpace(ctx, existingPost); err != nil {
 		return err
 	}
-	existingPost = extendedPosts[0].Post
+
+	if existingPost.CreatedBy != userID {
+		canModerate, err := p.spaceAuthorizer.CanModerate(ctx, existingPost.SpaceID, userID)
+		if err != nil {
+			return err
+		}
+		if !canModerate {
+			return apperror.NewForbidden("You can only delete your own posts", nil, "post_usecase.go:Delete")
+		}
+	}
 
 	if err := p.postRepository.Delete(ctx, existingPost.ID); err != nil {
 		return err
@@ -362,3 +435,139 @@ func (p *postUseCase) Delete(ctx context.Context, postID int) error {
 
 	return nil
 }
+
+// Pin pins or unpins a post, only owners and moderators of the space can do it
+func (p *postUseCase) Pin(ctx context.Context, userID int, postID int, pinned bool) error {
+	existingPost, err := pghelpers.FindEntity(ctx, p.postRepository, "id", postID, "Post not found")
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.findWritableSpace(ctx, existingPost); err != nil {
+		return err
+	}
+
+	canModerate, err := p.spaceAuthorizer.CanModerate(ctx, existingPost.SpaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !canModerate {
+		return apperror.NewForbidden("Only owners and moderators can pin posts", nil, "post_usecase.go:Pin")
+	}
+
+	var pinnedAt *time.Time
+	if pinned {
+		now := helpers.GetTime()
+		pinnedAt = &now
+	}
+
+	return p.postRepository.SetPinned(ctx, existingPost.ID, pinnedAt)
+}
+
+// findWritableSpace loads the space of a post and fails when it is archived
+func (p *postUseCase) findWritableSpace(ctx context.Context, post *domain.Post) (*domain.Space, error) {
+	space, err := pghelpers.FindEntity(ctx, p.spaceRepository, "id", post.SpaceID, "Space not found")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorization.RequireWritable(space); err != nil {
+		return nil, err
+	}
+
+	return space, nil
+}
+
+// notifyNewPost tells every member of the space about the post. Mentioned members
+// get a mention instead, nobody is notified twice. The members are notified in one fan-out.
+func (p *postUseCase) notifyNewPost(ctx context.Context, post *domain.Post) {
+	notified := map[int]bool{post.CreatedBy: true}
+	p.notifyMentions(ctx, post.SpaceID, domain.EntityTypePost, post.ID, &post.ID, post.CreatedBy, notified, post.Title, post.Content)
+
+	memberIDs, err := p.userSpaceRepository.FindUserIDsBySpaceID(ctx, post.SpaceID)
+	if err != nil {
+		log.Printf("Error finding members of space %d: %v", post.SpaceID, err)
+		return
+	}
+
+	recipientIDs := make([]int, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if notified[memberID] {
+			continue
+		}
+		notified[memberID] = true
+		recipientIDs = append(recipientIDs, memberID)
+	}
+
+	if len(recipientIDs) == 0 {
+		return
+	}
+
+	err = p.notificationUsecase.FanOutNotification(ctx, dto.FanOutNotificationParams{
+		NotificationType: domain.NotificationTypeNewPost,
+		EntityType:       domain.EntityTypePost,
+		EntityID:         post.ID,
+		PostID:           &post.ID,
+		OwnerUserIDs:     recipientIDs,
+		ActorUserID:      post.CreatedBy,
+		SpaceID:          post.SpaceID,
+	})
+	if err != nil {
+		log.Printf("Error notifying the members of space %d about post %d: %v", post.SpaceID, post.ID, err)
+	}
+}
+
+// notifyComment tells the author of the replied comment, then the author of the post,
+// then the mentioned members. Each user gets the most specific notification only.
+func (p *postUseCase) notifyComment(ctx context.Context, post *domain.Post, comment *domain.Comment, parentComment *domain.CommentWithInfo) {
+	notified := map[int]bool{comment.CreatedBy: true}
+
+	if parentComment != nil && !notified[parentComment.Comment.CreatedBy] {
+		notified[parentComment.Comment.CreatedBy] = true
+		p.notify(ctx, domain.NotificationTypeReply, domain.EntityTypeComment, comment.ID, &post.ID, post.SpaceID, comment.CreatedBy, parentComment.Comment.CreatedBy)
+	}
+
+	if !notified[post.CreatedBy] {
+		notified[post.CreatedBy] = true
+		p.notify(ctx, domain.NotificationTypeComment, domain.EntityTypeComment, comment.ID, &post.ID, post.SpaceID, comment.CreatedBy, post.CreatedBy)
+	}
+
+	p.notifyMentions(ctx, post.SpaceID, domain.EntityTypeComment, comment.ID, &post.ID, comment.CreatedBy, notified, comment.Content)
+}
+
+// notifyMentions only notifies members of the space, a mention cannot reach outsiders
+func (p *postUseCase) notifyMentions(ctx context.Context, spaceID int, entityType domain.EntityType, entityID int, postID *int, actorID int, notified map[int]bool, texts ...string) {
+	for _, userID := range domain.ParseMentions(texts...) {
+		if notified[userID] {
+			continue
+		}
+
+		isMember, err := p.userSpaceRepository.Exists(ctx, userID, spaceID)
+		if err != nil {
+			log.Printf("Error checking membership of user %d in space %d: %v", userID, spaceID, err)
+			continue
+		}
+		if !isMember {
+			continue
+		}
+
+		notified[userID] = true
+		p.notify(ctx, domain.NotificationTypeMention, entityType, entityID, postID, spaceID, actorID, userID)
+	}
+}
+
+// notify is best effort, the post or comment is already saved
+func (p *postUseCase) notify(ctx context.Context, notificationType domain.NotificationType, entityType domain.EntityType, entityID int, postID *int, spaceID int, actorID int, userID int) {
+	err := p.notificationUsecase.CreateNotification(ctx, dto.CreateNotificationParams{
+		NotificationType: notificationType,
+		EntityType:       entityType,
+		EntityID:         entityID,
+		PostID:           postID,
+		OwnerUserID:      userID,
+		ActorUserID:      actorID,
+		SpaceID:          spaceID,
+	})
+	if err != nil {
+		log.Printf("Error creating %s notification for user %d: %v", notificationType, userID, err)
+	}
+}
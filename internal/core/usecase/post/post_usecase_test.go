@@ -0,0 +1,814 @@
+package post
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/criteria"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
+	mocks "cpi-hub-api/internal/core/usecase/notification/mock"
+	"cpi-hub-api/pkg/apperror"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// postNotification builds the params for a post in space 1, where every test post lives
+func postNotification(notificationType domain.NotificationType, entityType domain.EntityType, entityID int, postID int, actorID int, userID int) dto.CreateNotificationParams {
+	return dto.CreateNotificationParams{
+		NotificationType: notificationType,
+		EntityType:       entityType,
+		EntityID:         entityID,
+		PostID:           &postID,
+		OwnerUserID:      userID,
+		ActorUserID:      actorID,
+		SpaceID:          1,
+	}
+}
+
+// runNow makes the usecase notify before returning, so tests can expect the calls
+func runNow(usecase PostUseCase) PostUseCase {
+	usecase.(*postUseCase).async = func(task func()) { task() }
+	return usecase
+}
+
+func TestCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+	postUseCase := runNow(NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase))
+
+	type args struct {
+		context context.Context
+		post    *domain.Post
+	}
+
+	type want struct {
+		err error
+	}
+
+	givenUser := &domain.User{ID: 1, Name: "Test User"}
+	givenSpace := &domain.Space{ID: 1, Name: "Test Space", CreatedBy: 2}
+
+	tests := []struct {
+		name  string
+		args  args
+		want  want
+		calls []*gomock.Call
+	}{
+		{
+			name: "success",
+			args: args{
+				context: context.Background(),
+				post:    &domain.Post{Title: "Test Post", Content: "Hi @[Ana](3)", CreatedBy: 1, SpaceID: 1},
+			},
+			want: want{},
+			calls: []*gomock.Call{
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 1, 1).Return(true, nil),
+				mockPostRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil),
+				mockSpaceRepository.EXPECT().Touch(gomock.Any(), 1, 1, gomock.Any()).Return(nil),
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 3, 1).Return(true, nil),
+				mockNotificationUsecase.EXPECT().CreateNotification(gomock.Any(), postNotification(domain.NotificationTypeMention, domain.EntityTypePost, 0, 0, 1, 3)).Return(nil),
+				mockUserSpaceRepository.EXPECT().FindUserIDsBySpaceID(gomock.Any(), 1).Return([]int{1, 2, 3}, nil),
+				mockNotificationUsecase.EXPECT().FanOutNotification(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, params dto.FanOutNotificationParams) error {
+					assert.Equal(t, postNotification(domain.NotificationTypeNewPost, domain.EntityTypePost, 0, 0, 1, 2), params.For(2))
+					assert.Equal(t, []int{2}, params.OwnerUserIDs)
+					return nil
+				}),
+			},
+		},
+		{
+			name: "error user is not a member of the space",
+			args: args{
+				context: context.Background(),
+				post:    &domain.Post{Title: "Test Post", Content: "Test Content", CreatedBy: 1, SpaceID: 1},
+			},
+			want: want{
+				err: apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireMember"),
+			},
+			calls: []*gomock.Call{
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 1, 1).Return(false, nil),
+			},
+		},
+		{
+			name: "error checking membership",
+			args: args{
+				context: context.Background(),
+				post:    &domain.Post{Title: "Test Post", Content: "Test Content", CreatedBy: 1, SpaceID: 1},
+			},
+			want: want{
+				err: errors.New("unexpected error"),
+			},
+			calls: []*gomock.Call{
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 1, 1).Return(false, errors.New("unexpected error")),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		calls := make([]interface{}, len(test.calls))
+		for i, c := range test.calls {
+			calls[i] = c
+		}
+
+		gomock.InOrder(calls...)
+
+		_, gotErr := postUseCase.Create(test.args.context, test.args.post)
+
+		assert.Equal(t, test.want.err, gotErr)
+	}
+}
+
+func TestCreateNotifiesAfterReturning(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+	usecase := NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase)
+
+	var pending []func()
+	usecase.(*postUseCase).async = func(task func()) { pending = append(pending, task) }
+
+	mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1}, nil)
+	mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 1}, nil)
+	mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 1, 1).Return(true, nil)
+	mockPostRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	mockSpaceRepository.EXPECT().Touch(gomock.Any(), 1, 1, gomock.Any()).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := usecase.Create(ctx, &domain.Post{Title: "Test Post", Content: "Test Content", CreatedBy: 1, SpaceID: 1})
+	cancel()
+
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	// The fan-out still runs once the request context is canceled
+	mockUserSpaceRepository.EXPECT().FindUserIDsBySpaceID(gomock.Any(), 1).DoAndReturn(func(ctx context.Context, _ int) ([]int, error) {
+		assert.NoError(t, ctx.Err())
+		return []int{1, 2, 3}, nil
+	})
+	mockNotificationUsecase.EXPECT().FanOutNotification(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, params dto.FanOutNotificationParams) error {
+		assert.Equal(t, []int{2, 3}, params.OwnerUserIDs)
+		return nil
+	})
+
+	pending[0]()
+}
+
+func TestAddComment(t *testing.T) {
+	post := &domain.Post{ID: 20, CreatedBy: 2, SpaceID: 1}
+	space := &domain.Space{ID: 1}
+	rootComment := &domain.CommentWithInfo{Comment: &domain.Comment{ID: 30, PostID: 20, CreatedBy: 3}}
+	parentID := 30
+
+	tests := []struct {
+		name    string
+		comment dto.CreateComment
+		setup   func(m *mock.MockUserSpaceRepository, n *mocks.MockNotificationUsecase) []*gomock.Call
+	}{
+		{
+			name:    "comment notifies the post author",
+			comment: dto.CreateComment{PostID: 20, Content: "nice", CreatedBy: 1},
+			setup: func(m *mock.MockUserSpaceRepository, n *mocks.MockNotificationUsecase) []*gomock.Call {
+				return []*gomock.Call{
+					n.EXPECT().CreateNotification(gomock.Any(), postNotification(domain.NotificationTypeComment, domain.EntityTypeComment, 40, 20, 1, 2)).Return(nil),
+				}
+			},
+		},
+		{
+			name:    "reply notifies the parent author and the post author",
+			comment: dto.CreateComment{PostID: 20, Content: "agree", CreatedBy: 1, ParentCommentID: &parentID},
+			setup: func(m *mock.MockUserSpaceRepository, n *mocks.MockNotificationUsecase) []*gomock.Call {
+				return []*gomock.Call{
+					n.EXPECT().CreateNotification(gomock.Any(), postNotification(domain.NotificationTypeReply, domain.EntityTypeComment, 40, 20, 1, 3)).Return(nil),
+					n.EXPECT().CreateNotification(gomock.Any(), postNotification(domain.NotificationTypeComment, domain.EntityTypeComment, 40, 20, 1, 2)).Return(nil),
+				}
+			},
+		},
+		{
+			name:    "mentions skip notified users and outsiders",
+			comment: dto.CreateComment{PostID: 20, Content: "@[Bob](2) @[Eve](5) @[Ana](6)", CreatedBy: 1},
+			setup: func(m *mock.MockUserSpaceRepository, n *mocks.MockNotificationUsecase) []*gomock.Call {
+				return []*gomock.Call{
+					n.EXPECT().CreateNotification(gomock.Any(), postNotification(domain.NotificationTypeComment, domain.EntityTypeComment, 40, 20, 1, 2)).Return(nil),
+					m.EXPECT().Exists(gomock.Any(), 5, 1).Return(false, nil),
+					m.EXPECT().Exists(gomock.Any(), 6, 1).Return(true, nil),
+					n.EXPECT().CreateNotification(gomock.Any(), postNotification(domain.NotificationTypeMention, domain.EntityTypeComment, 40, 20, 1, 6)).Return(nil),
+				}
+			},
+		},
+		{
+			name:    "post author commenting is not notified",
+			comment: dto.CreateComment{PostID: 20, Content: "thanks", CreatedBy: 2},
+			setup: func(m *mock.MockUserSpaceRepository, n *mocks.MockNotificationUsecase) []*gomock.Call {
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostRepository := mock.NewMockPostRepository(ctrl)
+			mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+			mockUserRepository := mock.NewMockUserRepository(ctrl)
+			mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+			mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+			mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+			postUseCase := NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase)
+
+			mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: tt.comment.CreatedBy}, nil)
+			mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(post, nil)
+			mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(space, nil)
+			mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), tt.comment.CreatedBy, 1).Return(true, nil)
+			if tt.comment.ParentCommentID != nil {
+				mockCommentRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(rootComment, nil)
+			}
+			mockCommentRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, comment *domain.Comment) error {
+				comment.ID = 40
+				return nil
+			})
+			mockPostRepository.EXPECT().Touch(gomock.Any(), 20, tt.comment.CreatedBy, gomock.Any()).Return(nil)
+			mockSpaceRepository.EXPECT().Touch(gomock.Any(), 1, tt.comment.CreatedBy, gomock.Any()).Return(nil)
+
+			calls := []interface{}{}
+			for _, c := range tt.setup(mockUserSpaceRepository, mockNotificationUsecase) {
+				calls = append(calls, c)
+			}
+			if len(calls) > 1 {
+				gomock.InOrder(calls...)
+			}
+
+			_, err := postUseCase.AddComment(context.Background(), tt.comment)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAddCommentNonMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+	postUseCase := NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase)
+
+	gomock.InOrder(
+		mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 4}, nil),
+		mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Post{ID: 20, CreatedBy: 2, SpaceID: 1}, nil),
+		mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 1}, nil),
+		mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 4, 1).Return(false, nil),
+	)
+
+	_, err := postUseCase.AddComment(context.Background(), dto.CreateComment{PostID: 20, Content: "hi", CreatedBy: 4})
+
+	assert.Equal(t, apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireMember"), err)
+}
+
+func TestGet(t *testing.T) {
+	post := &domain.Post{ID: 20, CreatedBy: 1, SpaceID: 5}
+	privateSpace := &domain.Space{ID: 5, Visibility: domain.SpaceVisibilityPrivate}
+
+	tests := []struct {
+		name     string
+		viewerID int
+		isMember bool
+		wantErr  error
+	}{
+		{
+			name:     "member sees the post of a private space",
+			viewerID: 1,
+			isMember: true,
+		},
+		{
+			name:     "non member cannot see the post of a private space",
+			viewerID: 2,
+			wantErr:  apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireView"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostRepository := mock.NewMockPostRepository(ctrl)
+			mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+			mockUserRepository := mock.NewMockUserRepository(ctrl)
+			mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+			mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+			mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+			postUseCase := NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase)
+
+			mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(post, nil)
+			mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(privateSpace, nil)
+			mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), tt.viewerID, 5).Return(tt.isMember, nil)
+			if tt.wantErr == nil {
+				mockCommentRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.CommentWithInfo{}, nil)
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(privateSpace, nil)
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1}, nil)
+			}
+
+			got, err := postUseCase.Get(context.Background(), tt.viewerID, 20)
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.Equal(t, post, got.Post)
+			}
+		})
+	}
+}
+
+func TestSearchHidesPrivateSpaces(t *testing.T) {
+	privateSpace := &domain.Space{ID: 5, Visibility: domain.SpaceVisibilityPrivate}
+	hiddenFilter := criteria.Filter{Field: "space_id", Value: []interface{}{5}, Operator: criteria.OperatorNotIn}
+
+	tests := []struct {
+		name           string
+		viewerID       int
+		memberSpaceIDs []int
+		wantRequired   []criteria.Filter
+	}{
+		{
+			name:           "non member does not see posts of private spaces",
+			viewerID:       2,
+			memberSpaceIDs: []int{},
+			wantRequired:   []criteria.Filter{hiddenFilter},
+		},
+		{
+			name:           "member sees posts of their private spaces",
+			viewerID:       1,
+			memberSpaceIDs: []int{5},
+			wantRequired:   []criteria.Filter{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostRepository := mock.NewMockPostRepository(ctrl)
+			mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+			mockUserRepository := mock.NewMockUserRepository(ctrl)
+			mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+			mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+			mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+			postUseCase := NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase)
+
+			mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{privateSpace}, nil)
+			mockUserSpaceRepository.EXPECT().FindSpacesIDsByUserID(gomock.Any(), tt.viewerID).Return(tt.memberSpaceIDs, nil)
+			mockPostRepository.EXPECT().Count(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *criteria.Criteria) (int, error) {
+				assert.Equal(t, tt.wantRequired, c.RequiredFilters)
+				return 0, nil
+			})
+			mockPostRepository.EXPECT().Search(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *criteria.Criteria) ([]*domain.Post, error) {
+				assert.Equal(t, tt.wantRequired, c.RequiredFilters)
+				return []*domain.Post{}, nil
+			})
+
+			result, err := postUseCase.Search(context.Background(), dto.SearchPostsParams{Query: "secret", ViewerID: tt.viewerID})
+
+			assert.NoError(t, err)
+			assert.Empty(t, result.Posts)
+		})
+	}
+}
+
+func TestGetInterestedPostsHidesPrivateSpaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+	postUseCase := NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase)
+
+	hiddenFilter := criteria.Filter{Field: "space_id", Value: []interface{}{5}, Operator: criteria.OperatorNotIn}
+
+	mockUserSpaceRepository.EXPECT().FindSpacesIDsByUserID(gomock.Any(), 1).Return([]int{5}, nil)
+	mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{{ID: 5, Visibility: domain.SpaceVisibilityPrivate}}, nil)
+	mockUserSpaceRepository.EXPECT().FindSpacesIDsByUserID(gomock.Any(), 2).Return([]int{}, nil)
+	mockPostRepository.EXPECT().Count(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *criteria.Criteria) (int, error) {
+		assert.Equal(t, []criteria.Filter{hiddenFilter}, c.RequiredFilters)
+		return 0, nil
+	})
+	mockPostRepository.EXPECT().Search(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *criteria.Criteria) ([]*domain.Post, error) {
+		assert.Equal(t, []criteria.Filter{hiddenFilter}, c.RequiredFilters)
+		return []*domain.Post{}, nil
+	})
+
+	result, err := postUseCase.GetInterestedPosts(context.Background(), dto.InterestedPostsParams{UserID: 1, ViewerID: 2})
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Posts)
+	assert.Equal(t, 0, result.Total)
+}
+
+func TestUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+	postUseCase := NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase)
+
+	type args struct {
+		context context.Context
+		userID  int
+		post    *dto.UpdatePost
+	}
+
+	type want struct {
+		err error
+	}
+
+	givenPost := &domain.Post{
+		ID:        1,
+		Title:     "Test Post",
+		Content:   "Test Content",
+		CreatedBy: 1,
+		SpaceID:   1,
+	}
+
+	archivedAt := time.Now()
+	givenSpace := &domain.Space{ID: 1}
+	archivedSpace := &domain.Space{ID: 1, ArchivedAt: &archivedAt}
+
+	tests := []struct {
+		name  string
+		args  args
+		want  want
+		calls []*gomock.Call
+	}{
+		{
+			name: "success",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				post:    &dto.UpdatePost{PostID: 1, Title: "New Title"},
+			},
+			want: want{},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockPostRepository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil),
+			},
+		},
+		{
+			name: "error post not found",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				post:    &dto.UpdatePost{PostID: 1, Title: "New Title"},
+			},
+			want: want{
+				err: apperror.NewNotFound("Post not found", nil, "EntityFinder.go:FindEntity"),
+			},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil),
+			},
+		},
+		{
+			name: "error user is not the author",
+			args: args{
+				context: context.Background(),
+				userID:  2,
+				post:    &dto.UpdatePost{PostID: 1, Title: "New Title"},
+			},
+			want: want{
+				err: apperror.NewForbidden("You can only edit your own posts", nil, "post_usecase.go:Update"),
+			},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+			},
+		},
+		{
+			name: "error updating post",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				post:    &dto.UpdatePost{PostID: 1, Title: "New Title"},
+			},
+			want: want{
+				err: errors.New("unexpected error"),
+			},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockPostRepository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("unexpected error")),
+			},
+		},
+		{
+			name: "error space is archived",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				post:    &dto.UpdatePost{PostID: 1, Title: "New Title"},
+			},
+			want: want{
+				err: apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable"),
+			},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(archivedSpace, nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		calls := make([]interface{}, len(test.calls))
+		for i, c := range test.calls {
+			calls[i] = c
+		}
+
+		gomock.InOrder(calls...)
+
+		gotErr := postUseCase.Update(test.args.context, test.args.userID, test.args.post)
+
+		assert.Equal(t, test.want.err, gotErr)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+	postUseCase := NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase)
+
+	type args struct {
+		context context.Context
+		userID  int
+		postID  int
+	}
+
+	type want struct {
+		err error
+	}
+
+	givenPost := &domain.Post{
+		ID:        1,
+		Title:     "Test Post",
+		CreatedBy: 1,
+		SpaceID:   1,
+	}
+
+	archivedAt := time.Now()
+	givenSpace := &domain.Space{ID: 1}
+	archivedSpace := &domain.Space{ID: 1, ArchivedAt: &archivedAt}
+
+	tests := []struct {
+		name  string
+		args  args
+		want  want
+		calls []*gomock.Call
+	}{
+		{
+			name: "success author deletes own post",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				postID:  1,
+			},
+			want: want{},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockPostRepository.EXPECT().Delete(gomock.Any(), 1).Return(nil),
+			},
+		},
+		{
+			name: "success moderator deletes post",
+			args: args{
+				context: context.Background(),
+				userID:  3,
+				postID:  1,
+			},
+			want: want{},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 3, 1).Return(domain.SpaceRoleModerator, nil),
+				mockPostRepository.EXPECT().Delete(gomock.Any(), 1).Return(nil),
+			},
+		},
+		{
+			name: "error user cannot moderate space",
+			args: args{
+				context: context.Background(),
+				userID:  2,
+				postID:  1,
+			},
+			want: want{
+				err: apperror.NewForbidden("You can only delete your own posts", nil, "post_usecase.go:Delete"),
+			},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 1).Return(domain.SpaceRoleMember, nil),
+			},
+		},
+		{
+			name: "error finding role",
+			args: args{
+				context: context.Background(),
+				userID:  2,
+				postID:  1,
+			},
+			want: want{
+				err: errors.New("unexpected error"),
+			},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 1).Return(domain.SpaceRole(""), errors.New("unexpected error")),
+			},
+		},
+		{
+			name: "error post not found",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				postID:  1,
+			},
+			want: want{
+				err: apperror.NewNotFound("Post not found", nil, "EntityFinder.go:FindEntity"),
+			},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil),
+			},
+		},
+		{
+			name: "error space is archived",
+			args: args{
+				context: context.Background(),
+				userID:  1,
+				postID:  1,
+			},
+			want: want{
+				err: apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable"),
+			},
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(archivedSpace, nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		calls := make([]interface{}, len(test.calls))
+		for i, c := range test.calls {
+			calls[i] = c
+		}
+
+		gomock.InOrder(calls...)
+
+		gotErr := postUseCase.Delete(test.args.context, test.args.userID, test.args.postID)
+
+		assert.Equal(t, test.want.err, gotErr)
+	}
+}
+
+func TestPin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockNotificationUsecase := mocks.NewMockNotificationUsecase(ctrl)
+
+	postUseCase := NewPostUsecase(mockPostRepository, mockSpaceRepository, mockUserRepository, mockCommentRepository, mockUserSpaceRepository, mockNotificationUsecase)
+
+	givenPost := &domain.Post{ID: 1, Title: "Test Post", CreatedBy: 1, SpaceID: 1}
+	archivedAt := time.Now()
+	givenSpace := &domain.Space{ID: 1}
+	archivedSpace := &domain.Space{ID: 1, ArchivedAt: &archivedAt}
+
+	tests := []struct {
+		name   string
+		userID int
+		pinned bool
+		want   error
+		calls  []*gomock.Call
+	}{
+		{
+			name:   "success moderator pins post",
+			userID: 2,
+			pinned: true,
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 1).Return(domain.SpaceRoleModerator, nil),
+				mockPostRepository.EXPECT().SetPinned(gomock.Any(), givenPost.ID, gomock.Not(gomock.Nil())).Return(nil),
+			},
+		},
+		{
+			name:   "success owner unpins post",
+			userID: 3,
+			pinned: false,
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 3, 1).Return(domain.SpaceRoleOwner, nil),
+				mockPostRepository.EXPECT().SetPinned(gomock.Any(), givenPost.ID, gomock.Nil()).Return(nil),
+			},
+		},
+		{
+			name:   "error author without moderation rights",
+			userID: 1,
+			pinned: true,
+			want:   apperror.NewForbidden("Only owners and moderators can pin posts", nil, "post_usecase.go:Pin"),
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 1).Return(domain.SpaceRoleMember, nil),
+			},
+		},
+		{
+			name:   "error pinning in an archived space",
+			userID: 2,
+			pinned: true,
+			want:   apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable"),
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(archivedSpace, nil),
+			},
+		},
+		{
+			name:   "error unpinning in an archived space",
+			userID: 2,
+			pinned: false,
+			want:   apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable"),
+			calls: []*gomock.Call{
+				mockPostRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPost, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(archivedSpace, nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		calls := make([]interface{}, len(test.calls))
+		for i, c := range test.calls {
+			calls[i] = c
+		}
+
+		gomock.InOrder(calls...)
+
+		gotErr := postUseCase.Pin(context.Background(), test.userID, 1, test.pinned)
+
+		assert.Equal(t, test.want, gotErr)
+	}
+}
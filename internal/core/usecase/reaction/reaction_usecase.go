@@ -5,6 +5,7 @@ import (
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/domain/criteria"
 	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/authorization"
 	pghelpers "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/helpers"
 	"cpi-hub-api/pkg/apperror"
 	"log"
@@ -12,7 +13,7 @@ import (
 
 type ReactionUseCase interface {
 	AddReaction(ctx context.Context, reaction *domain.Reaction) (*domain.Reaction, error)
-	RemoveReaction(ctx context.Context, reactionID string) error
+	RemoveReaction(ctx context.Context, userID int, reactionID string) error
 	GetLikesCount(ctx context.Context, getLikesCountDTO dto.GetLikesCountDTO) (*dto.LikesCountDTO, error)
 	GetUserLikes(ctx context.Context, userID int, entitiesData dto.EntitiesDataDTO) ([]dto.UserLikeDTO, error)
 }
@@ -22,11 +23,21 @@ type reactionUsecase struct {
 	userRepo            domain.UserRepository
 	postRepo            domain.PostRepository
 	commentRepo         domain.CommentRepository
+	spaceRepo           domain.SpaceRepository
 	notificationUsecase NotificationUsecase
+	membershipPolicy    authorization.MembershipPolicy
+}
+
+// reactionTarget is what a reaction needs to know about the post or comment it is on
+type reactionTarget struct {
+	ownerUserID int
+	postID      *int
+	space       *domain.Space
 }
 
 type NotificationUsecase interface {
 	CreateNotification(ctx context.Context, params dto.CreateNotificationParams) error
+	RetractNotification(ctx context.Context, params dto.RetractNotificationParams) error
 }
 
 func NewReactionUsecase(
@@ -34,6 +45,8 @@ func NewReactionUsecase(
 	userRepo domain.UserRepository,
 	postRepo domain.PostRepository,
 	commentRepo domain.CommentRepository,
+	spaceRepo domain.SpaceRepository,
+	userSpaceRepo domain.UserSpaceRepository,
 	notificationUsecase NotificationUsecase,
 ) ReactionUseCase {
 	return &reactionUsecase{
@@ -41,7 +54,9 @@ func NewReactionUsecase(
 		userRepo:            userRepo,
 		postRepo:            postRepo,
 		commentRepo:         commentRepo,
+		spaceRepo:           spaceRepo,
 		notificationUsecase: notificationUsecase,
+		membershipPolicy:    authorization.NewMembershipPolicy(spaceRepo, userSpaceRepo),
 	}
 }
 
@@ -59,19 +74,17 @@ func (u *reactionUsecase) AddReaction(ctx context.Context, reaction *domain.Reac
 		return nil, err
 	}
 
-	switch reaction.EntityType {
-	case domain.EntityTypePost:
-		_, err = pghelpers.FindEntity(ctx, u.postRepo, "id", reaction.EntityID, "Post not found")
-		if err != nil {
-			return nil, err
-		}
-	case domain.EntityTypeComment:
-		_, err = pghelpers.FindEntity(ctx, u.commentRepo, "id", reaction.EntityID, "Comment not found")
-		if err != nil {
-			return nil, err
-		}
-	default:
-		return nil, apperror.NewError(apperror.InvalidData, "Invalid entity type", nil, "")
+	target, err := u.findTarget(ctx, reaction.EntityType, reaction.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorization.RequireWritable(target.space); err != nil {
+		return nil, err
+	}
+
+	if err := u.membershipPolicy.RequireMember(ctx, target.space.ID, reaction.UserID); err != nil {
+		return nil, err
 	}
 
 	criteria := &criteria.Criteria{
@@ -93,39 +106,29 @@ func (u *reactionUsecase) AddReaction(ctx context.Context, reaction *domain.Reac
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		err = u.reactionRepo.AddReaction(ctx, reaction)
-		if err != nil {
-			return nil, err
-		}
-	}
 
-	var ownerUserID int
-	var postID *int
-	switch reaction.EntityType {
-	case domain.EntityTypePost:
-		post, err := pghelpers.FindEntity(ctx, u.postRepo, "id", reaction.EntityID, "Post not found")
-		if err != nil {
-			return nil, err
+		// The owner was already notified of this reaction, a flip replaces that notification
+		if existingReaction.Action == reaction.Action {
+			return reaction, nil
 		}
-		ownerUserID = post.CreatedBy
-		postID = &reaction.EntityID
-	case domain.EntityTypeComment:
-		commentWithInfo, err := pghelpers.FindEntity(ctx, u.commentRepo, "id", reaction.EntityID, "Comment not found")
+		u.retractNotification(ctx, target, existingReaction)
+	} else {
+		err = u.reactionRepo.AddReaction(ctx, reaction)
 		if err != nil {
 			return nil, err
 		}
-		ownerUserID = commentWithInfo.Comment.CreatedBy
-		postID = &commentWithInfo.Comment.PostID
 	}
 
-	if ownerUserID != reaction.UserID && u.notificationUsecase != nil {
+	if target.ownerUserID != reaction.UserID && u.notificationUsecase != nil {
 		params := dto.CreateNotificationParams{
 			NotificationType: domain.NotificationTypeReaction,
 			EntityType:       reaction.EntityType,
 			EntityID:         reaction.EntityID,
-			PostID:           postID,
-			OwnerUserID:      ownerUserID,
+			PostID:           target.postID,
+			OwnerUserID:      target.ownerUserID,
+			ActorUserID:      reaction.UserID,
+			SpaceID:          target.space.ID,
+			Action:           reaction.Action,
 		}
 		err = u.notificationUsecase.CreateNotification(ctx, params)
 		if err != nil {
@@ -136,14 +139,92 @@ func (u *reactionUsecase) AddReaction(ctx context.Context, reaction *domain.Reac
 	return reaction, nil
 }
 
-func (u *reactionUsecase) RemoveReaction(ctx context.Context, reactionID string) error {
-	err := u.reactionRepo.DeleteReaction(ctx, reactionID)
+func (u *reactionUsecase) findTarget(ctx context.Context, entityType domain.EntityType, entityID int) (*reactionTarget, error) {
+	var target reactionTarget
+	var spaceID int
+
+	switch entityType {
+	case domain.EntityTypePost:
+		post, err := pghelpers.FindEntity(ctx, u.postRepo, "id", entityID, "Post not found")
+		if err != nil {
+			return nil, err
+		}
+		target.ownerUserID = post.CreatedBy
+		target.postID = &post.ID
+		spaceID = post.SpaceID
+	case domain.EntityTypeComment:
+		commentWithInfo, err := pghelpers.FindEntity(ctx, u.commentRepo, "id", entityID, "Comment not found")
+		if err != nil {
+			return nil, err
+		}
+		target.ownerUserID = commentWithInfo.Comment.CreatedBy
+		target.postID = &commentWithInfo.Comment.PostID
+		spaceID = commentWithInfo.Space.ID
+	default:
+		return nil, apperror.NewError(apperror.InvalidData, "Invalid entity type", nil, "")
+	}
+
+	// the space joined to a comment has no archived_at, so it is always loaded
+	space, err := pghelpers.FindEntity(ctx, u.spaceRepo, "id", spaceID, "Space not found")
+	if err != nil {
+		return nil, err
+	}
+	target.space = space
+
+	return &target, nil
+}
+
+func (u *reactionUsecase) RemoveReaction(ctx context.Context, userID int, reactionID string) error {
+	reaction, err := u.reactionRepo.FindReactionByID(ctx, reactionID)
+	if err != nil {
+		return err
+	}
+	if reaction == nil {
+		return apperror.NewNotFound("Reaction not found", nil, "reaction_usecase.go:RemoveReaction")
+	}
+	if reaction.UserID != userID {
+		return apperror.NewForbidden("You can only remove your own reactions", nil, "reaction_usecase.go:RemoveReaction")
+	}
+
+	target, err := u.findTarget(ctx, reaction.EntityType, reaction.EntityID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorization.RequireWritable(target.space); err != nil {
+		return err
+	}
+
+	err = u.reactionRepo.DeleteReaction(ctx, reactionID)
 	if err != nil {
 		return err
 	}
+
+	u.retractNotification(ctx, target, reaction)
+
 	return nil
 }
 
+// retractNotification takes the reaction out of the owner's notification, a failure
+// leaves the notification behind but does not undo the reaction change
+func (u *reactionUsecase) retractNotification(ctx context.Context, target *reactionTarget, reaction *domain.Reaction) {
+	if u.notificationUsecase == nil {
+		return
+	}
+
+	err := u.notificationUsecase.RetractNotification(ctx, dto.RetractNotificationParams{
+		NotificationType: domain.NotificationTypeReaction,
+		EntityType:       reaction.EntityType,
+		EntityID:         reaction.EntityID,
+		Action:           reaction.Action,
+		OwnerUserID:      target.ownerUserID,
+		ActorUserID:      reaction.UserID,
+	})
+	if err != nil {
+		log.Printf("Error retracting notification: %v", err)
+	}
+}
+
 func (u *reactionUsecase) GetLikesCount(ctx context.Context, getLikesCountDTO dto.GetLikesCountDTO) (*dto.LikesCountDTO, error) {
 
 	buildBaseCriteria := func() *criteria.CriteriaBuilder {
@@ -0,0 +1,206 @@
+package reaction
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
+	mocks "cpi-hub-api/internal/core/usecase/notification/mock"
+	"cpi-hub-api/pkg/apperror"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type reactionMocks struct {
+	reactionRepo        *mock.MockReactionRepository
+	userRepo            *mock.MockUserRepository
+	postRepo            *mock.MockPostRepository
+	spaceRepo           *mock.MockSpaceRepository
+	userSpaceRepo       *mock.MockUserSpaceRepository
+	notificationUsecase *mocks.MockNotificationUsecase
+}
+
+func newReactionUsecaseForTest(ctrl *gomock.Controller) (ReactionUseCase, reactionMocks) {
+	m := reactionMocks{
+		reactionRepo:        mock.NewMockReactionRepository(ctrl),
+		userRepo:            mock.NewMockUserRepository(ctrl),
+		postRepo:            mock.NewMockPostRepository(ctrl),
+		spaceRepo:           mock.NewMockSpaceRepository(ctrl),
+		userSpaceRepo:       mock.NewMockUserSpaceRepository(ctrl),
+		notificationUsecase: mocks.NewMockNotificationUsecase(ctrl),
+	}
+	usecase := NewReactionUsecase(m.reactionRepo, m.userRepo, m.postRepo, mock.NewMockCommentRepository(ctrl), m.spaceRepo, m.userSpaceRepo, m.notificationUsecase)
+	return usecase, m
+}
+
+func retractParams(action domain.ActionType) dto.RetractNotificationParams {
+	return dto.RetractNotificationParams{
+		NotificationType: domain.NotificationTypeReaction,
+		EntityType:       domain.EntityTypePost,
+		EntityID:         20,
+		Action:           action,
+		OwnerUserID:      1,
+		ActorUserID:      4,
+	}
+}
+
+func TestAddReaction(t *testing.T) {
+	post := &domain.Post{ID: 20, CreatedBy: 1, SpaceID: 3}
+	postID := 20
+
+	tests := []struct {
+		name     string
+		action   domain.ActionType
+		existing *domain.Reaction
+		setup    func(m reactionMocks)
+	}{
+		{
+			name:   "new reaction notifies the owner",
+			action: domain.ActionTypeLike,
+			setup: func(m reactionMocks) {
+				m.reactionRepo.EXPECT().AddReaction(gomock.Any(), gomock.Any()).Return(nil)
+				m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), dto.CreateNotificationParams{
+					NotificationType: domain.NotificationTypeReaction,
+					EntityType:       domain.EntityTypePost,
+					EntityID:         20,
+					PostID:           &postID,
+					OwnerUserID:      1,
+					ActorUserID:      4,
+					SpaceID:          3,
+					Action:           domain.ActionTypeLike,
+				}).Return(nil)
+			},
+		},
+		{
+			name:     "same reaction again does not notify twice",
+			action:   domain.ActionTypeLike,
+			existing: &domain.Reaction{ID: "r-1", UserID: 4, EntityType: domain.EntityTypePost, EntityID: 20, Action: domain.ActionTypeLike},
+			setup: func(m reactionMocks) {
+				m.reactionRepo.EXPECT().UpdateReaction(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:     "flipped reaction replaces the notification",
+			action:   domain.ActionTypeDislike,
+			existing: &domain.Reaction{ID: "r-1", UserID: 4, EntityType: domain.EntityTypePost, EntityID: 20, Action: domain.ActionTypeLike},
+			setup: func(m reactionMocks) {
+				m.reactionRepo.EXPECT().UpdateReaction(gomock.Any(), gomock.Any()).Return(nil)
+				gomock.InOrder(
+					m.notificationUsecase.EXPECT().RetractNotification(gomock.Any(), retractParams(domain.ActionTypeLike)).Return(nil),
+					m.notificationUsecase.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, params dto.CreateNotificationParams) error {
+						assert.Equal(t, domain.ActionTypeDislike, params.Action)
+						return nil
+					}),
+				)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usecase, m := newReactionUsecaseForTest(ctrl)
+
+			m.userRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 4}, nil)
+			m.postRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(post, nil)
+			m.spaceRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 3}, nil)
+			m.userSpaceRepo.EXPECT().Exists(gomock.Any(), 4, 3).Return(true, nil)
+			m.reactionRepo.EXPECT().FindReaction(gomock.Any(), gomock.Any()).Return(tt.existing, nil)
+			tt.setup(m)
+
+			reaction, err := usecase.AddReaction(context.Background(), &domain.Reaction{
+				UserID:     4,
+				EntityType: domain.EntityTypePost,
+				EntityID:   20,
+				Action:     tt.action,
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.action, reaction.Action)
+		})
+	}
+}
+
+func TestAddReactionNonMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newReactionUsecaseForTest(ctrl)
+
+	m.userRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 4}, nil)
+	m.postRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Post{ID: 20, CreatedBy: 1, SpaceID: 3}, nil)
+	m.spaceRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 3}, nil)
+	m.userSpaceRepo.EXPECT().Exists(gomock.Any(), 4, 3).Return(false, nil)
+
+	_, err := usecase.AddReaction(context.Background(), &domain.Reaction{
+		UserID:     4,
+		EntityType: domain.EntityTypePost,
+		EntityID:   20,
+		Action:     domain.ActionTypeLike,
+	})
+
+	assert.Equal(t, apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireMember"), err)
+}
+
+func TestAddReactionArchivedSpace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newReactionUsecaseForTest(ctrl)
+
+	archivedAt := time.Now()
+	m.userRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 4}, nil)
+	m.postRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Post{ID: 20, CreatedBy: 1, SpaceID: 3}, nil)
+	m.spaceRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 3, ArchivedAt: &archivedAt}, nil)
+
+	_, err := usecase.AddReaction(context.Background(), &domain.Reaction{
+		UserID:     4,
+		EntityType: domain.EntityTypePost,
+		EntityID:   20,
+		Action:     domain.ActionTypeLike,
+	})
+
+	assert.Equal(t, apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable"), err)
+}
+
+func TestRemoveReaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newReactionUsecaseForTest(ctrl)
+
+	m.reactionRepo.EXPECT().FindReactionByID(gomock.Any(), "r-1").Return(&domain.Reaction{
+		ID: "r-1", UserID: 4, EntityType: domain.EntityTypePost, EntityID: 20, Action: domain.ActionTypeLike,
+	}, nil)
+	m.postRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Post{ID: 20, CreatedBy: 1, SpaceID: 3}, nil)
+	m.spaceRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 3}, nil)
+	gomock.InOrder(
+		m.reactionRepo.EXPECT().DeleteReaction(gomock.Any(), "r-1").Return(nil),
+		m.notificationUsecase.EXPECT().RetractNotification(gomock.Any(), retractParams(domain.ActionTypeLike)).Return(nil),
+	)
+
+	assert.NoError(t, usecase.RemoveReaction(context.Background(), 4, "r-1"))
+}
+
+func TestRemoveReactionArchivedSpace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newReactionUsecaseForTest(ctrl)
+
+	archivedAt := time.Now()
+	m.reactionRepo.EXPECT().FindReactionByID(gomock.Any(), "r-1").Return(&domain.Reaction{
+		ID: "r-1", UserID: 4, EntityType: domain.EntityTypePost, EntityID: 20, Action: domain.ActionTypeLike,
+	}, nil)
+	m.postRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Post{ID: 20, CreatedBy: 1, SpaceID: 3}, nil)
+	m.spaceRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 3, ArchivedAt: &archivedAt}, nil)
+
+	err := usecase.RemoveReaction(context.Background(), 4, "r-1")
+
+	assert.Equal(t, apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable"), err)
+}
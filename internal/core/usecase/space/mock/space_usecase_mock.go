@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	domain "cpi-hub-api/internal/core/domain"
+	dto "cpi-hub-api/internal/core/dto"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -41,6 +42,20 @@ func (m *MockSpaceUseCase) EXPECT() *MockSpaceUseCaseMockRecorder {
 	return m.recorder
 }
 
+// Archive mocks base method.
+func (m *MockSpaceUseCase) Archive(ctx context.Context, actorID, spaceID int, archived bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", ctx, actorID, spaceID, archived)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockSpaceUseCaseMockRecorder) Archive(ctx, actorID, spaceID, archived any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockSpaceUseCase)(nil).Archive), ctx, actorID, spaceID, archived)
+}
+
 // Create mocks base method.
 func (m *MockSpaceUseCase) Create(ctx context.Context, space *domain.Space) (*domain.SpaceWithUserAndCounts, error) {
 	m.ctrl.T.Helper()
@@ -56,34 +71,62 @@ func (mr *MockSpaceUseCaseMockRecorder) Create(ctx, space any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSpaceUseCase)(nil).Create), ctx, space)
 }
 
+// Delete mocks base method.
+func (m *MockSpaceUseCase) Delete(ctx context.Context, actorID, spaceID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, actorID, spaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSpaceUseCaseMockRecorder) Delete(ctx, actorID, spaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSpaceUseCase)(nil).Delete), ctx, actorID, spaceID)
+}
+
 // Get mocks base method.
-func (m *MockSpaceUseCase) Get(ctx context.Context, id string) (*domain.SpaceWithUserAndCounts, error) {
+func (m *MockSpaceUseCase) Get(ctx context.Context, userID int, id string) (*domain.SpaceWithUserAndCounts, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret := m.ctrl.Call(m, "Get", ctx, userID, id)
 	ret0, _ := ret[0].(*domain.SpaceWithUserAndCounts)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockSpaceUseCaseMockRecorder) Get(ctx, id any) *gomock.Call {
+func (mr *MockSpaceUseCaseMockRecorder) Get(ctx, userID, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSpaceUseCase)(nil).Get), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSpaceUseCase)(nil).Get), ctx, userID, id)
 }
 
 // GetUsersBySpace mocks base method.
-func (m *MockSpaceUseCase) GetUsersBySpace(ctx context.Context, spaceID string) ([]*domain.User, error) {
+func (m *MockSpaceUseCase) GetUsersBySpace(ctx context.Context, viewerID int, spaceID string) ([]*domain.SpaceMember, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUsersBySpace", ctx, spaceID)
-	ret0, _ := ret[0].([]*domain.User)
+	ret := m.ctrl.Call(m, "GetUsersBySpace", ctx, viewerID, spaceID)
+	ret0, _ := ret[0].([]*domain.SpaceMember)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUsersBySpace indicates an expected call of GetUsersBySpace.
-func (mr *MockSpaceUseCaseMockRecorder) GetUsersBySpace(ctx, spaceID any) *gomock.Call {
+func (mr *MockSpaceUseCaseMockRecorder) GetUsersBySpace(ctx, viewerID, spaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersBySpace", reflect.TypeOf((*MockSpaceUseCase)(nil).GetUsersBySpace), ctx, viewerID, spaceID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockSpaceUseCase) RemoveMember(ctx context.Context, actorID, spaceID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, actorID, spaceID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockSpaceUseCaseMockRecorder) RemoveMember(ctx, actorID, spaceID, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersBySpace", reflect.TypeOf((*MockSpaceUseCase)(nil).GetUsersBySpace), ctx, spaceID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockSpaceUseCase)(nil).RemoveMember), ctx, actorID, spaceID, userID)
 }
 
 // Search mocks base method.
@@ -100,3 +143,46 @@ func (mr *MockSpaceUseCaseMockRecorder) Search(ctx, criteria any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockSpaceUseCase)(nil).Search), ctx, criteria)
 }
+
+// TransferOwnership mocks base method.
+func (m *MockSpaceUseCase) TransferOwnership(ctx context.Context, actorID, spaceID, newOwnerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferOwnership", ctx, actorID, spaceID, newOwnerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferOwnership indicates an expected call of TransferOwnership.
+func (mr *MockSpaceUseCaseMockRecorder) TransferOwnership(ctx, actorID, spaceID, newOwnerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferOwnership", reflect.TypeOf((*MockSpaceUseCase)(nil).TransferOwnership), ctx, actorID, spaceID, newOwnerID)
+}
+
+// Update mocks base method.
+func (m *MockSpaceUseCase) Update(ctx context.Context, actorID, spaceID int, updateSpaceDTO dto.UpdateSpaceDTO) (*domain.SpaceWithUserAndCounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, actorID, spaceID, updateSpaceDTO)
+	ret0, _ := ret[0].(*domain.SpaceWithUserAndCounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockSpaceUseCaseMockRecorder) Update(ctx, actorID, spaceID, updateSpaceDTO any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSpaceUseCase)(nil).Update), ctx, actorID, spaceID, updateSpaceDTO)
+}
+
+// UpdateMemberRole mocks base method.
+func (m *MockSpaceUseCase) UpdateMemberRole(ctx context.Context, actorID, spaceID, userID int, role domain.SpaceRole) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberRole", ctx, actorID, spaceID, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMemberRole indicates an expected call of UpdateMemberRole.
+func (mr *MockSpaceUseCaseMockRecorder) UpdateMemberRole(ctx, actorID, spaceID, userID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRole", reflect.TypeOf((*MockSpaceUseCase)(nil).UpdateMemberRole), ctx, actorID, spaceID, userID, role)
+}
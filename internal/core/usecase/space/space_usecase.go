@@ -4,34 +4,62 @@ import (
 	"context"
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/domain/criteria"
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/authorization"
 	pghelpers "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/helpers"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
+	"log"
 	"strconv"
 	"strings"
+	"time"
 )
 
 //go:generate mockgen -destination=mock/space_usecase_mock.go -package=mocks . SpaceUseCase
 type SpaceUseCase interface {
 	Create(ctx context.Context, space *domain.Space) (*domain.SpaceWithUserAndCounts, error)
-	Get(ctx context.Context, id string) (*domain.SpaceWithUserAndCounts, error)
+	Get(ctx context.Context, userID int, id string) (*domain.SpaceWithUserAndCounts, error)
 	Search(ctx context.Context, criteria *domain.SpaceSearchCriteria) (*domain.SearchResult, error)
-	GetUsersBySpace(ctx context.Context, spaceID string) ([]*domain.User, error)
+	GetUsersBySpace(ctx context.Context, viewerID int, spaceID string) ([]*domain.SpaceMember, error)
+	UpdateMemberRole(ctx context.Context, actorID int, spaceID int, userID int, role domain.SpaceRole) error
+	RemoveMember(ctx context.Context, actorID int, spaceID int, userID int) error
+	TransferOwnership(ctx context.Context, actorID int, spaceID int, newOwnerID int) error
+	Update(ctx context.Context, actorID int, spaceID int, updateSpaceDTO dto.UpdateSpaceDTO) (*domain.SpaceWithUserAndCounts, error)
+	Archive(ctx context.Context, actorID int, spaceID int, archived bool) error
+	Delete(ctx context.Context, actorID int, spaceID int) error
 }
 
 type spaceUseCase struct {
-	spaceRepository     domain.SpaceRepository
-	userRepository      domain.UserRepository
-	userSpaceRepository domain.UserSpaceRepository
-	postRepository      domain.PostRepository
+	spaceRepository        domain.SpaceRepository
+	userRepository         domain.UserRepository
+	userSpaceRepository    domain.UserSpaceRepository
+	postRepository         domain.PostRepository
+	commentRepository      domain.CommentRepository
+	reactionRepository     domain.ReactionRepository
+	notificationRepository domain.NotificationRepository
+	membershipPolicy       authorization.MembershipPolicy
+	spaceAuthorizer        authorization.SpaceAuthorizer
 }
 
-func NewSpaceUsecase(spaceRepository domain.SpaceRepository, userRepository domain.UserRepository, userSpaceRepository domain.UserSpaceRepository, postRepository domain.PostRepository) SpaceUseCase {
+func NewSpaceUsecase(
+	spaceRepository domain.SpaceRepository,
+	userRepository domain.UserRepository,
+	userSpaceRepository domain.UserSpaceRepository,
+	postRepository domain.PostRepository,
+	commentRepository domain.CommentRepository,
+	reactionRepository domain.ReactionRepository,
+	notificationRepository domain.NotificationRepository,
+) SpaceUseCase {
 	return &spaceUseCase{
-		spaceRepository:     spaceRepository,
-		userRepository:      userRepository,
-		userSpaceRepository: userSpaceRepository,
-		postRepository:      postRepository,
+		spaceRepository:        spaceRepository,
+		userRepository:         userRepository,
+		userSpaceRepository:    userSpaceRepository,
+		postRepository:         postRepository,
+		commentRepository:      commentRepository,
+		reactionRepository:     reactionRepository,
+		notificationRepository: notificationRepository,
+		membershipPolicy:       authorization.NewMembershipPolicy(spaceRepository, userSpaceRepository),
+		spaceAuthorizer:        authorization.NewSpaceAuthorizer(userSpaceRepository),
 	}
 }
 
@@ -67,7 +95,7 @@ func (s *spaceUseCase) getSpaceCounts(ctx context.Context, space domain.Space) (
 
 	criteria := criteriaBuilder.Build()
 
-	usersCount, err := s.userSpaceRepository.Count(ctx, criteria)
+	roleCounts, err := s.userSpaceRepository.CountByRole(ctx, space.ID)
 	if err != nil {
 		return domain.SpaceCounts{}, err
 	}
@@ -77,7 +105,10 @@ func (s *spaceUseCase) getSpaceCounts(ctx context.Context, space domain.Space) (
 		return domain.SpaceCounts{}, err
 	}
 
-	counts.Users = usersCount
+	counts.Owners = roleCounts[domain.SpaceRoleOwner]
+	counts.Moderators = roleCounts[domain.SpaceRoleModerator]
+	counts.Members = roleCounts[domain.SpaceRoleMember]
+	counts.Users = counts.Owners + counts.Moderators + counts.Members
 	counts.Posts = postCount
 
 	return counts, nil
@@ -102,22 +133,12 @@ func (s *spaceUseCase) Create(ctx context.Context, space *domain.Space) (*domain
 		return nil, apperror.NewNotFound("User not found", nil, "space_usecase.go:Create")
 	}
 
-	existingSpace, err := s.spaceRepository.Find(ctx, &criteria.Criteria{
-		Filters: []criteria.Filter{
-			{
-				Field:    "name",
-				Value:    space.Name,
-				Operator: criteria.OperatorEqual,
-			},
-		},
-	})
-
-	if err != nil {
+	if err := s.ensureNameAvailable(ctx, space.Name, 0); err != nil {
 		return nil, err
 	}
 
-	if existingSpace != nil {
-		return nil, apperror.NewInvalidData("Space with this name already exists", nil, "space_usecase.go:Create")
+	if space.Visibility == "" {
+		space.Visibility = domain.SpaceVisibilityPublic
 	}
 
 	space.CreatedAt, space.UpdatedAt = helpers.GetTime(), helpers.GetTime()
@@ -128,7 +149,7 @@ func (s *spaceUseCase) Create(ctx context.Context, space *domain.Space) (*domain
 		return nil, err
 	}
 
-	err = s.userSpaceRepository.Update(ctx, existingUser.ID, []int{space.ID}, domain.AddUserToSpace)
+	err = s.userSpaceRepository.AddMember(ctx, existingUser.ID, space.ID, domain.SpaceRoleOwner)
 	if err != nil {
 		return nil, err
 	}
@@ -137,18 +158,28 @@ func (s *spaceUseCase) Create(ctx context.Context, space *domain.Space) (*domain
 		Space: space,
 		User:  existingUser,
 		SpaceCounts: domain.SpaceCounts{
-			Users: 1,
-			Posts: 0,
+			Users:  1,
+			Owners: 1,
+			Posts:  0,
 		},
 	}, nil
 }
 
-func (s *spaceUseCase) Get(ctx context.Context, id string) (*domain.SpaceWithUserAndCounts, error) {
+func (s *spaceUseCase) Get(ctx context.Context, userID int, id string) (*domain.SpaceWithUserAndCounts, error) {
 	space, err := pghelpers.FindEntity(ctx, s.spaceRepository, "id", id, "Space not found")
 	if err != nil {
 		return nil, err
 	}
 
+	canView, err := s.membershipPolicy.CanView(ctx, space, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canView {
+		return nil, apperror.NewNotFound("Space not found", nil, "space_usecase.go:Get")
+	}
+
 	user, err := pghelpers.FindEntity(ctx, s.userRepository, "id", space.CreatedBy, "User not found")
 	if err != nil {
 		return nil, err
@@ -165,16 +196,15 @@ func (s *spaceUseCase) Get(ctx context.Context, id string) (*domain.SpaceWithUse
 			ID:          space.ID,
 			Name:        space.Name,
 			Description: space.Description,
+			Visibility:  space.Visibility,
+			ArchivedAt:  space.ArchivedAt,
 			CreatedAt:   space.CreatedAt,
 			UpdatedAt:   space.UpdatedAt,
 			CreatedBy:   space.CreatedBy,
 			UpdatedBy:   space.UpdatedBy,
 		},
-		User: user,
-		SpaceCounts: domain.SpaceCounts{
-			Users: spaceCounts.Users,
-			Posts: spaceCounts.Posts,
-		},
+		User:        user,
+		SpaceCounts: spaceCounts,
 	}, nil
 }
 
@@ -208,6 +238,23 @@ func (s *spaceUseCase) Search(ctx context.Context, searchCriteria *domain.SpaceS
 		criteriaBuilder.WithFilter("created_by", *searchCriteria.CreatedBy, criteria.OperatorEqual)
 	}
 
+	if !searchCriteria.IncludeArchived {
+		criteriaBuilder.WithFilter("archived_at", false, criteria.OperatorExists)
+	}
+
+	hiddenSpaceIDs, err := s.membershipPolicy.HiddenSpaceIDs(ctx, searchCriteria.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hiddenSpaceIDs) > 0 {
+		ids := make([]interface{}, len(hiddenSpaceIDs))
+		for i, id := range hiddenSpaceIDs {
+			ids[i] = id
+		}
+		criteriaBuilder.WithFilter("id", ids, criteria.OperatorNotIn)
+	}
+
 	criteria := criteriaBuilder.Build()
 
 	totalCount, err := s.spaceRepository.Count(ctx, criteria)
@@ -236,34 +283,276 @@ func (s *spaceUseCase) Search(ctx context.Context, searchCriteria *domain.SpaceS
 	}, nil
 }
 
-func (s *spaceUseCase) GetUsersBySpace(ctx context.Context, spaceID string) ([]*domain.User, error) {
+func (s *spaceUseCase) GetUsersBySpace(ctx context.Context, viewerID int, spaceID string) ([]*domain.SpaceMember, error) {
 	spaceIDInt, err := strconv.Atoi(spaceID)
 	if err != nil {
 		return nil, apperror.NewInvalidData("Invalid space ID format", err, "space_usecase.go:GetUsersBySpace")
 	}
 
-	_, err = pghelpers.FindEntity(ctx, s.spaceRepository, "id", spaceID, "Space not found")
+	space, err := pghelpers.FindEntity(ctx, s.spaceRepository, "id", spaceID, "Space not found")
 	if err != nil {
 		return nil, err
 	}
 
-	userIDs, err := s.userSpaceRepository.FindUserIDsBySpaceID(ctx, spaceIDInt)
-	if err != nil {
+	if err := s.membershipPolicy.RequireView(ctx, space, viewerID); err != nil {
 		return nil, err
 	}
 
-	if len(userIDs) == 0 {
-		return []*domain.User{}, nil
+	memberships, err := s.userSpaceRepository.FindMembersBySpaceID(ctx, spaceIDInt)
+	if err != nil {
+		return nil, err
 	}
 
-	var users []*domain.User
-	for _, userID := range userIDs {
-		user, err := pghelpers.FindEntity(ctx, s.userRepository, "id", strconv.Itoa(userID), "User not found")
+	members := make([]*domain.SpaceMember, 0, len(memberships))
+	for _, membership := range memberships {
+		user, err := pghelpers.FindEntity(ctx, s.userRepository, "id", strconv.Itoa(membership.UserID), "User not found")
 		if err != nil {
 			return nil, err
 		}
-		users = append(users, user)
+		members = append(members, &domain.SpaceMember{
+			User: user,
+			Role: membership.Role,
+		})
+	}
+
+	return members, nil
+}
+
+func (s *spaceUseCase) UpdateMemberRole(ctx context.Context, actorID int, spaceID int, userID int, role domain.SpaceRole) error {
+	if role != domain.SpaceRoleModerator && role != domain.SpaceRoleMember {
+		return apperror.NewInvalidData("Role must be moderator or member", nil, "space_usecase.go:UpdateMemberRole")
+	}
+
+	if err := s.requireRole(ctx, spaceID, actorID, domain.SpaceRoleOwner, "Only the owner can change member roles"); err != nil {
+		return err
+	}
+
+	targetRole, err := s.findMemberRole(ctx, spaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	if targetRole == domain.SpaceRoleOwner {
+		return apperror.NewInvalidData("The owner role can only be changed by transferring ownership", nil, "space_usecase.go:UpdateMemberRole")
+	}
+
+	return s.userSpaceRepository.UpdateRole(ctx, userID, spaceID, role)
+}
+
+func (s *spaceUseCase) RemoveMember(ctx context.Context, actorID int, spaceID int, userID int) error {
+	if actorID == userID {
+		return apperror.NewInvalidData("You cannot remove yourself from the space", nil, "space_usecase.go:RemoveMember")
+	}
+
+	actorRole, err := s.spaceAuthorizer.Role(ctx, spaceID, actorID)
+	if err != nil {
+		return err
+	}
+
+	if !actorRole.CanModerate() {
+		return apperror.NewForbidden("Only owners and moderators can remove members", nil, "space_usecase.go:RemoveMember")
+	}
+
+	targetRole, err := s.findMemberRole(ctx, spaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	if targetRole == domain.SpaceRoleOwner || (targetRole == domain.SpaceRoleModerator && actorRole != domain.SpaceRoleOwner) {
+		return apperror.NewForbidden("You cannot remove this member", nil, "space_usecase.go:RemoveMember")
+	}
+
+	return s.userSpaceRepository.Update(ctx, userID, []int{spaceID}, domain.RemoveUserFromSpace)
+}
+
+func (s *spaceUseCase) TransferOwnership(ctx context.Context, actorID int, spaceID int, newOwnerID int) error {
+	if actorID == newOwnerID {
+		return apperror.NewInvalidData("You already own this space", nil, "space_usecase.go:TransferOwnership")
+	}
+
+	if err := s.requireRole(ctx, spaceID, actorID, domain.SpaceRoleOwner, "Only the owner can transfer ownership"); err != nil {
+		return err
+	}
+
+	if _, err := s.findMemberRole(ctx, spaceID, newOwnerID); err != nil {
+		return err
+	}
+
+	return s.userSpaceRepository.TransferOwnership(ctx, spaceID, actorID, newOwnerID)
+}
+
+func (s *spaceUseCase) requireRole(ctx context.Context, spaceID int, userID int, role domain.SpaceRole, message string) error {
+	userRole, err := s.spaceAuthorizer.Role(ctx, spaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	if userRole != role {
+		return apperror.NewForbidden(message, nil, "space_usecase.go:requireRole")
+	}
+
+	return nil
+}
+
+func (s *spaceUseCase) findMemberRole(ctx context.Context, spaceID int, userID int) (domain.SpaceRole, error) {
+	role, err := s.spaceAuthorizer.Role(ctx, spaceID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if role == "" {
+		return "", apperror.NewNotFound("Member not found", nil, "space_usecase.go:findMemberRole")
+	}
+
+	return role, nil
+}
+
+func (s *spaceUseCase) Update(ctx context.Context, actorID int, spaceID int, updateSpaceDTO dto.UpdateSpaceDTO) (*domain.SpaceWithUserAndCounts, error) {
+	space, err := pghelpers.FindEntity(ctx, s.spaceRepository, "id", spaceID, "Space not found")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.requireRole(ctx, spaceID, actorID, domain.SpaceRoleOwner, "Only the owner can edit the space"); err != nil {
+		return nil, err
+	}
+
+	if err := authorization.RequireWritable(space); err != nil {
+		return nil, err
+	}
+
+	if updateSpaceDTO.Name != nil && *updateSpaceDTO.Name != space.Name {
+		if err := s.ensureNameAvailable(ctx, *updateSpaceDTO.Name, space.ID); err != nil {
+			return nil, err
+		}
+		space.Name = *updateSpaceDTO.Name
+	}
+	if updateSpaceDTO.Description != nil {
+		space.Description = *updateSpaceDTO.Description
+	}
+	if updateSpaceDTO.Visibility != nil {
+		space.Visibility = *updateSpaceDTO.Visibility
+	}
+
+	space.UpdatedAt = helpers.GetTime()
+	space.UpdatedBy = actorID
+
+	if err := s.spaceRepository.Update(ctx, space); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, actorID, strconv.Itoa(space.ID))
+}
+
+// Archive makes the space read-only and hides it from search, archived=false restores it
+func (s *spaceUseCase) Archive(ctx context.Context, actorID int, spaceID int, archived bool) error {
+	space, err := pghelpers.FindEntity(ctx, s.spaceRepository, "id", spaceID, "Space not found")
+	if err != nil {
+		return err
+	}
+
+	if err := s.requireRole(ctx, spaceID, actorID, domain.SpaceRoleOwner, "Only the owner can archive the space"); err != nil {
+		return err
+	}
+
+	if space.IsArchived() == archived {
+		return nil
+	}
+
+	now := helpers.GetTime()
+	var archivedAt *time.Time
+	if archived {
+		archivedAt = &now
+	}
+
+	return s.spaceRepository.SetArchived(ctx, space.ID, archivedAt, actorID, now)
+}
+
+// Delete removes the space permanently. Postgres cascades memberships, posts, comments and chat
+// messages, the reactions and notifications stored in Mongo are removed here.
+func (s *spaceUseCase) Delete(ctx context.Context, actorID int, spaceID int) error {
+	space, err := pghelpers.FindEntity(ctx, s.spaceRepository, "id", spaceID, "Space not found")
+	if err != nil {
+		return err
+	}
+
+	if err := s.requireRole(ctx, spaceID, actorID, domain.SpaceRoleOwner, "Only the owner can delete the space"); err != nil {
+		return err
+	}
+
+	postIDs, commentIDs, err := s.findContentIDs(ctx, space.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.spaceRepository.Delete(ctx, space.ID); err != nil {
+		return err
+	}
+
+	s.deleteMongoContent(ctx, domain.EntityTypePost, postIDs)
+	s.deleteMongoContent(ctx, domain.EntityTypeComment, commentIDs)
+
+	if err := s.notificationRepository.DeleteByEntities(ctx, domain.EntityTypeSpace, []int{space.ID}); err != nil {
+		log.Printf("Error deleting space notifications: %v", err)
+	}
+
+	return nil
+}
+
+func (s *spaceUseCase) findContentIDs(ctx context.Context, spaceID int) ([]int, []int, error) {
+	posts, err := s.postRepository.Search(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("space_id", spaceID, criteria.OperatorEqual).
+		Build())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(posts) == 0 {
+		return []int{}, []int{}, nil
+	}
+
+	postIDs := make([]int, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	comments, err := s.commentRepository.FindAll(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("post_id", postIDs, criteria.OperatorIn).
+		Build())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commentIDs := make([]int, len(comments))
+	for i, comment := range comments {
+		commentIDs[i] = comment.Comment.ID
+	}
+
+	return postIDs, commentIDs, nil
+}
+
+// deleteMongoContent is best effort, the space is already gone from Postgres at this point
+func (s *spaceUseCase) deleteMongoContent(ctx context.Context, entityType domain.EntityType, entityIDs []int) {
+	if err := s.reactionRepository.DeleteByEntities(ctx, entityType, entityIDs); err != nil {
+		log.Printf("Error deleting %s reactions: %v", entityType, err)
+	}
+
+	if err := s.notificationRepository.DeleteByEntities(ctx, entityType, entityIDs); err != nil {
+		log.Printf("Error deleting %s notifications: %v", entityType, err)
+	}
+}
+
+func (s *spaceUseCase) ensureNameAvailable(ctx context.Context, name string, spaceID int) error {
+	existingSpace, err := s.spaceRepository.Find(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("name", name, criteria.OperatorEqual).
+		Build())
+	if err != nil {
+		return err
+	}
+
+	if existingSpace != nil && existingSpace.ID != spaceID {
+		return apperror.NewInvalidData("Space with this name already exists", nil, "space_usecase.go:ensureNameAvailable")
 	}
 
-	return users, nil
+	return nil
 }
@@ -4,9 +4,11 @@ import (
 	"context"
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
 	"cpi-hub-api/pkg/apperror"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -20,8 +22,11 @@ func TestCreate(t *testing.T) {
 	mockUserRepository := mock.NewMockUserRepository(ctrl)
 	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
 	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
 
-	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository)
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
 
 	type args struct {
 		context context.Context
@@ -78,7 +83,7 @@ func TestCreate(t *testing.T) {
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
 				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil),
 				mockSpaceRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil),
-				mockUserSpaceRepository.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+				mockUserSpaceRepository.EXPECT().AddMember(gomock.Any(), gomock.Any(), gomock.Any(), domain.SpaceRoleOwner).Return(nil),
 			},
 		},
 		{
@@ -128,7 +133,7 @@ func TestCreate(t *testing.T) {
 				space:   givenSpace,
 			},
 			want: want{
-				err: apperror.NewInvalidData("Space with this name already exists", nil, "space_usecase.go:Create"),
+				err: apperror.NewInvalidData("Space with this name already exists", nil, "space_usecase.go:ensureNameAvailable"),
 			},
 			calls: []*gomock.Call{
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
@@ -163,7 +168,7 @@ func TestCreate(t *testing.T) {
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
 				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil),
 				mockSpaceRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil),
-				mockUserSpaceRepository.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("error updating user space")),
+				mockUserSpaceRepository.EXPECT().AddMember(gomock.Any(), gomock.Any(), gomock.Any(), domain.SpaceRoleOwner).Return(errors.New("error updating user space")),
 			},
 		},
 	}
@@ -198,11 +203,15 @@ func TestGet(t *testing.T) {
 	mockUserRepository := mock.NewMockUserRepository(ctrl)
 	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
 	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
 
-	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository)
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
 
 	type args struct {
 		context context.Context
+		userID  int
 		id      string
 	}
 
@@ -218,6 +227,14 @@ func TestGet(t *testing.T) {
 		CreatedBy:   1,
 	}
 
+	givenPrivateSpace := &domain.Space{
+		ID:          1,
+		Name:        "Private Space",
+		Description: "Test Description",
+		Visibility:  domain.SpaceVisibilityPrivate,
+		CreatedBy:   1,
+	}
+
 	givenUser := &domain.User{
 		ID:   1,
 		Name: "Test User",
@@ -233,6 +250,7 @@ func TestGet(t *testing.T) {
 			name: "success",
 			args: args{
 				context: context.Background(),
+				userID:  1,
 				id:      "1",
 			},
 			want: want{
@@ -255,14 +273,30 @@ func TestGet(t *testing.T) {
 			calls: []*gomock.Call{
 				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
-				mockUserSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(1, nil),
+				mockUserSpaceRepository.EXPECT().CountByRole(gomock.Any(), gomock.Any()).Return(map[domain.SpaceRole]int{domain.SpaceRoleOwner: 1}, nil),
 				mockPostRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(0, nil),
 			},
 		},
+		{
+			name: "error private space hidden from non members",
+			args: args{
+				context: context.Background(),
+				userID:  2,
+				id:      "1",
+			},
+			want: want{
+				err: apperror.NewNotFound("Space not found", nil, "space_usecase.go:Get"),
+			},
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPrivateSpace, nil),
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 2, 1).Return(false, nil),
+			},
+		},
 		{
 			name: "error finding space",
 			args: args{
 				context: context.Background(),
+				userID:  1,
 				id:      "1",
 			},
 			want: want{
@@ -276,6 +310,7 @@ func TestGet(t *testing.T) {
 			name: "error finding user",
 			args: args{
 				context: context.Background(),
+				userID:  1,
 				id:      "1",
 			},
 			want: want{
@@ -290,6 +325,7 @@ func TestGet(t *testing.T) {
 			name: "error counting user spaces",
 			args: args{
 				context: context.Background(),
+				userID:  1,
 				id:      "1",
 			},
 			want: want{
@@ -298,13 +334,14 @@ func TestGet(t *testing.T) {
 			calls: []*gomock.Call{
 				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
-				mockUserSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(0, errors.New("unexpected error")),
+				mockUserSpaceRepository.EXPECT().CountByRole(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error")),
 			},
 		},
 		{
 			name: "error counting posts",
 			args: args{
 				context: context.Background(),
+				userID:  1,
 				id:      "1",
 			},
 			want: want{
@@ -313,7 +350,7 @@ func TestGet(t *testing.T) {
 			calls: []*gomock.Call{
 				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
-				mockUserSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(0, nil),
+				mockUserSpaceRepository.EXPECT().CountByRole(gomock.Any(), gomock.Any()).Return(map[domain.SpaceRole]int{}, nil),
 				mockPostRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(0, errors.New("unexpected error")),
 			},
 		},
@@ -326,7 +363,7 @@ func TestGet(t *testing.T) {
 		}
 		gomock.InOrder(calls...)
 
-		got, gotErr := spaceUseCase.Get(test.args.context, test.args.id)
+		got, gotErr := spaceUseCase.Get(test.args.context, test.args.userID, test.args.id)
 
 		assert.Equal(t, test.want.err, gotErr)
 		if test.want.err == nil {
@@ -348,8 +385,11 @@ func TestSearch(t *testing.T) {
 	mockUserRepository := mock.NewMockUserRepository(ctrl)
 	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
 	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
 
-	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository)
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
 
 	type args struct {
 		context  context.Context
@@ -368,6 +408,13 @@ func TestSearch(t *testing.T) {
 		CreatedBy:   1,
 	}
 
+	givenPrivateSpace := &domain.Space{
+		ID:         2,
+		Name:       "Private Space",
+		Visibility: domain.SpaceVisibilityPrivate,
+		CreatedBy:  1,
+	}
+
 	givenUser := &domain.User{
 		ID:   1,
 		Name: "Test User",
@@ -393,18 +440,59 @@ func TestSearch(t *testing.T) {
 			want: want{
 				result: &domain.SearchResult{
 					Data: []*domain.SpaceWithUserAndCounts{
-						{Space: givenSpace, User: givenUser, SpaceCounts: domain.SpaceCounts{Users: 1, Posts: 0}},
+						{Space: givenSpace, User: givenUser, SpaceCounts: domain.SpaceCounts{Users: 1, Owners: 1, Posts: 0}},
+					},
+				},
+			},
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{}, nil),
+				mockSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(1, nil),
+				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{givenSpace}, nil),
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
+				mockUserSpaceRepository.EXPECT().CountByRole(gomock.Any(), gomock.Any()).Return(map[domain.SpaceRole]int{domain.SpaceRoleOwner: 1}, nil),
+				mockPostRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(0, nil),
+			},
+		},
+		{
+			name: "success hiding private spaces of non members",
+			args: args{
+				context: context.Background(),
+				criteria: &domain.SpaceSearchCriteria{
+					ViewerID: 2,
+				},
+			},
+			want: want{
+				result: &domain.SearchResult{
+					Data: []*domain.SpaceWithUserAndCounts{
+						{Space: givenSpace, User: givenUser, SpaceCounts: domain.SpaceCounts{Users: 1, Owners: 1, Posts: 0}},
 					},
 				},
 			},
 			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{givenPrivateSpace}, nil),
+				mockUserSpaceRepository.EXPECT().FindSpacesIDsByUserID(gomock.Any(), 2).Return([]int{1}, nil),
 				mockSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(1, nil),
 				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{givenSpace}, nil),
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
-				mockUserSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(1, nil),
+				mockUserSpaceRepository.EXPECT().CountByRole(gomock.Any(), gomock.Any()).Return(map[domain.SpaceRole]int{domain.SpaceRoleOwner: 1}, nil),
 				mockPostRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(0, nil),
 			},
 		},
+		{
+			name: "error finding private spaces",
+			args: args{
+				context: context.Background(),
+				criteria: &domain.SpaceSearchCriteria{
+					ViewerID: 2,
+				},
+			},
+			want: want{
+				err: errors.New("unexpected error"),
+			},
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error")),
+			},
+		},
 		{
 			name: "error counting spaces",
 			args: args{
@@ -417,6 +505,7 @@ func TestSearch(t *testing.T) {
 				err: errors.New("unexpected error"),
 			},
 			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{}, nil),
 				mockSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(0, errors.New("unexpected error")),
 			},
 		},
@@ -432,10 +521,11 @@ func TestSearch(t *testing.T) {
 				err: errors.New("unexpected error"),
 			},
 			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{}, nil),
 				mockSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(1, nil),
 				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{givenSpace}, nil),
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
-				mockUserSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(0, errors.New("unexpected error")),
+				mockUserSpaceRepository.EXPECT().CountByRole(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error")),
 			},
 		},
 		{
@@ -451,6 +541,7 @@ func TestSearch(t *testing.T) {
 				err: errors.New("unexpected error"),
 			},
 			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{}, nil),
 				mockSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(1, nil),
 				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error")),
 			},
@@ -467,6 +558,7 @@ func TestSearch(t *testing.T) {
 				err: errors.New("unexpected error"),
 			},
 			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{}, nil),
 				mockSpaceRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(1, nil),
 				mockSpaceRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.Space{givenSpace}, nil),
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error")),
@@ -500,17 +592,21 @@ func TestGetUsersBySpace(t *testing.T) {
 	mockUserRepository := mock.NewMockUserRepository(ctrl)
 	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
 	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
 
-	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository)
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
 
 	type args struct {
-		context context.Context
-		spaceID string
+		context  context.Context
+		viewerID int
+		spaceID  string
 	}
 
 	type want struct {
-		users []*domain.User
-		err   error
+		members []*domain.SpaceMember
+		err     error
 	}
 
 	givenSpace := &domain.Space{
@@ -525,6 +621,10 @@ func TestGetUsersBySpace(t *testing.T) {
 		Name: "Test User",
 	}
 
+	givenMembership := &domain.SpaceMembership{UserID: 1, SpaceID: 1, Role: domain.SpaceRoleOwner}
+
+	givenPrivateSpace := &domain.Space{ID: 1, Visibility: domain.SpaceVisibilityPrivate, CreatedBy: 1}
+
 	tests := []struct {
 		name  string
 		args  args
@@ -538,11 +638,11 @@ func TestGetUsersBySpace(t *testing.T) {
 				spaceID: "1",
 			},
 			want: want{
-				users: []*domain.User{givenUser},
+				members: []*domain.SpaceMember{{User: givenUser, Role: domain.SpaceRoleOwner}},
 			},
 			calls: []*gomock.Call{
 				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
-				mockUserSpaceRepository.EXPECT().FindUserIDsBySpaceID(gomock.Any(), gomock.Any()).Return([]int{1}, nil),
+				mockUserSpaceRepository.EXPECT().FindMembersBySpaceID(gomock.Any(), 1).Return([]*domain.SpaceMembership{givenMembership}, nil),
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
 			},
 		},
@@ -553,11 +653,43 @@ func TestGetUsersBySpace(t *testing.T) {
 				spaceID: "1",
 			},
 			want: want{
-				users: []*domain.User{},
+				members: []*domain.SpaceMember{},
 			},
 			calls: []*gomock.Call{
 				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
-				mockUserSpaceRepository.EXPECT().FindUserIDsBySpaceID(gomock.Any(), gomock.Any()).Return([]int{}, nil),
+				mockUserSpaceRepository.EXPECT().FindMembersBySpaceID(gomock.Any(), 1).Return([]*domain.SpaceMembership{}, nil),
+			},
+		},
+		{
+			name: "success member of a private space",
+			args: args{
+				context:  context.Background(),
+				viewerID: 1,
+				spaceID:  "1",
+			},
+			want: want{
+				members: []*domain.SpaceMember{{User: givenUser, Role: domain.SpaceRoleOwner}},
+			},
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPrivateSpace, nil),
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 1, 1).Return(true, nil),
+				mockUserSpaceRepository.EXPECT().FindMembersBySpaceID(gomock.Any(), 1).Return([]*domain.SpaceMembership{givenMembership}, nil),
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
+			},
+		},
+		{
+			name: "error non member of a private space",
+			args: args{
+				context:  context.Background(),
+				viewerID: 2,
+				spaceID:  "1",
+			},
+			want: want{
+				err: apperror.NewForbidden("You must be a member of this space", nil, "membership_policy.go:RequireView"),
+			},
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenPrivateSpace, nil),
+				mockUserSpaceRepository.EXPECT().Exists(gomock.Any(), 2, 1).Return(false, nil),
 			},
 		},
 		{
@@ -594,7 +726,7 @@ func TestGetUsersBySpace(t *testing.T) {
 			},
 			calls: []*gomock.Call{
 				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
-				mockUserSpaceRepository.EXPECT().FindUserIDsBySpaceID(gomock.Any(), gomock.Any()).Return([]int{}, errors.New("unexpected error")),
+				mockUserSpaceRepository.EXPECT().FindMembersBySpaceID(gomock.Any(), 1).Return(nil, errors.New("unexpected error")),
 			},
 		},
 		{
@@ -608,7 +740,7 @@ func TestGetUsersBySpace(t *testing.T) {
 			},
 			calls: []*gomock.Call{
 				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace, nil),
-				mockUserSpaceRepository.EXPECT().FindUserIDsBySpaceID(gomock.Any(), gomock.Any()).Return([]int{1}, nil),
+				mockUserSpaceRepository.EXPECT().FindMembersBySpaceID(gomock.Any(), 1).Return([]*domain.SpaceMembership{givenMembership}, nil),
 				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error")),
 			},
 		},
@@ -621,9 +753,468 @@ func TestGetUsersBySpace(t *testing.T) {
 		}
 		gomock.InOrder(calls...)
 
-		got, gotErr := spaceUseCase.GetUsersBySpace(test.args.context, test.args.spaceID)
+		got, gotErr := spaceUseCase.GetUsersBySpace(test.args.context, test.args.viewerID, test.args.spaceID)
 
 		assert.Equal(t, test.want.err, gotErr)
-		assert.Equal(t, test.want.users, got)
+		assert.Equal(t, test.want.members, got)
+	}
+}
+
+func TestUpdateMemberRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
+
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
+
+	type args struct {
+		actorID int
+		userID  int
+		role    domain.SpaceRole
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		want  error
+		calls []*gomock.Call
+	}{
+		{
+			name: "success owner promotes member",
+			args: args{actorID: 1, userID: 2, role: domain.SpaceRoleModerator},
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRoleMember, nil),
+				mockUserSpaceRepository.EXPECT().UpdateRole(gomock.Any(), 2, 10, domain.SpaceRoleModerator).Return(nil),
+			},
+		},
+		{
+			name: "error invalid role",
+			args: args{actorID: 1, userID: 2, role: domain.SpaceRoleOwner},
+			want: apperror.NewInvalidData("Role must be moderator or member", nil, "space_usecase.go:UpdateMemberRole"),
+		},
+		{
+			name: "error actor is not the owner",
+			args: args{actorID: 3, userID: 2, role: domain.SpaceRoleModerator},
+			want: apperror.NewForbidden("Only the owner can change member roles", nil, "space_usecase.go:requireRole"),
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 3, 10).Return(domain.SpaceRoleModerator, nil),
+			},
+		},
+		{
+			name: "error target is not a member",
+			args: args{actorID: 1, userID: 2, role: domain.SpaceRoleMember},
+			want: apperror.NewNotFound("Member not found", nil, "space_usecase.go:findMemberRole"),
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRole(""), nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := make([]interface{}, len(test.calls))
+			for i, c := range test.calls {
+				calls[i] = c
+			}
+			gomock.InOrder(calls...)
+
+			gotErr := spaceUseCase.UpdateMemberRole(context.Background(), test.args.actorID, 10, test.args.userID, test.args.role)
+
+			assert.Equal(t, test.want, gotErr)
+		})
+	}
+}
+
+func TestRemoveMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
+
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
+
+	tests := []struct {
+		name    string
+		actorID int
+		userID  int
+		want    error
+		calls   []*gomock.Call
+	}{
+		{
+			name:    "success moderator kicks member",
+			actorID: 2,
+			userID:  3,
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRoleModerator, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 3, 10).Return(domain.SpaceRoleMember, nil),
+				mockUserSpaceRepository.EXPECT().Update(gomock.Any(), 3, []int{10}, domain.RemoveUserFromSpace).Return(nil),
+			},
+		},
+		{
+			name:    "success owner kicks moderator",
+			actorID: 1,
+			userID:  2,
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRoleModerator, nil),
+				mockUserSpaceRepository.EXPECT().Update(gomock.Any(), 2, []int{10}, domain.RemoveUserFromSpace).Return(nil),
+			},
+		},
+		{
+			name:    "error moderator kicks moderator",
+			actorID: 2,
+			userID:  4,
+			want:    apperror.NewForbidden("You cannot remove this member", nil, "space_usecase.go:RemoveMember"),
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRoleModerator, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 4, 10).Return(domain.SpaceRoleModerator, nil),
+			},
+		},
+		{
+			name:    "error member cannot kick",
+			actorID: 3,
+			userID:  5,
+			want:    apperror.NewForbidden("Only owners and moderators can remove members", nil, "space_usecase.go:RemoveMember"),
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 3, 10).Return(domain.SpaceRoleMember, nil),
+			},
+		},
+		{
+			name:    "error removing yourself",
+			actorID: 1,
+			userID:  1,
+			want:    apperror.NewInvalidData("You cannot remove yourself from the space", nil, "space_usecase.go:RemoveMember"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := make([]interface{}, len(test.calls))
+			for i, c := range test.calls {
+				calls[i] = c
+			}
+			gomock.InOrder(calls...)
+
+			gotErr := spaceUseCase.RemoveMember(context.Background(), test.actorID, 10, test.userID)
+
+			assert.Equal(t, test.want, gotErr)
+		})
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
+
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
+
+	tests := []struct {
+		name       string
+		actorID    int
+		newOwnerID int
+		want       error
+		calls      []*gomock.Call
+	}{
+		{
+			name:       "success",
+			actorID:    1,
+			newOwnerID: 2,
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRoleModerator, nil),
+				mockUserSpaceRepository.EXPECT().TransferOwnership(gomock.Any(), 10, 1, 2).Return(nil),
+			},
+		},
+		{
+			name:       "error actor is not the owner",
+			actorID:    2,
+			newOwnerID: 3,
+			want:       apperror.NewForbidden("Only the owner can transfer ownership", nil, "space_usecase.go:requireRole"),
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRoleModerator, nil),
+			},
+		},
+		{
+			name:       "error new owner is not a member",
+			actorID:    1,
+			newOwnerID: 9,
+			want:       apperror.NewNotFound("Member not found", nil, "space_usecase.go:findMemberRole"),
+			calls: []*gomock.Call{
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 9, 10).Return(domain.SpaceRole(""), nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := make([]interface{}, len(test.calls))
+			for i, c := range test.calls {
+				calls[i] = c
+			}
+			gomock.InOrder(calls...)
+
+			gotErr := spaceUseCase.TransferOwnership(context.Background(), test.actorID, 10, test.newOwnerID)
+
+			assert.Equal(t, test.want, gotErr)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
+
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
+
+	newName := "Renamed Space"
+	archivedAt := time.Now()
+
+	givenSpace := func() *domain.Space {
+		return &domain.Space{ID: 10, Name: "Test Space", Description: "Test Description", CreatedBy: 1}
+	}
+
+	tests := []struct {
+		name    string
+		actorID int
+		want    error
+		calls   []*gomock.Call
+	}{
+		{
+			name:    "success owner renames space",
+			actorID: 1,
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace(), nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil),
+				mockSpaceRepository.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, space *domain.Space) error {
+					assert.Equal(t, newName, space.Name)
+					return nil
+				}),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, Name: newName, CreatedBy: 1}, nil),
+				mockUserRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1}, nil),
+				mockUserSpaceRepository.EXPECT().CountByRole(gomock.Any(), 10).Return(map[domain.SpaceRole]int{domain.SpaceRoleOwner: 1}, nil),
+				mockPostRepository.EXPECT().Count(gomock.Any(), gomock.Any()).Return(0, nil),
+			},
+		},
+		{
+			name:    "error name already taken",
+			actorID: 1,
+			want:    apperror.NewInvalidData("Space with this name already exists", nil, "space_usecase.go:ensureNameAvailable"),
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace(), nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 11, Name: newName}, nil),
+			},
+		},
+		{
+			name:    "error actor is not the owner",
+			actorID: 2,
+			want:    apperror.NewForbidden("Only the owner can edit the space", nil, "space_usecase.go:requireRole"),
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenSpace(), nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRoleModerator, nil),
+			},
+		},
+		{
+			name:    "error space is archived",
+			actorID: 1,
+			want:    apperror.NewForbidden("Archived spaces are read-only", nil, "space_authorizer.go:RequireWritable"),
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, Name: "Test Space", ArchivedAt: &archivedAt}, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := make([]interface{}, len(test.calls))
+			for i, c := range test.calls {
+				calls[i] = c
+			}
+			gomock.InOrder(calls...)
+
+			gotErr := func() error {
+				_, err := spaceUseCase.Update(context.Background(), test.actorID, 10, dto.UpdateSpaceDTO{Name: &newName})
+				return err
+			}()
+
+			assert.Equal(t, test.want, gotErr)
+		})
+	}
+}
+
+func TestArchive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
+
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
+
+	archivedAt := time.Now()
+
+	tests := []struct {
+		name     string
+		actorID  int
+		archived bool
+		want     error
+		calls    []*gomock.Call
+	}{
+		{
+			name:     "success owner archives space",
+			actorID:  1,
+			archived: true,
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, CreatedBy: 1}, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockSpaceRepository.EXPECT().SetArchived(gomock.Any(), 10, gomock.Not(gomock.Nil()), 1, gomock.Any()).Return(nil),
+			},
+		},
+		{
+			name:     "success owner unarchives space",
+			actorID:  1,
+			archived: false,
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, CreatedBy: 1, ArchivedAt: &archivedAt}, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockSpaceRepository.EXPECT().SetArchived(gomock.Any(), 10, gomock.Nil(), 1, gomock.Any()).Return(nil),
+			},
+		},
+		{
+			name:     "error moderator cannot archive",
+			actorID:  2,
+			archived: true,
+			want:     apperror.NewForbidden("Only the owner can archive the space", nil, "space_usecase.go:requireRole"),
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, CreatedBy: 1}, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 2, 10).Return(domain.SpaceRoleModerator, nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := make([]interface{}, len(test.calls))
+			for i, c := range test.calls {
+				calls[i] = c
+			}
+			gomock.InOrder(calls...)
+
+			gotErr := spaceUseCase.Archive(context.Background(), test.actorID, 10, test.archived)
+
+			assert.Equal(t, test.want, gotErr)
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSpaceRepository := mock.NewMockSpaceRepository(ctrl)
+	mockUserRepository := mock.NewMockUserRepository(ctrl)
+	mockUserSpaceRepository := mock.NewMockUserSpaceRepository(ctrl)
+	mockPostRepository := mock.NewMockPostRepository(ctrl)
+	mockCommentRepository := mock.NewMockCommentRepository(ctrl)
+	mockReactionRepository := mock.NewMockReactionRepository(ctrl)
+	mockNotificationRepository := mock.NewMockNotificationRepository(ctrl)
+
+	spaceUseCase := NewSpaceUsecase(mockSpaceRepository, mockUserRepository, mockUserSpaceRepository, mockPostRepository, mockCommentRepository, mockReactionRepository, mockNotificationRepository)
+
+	tests := []struct {
+		name    string
+		actorID int
+		want    error
+		calls   []*gomock.Call
+	}{
+		{
+			name:    "success removes mongo reactions and notifications",
+			actorID: 1,
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, CreatedBy: 1}, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockPostRepository.EXPECT().Search(gomock.Any(), gomock.Any()).Return([]*domain.Post{{ID: 5, SpaceID: 10}}, nil),
+				mockCommentRepository.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return([]*domain.CommentWithInfo{{Comment: &domain.Comment{ID: 7, PostID: 5}}}, nil),
+				mockSpaceRepository.EXPECT().Delete(gomock.Any(), 10).Return(nil),
+				mockReactionRepository.EXPECT().DeleteByEntities(gomock.Any(), domain.EntityTypePost, []int{5}).Return(nil),
+				mockNotificationRepository.EXPECT().DeleteByEntities(gomock.Any(), domain.EntityTypePost, []int{5}).Return(nil),
+				mockReactionRepository.EXPECT().DeleteByEntities(gomock.Any(), domain.EntityTypeComment, []int{7}).Return(nil),
+				mockNotificationRepository.EXPECT().DeleteByEntities(gomock.Any(), domain.EntityTypeComment, []int{7}).Return(nil),
+				mockNotificationRepository.EXPECT().DeleteByEntities(gomock.Any(), domain.EntityTypeSpace, []int{10}).Return(nil),
+			},
+		},
+		{
+			name:    "error member cannot delete",
+			actorID: 3,
+			want:    apperror.NewForbidden("Only the owner can delete the space", nil, "space_usecase.go:requireRole"),
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, CreatedBy: 1}, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 3, 10).Return(domain.SpaceRoleMember, nil),
+			},
+		},
+		{
+			name:    "error deleting space keeps mongo content",
+			actorID: 1,
+			want:    errors.New("unexpected error"),
+			calls: []*gomock.Call{
+				mockSpaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, CreatedBy: 1}, nil),
+				mockUserSpaceRepository.EXPECT().FindRole(gomock.Any(), 1, 10).Return(domain.SpaceRoleOwner, nil),
+				mockPostRepository.EXPECT().Search(gomock.Any(), gomock.Any()).Return([]*domain.Post{}, nil),
+				mockSpaceRepository.EXPECT().Delete(gomock.Any(), 10).Return(errors.New("unexpected error")),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := make([]interface{}, len(test.calls))
+			for i, c := range test.calls {
+				calls[i] = c
+			}
+			gomock.InOrder(calls...)
+
+			gotErr := spaceUseCase.Delete(context.Background(), test.actorID, 10)
+
+			assert.Equal(t, test.want, gotErr)
+		})
 	}
 }
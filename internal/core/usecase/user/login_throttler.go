@@ -0,0 +1,244 @@
+package user
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ThrottleConfig sets how failed logins are slowed down and locked out.
+// After FreeAttempts failures every new attempt must wait BaseDelay,
+// doubled on each failure up to MaxDelay. After LockoutThreshold failures
+// the key is locked for LockoutDuration.
+type ThrottleConfig struct {
+	FreeAttempts     int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// Window is how long a failure is remembered after the last one
+	Window time.Duration
+}
+
+var (
+	DefaultAccountThrottle = ThrottleConfig{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         30 * time.Second,
+		LockoutThreshold: 10,
+		LockoutDuration:  15 * time.Minute,
+		Window:           time.Hour,
+	}
+
+	// DefaultIPThrottle is looser because many users can share an address
+	DefaultIPThrottle = ThrottleConfig{
+		FreeAttempts:     10,
+		BaseDelay:        time.Second,
+		MaxDelay:         30 * time.Second,
+		LockoutThreshold: 50,
+		LockoutDuration:  15 * time.Minute,
+		Window:           time.Hour,
+	}
+)
+
+const (
+	maxTrackedAttempts = 10000
+	// maxTrackedIPsPerAccount bounds the addresses remembered for an unlock
+	maxTrackedIPsPerAccount = 100
+)
+
+type loginAttempts struct {
+	failures    int
+	lastFailure time.Time
+	nextAllowed time.Time
+	lockedUntil time.Time
+	// ips counts the failures each address made against an account, only set on account entries
+	ips map[string]int
+}
+
+// loginThrottler tracks failed logins in memory, per account and per IP
+type loginThrottler struct {
+	mu       sync.Mutex
+	accounts map[string]*loginAttempts
+	ips      map[string]*loginAttempts
+	account  ThrottleConfig
+	ip       ThrottleConfig
+	now      func() time.Time
+}
+
+func newLoginThrottler(account ThrottleConfig, ip ThrottleConfig) *loginThrottler {
+	return &loginThrottler{
+		accounts: make(map[string]*loginAttempts),
+		ips:      make(map[string]*loginAttempts),
+		account:  account,
+		ip:       ip,
+		now:      time.Now,
+	}
+}
+
+// Check returns how long the caller must wait before trying again, or zero
+// if the attempt is allowed
+func (t *loginThrottler) Check(email, ip string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	wait := t.waitFor(t.accounts[normalizeEmail(email)], now)
+	if ip != "" {
+		if ipWait := t.waitFor(t.ips[ip], now); ipWait > wait {
+			wait = ipWait
+		}
+	}
+
+	return wait
+}
+
+func (t *loginThrottler) RegisterFailure(email, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	attempts := t.record(t.accounts, normalizeEmail(email), t.account, now)
+	if ip != "" {
+		t.record(t.ips, ip, t.ip, now)
+		if attempts.ips == nil {
+			attempts.ips = make(map[string]int)
+		}
+		if _, ok := attempts.ips[ip]; ok || len(attempts.ips) < maxTrackedIPsPerAccount {
+			attempts.ips[ip]++
+		}
+	}
+}
+
+// RegisterSuccess clears the account failures. The IP counter is kept so a
+// single valid account cannot be used to reset a credential stuffing run.
+func (t *loginThrottler) RegisterSuccess(email string) {
+	t.Reset(email)
+}
+
+func (t *loginThrottler) Reset(email string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.accounts, normalizeEmail(email))
+}
+
+// Unlock clears the account failures and takes its failures out of the IPs
+// they came from, so an unlocked user behind one of them can log in right away
+// while the failures made there against other accounts still count
+func (t *loginThrottler) Unlock(email string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := normalizeEmail(email)
+	if attempts, ok := t.accounts[key]; ok {
+		for ip, failures := range attempts.ips {
+			t.forgive(ip, failures)
+		}
+	}
+	delete(t.accounts, key)
+}
+
+func (t *loginThrottler) forgive(ip string, failures int) {
+	attempts, ok := t.ips[ip]
+	if !ok {
+		return
+	}
+
+	attempts.failures -= failures
+	if attempts.failures <= 0 {
+		delete(t.ips, ip)
+		return
+	}
+
+	if attempts.failures < t.ip.LockoutThreshold {
+		attempts.lockedUntil = time.Time{}
+	}
+	attempts.nextAllowed = time.Time{}
+	if attempts.failures >= t.ip.FreeAttempts {
+		attempts.nextAllowed = attempts.lastFailure.Add(delayFor(attempts.failures, t.ip))
+	}
+}
+
+func (t *loginThrottler) waitFor(attempts *loginAttempts, now time.Time) time.Duration {
+	if attempts == nil {
+		return 0
+	}
+
+	if now.Before(attempts.lockedUntil) {
+		return attempts.lockedUntil.Sub(now)
+	}
+
+	if now.Before(attempts.nextAllowed) {
+		return attempts.nextAllowed.Sub(now)
+	}
+
+	return 0
+}
+
+func (t *loginThrottler) record(entries map[string]*loginAttempts, key string, config ThrottleConfig, now time.Time) *loginAttempts {
+	if len(entries) >= maxTrackedAttempts {
+		t.sweep(entries, config, now)
+	}
+
+	attempts, ok := entries[key]
+	if !ok || now.Sub(attempts.lastFailure) > config.Window {
+		attempts = &loginAttempts{}
+		entries[key] = attempts
+	}
+
+	attempts.failures++
+	attempts.lastFailure = now
+
+	if attempts.failures >= config.LockoutThreshold {
+		attempts.lockedUntil = now.Add(config.LockoutDuration)
+		return attempts
+	}
+
+	if attempts.failures >= config.FreeAttempts {
+		attempts.nextAllowed = now.Add(delayFor(attempts.failures, config))
+	}
+
+	return attempts
+}
+
+func delayFor(failures int, config ThrottleConfig) time.Duration {
+	exponent := float64(failures - config.FreeAttempts)
+	delay := time.Duration(float64(config.BaseDelay) * math.Pow(2, exponent))
+	if delay > config.MaxDelay || delay <= 0 {
+		delay = config.MaxDelay
+	}
+	return delay
+}
+
+// sweep drops the expired entries and, if that is not enough, the ones that
+// failed longest ago, so the map never grows past maxTrackedAttempts
+func (t *loginThrottler) sweep(entries map[string]*loginAttempts, config ThrottleConfig, now time.Time) {
+	for key, attempts := range entries {
+		if now.Sub(attempts.lastFailure) > config.Window && !now.Before(attempts.lockedUntil) {
+			delete(entries, key)
+		}
+	}
+
+	if len(entries) < maxTrackedAttempts {
+		return
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return entries[a].lastFailure.Compare(entries[b].lastFailure)
+	})
+
+	for _, key := range keys[:len(entries)-maxTrackedAttempts+1] {
+		delete(entries, key)
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,83 @@
+package user
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginThrottler(t *testing.T) {
+	config := ThrottleConfig{
+		FreeAttempts:     2,
+		BaseDelay:        time.Second,
+		MaxDelay:         4 * time.Second,
+		LockoutThreshold: 5,
+		LockoutDuration:  time.Minute,
+		Window:           time.Hour,
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	throttler := newLoginThrottler(config, config)
+	throttler.now = func() time.Time { return now }
+
+	throttler.RegisterFailure("user@test.com", "1.1.1.1")
+	assert.Equal(t, time.Duration(0), throttler.Check("user@test.com", "1.1.1.1"))
+
+	throttler.RegisterFailure("User@Test.com", "1.1.1.1")
+	assert.Equal(t, time.Second, throttler.Check("user@test.com", ""), "delay starts after the free attempts")
+
+	throttler.RegisterFailure("user@test.com", "1.1.1.1")
+	assert.Equal(t, 2*time.Second, throttler.Check("user@test.com", ""), "delay doubles on each failure")
+
+	throttler.RegisterFailure("user@test.com", "1.1.1.1")
+	throttler.RegisterFailure("user@test.com", "1.1.1.1")
+	assert.Equal(t, time.Minute, throttler.Check("user@test.com", ""), "account is locked after the threshold")
+	assert.Equal(t, time.Minute, throttler.Check("other@test.com", "1.1.1.1"), "the IP is locked too")
+
+	throttler.Reset("user@test.com")
+	assert.Equal(t, time.Duration(0), throttler.Check("user@test.com", ""))
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, time.Duration(0), throttler.Check("other@test.com", "1.1.1.1"), "lockout expires")
+
+	t.Run("unlock clears the IPs that failed against the account", func(t *testing.T) {
+		for i := 0; i < config.LockoutThreshold; i++ {
+			throttler.RegisterFailure("user@test.com", "2.2.2.2")
+		}
+		throttler.RegisterFailure("other@test.com", "3.3.3.3")
+		throttler.RegisterFailure("other@test.com", "3.3.3.3")
+
+		throttler.Unlock("user@test.com")
+
+		assert.Equal(t, time.Duration(0), throttler.Check("user@test.com", "2.2.2.2"))
+		assert.Equal(t, time.Second, throttler.Check("", "3.3.3.3"), "other IPs keep their counter")
+	})
+
+	t.Run("unlock keeps the failures made against other accounts", func(t *testing.T) {
+		for i := 0; i < config.LockoutThreshold; i++ {
+			throttler.RegisterFailure("user@test.com", "4.4.4.4")
+		}
+		throttler.RegisterFailure("other@test.com", "4.4.4.4")
+		throttler.RegisterFailure("another@test.com", "4.4.4.4")
+
+		throttler.Unlock("user@test.com")
+
+		assert.Equal(t, time.Duration(0), throttler.Check("user@test.com", ""))
+		assert.Equal(t, time.Second, throttler.Check("user@test.com", "4.4.4.4"), "the IP keeps the delay of its other failures")
+	})
+
+	t.Run("sweep evicts the oldest entries past the limit", func(t *testing.T) {
+		entries := make(map[string]*loginAttempts)
+		for i := range maxTrackedAttempts {
+			entries[fmt.Sprint(i)] = &loginAttempts{lastFailure: now.Add(time.Duration(i) * time.Second), lockedUntil: now.Add(time.Hour)}
+		}
+
+		throttler.sweep(entries, config, now)
+
+		assert.Len(t, entries, maxTrackedAttempts-1)
+		assert.NotContains(t, entries, "0")
+		assert.Contains(t, entries, "1")
+	})
+}
@@ -5,9 +5,15 @@ import (
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/domain/criteria"
 	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/authorization"
+	pghelpers "cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/helpers"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
+	"fmt"
+	"log"
+	"math"
 	"strconv"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -20,19 +26,67 @@ type UserUseCase interface {
 	Login(ctx context.Context, loginUser dto.LoginUser) (*domain.User, error)
 	Search(ctx context.Context, params dto.SearchUsersParams) (*dto.PaginatedUsersResponse, error)
 	UpdateUser(ctx context.Context, dto dto.UpdateUserDTO) error
+	SendEmailVerification(ctx context.Context, userID int) error
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, params dto.ResetPassword) error
+	ChangePassword(ctx context.Context, params dto.ChangePassword) error
+	ChangeEmail(ctx context.Context, params dto.ChangeEmail) error
+	ConfirmEmailChange(ctx context.Context, token string) error
+	UnlockUser(ctx context.Context, adminID int, userID int) error
 }
 
+// Config holds the account settings read from the environment
+type Config struct {
+	// RequireEmailVerification rejects logins of accounts that never verified their email
+	RequireEmailVerification bool
+	// AppURL is the frontend base URL used to build the links sent by email
+	AppURL string
+	// AccountThrottle and IPThrottle limit failed logins, zero values use the defaults
+	AccountThrottle ThrottleConfig
+	IPThrottle      ThrottleConfig
+}
+
+// dummyPasswordHash is compared against when the email is unknown, so both
+// failure paths take the same time
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
 type useCase struct {
-	userRepository      domain.UserRepository
-	spaceRepository     domain.SpaceRepository
-	userSpaceRepository domain.UserSpaceRepository
+	userRepository         domain.UserRepository
+	spaceRepository        domain.SpaceRepository
+	userSpaceRepository    domain.UserSpaceRepository
+	userTokenRepository    domain.UserTokenRepository
+	refreshTokenRepository domain.RefreshTokenRepository
+	mailer                 domain.Mailer
+	config                 Config
+	loginThrottler         *loginThrottler
 }
 
-func NewUserUsecase(userRepository domain.UserRepository, spaceRepository domain.SpaceRepository, userSpaceRepository domain.UserSpaceRepository) UserUseCase {
+func NewUserUsecase(
+	userRepository domain.UserRepository,
+	spaceRepository domain.SpaceRepository,
+	userSpaceRepository domain.UserSpaceRepository,
+	userTokenRepository domain.UserTokenRepository,
+	refreshTokenRepository domain.RefreshTokenRepository,
+	mailer domain.Mailer,
+	config Config,
+) UserUseCase {
+	if config.AccountThrottle.LockoutThreshold == 0 {
+		config.AccountThrottle = DefaultAccountThrottle
+	}
+	if config.IPThrottle.LockoutThreshold == 0 {
+		config.IPThrottle = DefaultIPThrottle
+	}
+
 	return &useCase{
-		userRepository:      userRepository,
-		spaceRepository:     spaceRepository,
-		userSpaceRepository: userSpaceRepository,
+		userRepository:         userRepository,
+		spaceRepository:        spaceRepository,
+		userSpaceRepository:    userSpaceRepository,
+		userTokenRepository:    userTokenRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		mailer:                 mailer,
+		config:                 config,
+		loginThrottler:         newLoginThrottler(config.AccountThrottle, config.IPThrottle),
 	}
 }
 
@@ -68,6 +122,11 @@ func (u *useCase) Create(ctx context.Context, user *domain.User) (*domain.User,
 		return nil, err
 	}
 
+	// The account exists already, a failed email can be resent later
+	if err := u.sendEmailVerification(ctx, user); err != nil {
+		log.Printf("Error sending verification email to user %d: %v", user.ID, err)
+	}
+
 	return user, nil
 }
 
@@ -155,6 +214,25 @@ func (u *useCase) Update(ctx context.Context, dto dto.UpdateUserSpacesDTO) error
 		if exists == nil {
 			return apperror.NewInvalidData("Space not found: "+strconv.Itoa(spaceID), nil, "user_usecase.go:Update")
 		}
+
+		if dto.Action == domain.AddUserToSpace {
+			if exists.IsPrivate() {
+				return apperror.NewForbidden("Private spaces can only be joined through an invitation or an approved join request", nil, "user_usecase.go:Update")
+			}
+			if err := authorization.RequireWritable(exists); err != nil {
+				return err
+			}
+		}
+
+		if dto.Action == domain.RemoveUserFromSpace {
+			role, err := u.userSpaceRepository.FindRole(ctx, user.ID, spaceID)
+			if err != nil {
+				return err
+			}
+			if role == domain.SpaceRoleOwner {
+				return apperror.NewInvalidData("Transfer ownership before leaving the space", nil, "user_usecase.go:Update")
+			}
+		}
 	}
 
 	if err := u.userSpaceRepository.Update(ctx, user.ID, dto.SpaceIDs, dto.Action); err != nil {
@@ -213,6 +291,11 @@ func (u *useCase) Search(ctx context.Context, params dto.SearchUsersParams) (*dt
 }
 
 func (u *useCase) Login(ctx context.Context, loginUser dto.LoginUser) (*domain.User, error) {
+	if wait := u.loginThrottler.Check(loginUser.Email, loginUser.IP); wait > 0 {
+		seconds := int(math.Ceil(wait.Seconds()))
+		return nil, apperror.NewThrottling(fmt.Sprintf("Too many failed login attempts, try again in %d seconds", seconds), nil, "user_usecase.go:Login")
+	}
+
 	user, err := u.userRepository.Find(ctx, &criteria.Criteria{
 		Filters: []criteria.Filter{
 			{
@@ -227,18 +310,47 @@ func (u *useCase) Login(ctx context.Context, loginUser dto.LoginUser) (*domain.U
 		return nil, err
 	}
 
-	if user == nil {
-		return nil, apperror.NewInvalidData("Invalid email", nil, "user_usecase.go:Login")
+	passwordHash := dummyPasswordHash
+	if user != nil {
+		passwordHash = []byte(user.Password)
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginUser.Password))
-	if err != nil {
-		return nil, apperror.NewInvalidData("Invalid password", nil, "user_usecase.go:Login")
+	err = bcrypt.CompareHashAndPassword(passwordHash, []byte(loginUser.Password))
+	if user == nil || err != nil {
+		u.loginThrottler.RegisterFailure(loginUser.Email, loginUser.IP)
+		return nil, apperror.NewInvalidData("Invalid email or password", nil, "user_usecase.go:Login")
+	}
+
+	u.loginThrottler.RegisterSuccess(loginUser.Email)
+
+	if u.config.RequireEmailVerification && user.EmailVerifiedAt == nil {
+		return nil, apperror.NewForbidden("Email address not verified", nil, "user_usecase.go:Login")
 	}
 
 	return user, nil
 }
 
+// UnlockUser clears the failed login attempts of an account and of the IPs they came from
+func (u *useCase) UnlockUser(ctx context.Context, adminID int, userID int) error {
+	admin, err := pghelpers.FindEntity(ctx, u.userRepository, "id", adminID, "User not found")
+	if err != nil {
+		return err
+	}
+
+	if !admin.IsAdmin {
+		return apperror.NewForbidden("Only administrators can unlock accounts", nil, "user_usecase.go:UnlockUser")
+	}
+
+	user, err := pghelpers.FindEntity(ctx, u.userRepository, "id", userID, "User not found")
+	if err != nil {
+		return err
+	}
+
+	u.loginThrottler.Unlock(user.Email)
+
+	return nil
+}
+
 func (u *useCase) UpdateUser(ctx context.Context, dto dto.UpdateUserDTO) error {
 	user, err := u.userRepository.Find(ctx, &criteria.Criteria{
 		Filters: []criteria.Filter{
@@ -275,3 +387,270 @@ func (u *useCase) UpdateUser(ctx context.Context, dto dto.UpdateUserDTO) error {
 
 	return nil
 }
+
+func (u *useCase) SendEmailVerification(ctx context.Context, userID int) error {
+	user, err := pghelpers.FindEntity(ctx, u.userRepository, "id", userID, "User not found")
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return apperror.NewInvalidData("Email already verified", nil, "user_usecase.go:SendEmailVerification")
+	}
+
+	return u.sendEmailVerification(ctx, user)
+}
+
+func (u *useCase) VerifyEmail(ctx context.Context, token string) error {
+	userToken, err := u.consumeUserToken(ctx, domain.UserTokenEmailVerification, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := pghelpers.FindEntity(ctx, u.userRepository, "id", userToken.UserID, "User not found")
+	if err != nil {
+		return err
+	}
+
+	now := helpers.GetTime()
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+
+	return u.userRepository.Update(ctx, user)
+}
+
+// ForgotPassword never reports whether the email is registered
+func (u *useCase) ForgotPassword(ctx context.Context, email string) error {
+	user, err := u.userRepository.Find(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("email", email, criteria.OperatorEqual).
+		Build())
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return nil
+	}
+
+	token, err := u.issueUserToken(ctx, user.ID, domain.UserTokenPasswordReset, "", domain.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	// A failed email is only logged, answering with an error would tell the email is registered
+	if err := u.mailer.Send(ctx, domain.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: "Use the following link to choose a new password. It expires in one hour.\n\n" +
+			u.config.AppURL + "/reset-password?token=" + token,
+	}); err != nil {
+		log.Printf("Error sending password reset email to user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password and revokes every open session of the user
+func (u *useCase) ResetPassword(ctx context.Context, params dto.ResetPassword) error {
+	userToken, err := u.consumeUserToken(ctx, domain.UserTokenPasswordReset, params.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := pghelpers.FindEntity(ctx, u.userRepository, "id", userToken.UserID, "User not found")
+	if err != nil {
+		return err
+	}
+
+	cryptedPassword, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return apperror.NewInvalidData("Failed to hash password", err, "user_usecase.go:ResetPassword")
+	}
+	user.Password = string(cryptedPassword)
+	user.UpdatedAt = helpers.GetTime()
+
+	if err := u.userRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return u.refreshTokenRepository.RevokeAllByUser(ctx, user.ID)
+}
+
+// ChangePassword keeps the session that made the change and revokes all the others.
+// Their access tokens are rejected from then on and their sockets are closed.
+func (u *useCase) ChangePassword(ctx context.Context, params dto.ChangePassword) error {
+	user, err := pghelpers.FindEntity(ctx, u.userRepository, "id", params.UserID, "User not found")
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(params.CurrentPassword)); err != nil {
+		return apperror.NewInvalidData("Current password is incorrect", nil, "user_usecase.go:ChangePassword")
+	}
+
+	cryptedPassword, err := bcrypt.GenerateFromPassword([]byte(params.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return apperror.NewInvalidData("Failed to hash password", err, "user_usecase.go:ChangePassword")
+	}
+	user.Password = string(cryptedPassword)
+	user.UpdatedAt = helpers.GetTime()
+
+	if err := u.userRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if params.SessionID == "" {
+		return u.refreshTokenRepository.RevokeAllByUser(ctx, user.ID)
+	}
+	return u.refreshTokenRepository.RevokeAllByUserExcept(ctx, user.ID, params.SessionID)
+}
+
+// ChangeEmail sends a confirmation link to the new address. The email is only
+// replaced once the link is used.
+func (u *useCase) ChangeEmail(ctx context.Context, params dto.ChangeEmail) error {
+	user, err := pghelpers.FindEntity(ctx, u.userRepository, "id", params.UserID, "User not found")
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(params.CurrentPassword)); err != nil {
+		return apperror.NewInvalidData("Current password is incorrect", nil, "user_usecase.go:ChangeEmail")
+	}
+
+	if params.Email == user.Email {
+		return apperror.NewInvalidData("New email must be different from the current one", nil, "user_usecase.go:ChangeEmail")
+	}
+
+	if err := u.ensureEmailAvailable(ctx, params.Email); err != nil {
+		return err
+	}
+
+	token, err := u.issueUserToken(ctx, user.ID, domain.UserTokenEmailChange, params.Email, domain.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return u.mailer.Send(ctx, domain.MailMessage{
+		To:      params.Email,
+		Subject: "Confirm your new email address",
+		Body: "Confirm that you want to use this address for your CPI Hub account:\n\n" +
+			u.config.AppURL + "/confirm-email?token=" + token,
+	})
+}
+
+func (u *useCase) ConfirmEmailChange(ctx context.Context, token string) error {
+	userToken, err := u.consumeUserToken(ctx, domain.UserTokenEmailChange, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := pghelpers.FindEntity(ctx, u.userRepository, "id", userToken.UserID, "User not found")
+	if err != nil {
+		return err
+	}
+
+	// Someone could have registered the address since the link was sent
+	if err := u.ensureEmailAvailable(ctx, userToken.Payload); err != nil {
+		return err
+	}
+
+	previousEmail := user.Email
+	now := helpers.GetTime()
+	user.Email = userToken.Payload
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+
+	if err := u.userRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if err := u.mailer.Send(ctx, domain.MailMessage{
+		To:      previousEmail,
+		Subject: "Your email address was changed",
+		Body:    "The email address of your CPI Hub account was changed to " + user.Email + ". If you did not request this, reset your password.",
+	}); err != nil {
+		log.Printf("Error notifying email change to user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+func (u *useCase) ensureEmailAvailable(ctx context.Context, email string) error {
+	existingUser, err := u.userRepository.Find(ctx, criteria.NewCriteriaBuilder().
+		WithFilter("email", email, criteria.OperatorEqual).
+		Build())
+	if err != nil {
+		return err
+	}
+
+	if existingUser != nil {
+		return apperror.NewInvalidData("User with this email already exists", nil, "user_usecase.go:ensureEmailAvailable")
+	}
+
+	return nil
+}
+
+func (u *useCase) sendEmailVerification(ctx context.Context, user *domain.User) error {
+	token, err := u.issueUserToken(ctx, user.ID, domain.UserTokenEmailVerification, "", domain.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return u.mailer.Send(ctx, domain.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: "Welcome to CPI Hub! Confirm your email address with the following link:\n\n" +
+			u.config.AppURL + "/verify-email?token=" + token,
+	})
+}
+
+// issueUserToken invalidates any pending token with the same purpose, so only
+// the most recent email link works
+func (u *useCase) issueUserToken(ctx context.Context, userID int, purpose domain.UserTokenPurpose, payload string, ttl time.Duration) (string, error) {
+	if err := u.userTokenRepository.InvalidateByUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	token, err := helpers.NewOpaqueToken()
+	if err != nil {
+		return "", apperror.NewInternalServer("Error generating token", err, "user_usecase.go:issueUserToken")
+	}
+
+	now := helpers.GetTime()
+	err = u.userTokenRepository.Create(ctx, &domain.UserToken{
+		ID:        helpers.NewULID(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: helpers.HashToken(token),
+		Payload:   payload,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (u *useCase) consumeUserToken(ctx context.Context, purpose domain.UserTokenPurpose, token string) (*domain.UserToken, error) {
+	userToken, err := u.userTokenRepository.FindByHash(ctx, purpose, helpers.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if userToken == nil || userToken.UsedAt != nil || helpers.GetTime().After(userToken.ExpiresAt) {
+		return nil, apperror.NewInvalidData("Invalid or expired token", nil, "user_usecase.go:consumeUserToken")
+	}
+
+	used, err := u.userTokenRepository.MarkUsed(ctx, userToken.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !used {
+		return nil, apperror.NewInvalidData("Invalid or expired token", nil, "user_usecase.go:consumeUserToken")
+	}
+
+	return userToken, nil
+}
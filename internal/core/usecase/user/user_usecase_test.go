@@ -0,0 +1,350 @@
+package user
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/core/domain/mock"
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/infrastructure/adapters/mailer"
+	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type userUseCaseMocks struct {
+	userRepository         *mock.MockUserRepository
+	spaceRepository        *mock.MockSpaceRepository
+	userSpaceRepository    *mock.MockUserSpaceRepository
+	userTokenRepository    *mock.MockUserTokenRepository
+	refreshTokenRepository *mock.MockRefreshTokenRepository
+	mailer                 *mailer.InMemoryMailer
+}
+
+func newTestUseCase(ctrl *gomock.Controller, config Config) (UserUseCase, userUseCaseMocks) {
+	mocks := userUseCaseMocks{
+		userRepository:         mock.NewMockUserRepository(ctrl),
+		spaceRepository:        mock.NewMockSpaceRepository(ctrl),
+		userSpaceRepository:    mock.NewMockUserSpaceRepository(ctrl),
+		userTokenRepository:    mock.NewMockUserTokenRepository(ctrl),
+		refreshTokenRepository: mock.NewMockRefreshTokenRepository(ctrl),
+		mailer:                 mailer.NewInMemoryMailer(),
+	}
+
+	useCase := NewUserUsecase(
+		mocks.userRepository,
+		mocks.spaceRepository,
+		mocks.userSpaceRepository,
+		mocks.userTokenRepository,
+		mocks.refreshTokenRepository,
+		mocks.mailer,
+		config,
+	)
+
+	return useCase, mocks
+}
+
+func TestLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	verifiedAt := helpers.GetTime()
+
+	unverifiedUser := &domain.User{ID: 1, Email: "test@test.com", Password: string(hashedPassword)}
+	verifiedUser := &domain.User{ID: 1, Email: "test@test.com", Password: string(hashedPassword), EmailVerifiedAt: &verifiedAt}
+
+	tests := []struct {
+		name    string
+		config  Config
+		user    *domain.User
+		wantErr error
+	}{
+		{
+			name:   "success without verification required",
+			config: Config{},
+			user:   unverifiedUser,
+		},
+		{
+			name:   "success verified user",
+			config: Config{RequireEmailVerification: true},
+			user:   verifiedUser,
+		},
+		{
+			name:    "error unverified user",
+			config:  Config{RequireEmailVerification: true},
+			user:    unverifiedUser,
+			wantErr: apperror.NewForbidden("Email address not verified", nil, "user_usecase.go:Login"),
+		},
+	}
+
+	for _, test := range tests {
+		useCase, mocks := newTestUseCase(ctrl, test.config)
+		mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(test.user, nil)
+
+		_, gotErr := useCase.Login(context.Background(), dto.LoginUser{Email: "test@test.com", Password: "secret"})
+
+		assert.Equal(t, test.wantErr, gotErr, test.name)
+	}
+
+	t.Run("same error for unknown email and wrong password", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		invalidCredentialsErr := apperror.NewInvalidData("Invalid email or password", nil, "user_usecase.go:Login")
+
+		mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil)
+		_, gotErr := useCase.Login(context.Background(), dto.LoginUser{Email: "missing@test.com", Password: "secret"})
+		assert.Equal(t, invalidCredentialsErr, gotErr)
+
+		mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(verifiedUser, nil)
+		_, gotErr = useCase.Login(context.Background(), dto.LoginUser{Email: "test@test.com", Password: "wrong"})
+		assert.Equal(t, invalidCredentialsErr, gotErr)
+	})
+
+	t.Run("locked account is rejected before checking the password", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{
+			AccountThrottle: ThrottleConfig{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Minute, LockoutThreshold: 1, LockoutDuration: time.Minute, Window: time.Hour},
+		})
+
+		mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(verifiedUser, nil)
+		_, _ = useCase.Login(context.Background(), dto.LoginUser{Email: "test@test.com", Password: "wrong"})
+
+		_, gotErr := useCase.Login(context.Background(), dto.LoginUser{Email: "test@test.com", Password: "secret"})
+		assert.Equal(t, apperror.NewThrottling("Too many failed login attempts, try again in 60 seconds", nil, "user_usecase.go:Login"), gotErr)
+	})
+}
+
+func TestUpdateSpaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("success joins public space", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		gomock.InOrder(
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1}, nil),
+			mocks.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, Visibility: domain.SpaceVisibilityPublic}, nil),
+			mocks.userSpaceRepository.EXPECT().Update(gomock.Any(), 1, []int{10}, domain.AddUserToSpace).Return(nil),
+		)
+
+		assert.Nil(t, useCase.Update(context.Background(), dto.UpdateUserSpacesDTO{UserID: 1, SpaceIDs: []int{10}, Action: domain.AddUserToSpace}))
+	})
+
+	t.Run("error private space needs an invitation", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		gomock.InOrder(
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1}, nil),
+			mocks.spaceRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.Space{ID: 10, Visibility: domain.SpaceVisibilityPrivate}, nil),
+		)
+
+		gotErr := useCase.Update(context.Background(), dto.UpdateUserSpacesDTO{UserID: 1, SpaceIDs: []int{10}, Action: domain.AddUserToSpace})
+		assert.Equal(t, apperror.NewForbidden("Private spaces can only be joined through an invitation or an approved join request", nil, "user_usecase.go:Update"), gotErr)
+	})
+}
+
+func TestUnlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("error caller is not admin", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1}, nil)
+
+		gotErr := useCase.UnlockUser(context.Background(), 1, 2)
+		assert.Equal(t, apperror.NewForbidden("Only administrators can unlock accounts", nil, "user_usecase.go:UnlockUser"), gotErr)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		gomock.InOrder(
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1, IsAdmin: true}, nil),
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 2, Email: "test@test.com"}, nil),
+		)
+
+		assert.Nil(t, useCase.UnlockUser(context.Background(), 1, 2))
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usedAt := helpers.GetTime()
+
+	validToken := &domain.UserToken{ID: "token-1", UserID: 1, ExpiresAt: helpers.GetTime().Add(time.Hour)}
+	usedToken := &domain.UserToken{ID: "token-1", UserID: 1, ExpiresAt: helpers.GetTime().Add(time.Hour), UsedAt: &usedAt}
+	expiredToken := &domain.UserToken{ID: "token-1", UserID: 1, ExpiresAt: helpers.GetTime().Add(-time.Hour)}
+
+	invalidTokenErr := apperror.NewInvalidData("Invalid or expired token", nil, "user_usecase.go:consumeUserToken")
+
+	t.Run("success", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+
+		gomock.InOrder(
+			mocks.userTokenRepository.EXPECT().FindByHash(gomock.Any(), domain.UserTokenEmailVerification, helpers.HashToken("token")).Return(validToken, nil),
+			mocks.userTokenRepository.EXPECT().MarkUsed(gomock.Any(), "token-1").Return(true, nil),
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1}, nil),
+			mocks.userRepository.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user *domain.User) error {
+				assert.NotNil(t, user.EmailVerifiedAt)
+				return nil
+			}),
+		)
+
+		assert.Nil(t, useCase.VerifyEmail(context.Background(), "token"))
+	})
+
+	t.Run("error token already used", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		mocks.userTokenRepository.EXPECT().FindByHash(gomock.Any(), gomock.Any(), gomock.Any()).Return(usedToken, nil)
+
+		assert.Equal(t, invalidTokenErr, useCase.VerifyEmail(context.Background(), "token"))
+	})
+
+	t.Run("error token expired", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		mocks.userTokenRepository.EXPECT().FindByHash(gomock.Any(), gomock.Any(), gomock.Any()).Return(expiredToken, nil)
+
+		assert.Equal(t, invalidTokenErr, useCase.VerifyEmail(context.Background(), "token"))
+	})
+
+	t.Run("error token consumed concurrently", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		mocks.userTokenRepository.EXPECT().FindByHash(gomock.Any(), gomock.Any(), gomock.Any()).Return(validToken, nil)
+		mocks.userTokenRepository.EXPECT().MarkUsed(gomock.Any(), "token-1").Return(false, nil)
+
+		assert.Equal(t, invalidTokenErr, useCase.VerifyEmail(context.Background(), "token"))
+	})
+}
+
+func TestForgotPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("unknown email sends nothing", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		assert.Nil(t, useCase.ForgotPassword(context.Background(), "missing@test.com"))
+		assert.Empty(t, mocks.mailer.Sent())
+	})
+
+	t.Run("sends reset link", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{AppURL: "http://app"})
+
+		gomock.InOrder(
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1, Email: "test@test.com"}, nil),
+			mocks.userTokenRepository.EXPECT().InvalidateByUser(gomock.Any(), 1, domain.UserTokenPasswordReset).Return(nil),
+			mocks.userTokenRepository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil),
+		)
+
+		assert.Nil(t, useCase.ForgotPassword(context.Background(), "test@test.com"))
+
+		sent := mocks.mailer.Sent()
+		if assert.Len(t, sent, 1) {
+			assert.Equal(t, "test@test.com", sent[0].To)
+			assert.Contains(t, sent[0].Body, "http://app/reset-password?token=")
+		}
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	useCase, mocks := newTestUseCase(ctrl, Config{})
+
+	gomock.InOrder(
+		mocks.userTokenRepository.EXPECT().FindByHash(gomock.Any(), domain.UserTokenPasswordReset, gomock.Any()).
+			Return(&domain.UserToken{ID: "token-1", UserID: 1, ExpiresAt: helpers.GetTime().Add(time.Hour)}, nil),
+		mocks.userTokenRepository.EXPECT().MarkUsed(gomock.Any(), "token-1").Return(true, nil),
+		mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1, Password: "old"}, nil),
+		mocks.userRepository.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user *domain.User) error {
+			assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password")))
+			return nil
+		}),
+		mocks.refreshTokenRepository.EXPECT().RevokeAllByUser(gomock.Any(), 1).Return(nil),
+	)
+
+	assert.Nil(t, useCase.ResetPassword(context.Background(), dto.ResetPassword{Token: "token", Password: "new-password"}))
+}
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+
+	t.Run("success keeps current session", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+
+		gomock.InOrder(
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1, Password: string(hashedPassword)}, nil),
+			mocks.userRepository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil),
+			mocks.refreshTokenRepository.EXPECT().RevokeAllByUserExcept(gomock.Any(), 1, "family-1").Return(nil),
+		)
+
+		assert.Nil(t, useCase.ChangePassword(context.Background(), dto.ChangePassword{
+			UserID:          1,
+			SessionID:       "family-1",
+			CurrentPassword: "secret",
+			NewPassword:     "new-secret",
+		}))
+	})
+
+	t.Run("error wrong current password", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+		mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1, Password: string(hashedPassword)}, nil)
+
+		gotErr := useCase.ChangePassword(context.Background(), dto.ChangePassword{
+			UserID:          1,
+			CurrentPassword: "wrong",
+			NewPassword:     "new-secret",
+		})
+
+		assert.Equal(t, apperror.NewInvalidData("Current password is incorrect", nil, "user_usecase.go:ChangePassword"), gotErr)
+	})
+}
+
+func TestChangeEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	givenUser := &domain.User{ID: 1, Email: "old@test.com", Password: string(hashedPassword)}
+
+	t.Run("success sends confirmation to new address", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{AppURL: "http://app"})
+
+		gomock.InOrder(
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil),
+			mocks.userTokenRepository.EXPECT().InvalidateByUser(gomock.Any(), 1, domain.UserTokenEmailChange).Return(nil),
+			mocks.userTokenRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token *domain.UserToken) error {
+				assert.Equal(t, "new@test.com", token.Payload)
+				return nil
+			}),
+		)
+
+		assert.Nil(t, useCase.ChangeEmail(context.Background(), dto.ChangeEmail{UserID: 1, CurrentPassword: "secret", Email: "new@test.com"}))
+
+		sent := mocks.mailer.Sent()
+		if assert.Len(t, sent, 1) {
+			assert.Equal(t, "new@test.com", sent[0].To)
+		}
+	})
+
+	t.Run("error email already taken", func(t *testing.T) {
+		useCase, mocks := newTestUseCase(ctrl, Config{})
+
+		gomock.InOrder(
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(givenUser, nil),
+			mocks.userRepository.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&domain.User{ID: 2}, nil),
+		)
+
+		gotErr := useCase.ChangeEmail(context.Background(), dto.ChangeEmail{UserID: 1, CurrentPassword: "secret", Email: "new@test.com"})
+
+		assert.Equal(t, apperror.NewInvalidData("User with this email already exists", nil, "user_usecase.go:ensureEmailAvailable"), gotErr)
+	})
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"log"
+	"sync"
+)
+
+// LogMailer prints emails instead of sending them, for local development
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, message domain.MailMessage) error {
+	log.Printf("Email to %s | %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// InMemoryMailer keeps sent emails so tests can assert on them
+type InMemoryMailer struct {
+	mu   sync.Mutex
+	sent []domain.MailMessage
+}
+
+func NewInMemoryMailer() *InMemoryMailer {
+	return &InMemoryMailer{}
+}
+
+func (m *InMemoryMailer) Send(ctx context.Context, message domain.MailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+func (m *InMemoryMailer) Sent() []domain.MailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]domain.MailMessage(nil), m.sent...)
+}
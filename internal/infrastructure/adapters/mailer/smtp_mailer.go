@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+}
+
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{config: config}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message domain.MailMessage) error {
+	addr := fmt.Sprintf("%s:%d", m.config.Host, m.config.Port)
+
+	var auth smtp.Auth
+	if m.config.User != "" {
+		auth = smtp.PlainAuth("", m.config.User, m.config.Password, m.config.Host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + m.config.From,
+		"To: " + message.To,
+		"Subject: " + message.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		message.Body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(addr, auth, m.config.From, []string{message.To}, []byte(body)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
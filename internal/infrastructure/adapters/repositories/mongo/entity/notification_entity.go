@@ -9,6 +9,21 @@ type Notification struct {
 	EntityID   int                `bson:"entity_id"`
 	PostID     *int               `bson:"post_id,omitempty"`
 	UserID     int                `bson:"user_id"`
+	Action     string             `bson:"action,omitempty"`
+	ActorID    int                `bson:"actor_id,omitempty"`
+	ActorIDs   []int              `bson:"actor_ids,omitempty"`
+	ActorCount int                `bson:"actor_count,omitempty"`
+	GroupKey   string             `bson:"group_key,omitempty"`
 	Read       bool               `bson:"read"`
 	CreatedAt  primitive.DateTime `bson:"created_at"`
+	UpdatedAt  primitive.DateTime `bson:"updated_at,omitempty"`
+	// EmailDigest notifications wait for the digest email until DigestedAt is set
+	EmailDigest bool                `bson:"email_digest,omitempty"`
+	DigestedAt  *primitive.DateTime `bson:"digested_at,omitempty"`
+	DigestOnly  bool                `bson:"digest_only,omitempty"`
+
+	// Outbox state, written together with the notification
+	DeliveryAttempts int                 `bson:"delivery_attempts"`
+	NextDeliveryAt   *primitive.DateTime `bson:"next_delivery_at"`
+	DeliveredAt      *primitive.DateTime `bson:"delivered_at,omitempty"`
 }
@@ -0,0 +1,25 @@
+package entity
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type NotificationPreferences struct {
+	ID            primitive.ObjectID              `bson:"_id,omitempty"`
+	UserID        int                             `bson:"user_id"`
+	Types         map[string]NotificationChannels `bson:"types,omitempty"`
+	MutedSpaceIDs []int                           `bson:"muted_space_ids,omitempty"`
+	MutedPostIDs  []int                           `bson:"muted_post_ids,omitempty"`
+	QuietHours    *QuietHours                     `bson:"quiet_hours,omitempty"`
+	UpdatedAt     primitive.DateTime              `bson:"updated_at"`
+}
+
+type NotificationChannels struct {
+	InApp       bool `bson:"in_app"`
+	Push        bool `bson:"push"`
+	EmailDigest bool `bson:"email_digest"`
+}
+
+type QuietHours struct {
+	Start    int    `bson:"start"`
+	End      int    `bson:"end"`
+	Timezone string `bson:"timezone"`
+}
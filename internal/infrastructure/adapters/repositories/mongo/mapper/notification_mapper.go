@@ -21,15 +21,28 @@ func ToMongoNotification(notification *domain.Notification) *entity.Notification
 		createdAt = primitive.NewDateTimeFromTime(notification.CreatedAt)
 	}
 
+	updatedAt := createdAt
+	if !notification.UpdatedAt.IsZero() {
+		updatedAt = primitive.NewDateTimeFromTime(notification.UpdatedAt)
+	}
+
 	return &entity.Notification{
-		ID:         oid,
-		Type:       string(notification.Type),
-		EntityType: string(notification.EntityType),
-		EntityID:   notification.EntityID,
-		PostID:     notification.PostID,
-		UserID:     notification.UserID,
-		Read:       notification.Read,
-		CreatedAt:  createdAt,
+		ID:          oid,
+		Type:        string(notification.Type),
+		EntityType:  string(notification.EntityType),
+		EntityID:    notification.EntityID,
+		PostID:      notification.PostID,
+		UserID:      notification.UserID,
+		Action:      string(notification.Action),
+		ActorID:     notification.ActorID,
+		ActorIDs:    notification.ActorIDs,
+		ActorCount:  notification.ActorCount,
+		GroupKey:    notification.GroupKey(),
+		Read:        notification.Read,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		EmailDigest: notification.EmailDigest,
+		DigestOnly:  notification.DigestOnly,
 	}
 }
 
@@ -44,14 +57,46 @@ func ToDomainNotification(notificationEntity *entity.Notification) *domain.Notif
 		createdAt = notificationEntity.CreatedAt.Time()
 	}
 
+	updatedAt := createdAt
+	if notificationEntity.UpdatedAt != 0 {
+		updatedAt = notificationEntity.UpdatedAt.Time()
+	}
+
+	// Notifications saved before grouping only have the actor_id
+	actorIDs, actorCount := notificationEntity.ActorIDs, notificationEntity.ActorCount
+	if len(actorIDs) == 0 && notificationEntity.ActorID != 0 {
+		actorIDs, actorCount = []int{notificationEntity.ActorID}, 1
+	}
+
 	return &domain.Notification{
-		ID:         idStr,
-		Type:       domain.NotificationType(notificationEntity.Type),
-		EntityType: domain.EntityType(notificationEntity.EntityType),
-		EntityID:   notificationEntity.EntityID,
-		PostID:     notificationEntity.PostID,
-		UserID:     notificationEntity.UserID,
-		Read:       notificationEntity.Read,
-		CreatedAt:  createdAt,
+		ID:          idStr,
+		Type:        domain.NotificationType(notificationEntity.Type),
+		EntityType:  domain.EntityType(notificationEntity.EntityType),
+		EntityID:    notificationEntity.EntityID,
+		PostID:      notificationEntity.PostID,
+		UserID:      notificationEntity.UserID,
+		Action:      domain.ActionType(notificationEntity.Action),
+		ActorID:     notificationEntity.ActorID,
+		ActorIDs:    actorIDs,
+		ActorCount:  actorCount,
+		Read:        notificationEntity.Read,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		EmailDigest: notificationEntity.EmailDigest,
+		DigestOnly:  notificationEntity.DigestOnly,
+	}
+}
+
+func ToDomainNotificationDelivery(notificationEntity *entity.Notification) *domain.NotificationDelivery {
+	var nextAttemptAt *time.Time
+	if notificationEntity.NextDeliveryAt != nil {
+		next := notificationEntity.NextDeliveryAt.Time()
+		nextAttemptAt = &next
+	}
+
+	return &domain.NotificationDelivery{
+		Notification:  ToDomainNotification(notificationEntity),
+		Attempts:      notificationEntity.DeliveryAttempts,
+		NextAttemptAt: nextAttemptAt,
 	}
 }
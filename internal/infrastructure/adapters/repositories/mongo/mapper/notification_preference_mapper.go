@@ -0,0 +1,59 @@
+package mapper
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/mongo/entity"
+)
+
+func ToMongoNotificationChannels(types map[domain.NotificationType]domain.NotificationChannels) map[string]entity.NotificationChannels {
+	channels := make(map[string]entity.NotificationChannels, len(types))
+	for notificationType, typeChannels := range types {
+		channels[string(notificationType)] = entity.NotificationChannels{
+			InApp:       typeChannels.InApp,
+			Push:        typeChannels.Push,
+			EmailDigest: typeChannels.EmailDigest,
+		}
+	}
+	return channels
+}
+
+func ToMongoQuietHours(quietHours *domain.QuietHours) *entity.QuietHours {
+	if quietHours == nil {
+		return nil
+	}
+
+	return &entity.QuietHours{
+		Start:    quietHours.Start,
+		End:      quietHours.End,
+		Timezone: quietHours.Timezone,
+	}
+}
+
+func ToDomainNotificationPreferences(preferencesEntity *entity.NotificationPreferences) *domain.NotificationPreferences {
+	preferences := domain.NewNotificationPreferences(preferencesEntity.UserID)
+
+	for notificationType, channels := range preferencesEntity.Types {
+		preferences.Types[domain.NotificationType(notificationType)] = domain.NotificationChannels{
+			InApp:       channels.InApp,
+			Push:        channels.Push,
+			EmailDigest: channels.EmailDigest,
+		}
+	}
+
+	if preferencesEntity.QuietHours != nil {
+		preferences.QuietHours = &domain.QuietHours{
+			Start:    preferencesEntity.QuietHours.Start,
+			End:      preferencesEntity.QuietHours.End,
+			Timezone: preferencesEntity.QuietHours.Timezone,
+		}
+	}
+
+	preferences.MutedSpaceIDs = preferencesEntity.MutedSpaceIDs
+	preferences.MutedPostIDs = preferencesEntity.MutedPostIDs
+
+	if preferencesEntity.UpdatedAt != 0 {
+		preferences.UpdatedAt = preferencesEntity.UpdatedAt.Time()
+	}
+
+	return preferences
+}
@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/mongo/entity"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/mongo/mapper"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotificationPreferenceRepository keeps one document per user, users who never
+// changed their preferences have none
+type NotificationPreferenceRepository struct {
+	db *mongo.Database
+}
+
+func NewNotificationPreferenceRepository(db *mongo.Database) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{
+		db: db,
+	}
+}
+
+func (r *NotificationPreferenceRepository) Find(ctx context.Context, userID int) (*domain.NotificationPreferences, error) {
+	var preferencesEntity entity.NotificationPreferences
+
+	err := r.db.Collection("notification_preferences").FindOne(ctx, bson.M{"user_id": userID}).Decode(&preferencesEntity)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification preferences: %w", err)
+	}
+
+	return mapper.ToDomainNotificationPreferences(&preferencesEntity), nil
+}
+
+func (r *NotificationPreferenceRepository) FindByUserIDs(ctx context.Context, userIDs []int) ([]*domain.NotificationPreferences, error) {
+	if len(userIDs) == 0 {
+		return []*domain.NotificationPreferences{}, nil
+	}
+
+	cursor, err := r.db.Collection("notification_preferences").Find(ctx, bson.M{"user_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification preferences: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	preferences := []*domain.NotificationPreferences{}
+	for cursor.Next(ctx) {
+		var preferencesEntity entity.NotificationPreferences
+		if err := cursor.Decode(&preferencesEntity); err != nil {
+			return nil, fmt.Errorf("failed to decode notification preferences: %w", err)
+		}
+		preferences = append(preferences, mapper.ToDomainNotificationPreferences(&preferencesEntity))
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return preferences, nil
+}
+
+// Save replaces the channels and quiet hours, the mutes are kept
+func (r *NotificationPreferenceRepository) Save(ctx context.Context, preferences *domain.NotificationPreferences) error {
+	set := bson.M{
+		"types":      mapper.ToMongoNotificationChannels(preferences.Types),
+		"updated_at": primitive.NewDateTimeFromTime(preferences.UpdatedAt),
+	}
+	update := bson.M{"$set": set}
+
+	if quietHours := mapper.ToMongoQuietHours(preferences.QuietHours); quietHours != nil {
+		set["quiet_hours"] = quietHours
+	} else {
+		update["$unset"] = bson.M{"quiet_hours": ""}
+	}
+
+	_, err := r.db.Collection("notification_preferences").UpdateOne(ctx, bson.M{"user_id": preferences.UserID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationPreferenceRepository) Delete(ctx context.Context, userID int) error {
+	if _, err := r.db.Collection("notification_preferences").DeleteOne(ctx, bson.M{"user_id": userID}); err != nil {
+		return fmt.Errorf("failed to delete notification preferences: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationPreferenceRepository) Mute(ctx context.Context, userID int, entityType domain.EntityType, entityID int) error {
+	field, err := mutedField(entityType)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$addToSet": bson.M{field: entityID},
+		"$set":      bson.M{"updated_at": primitive.NewDateTimeFromTime(time.Now())},
+	}
+
+	_, err = r.db.Collection("notification_preferences").UpdateOne(ctx, bson.M{"user_id": userID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to mute %s: %w", entityType, err)
+	}
+
+	return nil
+}
+
+func (r *NotificationPreferenceRepository) Unmute(ctx context.Context, userID int, entityType domain.EntityType, entityID int) (bool, error) {
+	field, err := mutedField(entityType)
+	if err != nil {
+		return false, err
+	}
+
+	update := bson.M{
+		"$pull": bson.M{field: entityID},
+		"$set":  bson.M{"updated_at": primitive.NewDateTimeFromTime(time.Now())},
+	}
+
+	result, err := r.db.Collection("notification_preferences").UpdateOne(ctx, bson.M{"user_id": userID, field: entityID}, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to unmute %s: %w", entityType, err)
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+func mutedField(entityType domain.EntityType) (string, error) {
+	switch entityType {
+	case domain.EntityTypeSpace:
+		return "muted_space_ids", nil
+	case domain.EntityTypePost:
+		return "muted_post_ids", nil
+	}
+	return "", fmt.Errorf("%s cannot be muted", entityType)
+}
@@ -5,7 +5,10 @@ import (
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/infrastructure/adapters/repositories/mongo/entity"
 	"cpi-hub-api/internal/infrastructure/adapters/repositories/mongo/mapper"
+	"cpi-hub-api/pkg/apperror"
+	"errors"
 	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -23,8 +26,36 @@ func NewNotificationRepository(db *mongo.Database) *NotificationRepository {
 	}
 }
 
+// EnsureIndexes creates the indexes the repository relies on. The unique one keeps a single
+// unread notification per group, so two saveGrouped upserts cannot both insert it.
+func (r *NotificationRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.db.Collection("notifications").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "group_key", Value: 1}},
+		Options: options.Index().
+			SetName("unread_group").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"read": false, "group_key": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create notification indexes: %w", err)
+	}
+
+	return nil
+}
+
+// actorSampleProjection only reads the most recent actors of a grouped notification
+var actorSampleProjection = bson.M{"actor_ids": bson.M{"$slice": domain.NotificationActorSample}}
+
+// SaveNotification groups the notification with the unread one of the same group. Digest
+// only notifications are never unread and are saved on their own.
 func (r *NotificationRepository) SaveNotification(ctx context.Context, notification *domain.Notification) error {
+	if groupKey := notification.GroupKey(); groupKey != "" && notification.ActorID != 0 && !notification.DigestOnly {
+		return r.saveGrouped(ctx, notification, groupKey)
+	}
+
 	notificationEntity := mapper.ToMongoNotification(notification)
+	// The pending delivery lives in the same document, so both are written atomically
+	notificationEntity.NextDeliveryAt, notificationEntity.DeliveredAt = deliveryState(notification)
 
 	collection := r.db.Collection("notifications")
 
@@ -40,15 +71,115 @@ func (r *NotificationRepository) SaveNotification(ctx context.Context, notificat
 	return nil
 }
 
+// SaveNotifications writes a fan-out with a single InsertMany, grouped notifications must
+// go through SaveNotification
+func (r *NotificationRepository) SaveNotifications(ctx context.Context, notifications []*domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(notifications))
+	for i, notification := range notifications {
+		notificationEntity := mapper.ToMongoNotification(notification)
+		notificationEntity.NextDeliveryAt, notificationEntity.DeliveredAt = deliveryState(notification)
+		documents[i] = notificationEntity
+	}
+
+	res, err := r.db.Collection("notifications").InsertMany(ctx, documents)
+	if err != nil {
+		return fmt.Errorf("failed to save notifications: %w", err)
+	}
+
+	for i, insertedID := range res.InsertedIDs {
+		if oid, ok := insertedID.(primitive.ObjectID); ok {
+			notifications[i].ID = oid.Hex()
+		}
+	}
+
+	return nil
+}
+
+// saveGrouped folds the notification into the unread one of the same group, or creates it.
+// The actor moves to the front of actor_ids, and the delivery and the digest are pending again.
+func (r *NotificationRepository) saveGrouped(ctx context.Context, notification *domain.Notification, groupKey string) error {
+	now := primitive.NewDateTimeFromTime(notification.CreatedAt)
+	actorID := notification.ActorID
+
+	var deliveredAt interface{} = "$$REMOVE"
+	nextDeliveryAt, skipped := deliveryState(notification)
+	if skipped != nil {
+		deliveredAt = skipped
+	}
+
+	otherActors := bson.M{"$filter": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$actor_ids", bson.A{}}},
+		"cond":  bson.M{"$ne": bson.A{"$$this", actorID}},
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"type":              string(notification.Type),
+			"entity_type":       string(notification.EntityType),
+			"entity_id":         notification.EntityID,
+			"post_id":           notification.PostID,
+			"action":            string(notification.Action),
+			"actor_id":          actorID,
+			"actor_ids":         bson.M{"$concatArrays": bson.A{bson.A{actorID}, otherActors}},
+			"created_at":        bson.M{"$ifNull": bson.A{"$created_at", now}},
+			"updated_at":        now,
+			"email_digest":      notification.EmailDigest,
+			"digested_at":       "$$REMOVE",
+			"delivery_attempts": 0,
+			"next_delivery_at":  nextDeliveryAt,
+			"delivered_at":      deliveredAt,
+		}}},
+		{{Key: "$set", Value: bson.M{"actor_count": bson.M{"$size": "$actor_ids"}}}},
+	}
+
+	filter := bson.M{"user_id": notification.UserID, "group_key": groupKey, "read": false}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After).
+		SetProjection(actorSampleProjection)
+
+	var notificationEntity entity.Notification
+	err := r.db.Collection("notifications").FindOneAndUpdate(ctx, filter, pipeline, opts).Decode(&notificationEntity)
+	// A concurrent upsert inserted the group first, the retry updates it
+	if mongo.IsDuplicateKeyError(err) {
+		err = r.db.Collection("notifications").FindOneAndUpdate(ctx, filter, pipeline, opts).Decode(&notificationEntity)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save grouped notification: %w", err)
+	}
+
+	*notification = *mapper.ToDomainNotification(&notificationEntity)
+
+	return nil
+}
+
+// deliveryState returns when to push the notification. When the user turned push off
+// there is nothing to deliver and it is saved as delivered.
+func deliveryState(notification *domain.Notification) (nextDeliveryAt *primitive.DateTime, deliveredAt *primitive.DateTime) {
+	if notification.DeliverAt == nil {
+		skipped := primitive.NewDateTimeFromTime(notification.CreatedAt)
+		return nil, &skipped
+	}
+
+	next := primitive.NewDateTimeFromTime(*notification.DeliverAt)
+	return &next, nil
+}
+
 func (r *NotificationRepository) GetUserNotifications(ctx context.Context, userID int, limit, offset int) ([]*domain.Notification, error) {
 	collection := r.db.Collection("notifications")
 
-	filter := bson.M{"user_id": userID}
+	filter := bson.M{"user_id": userID, "digest_only": bson.M{"$ne": true}}
 
+	// Grouped notifications move up when a new actor joins them
 	opts := options.Find().
-		SetSort(bson.M{"created_at": -1}).
+		SetSort(bson.D{{Key: "updated_at", Value: -1}, {Key: "created_at", Value: -1}}).
 		SetLimit(int64(limit)).
-		SetSkip(int64(offset))
+		SetSkip(int64(offset)).
+		SetProjection(actorSampleProjection)
 
 	cursor, err := collection.Find(ctx, filter, opts)
 	if err != nil {
@@ -75,7 +206,76 @@ func (r *NotificationRepository) GetUserNotifications(ctx context.Context, userI
 	return notifications, nil
 }
 
-func (r *NotificationRepository) MarkAsRead(ctx context.Context, notificationID string) error {
+// RetractActor takes the actor out of the recipient's group one notification at a time,
+// the same actor can be in a read notification and in the unread one that came after it
+func (r *NotificationRepository) RetractActor(ctx context.Context, target *domain.Notification, actorID int) ([]*domain.NotificationRetraction, error) {
+	collection := r.db.Collection("notifications")
+
+	// Notifications saved before grouping only have the actor_id
+	remainingActors := bson.M{"$filter": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$actor_ids", bson.A{"$actor_id"}}},
+		"cond":  bson.M{"$ne": bson.A{"$$this", actorID}},
+	}}
+	latestActor := bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{bson.M{"$size": "$actor_ids"}, 0}},
+		bson.M{"$arrayElemAt": bson.A{"$actor_ids", 0}},
+		"$$REMOVE",
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"actor_ids": remainingActors}}},
+		{{Key: "$set", Value: bson.M{"actor_count": bson.M{"$size": "$actor_ids"}, "actor_id": latestActor}}},
+	}
+
+	// Only the recipient's notifications are touched, the ones saved before grouping have
+	// no group_key and are matched by their entity and actor instead
+	filter := bson.M{
+		"user_id": target.UserID,
+		"$or": bson.A{
+			bson.M{"group_key": target.GroupKey(), "actor_ids": actorID},
+			bson.M{
+				"group_key":   bson.M{"$exists": false},
+				"type":        string(target.Type),
+				"entity_type": string(target.EntityType),
+				"entity_id":   target.EntityID,
+				"action":      bson.M{"$in": bson.A{string(target.Action), nil}},
+				"actor_id":    actorID,
+			},
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(actorSampleProjection)
+
+	var retractions []*domain.NotificationRetraction
+	for {
+		var notificationEntity entity.Notification
+		err := collection.FindOneAndUpdate(ctx, filter, pipeline, opts).Decode(&notificationEntity)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to retract notification actor: %w", err)
+		}
+
+		retraction := &domain.NotificationRetraction{Notification: mapper.ToDomainNotification(&notificationEntity)}
+
+		// Another actor may have joined in the meantime, then the notification stays
+		if notificationEntity.ActorCount == 0 {
+			res, err := collection.DeleteOne(ctx, bson.M{"_id": notificationEntity.ID, "actor_count": 0})
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete retracted notification: %w", err)
+			}
+			retraction.Deleted = res.DeletedCount > 0
+		}
+
+		retractions = append(retractions, retraction)
+	}
+
+	return retractions, nil
+}
+
+func (r *NotificationRepository) MarkAsRead(ctx context.Context, userID int, notificationID string) error {
 	oid, err := primitive.ObjectIDFromHex(notificationID)
 	if err != nil {
 		return fmt.Errorf("invalid notification ID: %w", err)
@@ -86,13 +286,13 @@ func (r *NotificationRepository) MarkAsRead(ctx context.Context, notificationID
 		"$set": bson.M{"read": true},
 	}
 
-	result, err := collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": oid, "user_id": userID}, update)
 	if err != nil {
 		return fmt.Errorf("failed to mark notification as read: %w", err)
 	}
 
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("notification not found")
+		return apperror.NewNotFound("Notification not found", nil, "notification_repository.go:MarkAsRead")
 	}
 
 	return nil
@@ -124,3 +324,203 @@ func (r *NotificationRepository) GetUnreadCount(ctx context.Context, userID int)
 
 	return int(count), nil
 }
+
+func (r *NotificationRepository) DeleteByEntities(ctx context.Context, entityType domain.EntityType, entityIDs []int) error {
+	if len(entityIDs) == 0 {
+		return nil
+	}
+
+	filter := bson.M{"entity_type": string(entityType), "entity_id": bson.M{"$in": entityIDs}}
+	if entityType == domain.EntityTypePost {
+		filter = bson.M{"$or": []bson.M{filter, {"post_id": bson.M{"$in": entityIDs}}}}
+	}
+
+	if _, err := r.db.Collection("notifications").DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete notifications: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.NotificationDelivery, error) {
+	collection := r.db.Collection("notifications")
+
+	filter := bson.M{
+		"delivered_at":     nil,
+		"next_delivery_at": bson.M{"$lte": primitive.NewDateTimeFromTime(now)},
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"next_delivery_at": 1}).
+		SetLimit(int64(limit)).
+		SetProjection(actorSampleProjection)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find due deliveries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []*domain.NotificationDelivery
+	for cursor.Next(ctx) {
+		var notificationEntity entity.Notification
+
+		if err := cursor.Decode(&notificationEntity); err != nil {
+			return nil, fmt.Errorf("failed to decode notification: %w", err)
+		}
+
+		deliveries = append(deliveries, mapper.ToDomainNotificationDelivery(&notificationEntity))
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *NotificationRepository) ScheduleRetry(ctx context.Context, delivery *domain.NotificationDelivery, nextAttemptAt *time.Time) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(delivery.Notification.ID)
+	if err != nil {
+		return false, fmt.Errorf("invalid notification ID: %w", err)
+	}
+
+	var nextDeliveryAt *primitive.DateTime
+	if nextAttemptAt != nil {
+		next := primitive.NewDateTimeFromTime(*nextAttemptAt)
+		nextDeliveryAt = &next
+	}
+
+	filter := bson.M{"_id": oid, "delivered_at": nil, "delivery_attempts": delivery.Attempts}
+	update := bson.M{
+		"$set": bson.M{"delivery_attempts": delivery.Attempts + 1, "next_delivery_at": nextDeliveryAt},
+	}
+
+	result, err := r.db.Collection("notifications").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to schedule notification delivery: %w", err)
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *NotificationRepository) MarkDelivered(ctx context.Context, userID int, notificationID string) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(notificationID)
+	if err != nil {
+		return false, fmt.Errorf("invalid notification ID: %w", err)
+	}
+
+	filter := bson.M{"_id": oid, "user_id": userID, "delivered_at": nil}
+	update := bson.M{
+		"$set": bson.M{"delivered_at": primitive.NewDateTimeFromTime(time.Now()), "next_delivery_at": nil},
+	}
+
+	result, err := r.db.Collection("notifications").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification as delivered: %w", err)
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *NotificationRepository) RescheduleUser(ctx context.Context, userID int, nextAttemptAt time.Time) error {
+	// Only deliveries already attempted, pushes held back by quiet hours keep waiting
+	filter := bson.M{"user_id": userID, "delivered_at": nil, "delivery_attempts": bson.M{"$gte": 1}}
+	update := bson.M{
+		"$set": bson.M{"delivery_attempts": 0, "next_delivery_at": primitive.NewDateTimeFromTime(nextAttemptAt)},
+	}
+
+	if _, err := r.db.Collection("notifications").UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to reschedule notification deliveries: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) FindDigestUserIDs(ctx context.Context, afterUserID int, limit int) ([]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"email_digest": true, "digested_at": nil, "user_id": bson.M{"$gt": afterUserID}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$user_id"}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := r.db.Collection("notifications").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find digest users: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var userIDs []int
+	for cursor.Next(ctx) {
+		var group struct {
+			UserID int `bson:"_id"`
+		}
+
+		if err := cursor.Decode(&group); err != nil {
+			return nil, fmt.Errorf("failed to decode digest user: %w", err)
+		}
+
+		userIDs = append(userIDs, group.UserID)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+func (r *NotificationRepository) FindDigest(ctx context.Context, userID int, limit int) ([]*domain.Notification, error) {
+	filter := bson.M{"user_id": userID, "email_digest": true, "digested_at": nil}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}, {Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit)).
+		SetProjection(actorSampleProjection)
+
+	cursor, err := r.db.Collection("notifications").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find digest notifications: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var notifications []*domain.Notification
+	for cursor.Next(ctx) {
+		var notificationEntity entity.Notification
+
+		if err := cursor.Decode(&notificationEntity); err != nil {
+			return nil, fmt.Errorf("failed to decode notification: %w", err)
+		}
+
+		notifications = append(notifications, mapper.ToDomainNotification(&notificationEntity))
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) MarkDigested(ctx context.Context, userID int, notificationIDs []string) error {
+	oids := make([]primitive.ObjectID, 0, len(notificationIDs))
+	for _, notificationID := range notificationIDs {
+		oid, err := primitive.ObjectIDFromHex(notificationID)
+		if err != nil {
+			return fmt.Errorf("invalid notification ID: %w", err)
+		}
+		oids = append(oids, oid)
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": oids}, "user_id": userID}
+	update := bson.M{
+		"$set": bson.M{"digested_at": primitive.NewDateTimeFromTime(time.Now())},
+	}
+
+	if _, err := r.db.Collection("notifications").UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to mark notifications as digested: %w", err)
+	}
+
+	return nil
+}
@@ -57,6 +57,24 @@ func (r *ReactionRepository) FindReaction(ctx context.Context, criteria *criteri
 	return mapper.ToDomainReaction(&reactionEntity), nil
 }
 
+func (r *ReactionRepository) FindReactionByID(ctx context.Context, reactionID string) (*domain.Reaction, error) {
+	oid, err := primitive.ObjectIDFromHex(reactionID)
+	if err != nil {
+		return nil, nil
+	}
+
+	var reactionEntity entity.Reaction
+	err = r.db.Collection("reactions").FindOne(ctx, bson.M{"_id": oid}).Decode(&reactionEntity)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find reaction: %w", err)
+	}
+
+	return mapper.ToDomainReaction(&reactionEntity), nil
+}
+
 func (r *ReactionRepository) DeleteReaction(ctx context.Context, reactionID string) error {
 	oid, err := primitive.ObjectIDFromHex(reactionID)
 	if err != nil {
@@ -103,3 +121,16 @@ func (r *ReactionRepository) CountReactions(ctx context.Context, criteria *crite
 
 	return int(count), nil
 }
+
+func (r *ReactionRepository) DeleteByEntities(ctx context.Context, entityType domain.EntityType, entityIDs []int) error {
+	if len(entityIDs) == 0 {
+		return nil
+	}
+
+	filter := bson.M{"entity_type": string(entityType), "entity_id": bson.M{"$in": entityIDs}}
+	if _, err := r.db.Collection("reactions").DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete reactions: %w", err)
+	}
+
+	return nil
+}
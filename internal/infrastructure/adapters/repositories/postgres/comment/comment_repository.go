@@ -184,15 +184,35 @@ func (c *CommentRepository) findWithInfoByField(ctx context.Context, whereClause
 }
 
 func (c *CommentRepository) buildQueryWithAliases(criteria *criteria.Criteria) (string, []interface{}) {
-	return mapper.ToPostgreSQLQueryWithAlias(criteria, "c")
+	return mapper.ToPostgreSQLQueryWithAlias(withPostColumns(criteria), "c")
+}
+
+// withPostColumns points the space_id filters to the joined post, comments have no space of their own
+func withPostColumns(c *criteria.Criteria) *criteria.Criteria {
+	mapped := *c
+	mapped.Filters = mapPostColumns(c.Filters)
+	mapped.RequiredFilters = mapPostColumns(c.RequiredFilters)
+	return &mapped
+}
+
+func mapPostColumns(filters []criteria.Filter) []criteria.Filter {
+	mapped := make([]criteria.Filter, len(filters))
+	for i, f := range filters {
+		if f.Field == "space_id" {
+			f.Field = "p.space_id"
+		}
+		mapped[i] = f
+	}
+	return mapped
 }
 
 func (c *CommentRepository) Count(ctx context.Context, criteria *criteria.Criteria) (int, error) {
-	query, params := mapper.ToPostgreSQLCountQuery(criteria)
+	query, params := mapper.ToPostgreSQLQueryWithAliasAndOrderByAndPagination(withPostColumns(criteria), "c", false, false)
 
 	countQuery := `
 		SELECT COUNT(*)
-		FROM comments
+		FROM comments c
+		INNER JOIN posts p ON c.post_id = p.id
 	` + " " + query
 
 	var count int
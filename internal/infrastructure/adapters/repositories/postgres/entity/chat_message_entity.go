@@ -3,12 +3,15 @@ package entity
 import "time"
 
 type ChatMessageEntity struct {
-	ID        string    `db:"id"`
-	Content   string    `db:"content"`
-	UserID    int       `db:"user_id"`
-	Username  string    `db:"username"`
-	SpaceID   int       `db:"space_id"`
-	Timestamp time.Time `db:"timestamp"`
+	ID        string     `db:"id"`
+	Content   string     `db:"content"`
+	UserID    int        `db:"user_id"`
+	Username  string     `db:"username"`
+	SpaceID   int        `db:"space_id"`
+	Timestamp time.Time  `db:"timestamp"`
+	ReplyToID *string    `db:"reply_to_id"`
+	EditedAt  *time.Time `db:"edited_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
 }
 
 type ChatMessageEntityWithUser struct {
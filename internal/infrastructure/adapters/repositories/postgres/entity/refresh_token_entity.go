@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type RefreshTokenEntity struct {
+	ID         string     `db:"id"`
+	UserID     int        `db:"user_id"`
+	FamilyID   string     `db:"family_id"`
+	TokenHash  string     `db:"token_hash"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	ReplacedBy *string    `db:"replaced_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
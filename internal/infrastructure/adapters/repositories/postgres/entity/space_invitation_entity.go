@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+type SpaceInvitationEntity struct {
+	ID        string     `db:"id"`
+	SpaceID   int        `db:"space_id"`
+	InviterID int        `db:"inviter_id"`
+	InviteeID *int       `db:"invitee_id"`
+	TokenHash *string    `db:"token_hash"`
+	MaxUses   int        `db:"max_uses"`
+	Uses      int        `db:"uses"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type JoinRequestEntity struct {
+	ID         string     `db:"id"`
+	SpaceID    int        `db:"space_id"`
+	UserID     int        `db:"user_id"`
+	Status     string     `db:"status"`
+	Message    string     `db:"message"`
+	ReviewedBy *int       `db:"reviewed_by"`
+	ReviewedAt *time.Time `db:"reviewed_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
@@ -3,12 +3,15 @@ package entity
 import "time"
 
 type UserEntity struct {
-	ID        int       `db:"id"`
-	Name      string    `db:"name"`
-	LastName  string    `db:"last_name"`
-	Email     string    `db:"email"`
-	Password  string    `db:"password"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
-	Image     string    `db:"image"`
+	ID              int        `db:"id"`
+	Name            string     `db:"name"`
+	LastName        string     `db:"last_name"`
+	Email           string     `db:"email"`
+	Password        string     `db:"password"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+	Image           string     `db:"image"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	IsAdmin         bool       `db:"is_admin"`
+	LastSeenAt      *time.Time `db:"last_seen_at"`
 }
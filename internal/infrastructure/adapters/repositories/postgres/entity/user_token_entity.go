@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type UserTokenEntity struct {
+	ID        string     `db:"id"`
+	UserID    int        `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	TokenHash string     `db:"token_hash"`
+	Payload   string     `db:"payload"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -1,7 +1,9 @@
 package events
 
 import (
+	"context"
 	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
 	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/mapper"
 	"database/sql"
 )
@@ -20,8 +22,8 @@ func (r *EventsRepository) SaveMessage(message *domain.ChatMessage) error {
 	chatEntity := mapper.ToPostgresChatMessage(message)
 
 	query := `
-		INSERT INTO chat_messages (id, content, user_id, username, space_id, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO chat_messages (id, content, user_id, username, space_id, timestamp, reply_to_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	_, err := r.db.Exec(query,
@@ -31,7 +33,70 @@ func (r *EventsRepository) SaveMessage(message *domain.ChatMessage) error {
 		chatEntity.Username,
 		chatEntity.SpaceID,
 		chatEntity.Timestamp,
+		chatEntity.ReplyToID,
 	)
 
 	return err
 }
+
+func (r *EventsRepository) FindMessage(ctx context.Context, id string) (*domain.ChatMessage, error) {
+	var chatEntity entity.ChatMessageEntity
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, content, user_id, username, space_id, timestamp, reply_to_id, edited_at, deleted_at
+		FROM chat_messages WHERE id = $1`,
+		id,
+	).Scan(
+		&chatEntity.ID,
+		&chatEntity.Content,
+		&chatEntity.UserID,
+		&chatEntity.Username,
+		&chatEntity.SpaceID,
+		&chatEntity.Timestamp,
+		&chatEntity.ReplyToID,
+		&chatEntity.EditedAt,
+		&chatEntity.DeletedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return mapper.ToDomainChatMessage(&chatEntity), nil
+}
+
+// UpdateMessage guarda el contenido y las marcas de edición y borrado
+func (r *EventsRepository) UpdateMessage(ctx context.Context, message *domain.ChatMessage) error {
+	chatEntity := mapper.ToPostgresChatMessage(message)
+
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE chat_messages SET content = $1, edited_at = $2, deleted_at = $3 WHERE id = $4`,
+		chatEntity.Content, chatEntity.EditedAt, chatEntity.DeletedAt, chatEntity.ID,
+	)
+
+	return err
+}
+
+// MarkRead guarda el último mensaje leído, un mensaje anterior al ya guardado no lo cambia
+func (r *EventsRepository) MarkRead(ctx context.Context, receipt *domain.ReadReceipt) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO chat_read_receipts (user_id, space_id, last_read_message_id, read_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, space_id) DO UPDATE
+		SET last_read_message_id = EXCLUDED.last_read_message_id, read_at = EXCLUDED.read_at
+		WHERE chat_read_receipts.last_read_message_id COLLATE "C" < EXCLUDED.last_read_message_id COLLATE "C"`,
+		receipt.UserID, receipt.SpaceID, receipt.MessageID, receipt.ReadAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
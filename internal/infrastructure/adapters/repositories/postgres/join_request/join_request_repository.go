@@ -0,0 +1,116 @@
+package join_request
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/mapper"
+	"cpi-hub-api/pkg/helpers"
+	"database/sql"
+)
+
+const selectJoinRequest = `SELECT id, space_id, user_id, status, message, reviewed_by, reviewed_at, created_at
+	FROM space_join_requests`
+
+type JoinRequestRepository struct {
+	db *sql.DB
+}
+
+func NewJoinRequestRepository(db *sql.DB) *JoinRequestRepository {
+	return &JoinRequestRepository{db: db}
+}
+
+func (r *JoinRequestRepository) Create(ctx context.Context, request *domain.JoinRequest) error {
+	requestEntity := mapper.ToPostgreJoinRequest(request)
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO space_join_requests (id, space_id, user_id, status, message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		requestEntity.ID, requestEntity.SpaceID, requestEntity.UserID, requestEntity.Status, requestEntity.Message, requestEntity.CreatedAt,
+	)
+	return err
+}
+
+func (r *JoinRequestRepository) FindByID(ctx context.Context, id string) (*domain.JoinRequest, error) {
+	return r.findOne(ctx, selectJoinRequest+` WHERE id = $1`, id)
+}
+
+// FindPending returns the pending request of the user for the space, nil when there is none
+func (r *JoinRequestRepository) FindPending(ctx context.Context, spaceID int, userID int) (*domain.JoinRequest, error) {
+	return r.findOne(ctx,
+		selectJoinRequest+` WHERE space_id = $1 AND user_id = $2 AND status = $3`,
+		spaceID, userID, string(domain.JoinRequestPending),
+	)
+}
+
+func (r *JoinRequestRepository) FindPendingBySpaceID(ctx context.Context, spaceID int) ([]*domain.JoinRequest, error) {
+	rows, err := r.db.QueryContext(ctx,
+		selectJoinRequest+` WHERE space_id = $1 AND status = $2 ORDER BY created_at`,
+		spaceID, string(domain.JoinRequestPending),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*domain.JoinRequest{}
+	for rows.Next() {
+		var requestEntity entity.JoinRequestEntity
+		if err := scanJoinRequest(rows, &requestEntity); err != nil {
+			return nil, err
+		}
+		requests = append(requests, mapper.ToDomainJoinRequest(&requestEntity))
+	}
+
+	return requests, rows.Err()
+}
+
+// Review resolves a pending request, returning false if it was already reviewed
+func (r *JoinRequestRepository) Review(ctx context.Context, id string, status domain.JoinRequestStatus, reviewerID int) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE space_join_requests SET status = $1, reviewed_by = $2, reviewed_at = $3
+		WHERE id = $4 AND status = $5`,
+		string(status), reviewerID, helpers.GetTime(), id, string(domain.JoinRequestPending),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *JoinRequestRepository) findOne(ctx context.Context, query string, args ...interface{}) (*domain.JoinRequest, error) {
+	var requestEntity entity.JoinRequestEntity
+
+	err := scanJoinRequest(r.db.QueryRowContext(ctx, query, args...), &requestEntity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return mapper.ToDomainJoinRequest(&requestEntity), nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJoinRequest(row scanner, requestEntity *entity.JoinRequestEntity) error {
+	return row.Scan(
+		&requestEntity.ID,
+		&requestEntity.SpaceID,
+		&requestEntity.UserID,
+		&requestEntity.Status,
+		&requestEntity.Message,
+		&requestEntity.ReviewedBy,
+		&requestEntity.ReviewedAt,
+		&requestEntity.CreatedAt,
+	)
+}
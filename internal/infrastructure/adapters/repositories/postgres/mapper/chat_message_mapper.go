@@ -13,6 +13,9 @@ func ToPostgresChatMessage(chatMessage *domain.ChatMessage) *entity.ChatMessageE
 		Username:  chatMessage.Username,
 		SpaceID:   chatMessage.SpaceID,
 		Timestamp: chatMessage.Timestamp,
+		ReplyToID: chatMessage.ReplyToID,
+		EditedAt:  chatMessage.EditedAt,
+		DeletedAt: chatMessage.DeletedAt,
 	}
 }
 
@@ -24,6 +27,9 @@ func ToDomainChatMessage(chatEntity *entity.ChatMessageEntity) *domain.ChatMessa
 		Username:  chatEntity.Username,
 		SpaceID:   chatEntity.SpaceID,
 		Timestamp: chatEntity.Timestamp,
+		ReplyToID: chatEntity.ReplyToID,
+		EditedAt:  chatEntity.EditedAt,
+		DeletedAt: chatEntity.DeletedAt,
 	}
 }
 
@@ -36,5 +42,8 @@ func ToDomainChatMessageWithUser(chatEntity *entity.ChatMessageEntityWithUser) *
 		SpaceID:   chatEntity.SpaceID,
 		Timestamp: chatEntity.Timestamp,
 		Image:     chatEntity.Image,
+		ReplyToID: chatEntity.ReplyToID,
+		EditedAt:  chatEntity.EditedAt,
+		DeletedAt: chatEntity.DeletedAt,
 	}
 }
@@ -24,36 +24,7 @@ func ToPostgreSQLQueryWithOrderBy(c *criteria.Criteria, includeOrderBy bool) (st
 }
 
 func ToPostgreSQLQueryWithOrderByAndPagination(c *criteria.Criteria, includeOrderBy bool, includePagination bool) (string, []interface{}) {
-	var whereParts []string
-	var params []interface{}
-	paramIndex := 1
-
-	for _, f := range c.Filters {
-		clause, clauseParams := buildFilterClause(f, paramIndex)
-		if clause != "" {
-			whereParts = append(whereParts, clause)
-			params = append(params, clauseParams...)
-			paramIndex += len(clauseParams)
-		}
-	}
-
-	query := ""
-	if len(whereParts) > 0 {
-		logicalOp := " AND "
-		if c.LogicalOperator == criteria.LogicalOperatorOr {
-			logicalOp = " OR "
-		}
-
-		if len(whereParts) > 1 {
-			if c.LogicalOperator == criteria.LogicalOperatorOr || len(whereParts) > 2 {
-				query += " WHERE (" + strings.Join(whereParts, logicalOp) + ")"
-			} else {
-				query += " WHERE " + strings.Join(whereParts, logicalOp)
-			}
-		} else {
-			query += " WHERE " + whereParts[0]
-		}
-	}
+	query, params := buildWhereClause(c, buildFilterClause)
 
 	if includeOrderBy && c.Sort.Field != "" {
 		query += fmt.Sprintf(" ORDER BY %s %s", c.Sort.Field, c.Sort.SortDirection)
@@ -72,20 +43,49 @@ func ToPostgreSQLQueryWithAlias(c *criteria.Criteria, tableAlias string) (string
 }
 
 func ToPostgreSQLQueryWithAliasAndOrderByAndPagination(c *criteria.Criteria, tableAlias string, includeOrderBy bool, includePagination bool) (string, []interface{}) {
-	var whereParts []string
+	query, params := buildWhereClause(c, func(f criteria.Filter, paramIndex int) (string, []interface{}) {
+		return buildFilterClauseWithAlias(f, tableAlias, paramIndex)
+	})
+
+	if includeOrderBy && c.Sort.Field != "" {
+		orderField := c.Sort.Field
+		if tableAlias != "" {
+			orderField = tableAlias + "." + c.Sort.Field
+		}
+		query += fmt.Sprintf(" ORDER BY %s %s", orderField, c.Sort.SortDirection)
+	}
+
+	if includePagination && c.Pagination.PageSize > 0 {
+		offset := (c.Pagination.Page - 1) * c.Pagination.PageSize
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", c.Pagination.PageSize, offset)
+	}
+
+	return query, params
+}
+
+// buildWhereClause joins the filters with the logical operator of the criteria, the required
+// filters are always added with AND so an OR search cannot escape them
+func buildWhereClause(c *criteria.Criteria, buildClause func(criteria.Filter, int) (string, []interface{})) (string, []interface{}) {
 	var params []interface{}
 	paramIndex := 1
 
-	for _, f := range c.Filters {
-		clause, clauseParams := buildFilterClauseWithAlias(f, tableAlias, paramIndex)
-		if clause != "" {
-			whereParts = append(whereParts, clause)
-			params = append(params, clauseParams...)
-			paramIndex += len(clauseParams)
+	buildParts := func(filters []criteria.Filter) []string {
+		var parts []string
+		for _, f := range filters {
+			clause, clauseParams := buildClause(f, paramIndex)
+			if clause != "" {
+				parts = append(parts, clause)
+				params = append(params, clauseParams...)
+				paramIndex += len(clauseParams)
+			}
 		}
+		return parts
 	}
 
-	query := ""
+	whereParts := buildParts(c.Filters)
+	requiredParts := buildParts(c.RequiredFilters)
+
+	condition := ""
 	if len(whereParts) > 0 {
 		logicalOp := " AND "
 		if c.LogicalOperator == criteria.LogicalOperatorOr {
@@ -94,35 +94,34 @@ func ToPostgreSQLQueryWithAliasAndOrderByAndPagination(c *criteria.Criteria, tab
 
 		if len(whereParts) > 1 {
 			if c.LogicalOperator == criteria.LogicalOperatorOr || len(whereParts) > 2 {
-				query += " WHERE (" + strings.Join(whereParts, logicalOp) + ")"
+				condition = "(" + strings.Join(whereParts, logicalOp) + ")"
 			} else {
-				query += " WHERE " + strings.Join(whereParts, logicalOp)
+				condition = strings.Join(whereParts, logicalOp)
 			}
 		} else {
-			query += " WHERE " + whereParts[0]
+			condition = whereParts[0]
 		}
 	}
 
-	if includeOrderBy && c.Sort.Field != "" {
-		orderField := c.Sort.Field
-		if tableAlias != "" {
-			orderField = tableAlias + "." + c.Sort.Field
+	if len(requiredParts) > 0 {
+		if condition != "" {
+			requiredParts = append([]string{condition}, requiredParts...)
 		}
-		query += fmt.Sprintf(" ORDER BY %s %s", orderField, c.Sort.SortDirection)
+		condition = strings.Join(requiredParts, " AND ")
 	}
 
-	if includePagination && c.Pagination.PageSize > 0 {
-		offset := (c.Pagination.Page - 1) * c.Pagination.PageSize
-		query += fmt.Sprintf(" LIMIT %d OFFSET %d", c.Pagination.PageSize, offset)
+	if condition == "" {
+		return "", params
 	}
 
-	return query, params
+	return " WHERE " + condition, params
 }
 
 func buildFilterClauseWithAlias(filter criteria.Filter, tableAlias string, startIndex int) (string, []interface{}) {
 	var params []interface{}
 	fieldName := filter.Field
-	if tableAlias != "" {
+	// fields that already name their table, like p.space_id, are left as they are
+	if tableAlias != "" && !strings.Contains(filter.Field, ".") {
 		fieldName = tableAlias + "." + filter.Field
 	}
 
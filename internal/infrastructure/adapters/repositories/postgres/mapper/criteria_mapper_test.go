@@ -94,6 +94,55 @@ func TestToPostgreSQLQuery(t *testing.T) {
 	}
 }
 
+func TestToPostgreSQLQueryWithRequiredFilters(t *testing.T) {
+	tests := []struct {
+		name           string
+		criteria       *criteria.Criteria
+		expectedQuery  string
+		expectedParams []interface{}
+	}{
+		{
+			name: "Required filter is added with AND to an OR search",
+			criteria: criteria.NewCriteriaBuilder().
+				WithFilter("title", "%go%", criteria.OperatorILike).
+				WithFilter("content", "%go%", criteria.OperatorILike).
+				WithLogicalOperator(criteria.LogicalOperatorOr).
+				WithRequiredFilter("space_id", []interface{}{3, 4}, criteria.OperatorNotIn).
+				Build(),
+			expectedQuery:  " WHERE (title ILIKE $1 OR content ILIKE $2) AND space_id NOT IN ($3, $4)",
+			expectedParams: []interface{}{"%go%", "%go%", 3, 4},
+		},
+		{
+			name: "Only required filters",
+			criteria: criteria.NewCriteriaBuilder().
+				WithRequiredFilter("space_id", []interface{}{3}, criteria.OperatorNotIn).
+				Build(),
+			expectedQuery:  " WHERE space_id NOT IN ($1)",
+			expectedParams: []interface{}{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, params := ToPostgreSQLCountQuery(tt.criteria)
+
+			if query != tt.expectedQuery {
+				t.Errorf("Expected query '%s', got '%s'", tt.expectedQuery, query)
+			}
+
+			if len(params) != len(tt.expectedParams) {
+				t.Errorf("Expected %d params, got %d", len(tt.expectedParams), len(params))
+			}
+
+			for i, param := range params {
+				if i < len(tt.expectedParams) && param != tt.expectedParams[i] {
+					t.Errorf("Expected param[%d] = %v, got %v", i, tt.expectedParams[i], param)
+				}
+			}
+		})
+	}
+}
+
 func TestBuildOrderBy(t *testing.T) {
 	tests := []struct {
 		name     string
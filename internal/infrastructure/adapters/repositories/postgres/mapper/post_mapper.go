@@ -16,6 +16,7 @@ func ToPostgresPost(post *domain.Post) *entity.PostEntity {
 		UpdatedAt: post.UpdatedAt,
 		UpdatedBy: post.UpdatedBy,
 		SpaceID:   post.SpaceID,
+		PinnedAt:  post.PinnedAt,
 	}
 }
 
@@ -30,5 +31,6 @@ func ToDomainPost(postEntity *entity.PostEntity) *domain.Post {
 		UpdatedAt: postEntity.UpdatedAt,
 		UpdatedBy: postEntity.UpdatedBy,
 		SpaceID:   postEntity.SpaceID,
+		PinnedAt:  postEntity.PinnedAt,
 	}
 }
@@ -0,0 +1,32 @@
+package mapper
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
+)
+
+func ToPostgreRefreshToken(token *domain.RefreshToken) *entity.RefreshTokenEntity {
+	return &entity.RefreshTokenEntity{
+		ID:         token.ID,
+		UserID:     token.UserID,
+		FamilyID:   token.FamilyID,
+		TokenHash:  token.TokenHash,
+		ExpiresAt:  token.ExpiresAt,
+		RevokedAt:  token.RevokedAt,
+		ReplacedBy: token.ReplacedBy,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+func ToDomainRefreshToken(tokenEntity *entity.RefreshTokenEntity) *domain.RefreshToken {
+	return &domain.RefreshToken{
+		ID:         tokenEntity.ID,
+		UserID:     tokenEntity.UserID,
+		FamilyID:   tokenEntity.FamilyID,
+		TokenHash:  tokenEntity.TokenHash,
+		ExpiresAt:  tokenEntity.ExpiresAt,
+		RevokedAt:  tokenEntity.RevokedAt,
+		ReplacedBy: tokenEntity.ReplacedBy,
+		CreatedAt:  tokenEntity.CreatedAt,
+	}
+}
@@ -0,0 +1,74 @@
+package mapper
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
+)
+
+func ToPostgreSpaceInvitation(invitation *domain.SpaceInvitation) *entity.SpaceInvitationEntity {
+	invitationEntity := &entity.SpaceInvitationEntity{
+		ID:        invitation.ID,
+		SpaceID:   invitation.SpaceID,
+		InviterID: invitation.InviterID,
+		InviteeID: invitation.InviteeID,
+		MaxUses:   invitation.MaxUses,
+		Uses:      invitation.Uses,
+		ExpiresAt: invitation.ExpiresAt,
+		RevokedAt: invitation.RevokedAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+
+	// Direct invitations have no token
+	if invitation.TokenHash != "" {
+		tokenHash := invitation.TokenHash
+		invitationEntity.TokenHash = &tokenHash
+	}
+
+	return invitationEntity
+}
+
+func ToDomainSpaceInvitation(invitationEntity *entity.SpaceInvitationEntity) *domain.SpaceInvitation {
+	invitation := &domain.SpaceInvitation{
+		ID:        invitationEntity.ID,
+		SpaceID:   invitationEntity.SpaceID,
+		InviterID: invitationEntity.InviterID,
+		InviteeID: invitationEntity.InviteeID,
+		MaxUses:   invitationEntity.MaxUses,
+		Uses:      invitationEntity.Uses,
+		ExpiresAt: invitationEntity.ExpiresAt,
+		RevokedAt: invitationEntity.RevokedAt,
+		CreatedAt: invitationEntity.CreatedAt,
+	}
+
+	if invitationEntity.TokenHash != nil {
+		invitation.TokenHash = *invitationEntity.TokenHash
+	}
+
+	return invitation
+}
+
+func ToPostgreJoinRequest(request *domain.JoinRequest) *entity.JoinRequestEntity {
+	return &entity.JoinRequestEntity{
+		ID:         request.ID,
+		SpaceID:    request.SpaceID,
+		UserID:     request.UserID,
+		Status:     string(request.Status),
+		Message:    request.Message,
+		ReviewedBy: request.ReviewedBy,
+		ReviewedAt: request.ReviewedAt,
+		CreatedAt:  request.CreatedAt,
+	}
+}
+
+func ToDomainJoinRequest(requestEntity *entity.JoinRequestEntity) *domain.JoinRequest {
+	return &domain.JoinRequest{
+		ID:         requestEntity.ID,
+		SpaceID:    requestEntity.SpaceID,
+		UserID:     requestEntity.UserID,
+		Status:     domain.JoinRequestStatus(requestEntity.Status),
+		Message:    requestEntity.Message,
+		ReviewedBy: requestEntity.ReviewedBy,
+		ReviewedAt: requestEntity.ReviewedAt,
+		CreatedAt:  requestEntity.CreatedAt,
+	}
+}
@@ -10,6 +10,8 @@ func ToPostgresSpace(space *domain.Space) *entity.SpaceEntity {
 		ID:          space.ID,
 		Name:        space.Name,
 		Description: space.Description,
+		Visibility:  space.Visibility,
+		ArchivedAt:  space.ArchivedAt,
 		CreatedBy:   space.CreatedBy,
 		CreatedAt:   space.CreatedAt,
 		UpdatedBy:   space.UpdatedBy,
@@ -22,6 +24,8 @@ func ToDomainSpace(space *entity.SpaceEntity) *domain.Space {
 		ID:          space.ID,
 		Name:        space.Name,
 		Description: space.Description,
+		Visibility:  space.Visibility,
+		ArchivedAt:  space.ArchivedAt,
 		CreatedAt:   space.CreatedAt,
 		CreatedBy:   space.CreatedBy,
 		UpdatedAt:   space.UpdatedAt,
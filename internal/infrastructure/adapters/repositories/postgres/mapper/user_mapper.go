@@ -7,26 +7,32 @@ import (
 
 func ToPostgreUser(user *domain.User) *entity.UserEntity {
 	return &entity.UserEntity{
-		ID:        user.ID,
-		Name:      user.Name,
-		LastName:  user.LastName,
-		Email:     user.Email,
-		Password:  user.Password,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-		Image:     user.Image,
+		ID:              user.ID,
+		Name:            user.Name,
+		LastName:        user.LastName,
+		Email:           user.Email,
+		Password:        user.Password,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		Image:           user.Image,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		IsAdmin:         user.IsAdmin,
+		LastSeenAt:      user.LastSeenAt,
 	}
 }
 
 func ToDomainUser(user *entity.UserEntity) *domain.User {
 	return &domain.User{
-		ID:        user.ID,
-		Name:      user.Name,
-		LastName:  user.LastName,
-		Email:     user.Email,
-		Password:  user.Password,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-		Image:     user.Image,
+		ID:              user.ID,
+		Name:            user.Name,
+		LastName:        user.LastName,
+		Email:           user.Email,
+		Password:        user.Password,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		Image:           user.Image,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		IsAdmin:         user.IsAdmin,
+		LastSeenAt:      user.LastSeenAt,
 	}
 }
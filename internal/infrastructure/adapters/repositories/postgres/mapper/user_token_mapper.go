@@ -0,0 +1,32 @@
+package mapper
+
+import (
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
+)
+
+func ToPostgreUserToken(token *domain.UserToken) *entity.UserTokenEntity {
+	return &entity.UserTokenEntity{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		Purpose:   string(token.Purpose),
+		TokenHash: token.TokenHash,
+		Payload:   token.Payload,
+		ExpiresAt: token.ExpiresAt,
+		UsedAt:    token.UsedAt,
+		CreatedAt: token.CreatedAt,
+	}
+}
+
+func ToDomainUserToken(tokenEntity *entity.UserTokenEntity) *domain.UserToken {
+	return &domain.UserToken{
+		ID:        tokenEntity.ID,
+		UserID:    tokenEntity.UserID,
+		Purpose:   domain.UserTokenPurpose(tokenEntity.Purpose),
+		TokenHash: tokenEntity.TokenHash,
+		Payload:   tokenEntity.Payload,
+		ExpiresAt: tokenEntity.ExpiresAt,
+		UsedAt:    tokenEntity.UsedAt,
+		CreatedAt: tokenEntity.CreatedAt,
+	}
+}
@@ -19,8 +19,10 @@ func NewMessageRepository(db *sql.DB) *MessageRepository {
 	}
 }
 
+const selectMessagesWithUser = "SELECT m.id, m.content, m.user_id, m.username, m.space_id, m.timestamp, m.reply_to_id, m.edited_at, m.deleted_at, u.image FROM chat_messages m INNER JOIN users u ON m.user_id = u.id"
+
 func (r *MessageRepository) SearchMessages(ctx context.Context, filters domain.SearchMessagesFilter) ([]*domain.ChatMessage, int, error) {
-	baseQuery := "SELECT m.id, m.content, m.user_id, m.username, m.space_id, m.timestamp, u.image FROM chat_messages m INNER JOIN users u ON m.user_id = u.id WHERE m.space_id = $1"
+	baseQuery := selectMessagesWithUser + " WHERE m.space_id = $1"
 	countQuery := "SELECT COUNT(*) FROM chat_messages WHERE space_id = $1"
 
 	var total int
@@ -56,7 +58,97 @@ func (r *MessageRepository) SearchMessages(ctx context.Context, filters domain.S
 	}
 	defer rows.Close()
 
-	var messages []*domain.ChatMessage
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+// SearchMessagesByCursor uses the (space_id, id) index instead of OFFSET, so pages stay
+// stable while new messages arrive. One extra row is fetched to know if there are more.
+func (r *MessageRepository) SearchMessagesByCursor(ctx context.Context, filter domain.MessageCursorFilter) ([]*domain.ChatMessage, bool, error) {
+	query := selectMessagesWithUser + " WHERE m.space_id = $1"
+	args := []interface{}{filter.SpaceID}
+
+	// Without an after cursor the page is read backwards from the newest message
+	descending := filter.After == ""
+	if filter.After != "" {
+		args = append(args, filter.After)
+		query += fmt.Sprintf(` AND m.id COLLATE "C" > $%d`, len(args))
+	}
+	if filter.Before != "" {
+		args = append(args, filter.Before)
+		query += fmt.Sprintf(` AND m.id COLLATE "C" < $%d`, len(args))
+	}
+
+	direction := "ASC"
+	if descending {
+		direction = "DESC"
+	}
+	query += fmt.Sprintf(` ORDER BY m.id COLLATE "C" %s LIMIT %d`, direction, filter.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(messages) > filter.Limit
+	if hasMore {
+		messages = messages[:filter.Limit]
+	}
+
+	if descending {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+
+	return messages, hasMore, nil
+}
+
+// CountUnreadBySpace counts messages from other users after the read receipt,
+// spaces without a receipt count every message
+func (r *MessageRepository) CountUnreadBySpace(ctx context.Context, userID int) ([]*domain.SpaceUnreadCount, error) {
+	query := `
+		SELECT us.space_id, COUNT(m.id)
+		FROM user_spaces us
+		LEFT JOIN chat_read_receipts rr ON rr.user_id = us.user_id AND rr.space_id = us.space_id
+		LEFT JOIN chat_messages m ON m.space_id = us.space_id
+			AND m.user_id <> us.user_id
+			AND m.deleted_at IS NULL
+			AND (rr.last_read_message_id IS NULL OR m.id COLLATE "C" > rr.last_read_message_id COLLATE "C")
+		WHERE us.user_id = $1
+		GROUP BY us.space_id
+		ORDER BY us.space_id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []*domain.SpaceUnreadCount{}
+	for rows.Next() {
+		var count domain.SpaceUnreadCount
+		if err := rows.Scan(&count.SpaceID, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &count)
+	}
+
+	return counts, rows.Err()
+}
+
+func scanMessages(rows *sql.Rows) ([]*domain.ChatMessage, error) {
+	messages := []*domain.ChatMessage{}
 	for rows.Next() {
 		var chatEntity entity.ChatMessageEntityWithUser
 		err := rows.Scan(
@@ -66,15 +158,17 @@ func (r *MessageRepository) SearchMessages(ctx context.Context, filters domain.S
 			&chatEntity.Username,
 			&chatEntity.SpaceID,
 			&chatEntity.Timestamp,
+			&chatEntity.ReplyToID,
+			&chatEntity.EditedAt,
+			&chatEntity.DeletedAt,
 			&chatEntity.Image,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 
-		message := mapper.ToDomainChatMessageWithUser(&chatEntity)
-		messages = append(messages, message)
+		messages = append(messages, mapper.ToDomainChatMessageWithUser(&chatEntity))
 	}
 
-	return messages, total, rows.Err()
+	return messages, rows.Err()
 }
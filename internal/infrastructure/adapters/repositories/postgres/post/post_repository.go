@@ -9,6 +9,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 )
 
 type PostRepository struct {
@@ -71,7 +72,7 @@ func (p *PostRepository) FindAll(ctx context.Context, criteria *criteria.Criteri
 func (p *PostRepository) executeQuery(ctx context.Context, params QueryParams) ([]*domain.Post, error) {
 	var posts []*domain.Post
 
-	query := "SELECT id, title, content, image, created_by, created_at, updated_by, updated_at, space_id FROM posts"
+	query := "SELECT id, title, content, image, created_by, created_at, updated_by, updated_at, space_id, pinned_at FROM posts"
 
 	if params.WhereClause != "" {
 		query += params.WhereClause
@@ -111,6 +112,7 @@ func (p *PostRepository) scanPostEntity(scanner interface{ Scan(...interface{})
 		&postEntity.UpdatedBy,
 		&postEntity.UpdatedAt,
 		&postEntity.SpaceID,
+		&postEntity.PinnedAt,
 	)
 }
 
@@ -123,6 +125,16 @@ func (p *PostRepository) Update(ctx context.Context, post *domain.Post) error {
 	return err
 }
 
+func (p *PostRepository) SetPinned(ctx context.Context, postID int, pinnedAt *time.Time) error {
+	_, err := p.db.ExecContext(ctx, "UPDATE posts SET pinned_at=$1 WHERE id=$2", pinnedAt, postID)
+	return err
+}
+
+func (p *PostRepository) Touch(ctx context.Context, postID int, updatedBy int, updatedAt time.Time) error {
+	_, err := p.db.ExecContext(ctx, "UPDATE posts SET updated_at=$1, updated_by=$2 WHERE id=$3", updatedAt, updatedBy, postID)
+	return err
+}
+
 func (p *PostRepository) Search(ctx context.Context, criteria *criteria.Criteria) ([]*domain.Post, error) {
 	var posts []*domain.Post
 
@@ -134,7 +146,7 @@ func (p *PostRepository) Search(ctx context.Context, criteria *criteria.Criteria
 		whereClause = strings.Replace(whereClause, oldPattern, newPattern, 1)
 	}
 
-	query := "SELECT id, title, content, image, created_by, created_at, updated_by, updated_at, space_id FROM posts" + whereClause
+	query := "SELECT id, title, content, image, created_by, created_at, updated_by, updated_at, space_id, pinned_at FROM posts" + whereClause
 
 	rows, err := p.db.QueryContext(ctx, query, params...)
 	if err != nil {
@@ -0,0 +1,123 @@
+package refresh_token
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/mapper"
+	"cpi-hub-api/pkg/helpers"
+	"database/sql"
+)
+
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	tokenEntity := mapper.ToPostgreRefreshToken(token)
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		tokenEntity.ID, tokenEntity.UserID, tokenEntity.FamilyID, tokenEntity.TokenHash, tokenEntity.ExpiresAt, tokenEntity.CreatedAt,
+	)
+	return err
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var tokenEntity entity.RefreshTokenEntity
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, replaced_by, created_at
+		FROM refresh_tokens WHERE token_hash = $1`,
+		tokenHash,
+	).Scan(
+		&tokenEntity.ID,
+		&tokenEntity.UserID,
+		&tokenEntity.FamilyID,
+		&tokenEntity.TokenHash,
+		&tokenEntity.ExpiresAt,
+		&tokenEntity.RevokedAt,
+		&tokenEntity.ReplacedBy,
+		&tokenEntity.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return mapper.ToDomainRefreshToken(&tokenEntity), nil
+}
+
+// MarkRotated revokes the token only if it is still active, so two concurrent
+// refreshes with the same token cannot both succeed
+func (r *RefreshTokenRepository) MarkRotated(ctx context.Context, id string, replacedBy string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $1, replaced_by = $2 WHERE id = $3 AND revoked_at IS NULL`,
+		helpers.GetTime(), replacedBy, id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, userID int, familyID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND family_id = $3 AND revoked_at IS NULL`,
+		helpers.GetTime(), userID, familyID,
+	)
+	return err
+}
+
+func (r *RefreshTokenRepository) RevokeAllByUser(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`,
+		helpers.GetTime(), userID,
+	)
+	return err
+}
+
+// RevokeAllByUserExcept keeps the given session alive, used when the user
+// changes their password from that session
+func (r *RefreshTokenRepository) RevokeAllByUserExcept(ctx context.Context, userID int, familyID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND family_id <> $3 AND revoked_at IS NULL`,
+		helpers.GetTime(), userID, familyID,
+	)
+	return err
+}
+
+// IsFamilyActive tells whether the session was logged out. A rotation revokes the
+// old token with replaced_by set, a logout or a detected reuse revokes the family
+// without it. Checking for that keeps the session valid between the rotation and
+// the insert of the new token.
+func (r *RefreshTokenRepository) IsFamilyActive(ctx context.Context, userID int, familyID string) (bool, error) {
+	var active bool
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (
+			SELECT 1 FROM refresh_tokens WHERE user_id = $1 AND family_id = $2 AND expires_at > $3
+		) AND NOT EXISTS (
+			SELECT 1 FROM refresh_tokens WHERE user_id = $1 AND family_id = $2 AND revoked_at IS NOT NULL AND replaced_by IS NULL
+		)`,
+		userID, familyID, helpers.GetTime(),
+	).Scan(&active)
+	if err != nil {
+		return false, err
+	}
+
+	return active, nil
+}
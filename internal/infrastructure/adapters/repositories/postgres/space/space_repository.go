@@ -7,6 +7,7 @@ import (
 	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
 	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/mapper"
 	"database/sql"
+	"time"
 )
 
 type SpaceRepository struct {
@@ -21,8 +22,8 @@ func (u *SpaceRepository) Create(ctx context.Context, space *domain.Space) error
 	var spaceEntity = *mapper.ToPostgresSpace(space)
 
 	if err := u.db.QueryRowContext(ctx,
-		"INSERT INTO spaces (name, description, created_by, created_at, updated_by, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
-		spaceEntity.Name, spaceEntity.Description, spaceEntity.CreatedBy, spaceEntity.CreatedAt, spaceEntity.UpdatedBy, spaceEntity.UpdatedAt).Scan(&spaceEntity.ID); err != nil {
+		"INSERT INTO spaces (name, description, visibility, created_by, created_at, updated_by, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		spaceEntity.Name, spaceEntity.Description, spaceEntity.Visibility, spaceEntity.CreatedBy, spaceEntity.CreatedAt, spaceEntity.UpdatedBy, spaceEntity.UpdatedAt).Scan(&spaceEntity.ID); err != nil {
 		return err
 	}
 
@@ -40,7 +41,7 @@ func (u *SpaceRepository) findSpaceByField(ctx context.Context, whereClause stri
 	var spaceEntity entity.SpaceEntity
 
 	query := `
-		SELECT id, name, description, created_by, created_at, updated_by, updated_at
+		SELECT id, name, description, visibility, archived_at, created_by, created_at, updated_by, updated_at
 		FROM spaces
 	` + " " + whereClause + " LIMIT 1"
 
@@ -48,6 +49,8 @@ func (u *SpaceRepository) findSpaceByField(ctx context.Context, whereClause stri
 		&spaceEntity.ID,
 		&spaceEntity.Name,
 		&spaceEntity.Description,
+		&spaceEntity.Visibility,
+		&spaceEntity.ArchivedAt,
 		&spaceEntity.CreatedBy,
 		&spaceEntity.CreatedAt,
 		&spaceEntity.UpdatedBy,
@@ -89,7 +92,7 @@ func (u *SpaceRepository) FindAll(ctx context.Context, criteria *criteria.Criter
 	whereClause, params := mapper.ToPostgreSQLQuery(criteria)
 
 	query := `
-        SELECT id, name, description, created_by, created_at, updated_by, updated_at
+        SELECT id, name, description, visibility, archived_at, created_by, created_at, updated_by, updated_at
         FROM spaces
     ` + " " + whereClause
 
@@ -106,6 +109,8 @@ func (u *SpaceRepository) FindAll(ctx context.Context, criteria *criteria.Criter
 			&spaceEntity.ID,
 			&spaceEntity.Name,
 			&spaceEntity.Description,
+			&spaceEntity.Visibility,
+			&spaceEntity.ArchivedAt,
 			&spaceEntity.CreatedBy,
 			&spaceEntity.CreatedAt,
 			&spaceEntity.UpdatedBy,
@@ -123,8 +128,24 @@ func (u *SpaceRepository) Update(ctx context.Context, space *domain.Space) error
 	spaceEntity := mapper.ToPostgresSpace(space)
 
 	_, err := u.db.ExecContext(ctx,
-		"UPDATE spaces SET name=$1, description=$2, updated_by=$3, updated_at=$4 WHERE id=$5",
-		spaceEntity.Name, spaceEntity.Description, spaceEntity.UpdatedBy, spaceEntity.UpdatedAt, spaceEntity.ID)
+		"UPDATE spaces SET name=$1, description=$2, visibility=$3, updated_by=$4, updated_at=$5 WHERE id=$6",
+		spaceEntity.Name, spaceEntity.Description, spaceEntity.Visibility, spaceEntity.UpdatedBy, spaceEntity.UpdatedAt, spaceEntity.ID)
+
+	return err
+}
+
+func (u *SpaceRepository) SetArchived(ctx context.Context, spaceID int, archivedAt *time.Time, updatedBy int, updatedAt time.Time) error {
+	_, err := u.db.ExecContext(ctx,
+		"UPDATE spaces SET archived_at=$1, updated_by=$2, updated_at=$3 WHERE id=$4",
+		archivedAt, updatedBy, updatedAt, spaceID)
+
+	return err
+}
+
+func (u *SpaceRepository) Touch(ctx context.Context, spaceID int, updatedBy int, updatedAt time.Time) error {
+	_, err := u.db.ExecContext(ctx,
+		"UPDATE spaces SET updated_by=$1, updated_at=$2 WHERE id=$3",
+		updatedBy, updatedAt, spaceID)
 
 	return err
 }
@@ -145,3 +166,9 @@ func (u *SpaceRepository) Count(ctx context.Context, criteria *criteria.Criteria
 
 	return count, nil
 }
+
+// Delete removes the space, its memberships, posts, comments and chat messages cascade with it
+func (u *SpaceRepository) Delete(ctx context.Context, spaceID int) error {
+	_, err := u.db.ExecContext(ctx, "DELETE FROM spaces WHERE id = $1", spaceID)
+	return err
+}
@@ -0,0 +1,146 @@
+package space_invitation
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/mapper"
+	"cpi-hub-api/pkg/helpers"
+	"database/sql"
+)
+
+const selectInvitation = `SELECT id, space_id, inviter_id, invitee_id, token_hash, max_uses, uses, expires_at, revoked_at, created_at
+	FROM space_invitations`
+
+type SpaceInvitationRepository struct {
+	db *sql.DB
+}
+
+func NewSpaceInvitationRepository(db *sql.DB) *SpaceInvitationRepository {
+	return &SpaceInvitationRepository{db: db}
+}
+
+func (r *SpaceInvitationRepository) Create(ctx context.Context, invitation *domain.SpaceInvitation) error {
+	invitationEntity := mapper.ToPostgreSpaceInvitation(invitation)
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO space_invitations (id, space_id, inviter_id, invitee_id, token_hash, max_uses, uses, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		invitationEntity.ID, invitationEntity.SpaceID, invitationEntity.InviterID, invitationEntity.InviteeID, invitationEntity.TokenHash,
+		invitationEntity.MaxUses, invitationEntity.Uses, invitationEntity.ExpiresAt, invitationEntity.CreatedAt,
+	)
+	return err
+}
+
+func (r *SpaceInvitationRepository) FindByID(ctx context.Context, id string) (*domain.SpaceInvitation, error) {
+	return r.findOne(ctx, selectInvitation+` WHERE id = $1`, id)
+}
+
+func (r *SpaceInvitationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.SpaceInvitation, error) {
+	return r.findOne(ctx, selectInvitation+` WHERE token_hash = $1`, tokenHash)
+}
+
+// FindActiveBySpaceID returns the invitations of the space that can still be redeemed
+func (r *SpaceInvitationRepository) FindActiveBySpaceID(ctx context.Context, spaceID int) ([]*domain.SpaceInvitation, error) {
+	return r.findMany(ctx,
+		selectInvitation+` WHERE space_id = $1 AND revoked_at IS NULL AND uses < max_uses AND expires_at > $2 ORDER BY created_at DESC`,
+		spaceID, helpers.GetTime(),
+	)
+}
+
+// FindActiveByInviteeID returns the direct invitations the user has not answered yet
+func (r *SpaceInvitationRepository) FindActiveByInviteeID(ctx context.Context, userID int) ([]*domain.SpaceInvitation, error) {
+	return r.findMany(ctx,
+		selectInvitation+` WHERE invitee_id = $1 AND revoked_at IS NULL AND uses < max_uses AND expires_at > $2 ORDER BY created_at DESC`,
+		userID, helpers.GetTime(),
+	)
+}
+
+// IncrementUses consumes one use of the invitation, returning false if it is no longer usable
+func (r *SpaceInvitationRepository) IncrementUses(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE space_invitations SET uses = uses + 1
+		WHERE id = $1 AND revoked_at IS NULL AND uses < max_uses AND expires_at > $2`,
+		id, helpers.GetTime(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// Revoke returns false if the invitation was already revoked
+func (r *SpaceInvitationRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE space_invitations SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`,
+		helpers.GetTime(), id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *SpaceInvitationRepository) findOne(ctx context.Context, query string, args ...interface{}) (*domain.SpaceInvitation, error) {
+	var invitationEntity entity.SpaceInvitationEntity
+
+	err := scanInvitation(r.db.QueryRowContext(ctx, query, args...), &invitationEntity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return mapper.ToDomainSpaceInvitation(&invitationEntity), nil
+}
+
+func (r *SpaceInvitationRepository) findMany(ctx context.Context, query string, args ...interface{}) ([]*domain.SpaceInvitation, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*domain.SpaceInvitation{}
+	for rows.Next() {
+		var invitationEntity entity.SpaceInvitationEntity
+		if err := scanInvitation(rows, &invitationEntity); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, mapper.ToDomainSpaceInvitation(&invitationEntity))
+	}
+
+	return invitations, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvitation(row scanner, invitationEntity *entity.SpaceInvitationEntity) error {
+	return row.Scan(
+		&invitationEntity.ID,
+		&invitationEntity.SpaceID,
+		&invitationEntity.InviterID,
+		&invitationEntity.InviteeID,
+		&invitationEntity.TokenHash,
+		&invitationEntity.MaxUses,
+		&invitationEntity.Uses,
+		&invitationEntity.ExpiresAt,
+		&invitationEntity.RevokedAt,
+		&invitationEntity.CreatedAt,
+	)
+}
@@ -7,6 +7,7 @@ import (
 	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
 	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/mapper"
 	"database/sql"
+	"time"
 )
 
 type UserRepository struct {
@@ -41,7 +42,7 @@ func (u *UserRepository) findUserByField(ctx context.Context, whereClause string
 	var userEntity entity.UserEntity
 
 	query := `
-		SELECT id, name, last_name, email, password, created_at, updated_at, image
+		SELECT id, name, last_name, email, password, created_at, updated_at, image, email_verified_at, is_admin, last_seen_at
 		FROM users
 	` + " " + whereClause + " LIMIT 1"
 
@@ -54,6 +55,9 @@ func (u *UserRepository) findUserByField(ctx context.Context, whereClause string
 		&userEntity.CreatedAt,
 		&userEntity.UpdatedAt,
 		&userEntity.Image,
+		&userEntity.EmailVerifiedAt,
+		&userEntity.IsAdmin,
+		&userEntity.LastSeenAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -87,7 +91,7 @@ func (u *UserRepository) Count(ctx context.Context, criteria *criteria.Criteria)
 
 func (u *UserRepository) findUsersByField(ctx context.Context, whereClause string, params []interface{}) ([]*domain.User, error) {
 	query := `
-		SELECT id, name, last_name, email, password, created_at, updated_at, image
+		SELECT id, name, last_name, email, password, created_at, updated_at, image, email_verified_at, is_admin, last_seen_at
 		FROM users
 	` + " " + whereClause
 
@@ -109,6 +113,9 @@ func (u *UserRepository) findUsersByField(ctx context.Context, whereClause strin
 			&userEntity.CreatedAt,
 			&userEntity.UpdatedAt,
 			&userEntity.Image,
+			&userEntity.EmailVerifiedAt,
+			&userEntity.IsAdmin,
+			&userEntity.LastSeenAt,
 		)
 		if err != nil {
 			return nil, err
@@ -125,8 +132,13 @@ func (u *UserRepository) findUsersByField(ctx context.Context, whereClause strin
 
 func (u *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	_, err := u.db.ExecContext(ctx,
-		"UPDATE users SET name = $1, last_name = $2, email = $3, password = $4, updated_at = $5, image = $6 WHERE id = $7",
-		user.Name, user.LastName, user.Email, user.Password, user.UpdatedAt, user.Image, user.ID,
+		"UPDATE users SET name = $1, last_name = $2, email = $3, password = $4, updated_at = $5, image = $6, email_verified_at = $7 WHERE id = $8",
+		user.Name, user.LastName, user.Email, user.Password, user.UpdatedAt, user.Image, user.EmailVerifiedAt, user.ID,
 	)
 	return err
 }
+
+func (u *UserRepository) UpdateLastSeen(ctx context.Context, userID int, lastSeenAt time.Time) error {
+	_, err := u.db.ExecContext(ctx, "UPDATE users SET last_seen_at = $1 WHERE id = $2", lastSeenAt, userID)
+	return err
+}
@@ -105,3 +105,104 @@ func (p *UserSpaceRepository) Count(ctx context.Context, criteria *criteria.Crit
 	err := p.db.QueryRowContext(ctx, query, params...).Scan(&count)
 	return count, err
 }
+
+func (u *UserSpaceRepository) AddMember(ctx context.Context, userID int, spaceID int, role domain.SpaceRole) error {
+	_, err := u.db.ExecContext(ctx, "INSERT INTO user_spaces (user_id, space_id, role) VALUES ($1, $2, $3)", userID, spaceID, string(role))
+	return err
+}
+
+// FindRole returns an empty role when the user is not a member of the space
+func (u *UserSpaceRepository) FindRole(ctx context.Context, userID int, spaceID int) (domain.SpaceRole, error) {
+	var role string
+	err := u.db.QueryRowContext(ctx, "SELECT role FROM user_spaces WHERE user_id = $1 AND space_id = $2", userID, spaceID).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return domain.SpaceRole(role), nil
+}
+
+func (u *UserSpaceRepository) FindMembersBySpaceID(ctx context.Context, spaceID int) ([]*domain.SpaceMembership, error) {
+	rows, err := u.db.QueryContext(ctx, "SELECT user_id, space_id, role FROM user_spaces WHERE space_id = $1 ORDER BY user_id", spaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*domain.SpaceMembership{}
+	for rows.Next() {
+		var member domain.SpaceMembership
+		var role string
+		if err := rows.Scan(&member.UserID, &member.SpaceID, &role); err != nil {
+			return nil, err
+		}
+		member.Role = domain.SpaceRole(role)
+		members = append(members, &member)
+	}
+
+	return members, rows.Err()
+}
+
+func (u *UserSpaceRepository) CountByRole(ctx context.Context, spaceID int) (map[domain.SpaceRole]int, error) {
+	rows, err := u.db.QueryContext(ctx, "SELECT role, COUNT(*) FROM user_spaces WHERE space_id = $1 GROUP BY role", spaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.SpaceRole]int)
+	for rows.Next() {
+		var role string
+		var count int
+		if err := rows.Scan(&role, &count); err != nil {
+			return nil, err
+		}
+		counts[domain.SpaceRole(role)] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (u *UserSpaceRepository) UpdateRole(ctx context.Context, userID int, spaceID int, role domain.SpaceRole) error {
+	_, err := u.db.ExecContext(ctx, "UPDATE user_spaces SET role = $1 WHERE user_id = $2 AND space_id = $3", string(role), userID, spaceID)
+	return err
+}
+
+// TransferOwnership demotes the current owner to moderator and promotes the new owner in a single
+// transaction. The owner is whoever holds the owner role in user_spaces, spaces.created_by keeps
+// the creator. The demote only matches the current owner, so of two concurrent transfers only
+// the first one goes through.
+func (u *UserSpaceRepository) TransferOwnership(ctx context.Context, spaceID int, fromUserID int, toUserID int) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE user_spaces SET role = $1 WHERE user_id = $2 AND space_id = $3 AND role = $4",
+		string(domain.SpaceRoleModerator), fromUserID, spaceID, string(domain.SpaceRoleOwner))
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return apperror.NewForbidden("Only the owner can transfer ownership", nil, "user_space_repository.go:TransferOwnership")
+	}
+
+	result, err = tx.ExecContext(ctx, "UPDATE user_spaces SET role = $1 WHERE user_id = $2 AND space_id = $3",
+		string(domain.SpaceRoleOwner), toUserID, spaceID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return apperror.NewNotFound("Member not found", nil, "user_space_repository.go:TransferOwnership")
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,82 @@
+package user_token
+
+import (
+	"context"
+	"cpi-hub-api/internal/core/domain"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/entity"
+	"cpi-hub-api/internal/infrastructure/adapters/repositories/postgres/mapper"
+	"cpi-hub-api/pkg/helpers"
+	"database/sql"
+)
+
+type UserTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+func (r *UserTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	tokenEntity := mapper.ToPostgreUserToken(token)
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO user_tokens (id, user_id, purpose, token_hash, payload, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		tokenEntity.ID, tokenEntity.UserID, tokenEntity.Purpose, tokenEntity.TokenHash, tokenEntity.Payload, tokenEntity.ExpiresAt, tokenEntity.CreatedAt,
+	)
+	return err
+}
+
+func (r *UserTokenRepository) FindByHash(ctx context.Context, purpose domain.UserTokenPurpose, tokenHash string) (*domain.UserToken, error) {
+	var tokenEntity entity.UserTokenEntity
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, user_id, purpose, token_hash, payload, expires_at, used_at, created_at
+		FROM user_tokens WHERE purpose = $1 AND token_hash = $2`,
+		string(purpose), tokenHash,
+	).Scan(
+		&tokenEntity.ID,
+		&tokenEntity.UserID,
+		&tokenEntity.Purpose,
+		&tokenEntity.TokenHash,
+		&tokenEntity.Payload,
+		&tokenEntity.ExpiresAt,
+		&tokenEntity.UsedAt,
+		&tokenEntity.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return mapper.ToDomainUserToken(&tokenEntity), nil
+}
+
+// MarkUsed consumes the token, returning false if it was already used
+func (r *UserTokenRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE user_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`,
+		helpers.GetTime(), id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *UserTokenRepository) InvalidateByUser(ctx context.Context, userID int, purpose domain.UserTokenPurpose) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE user_tokens SET used_at = $1 WHERE user_id = $2 AND purpose = $3 AND used_at IS NULL`,
+		helpers.GetTime(), userID, string(purpose),
+	)
+	return err
+}
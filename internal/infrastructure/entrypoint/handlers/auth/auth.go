@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/auth"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
+	"cpi-hub-api/pkg/apperror"
+	response "cpi-hub-api/pkg/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	AuthUseCase auth.AuthUseCase
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var refreshDTO dto.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&refreshDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid refresh data", err, "auth_handler.go:Refresh")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	tokens, err := h.AuthUseCase.Refresh(c.Request.Context(), refreshDTO.RefreshToken)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	c.Header("Authorization", "Bearer "+tokens.AccessToken)
+
+	response.SuccessResponse(c.Writer, dto.ToTokensResponse(tokens))
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	err := h.AuthUseCase.Logout(c.Request.Context(), middleware.GetUserID(c), middleware.GetSessionID(c))
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	err := h.AuthUseCase.LogoutAll(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
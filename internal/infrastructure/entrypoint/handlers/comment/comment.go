@@ -3,6 +3,7 @@ package comment
 import (
 	"cpi-hub-api/internal/core/dto"
 	"cpi-hub-api/internal/core/usecase/comment"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
 	response "cpi-hub-api/pkg/http"
@@ -37,6 +38,7 @@ func (h *CommentHandler) Search(c *gin.Context) {
 		SortDirection: sortDirection,
 		UserID:        &userIDInt,
 		PostID:        &postIDInt,
+		ViewerID:      middleware.GetUserID(c),
 	}
 
 	searchResult, err := h.CommentUseCase.Search(c.Request.Context(), searchParams)
@@ -72,7 +74,7 @@ func (h *CommentHandler) Update(c *gin.Context) {
 
 	updateDTO.CommentID = commentID
 
-	err = h.CommentUseCase.Update(c.Request.Context(), updateDTO)
+	err = h.CommentUseCase.Update(c.Request.Context(), middleware.GetUserID(c), updateDTO)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
@@ -90,7 +92,7 @@ func (h *CommentHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.CommentUseCase.Delete(c.Request.Context(), commentID)
+	err = h.CommentUseCase.Delete(c.Request.Context(), middleware.GetUserID(c), commentID)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
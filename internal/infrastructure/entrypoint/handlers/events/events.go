@@ -3,44 +3,86 @@ package events
 import (
 	"cpi-hub-api/internal/core/dto"
 	eventsUsecase "cpi-hub-api/internal/core/usecase/events"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
 	"cpi-hub-api/pkg/apperror"
 	response "cpi-hub-api/pkg/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 )
 
 // EventsHandler maneja las conexiones de eventos en tiempo real
 type EventsHandler struct {
 	eventsUsecase *eventsUsecase.EventsUsecase
+	sessions      middleware.SessionValidator
 }
 
 // NewEventsHandler crea una nueva instancia del handler
-func NewEventsHandler(eventsUsecase *eventsUsecase.EventsUsecase) *EventsHandler {
+func NewEventsHandler(eventsUsecase *eventsUsecase.EventsUsecase, sessions middleware.SessionValidator) *EventsHandler {
 	return &EventsHandler{
 		eventsUsecase: eventsUsecase,
+		sessions:      sessions,
 	}
 }
 
-// Connect maneja la conexión de eventos en tiempo real
-func (h *EventsHandler) Connect(c *gin.Context) {
-	spaceID := c.Param("space_id")
-	if spaceID == "" {
-		appErr := apperror.NewInvalidData("space_id es requerido", nil, "events_handler.go:Connect")
-		response.NewError(c.Writer, appErr)
+// IssueTicket emite un ticket de un solo uso para autenticar un handshake WebSocket
+func (h *EventsHandler) IssueTicket(c *gin.Context) {
+	ticket, err := h.eventsUsecase.IssueTicket(middleware.GetUserID(c), middleware.GetSessionID(c))
+	if err != nil {
+		response.NewError(c.Writer, err)
 		return
 	}
 
-	userID := c.Query("user_id")
-	if userID == "" {
-		appErr := apperror.NewInvalidData("user_id es requerido", nil, "events_handler.go:Connect")
-		response.NewError(c.Writer, appErr)
-		return
+	response.CreatedResponse(c.Writer, ticket)
+}
+
+// authenticateHandshake identifica al usuario de un handshake WebSocket. Acepta
+// un ticket en el query string (?ticket=...), un JWT en Sec-WebSocket-Protocol
+// ("bearer, <jwt>") o un header Authorization para clientes que no son navegadores.
+// En todos los casos la sesión no debe haberse cerrado.
+func (h *EventsHandler) authenticateHandshake(c *gin.Context) (int, string, error) {
+	if ticket := c.Query("ticket"); ticket != "" {
+		userID, sessionID, err := h.eventsUsecase.RedeemTicket(ticket)
+		if err != nil {
+			return 0, "", err
+		}
+
+		if err := h.sessions.ValidateSession(c.Request.Context(), userID, sessionID); err != nil {
+			return 0, "", err
+		}
+
+		return userID, sessionID, nil
+	}
+
+	token := tokenFromSubprotocols(websocket.Subprotocols(c.Request))
+	if token == "" {
+		token = middleware.BearerToken(c.Request.Header.Get("Authorization"))
+	}
+
+	if token == "" {
+		return 0, "", apperror.NewUnauthorized("Missing WebSocket credentials", nil, "events_handler.go:authenticateHandshake")
+	}
+
+	return middleware.ParseAccessToken(c.Request.Context(), h.sessions, token)
+}
+
+// tokenFromSubprotocols extrae el JWT enviado como ["bearer", "<jwt>"]
+func tokenFromSubprotocols(protocols []string) string {
+	for i, protocol := range protocols {
+		if strings.EqualFold(protocol, "bearer") && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
 	}
+	return ""
+}
 
-	userName := c.Query("username")
-	if userName == "" {
-		appErr := apperror.NewInvalidData("username es requerido", nil, "events_handler.go:Connect")
+// Connect maneja la conexión de eventos en tiempo real
+func (h *EventsHandler) Connect(c *gin.Context) {
+	spaceID := c.Param("space_id")
+	if spaceID == "" {
+		appErr := apperror.NewInvalidData("space_id es requerido", nil, "events_handler.go:Connect")
 		response.NewError(c.Writer, appErr)
 		return
 	}
@@ -52,24 +94,26 @@ func (h *EventsHandler) Connect(c *gin.Context) {
 		return
 	}
 
-	userIDInt, err := strconv.Atoi(userID)
+	userID, sessionID, err := h.authenticateHandshake(c)
 	if err != nil {
-		appErr := apperror.NewInvalidData("user_id debe ser un número", err, "events_handler.go:Connect")
-		response.NewError(c.Writer, appErr)
+		response.NewError(c.Writer, err)
 		return
 	}
 
 	connectionParams := dto.EventsConnectionParams{
-		UserID:   userIDInt,
-		SpaceID:  spaceIDInt,
-		Writer:   c.Writer,
-		Request:  c.Request,
-		Username: userName,
+		UserID:      userID,
+		SessionID:   sessionID,
+		SpaceID:     spaceIDInt,
+		LastEventID: c.Query("last_event_id"),
+		Writer:      c.Writer,
+		Request:     c.Request,
 	}
 
 	err = h.eventsUsecase.HandleConnection(connectionParams)
 	if err != nil {
-		response.NewError(c.Writer, err)
+		if !c.Writer.Written() {
+			response.NewError(c.Writer, err)
+		}
 		return
 	}
 }
@@ -91,6 +135,8 @@ func (h *EventsHandler) Broadcast(c *gin.Context) {
 		return
 	}
 
+	dto.UserID = middleware.GetUserID(c)
+
 	chatMsg, err := h.eventsUsecase.Broadcast(dto)
 	if err != nil {
 		response.NewError(c.Writer, err)
@@ -118,6 +164,8 @@ func (h *EventsHandler) ChatMessage(c *gin.Context) {
 		return
 	}
 
+	dto.UserID = middleware.GetUserID(c)
+
 	chatMsg, err := h.eventsUsecase.BroadcastToSpace(dto)
 	if err != nil {
 		response.NewError(c.Writer, err)
@@ -131,52 +179,40 @@ func (h *EventsHandler) ChatMessage(c *gin.Context) {
 }
 
 func (h *EventsHandler) HandleUserConnection(c *gin.Context) {
-	userID := c.Query("user_id")
-	if userID == "" {
-		appErr := apperror.NewInvalidData("user_id is required", nil, "events_handler.go:HandleUserConnection")
-		response.NewError(c.Writer, appErr)
-		return
-	}
-
-	userIDInt, err := strconv.Atoi(userID)
+	userID, sessionID, err := h.authenticateHandshake(c)
 	if err != nil {
-		appErr := apperror.NewInvalidData("user_id must be a number", err, "events_handler.go:HandleUserConnection")
-		response.NewError(c.Writer, appErr)
+		response.NewError(c.Writer, err)
 		return
 	}
 
 	params := dto.HandleUserConnectionParams{
-		UserID:  userIDInt,
-		Writer:  c.Writer,
-		Request: c.Request,
+		UserID:    userID,
+		SessionID: sessionID,
+		Writer:    c.Writer,
+		Request:   c.Request,
 	}
 
 	err = h.eventsUsecase.HandleUserConnection(params)
 	if err != nil {
-		response.NewError(c.Writer, err)
+		if !c.Writer.Written() {
+			response.NewError(c.Writer, err)
+		}
 		return
 	}
 }
 
 func (h *EventsHandler) ConnectNotifications(c *gin.Context) {
-	userID := c.Query("user_id")
-	if userID == "" {
-		appErr := apperror.NewInvalidData("user_id is required", nil, "events_handler.go:ConnectNotifications")
-		response.NewError(c.Writer, appErr)
-		return
-	}
-
-	userIDInt, err := strconv.Atoi(userID)
+	userID, sessionID, err := h.authenticateHandshake(c)
 	if err != nil {
-		appErr := apperror.NewInvalidData("user_id must be a number", err, "events_handler.go:ConnectNotifications")
-		response.NewError(c.Writer, appErr)
+		response.NewError(c.Writer, err)
 		return
 	}
 
 	params := dto.HandleNotificationConnectionParams{
-		UserID:  userIDInt,
-		Writer:  c.Writer,
-		Request: c.Request,
+		UserID:    userID,
+		SessionID: sessionID,
+		Writer:    c.Writer,
+		Request:   c.Request,
 	}
 
 	err = h.eventsUsecase.HandleNotificationConnection(params)
@@ -190,3 +226,56 @@ func (h *EventsHandler) ConnectNotifications(c *gin.Context) {
 		return
 	}
 }
+
+// EditMessage edita un mensaje de chat, solo el autor o un moderador pueden hacerlo
+func (h *EventsHandler) EditMessage(c *gin.Context) {
+	var params dto.EditChatMessageParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		appErr := apperror.NewInvalidData("Invalid request data", err, "events_handler.go:EditMessage")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	params.MessageID = c.Param("message_id")
+	params.UserID = middleware.GetUserID(c)
+
+	chatMsg, err := h.eventsUsecase.EditMessage(params)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToMessageDTO(chatMsg))
+}
+
+// DeleteMessage borra un mensaje de chat, solo el autor o un moderador pueden hacerlo
+func (h *EventsHandler) DeleteMessage(c *gin.Context) {
+	chatMsg, err := h.eventsUsecase.DeleteMessage(dto.DeleteChatMessageParams{
+		MessageID: c.Param("message_id"),
+		UserID:    middleware.GetUserID(c),
+	})
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToMessageDTO(chatMsg))
+}
+
+// GetPresence retorna los usuarios conectados al chat de un espacio
+func (h *EventsHandler) GetPresence(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("space_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("space_id debe ser un número", err, "events_handler.go:GetPresence")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	users, err := h.eventsUsecase.Presence(c.Request.Context(), spaceID, middleware.GetUserID(c))
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToSpacePresenceDTO(spaceID, users))
+}
@@ -0,0 +1,176 @@
+package invitation
+
+import (
+	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/invitation"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
+	"cpi-hub-api/pkg/apperror"
+	response "cpi-hub-api/pkg/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	InvitationUseCase invitation.InvitationUseCase
+}
+
+func (h *InvitationHandler) Create(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("space_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid space_id (must be integer)", err, "invitation_handler.go:Create")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	var createInvitationDTO dto.CreateInvitationDTO
+	if err := c.ShouldBindJSON(&createInvitationDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid invitation data", err, "invitation_handler.go:Create")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	createdInvitation, token, err := h.InvitationUseCase.CreateInvitation(c.Request.Context(), middleware.GetUserID(c), spaceID, createInvitationDTO)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	invitationDTO := dto.ToInvitationDTO(createdInvitation)
+	invitationDTO.Token = token
+
+	response.CreatedResponse(c.Writer, invitationDTO)
+}
+
+func (h *InvitationHandler) ListBySpace(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("space_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid space_id (must be integer)", err, "invitation_handler.go:ListBySpace")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	invitations, err := h.InvitationUseCase.ListInvitations(c.Request.Context(), middleware.GetUserID(c), spaceID)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToInvitationDTOs(invitations))
+}
+
+func (h *InvitationHandler) ListCurrentUser(c *gin.Context) {
+	invitations, err := h.InvitationUseCase.ListUserInvitations(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToInvitationDTOs(invitations))
+}
+
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	if err := h.InvitationUseCase.RevokeInvitation(c.Request.Context(), middleware.GetUserID(c), c.Param("invitation_id")); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *InvitationHandler) Accept(c *gin.Context) {
+	acceptedInvitation, err := h.InvitationUseCase.AcceptInvitation(c.Request.Context(), middleware.GetUserID(c), c.Param("invitation_id"))
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToInvitationDTO(acceptedInvitation))
+}
+
+func (h *InvitationHandler) AcceptLink(c *gin.Context) {
+	var acceptDTO dto.AcceptInvitationLinkDTO
+	if err := c.ShouldBindJSON(&acceptDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid invitation data", err, "invitation_handler.go:AcceptLink")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	acceptedInvitation, err := h.InvitationUseCase.AcceptInvitationLink(c.Request.Context(), middleware.GetUserID(c), acceptDTO.Token)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToInvitationDTO(acceptedInvitation))
+}
+
+func (h *InvitationHandler) Decline(c *gin.Context) {
+	if err := h.InvitationUseCase.DeclineInvitation(c.Request.Context(), middleware.GetUserID(c), c.Param("invitation_id")); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *InvitationHandler) RequestToJoin(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("space_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid space_id (must be integer)", err, "invitation_handler.go:RequestToJoin")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	var joinRequestDTO dto.CreateJoinRequestDTO
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&joinRequestDTO); err != nil {
+			appErr := apperror.NewInvalidData("Invalid join request data", err, "invitation_handler.go:RequestToJoin")
+			response.NewError(c.Writer, appErr)
+			return
+		}
+	}
+
+	joinRequest, err := h.InvitationUseCase.RequestToJoin(c.Request.Context(), middleware.GetUserID(c), spaceID, joinRequestDTO.Message)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.CreatedResponse(c.Writer, dto.ToJoinRequestDTO(joinRequest))
+}
+
+func (h *InvitationHandler) ListJoinRequests(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("space_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid space_id (must be integer)", err, "invitation_handler.go:ListJoinRequests")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	joinRequests, err := h.InvitationUseCase.ListJoinRequests(c.Request.Context(), middleware.GetUserID(c), spaceID)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToJoinRequestDTOs(joinRequests))
+}
+
+func (h *InvitationHandler) ApproveJoinRequest(c *gin.Context) {
+	h.reviewJoinRequest(c, true)
+}
+
+func (h *InvitationHandler) RejectJoinRequest(c *gin.Context) {
+	h.reviewJoinRequest(c, false)
+}
+
+func (h *InvitationHandler) reviewJoinRequest(c *gin.Context, approve bool) {
+	joinRequest, err := h.InvitationUseCase.ReviewJoinRequest(c.Request.Context(), middleware.GetUserID(c), c.Param("request_id"), approve)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToJoinRequestDTO(joinRequest))
+}
@@ -3,6 +3,7 @@ package message
 import (
 	"cpi-hub-api/internal/core/dto"
 	"cpi-hub-api/internal/core/usecase/message"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
 	response "cpi-hub-api/pkg/http"
@@ -34,12 +35,18 @@ func (h *MessageHandler) Search(c *gin.Context) {
 		return
 	}
 
+	if isCursorRequest(c) {
+		h.history(c, spaceID)
+		return
+	}
+
 	searchParams := dto.SearchMessagesParams{
 		Page:          page,
 		PageSize:      pageSize,
 		OrderBy:       orderBy,
 		SortDirection: sortDirection,
 		SpaceID:       spaceID,
+		UserID:        middleware.GetUserID(c),
 	}
 
 	searchResult, err := h.MessageUseCase.Search(c.Request.Context(), searchParams)
@@ -57,3 +64,62 @@ func (h *MessageHandler) Search(c *gin.Context) {
 
 	response.SuccessResponse(c.Writer, data)
 }
+
+// isCursorRequest reports whether the client asked for cursor pagination,
+// page and page_size keep working for existing clients
+func isCursorRequest(c *gin.Context) bool {
+	for _, param := range []string{"before", "after", "limit"} {
+		if _, ok := c.GetQuery(param); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *MessageHandler) history(c *gin.Context, spaceID int) {
+	params := dto.MessageHistoryParams{
+		SpaceID: spaceID,
+		UserID:  middleware.GetUserID(c),
+		Before:  c.Query("before"),
+		After:   c.Query("after"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			appErr := apperror.NewInvalidData("Invalid limit parameter (must be positive integer)", err, "message_handler.go:history")
+			response.NewError(c.Writer, appErr)
+			return
+		}
+		params.Limit = parsed
+	}
+
+	historyResult, err := h.MessageUseCase.History(c.Request.Context(), params)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.CursorMessagesResponse{
+		Data:       dto.ToMessageDTOs(historyResult.Messages),
+		NextCursor: historyResult.NextCursor,
+		HasMore:    historyResult.HasMore,
+	})
+}
+
+func (h *MessageHandler) UnreadCounts(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid user_id (must be integer)", err, "message_handler.go:UnreadCounts")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	counts, err := h.MessageUseCase.UnreadCounts(c.Request.Context(), userID)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToSpaceUnreadCountDTOs(counts))
+}
@@ -3,6 +3,7 @@ package notification
 import (
 	"cpi-hub-api/internal/core/dto"
 	notificationUsecase "cpi-hub-api/internal/core/usecase/notification"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
 	"cpi-hub-api/pkg/apperror"
 	response "cpi-hub-api/pkg/http"
 	"strconv"
@@ -100,7 +101,7 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	err := h.NotificationUseCase.MarkAsRead(c.Request.Context(), notificationID)
+	err := h.NotificationUseCase.MarkAsRead(c.Request.Context(), middleware.GetUserID(c), notificationID)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
@@ -136,3 +137,80 @@ func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
 		"message": "All notifications marked as read",
 	})
 }
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	preferences, err := h.NotificationUseCase.GetPreferences(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToNotificationPreferencesDTO(preferences))
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var params dto.UpdateNotificationPreferencesDTO
+	if err := c.ShouldBindJSON(&params); err != nil {
+		appErr := apperror.NewInvalidData("Invalid notification preferences data", err, "notification_handler.go:UpdatePreferences")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	preferences, err := h.NotificationUseCase.UpdatePreferences(c.Request.Context(), middleware.GetUserID(c), params)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToNotificationPreferencesDTO(preferences))
+}
+
+func (h *NotificationHandler) ResetPreferences(c *gin.Context) {
+	err := h.NotificationUseCase.ResetPreferences(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, gin.H{
+		"message": "Notification preferences reset",
+	})
+}
+
+func (h *NotificationHandler) Mute(c *gin.Context) {
+	var params dto.MuteNotificationsDTO
+	if err := c.ShouldBindJSON(&params); err != nil {
+		appErr := apperror.NewInvalidData("Invalid mute data", err, "notification_handler.go:Mute")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	err := h.NotificationUseCase.Mute(c.Request.Context(), middleware.GetUserID(c), params)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.CreatedResponse(c.Writer, gin.H{
+		"message": "Notifications muted",
+	})
+}
+
+func (h *NotificationHandler) Unmute(c *gin.Context) {
+	entityID, err := strconv.Atoi(c.Param("entity_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid entity_id (must be integer)", err, "notification_handler.go:Unmute")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	err = h.NotificationUseCase.Unmute(c.Request.Context(), middleware.GetUserID(c), c.Param("entity_type"), entityID)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, gin.H{
+		"message": "Notifications unmuted",
+	})
+}
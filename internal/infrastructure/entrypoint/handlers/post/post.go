@@ -3,10 +3,10 @@ package post
 import (
 	"cpi-hub-api/internal/core/dto"
 	"cpi-hub-api/internal/core/usecase/post"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
 	response "cpi-hub-api/pkg/http"
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -25,7 +25,7 @@ func (h *PostHandler) Create(c *gin.Context) {
 		return
 	}
 
-	fmt.Println("postDTO", postDTO)
+	postDTO.CreatedBy = middleware.GetUserID(c)
 
 	createdPost, err := h.PostUseCase.Create(c.Request.Context(), postDTO.ToDomain())
 	if err != nil {
@@ -45,7 +45,7 @@ func (h *PostHandler) Get(c *gin.Context) {
 		return
 	}
 
-	post, err := h.PostUseCase.Get(c.Request.Context(), postID)
+	post, err := h.PostUseCase.Get(c.Request.Context(), middleware.GetUserID(c), postID)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
@@ -71,6 +71,7 @@ func (h *PostHandler) AddComment(c *gin.Context) {
 	}
 
 	commentDTO.PostID = postID
+	commentDTO.CreatedBy = middleware.GetUserID(c)
 
 	createdComment, err := h.PostUseCase.AddComment(c.Request.Context(), commentDTO)
 
@@ -116,6 +117,7 @@ func (h *PostHandler) Search(context *gin.Context) {
 		SpaceID:       spaceID,
 		UserID:        userID,
 		Query:         context.Query("q"),
+		ViewerID:      middleware.GetUserID(context),
 	}
 
 	searchResult, err := h.PostUseCase.Search(context.Request.Context(), searchParams)
@@ -158,6 +160,7 @@ func (h *PostHandler) GetInterestedPosts(context *gin.Context) {
 		OrderBy:       orderBy,
 		SortDirection: sortDirection,
 		UserID:        userID,
+		ViewerID:      middleware.GetUserID(context),
 	}
 
 	searchResult, err := h.PostUseCase.GetInterestedPosts(context.Request.Context(), interestedParams)
@@ -195,7 +198,7 @@ func (h *PostHandler) Update(c *gin.Context) {
 
 	updatePostDTO.PostID = postID
 
-	err = h.PostUseCase.Update(c.Request.Context(), &updatePostDTO)
+	err = h.PostUseCase.Update(c.Request.Context(), middleware.GetUserID(c), &updatePostDTO)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
@@ -213,7 +216,7 @@ func (h *PostHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.PostUseCase.Delete(c.Request.Context(), postID)
+	err = h.PostUseCase.Delete(c.Request.Context(), middleware.GetUserID(c), postID)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
@@ -221,3 +224,29 @@ func (h *PostHandler) Delete(c *gin.Context) {
 
 	response.SuccessResponse(c.Writer, gin.H{"message": "Post deleted successfully"})
 }
+
+func (h *PostHandler) Pin(c *gin.Context) {
+	h.setPinned(c, true)
+}
+
+func (h *PostHandler) Unpin(c *gin.Context) {
+	h.setPinned(c, false)
+}
+
+func (h *PostHandler) setPinned(c *gin.Context, pinned bool) {
+	postIDStr := c.Param("post_id")
+	postID, err := strconv.Atoi(postIDStr)
+	if err != nil || postID <= 0 {
+		appErr := apperror.NewInvalidData("Invalid post_id parameter (must be positive integer)", err, "post_handler.go:setPinned")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	err = h.PostUseCase.Pin(c.Request.Context(), middleware.GetUserID(c), postID, pinned)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
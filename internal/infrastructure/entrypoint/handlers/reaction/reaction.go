@@ -3,6 +3,7 @@ package reaction
 import (
 	"cpi-hub-api/internal/core/dto"
 	"cpi-hub-api/internal/core/usecase/reaction"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
 	"cpi-hub-api/pkg/apperror"
 	"strconv"
 
@@ -23,6 +24,8 @@ func (h *ReactionHandler) AddReaction(c *gin.Context) {
 		return
 	}
 
+	reactionDTO.UserID = middleware.GetUserID(c)
+
 	reaction, err := h.ReactionUseCase.AddReaction(c.Request.Context(), reactionDTO.ToDomain())
 	if err != nil {
 		response.NewError(c.Writer, err)
@@ -40,7 +43,7 @@ func (h *ReactionHandler) RemoveReaction(c *gin.Context) {
 		return
 	}
 
-	err := h.ReactionUseCase.RemoveReaction(c.Request.Context(), reactionID)
+	err := h.ReactionUseCase.RemoveReaction(c.Request.Context(), middleware.GetUserID(c), reactionID)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
@@ -4,9 +4,11 @@ import (
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/dto"
 	"cpi-hub-api/internal/core/usecase/space"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
 	response "cpi-hub-api/pkg/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,6 +26,8 @@ func (h *SpaceHandler) Create(c *gin.Context) {
 		return
 	}
 
+	createSpaceDTO.CreatedBy = middleware.GetUserID(c)
+
 	createdSpace, err := h.SpaceUseCase.Create(c.Request.Context(), createSpaceDTO.ToDomain())
 	if err != nil {
 		response.NewError(c.Writer, err)
@@ -36,7 +40,7 @@ func (h *SpaceHandler) Create(c *gin.Context) {
 func (h *SpaceHandler) Get(c *gin.Context) {
 	spaceId := c.Param("space_id")
 
-	space, err := h.SpaceUseCase.Get(c.Request.Context(), spaceId)
+	space, err := h.SpaceUseCase.Get(c.Request.Context(), middleware.GetUserID(c), spaceId)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
@@ -58,13 +62,15 @@ func (h *SpaceHandler) Search(context *gin.Context) {
 	orderBy, sortDirection := helpers.GetSortValues(context)
 
 	searchCriteria := &domain.SpaceSearchCriteria{
-		Name:          searchDTO.Name,
-		CreatedBy:     searchDTO.CreatedBy,
-		OrderBy:       orderBy,
-		Page:          page,
-		PageSize:      pageSize,
-		SortDirection: sortDirection,
-		Query:         context.Query("q"),
+		Name:            searchDTO.Name,
+		CreatedBy:       searchDTO.CreatedBy,
+		OrderBy:         orderBy,
+		Page:            page,
+		PageSize:        pageSize,
+		SortDirection:   sortDirection,
+		Query:           context.Query("q"),
+		ViewerID:        middleware.GetUserID(context),
+		IncludeArchived: searchDTO.IncludeArchived,
 	}
 
 	searchResult, err := h.SpaceUseCase.Search(context.Request.Context(), searchCriteria)
@@ -86,16 +92,148 @@ func (h *SpaceHandler) Search(context *gin.Context) {
 func (h *SpaceHandler) GetUsersBySpace(c *gin.Context) {
 	spaceID := c.Param("space_id")
 
-	users, err := h.SpaceUseCase.GetUsersBySpace(c.Request.Context(), spaceID)
+	members, err := h.SpaceUseCase.GetUsersBySpace(c.Request.Context(), middleware.GetUserID(c), spaceID)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToSpaceMemberDTOs(members))
+}
+
+func (h *SpaceHandler) UpdateMemberRole(c *gin.Context) {
+	spaceID, userID, err := parseSpaceMemberParams(c)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	var updateRoleDTO dto.UpdateMemberRoleDTO
+	if err := c.ShouldBindJSON(&updateRoleDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid role data", err, "space_handler.go:UpdateMemberRole")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	err = h.SpaceUseCase.UpdateMemberRole(c.Request.Context(), middleware.GetUserID(c), spaceID, userID, domain.SpaceRole(updateRoleDTO.Role))
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *SpaceHandler) RemoveMember(c *gin.Context) {
+	spaceID, userID, err := parseSpaceMemberParams(c)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	err = h.SpaceUseCase.RemoveMember(c.Request.Context(), middleware.GetUserID(c), spaceID, userID)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *SpaceHandler) TransferOwnership(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("space_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid space_id (must be integer)", err, "space_handler.go:TransferOwnership")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	var transferDTO dto.TransferOwnershipDTO
+	if err := c.ShouldBindJSON(&transferDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid ownership data", err, "space_handler.go:TransferOwnership")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	err = h.SpaceUseCase.TransferOwnership(c.Request.Context(), middleware.GetUserID(c), spaceID, transferDTO.UserID)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
 	}
 
-	userDTOs := make([]dto.UserDTO, len(users))
-	for i, user := range users {
-		userDTOs[i] = dto.ToUserDTO(user)
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func parseSpaceMemberParams(c *gin.Context) (int, int, error) {
+	spaceID, err1 := strconv.Atoi(c.Param("space_id"))
+	userID, err2 := strconv.Atoi(c.Param("user_id"))
+	if err1 != nil || err2 != nil {
+		return 0, 0, apperror.NewInvalidData("Invalid space_id or user_id (must be integer)", nil, "space_handler.go:parseSpaceMemberParams")
+	}
+
+	return spaceID, userID, nil
+}
+
+func (h *SpaceHandler) Update(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("space_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid space_id (must be integer)", err, "space_handler.go:Update")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	var updateSpaceDTO dto.UpdateSpaceDTO
+	if err := c.ShouldBindJSON(&updateSpaceDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid space data", err, "space_handler.go:Update")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	space, err := h.SpaceUseCase.Update(c.Request.Context(), middleware.GetUserID(c), spaceID, updateSpaceDTO)
+	if err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, dto.ToSpaceWithUserDTO(space))
+}
+
+func (h *SpaceHandler) Archive(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+func (h *SpaceHandler) Unarchive(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+func (h *SpaceHandler) setArchived(c *gin.Context, archived bool) {
+	spaceID, err := strconv.Atoi(c.Param("space_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid space_id (must be integer)", err, "space_handler.go:setArchived")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	if err := h.SpaceUseCase.Archive(c.Request.Context(), middleware.GetUserID(c), spaceID, archived); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *SpaceHandler) Delete(c *gin.Context) {
+	spaceID, err := strconv.Atoi(c.Param("space_id"))
+	if err != nil {
+		appErr := apperror.NewInvalidData("Invalid space_id (must be integer)", err, "space_handler.go:Delete")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	if err := h.SpaceUseCase.Delete(c.Request.Context(), middleware.GetUserID(c), spaceID); err != nil {
+		response.NewError(c.Writer, err)
+		return
 	}
 
-	response.SuccessResponse(c.Writer, userDTOs)
+	response.SuccessResponse(c.Writer, gin.H{"message": "Space deleted successfully"})
 }
@@ -3,8 +3,10 @@ package user
 import (
 	"cpi-hub-api/internal/core/domain"
 	"cpi-hub-api/internal/core/dto"
+	"cpi-hub-api/internal/core/usecase/auth"
 	"cpi-hub-api/internal/core/usecase/post"
 	"cpi-hub-api/internal/core/usecase/user"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
 	"cpi-hub-api/pkg/apperror"
 	"cpi-hub-api/pkg/helpers"
 	response "cpi-hub-api/pkg/http"
@@ -16,6 +18,7 @@ import (
 type UserHandler struct {
 	UseCase     user.UserUseCase
 	PostUseCase post.PostUseCase
+	AuthUseCase auth.AuthUseCase
 }
 
 func (h *UserHandler) Register(c *gin.Context) {
@@ -35,17 +38,25 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	token, err := helpers.CreateToken(createdUser.Email, createdUser.ID)
+	// The account cannot sign in until the email sent on creation is confirmed
+	if h.AuthUseCase.RequiresEmailVerification(createdUser) {
+		response.CreatedResponse(c.Writer, dto.AuthResponse{User: dto.ToUserDTO(createdUser)})
+		return
+	}
+
+	tokens, err := h.AuthUseCase.IssueTokens(c.Request.Context(), createdUser)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
 	}
 
-	c.Header("Authorization", "Bearer "+token)
+	c.Header("Authorization", "Bearer "+tokens.AccessToken)
 
 	registerResponse := dto.AuthResponse{
-		User:  dto.ToUserDTO(createdUser),
-		Token: token,
+		User:         dto.ToUserDTO(createdUser),
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
 	}
 
 	response.CreatedResponse(c.Writer, registerResponse)
@@ -60,42 +71,166 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	loginDTO.IP = c.ClientIP()
+
 	user, err := h.UseCase.Login(c.Request.Context(), loginDTO)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
 	}
 
-	token, err := helpers.CreateToken(user.Email, user.ID)
+	tokens, err := h.AuthUseCase.IssueTokens(c.Request.Context(), user)
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
 	}
 
-	c.Header("Authorization", "Bearer "+token)
+	c.Header("Authorization", "Bearer "+tokens.AccessToken)
 
 	loginResponse := dto.AuthResponse{
-		User:  dto.ToUserDTO(user),
-		Token: token,
+		User:         dto.ToUserDTO(user),
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
 	}
 
 	response.SuccessResponse(c.Writer, loginResponse)
 }
 
-func (h *UserHandler) GetCurrentUser(c *gin.Context) {
-	token := c.Request.Header.Get("Authorization")
-	if token == "" {
-		response.NewError(c.Writer, apperror.NewUnauthorized("Missing authorization token", nil, "user_handler.go:GetCurrentUser"))
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var verifyDTO dto.VerifyEmail
+
+	if err := c.ShouldBindJSON(&verifyDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid verification data", err, "user_handler.go:VerifyEmail")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	if err := h.UseCase.VerifyEmail(c.Request.Context(), verifyDTO.Token); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *UserHandler) ResendEmailVerification(c *gin.Context) {
+	if err := h.UseCase.SendEmailVerification(c.Request.Context(), middleware.GetUserID(c)); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var forgotDTO dto.ForgotPassword
+
+	if err := c.ShouldBindJSON(&forgotDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid email", err, "user_handler.go:ForgotPassword")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	if err := h.UseCase.ForgotPassword(c.Request.Context(), forgotDTO.Email); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var resetDTO dto.ResetPassword
+
+	if err := c.ShouldBindJSON(&resetDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid reset data", err, "user_handler.go:ResetPassword")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	if err := h.UseCase.ResetPassword(c.Request.Context(), resetDTO); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var changePasswordDTO dto.ChangePassword
+
+	if err := c.ShouldBindJSON(&changePasswordDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid password data", err, "user_handler.go:ChangePassword")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	changePasswordDTO.UserID = middleware.GetUserID(c)
+	changePasswordDTO.SessionID = middleware.GetSessionID(c)
+
+	if err := h.UseCase.ChangePassword(c.Request.Context(), changePasswordDTO); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *UserHandler) ChangeEmail(c *gin.Context) {
+	var changeEmailDTO dto.ChangeEmail
+
+	if err := c.ShouldBindJSON(&changeEmailDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid email data", err, "user_handler.go:ChangeEmail")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	changeEmailDTO.UserID = middleware.GetUserID(c)
+
+	if err := h.UseCase.ChangeEmail(c.Request.Context(), changeEmailDTO); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	var verifyDTO dto.VerifyEmail
+
+	if err := c.ShouldBindJSON(&verifyDTO); err != nil {
+		appErr := apperror.NewInvalidData("Invalid confirmation data", err, "user_handler.go:ConfirmEmailChange")
+		response.NewError(c.Writer, appErr)
+		return
+	}
+
+	if err := h.UseCase.ConfirmEmailChange(c.Request.Context(), verifyDTO.Token); err != nil {
+		response.NewError(c.Writer, err)
 		return
 	}
 
-	userId, err := helpers.GetUserIdFromToken(token)
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
 	if err != nil {
-		response.NewError(c.Writer, apperror.NewUnauthorized("Invalid token", err, "user_handler.go:GetCurrentUser"))
+		appErr := apperror.NewInvalidData("Invalid user_id (must be integer)", err, "user_handler.go:UnlockUser")
+		response.NewError(c.Writer, appErr)
 		return
 	}
 
-	user, err := h.UseCase.Get(c.Request.Context(), userId)
+	if err := h.UseCase.UnlockUser(c.Request.Context(), middleware.GetUserID(c), userID); err != nil {
+		response.NewError(c.Writer, err)
+		return
+	}
+
+	response.SuccessResponse(c.Writer, nil)
+}
+
+func (h *UserHandler) GetCurrentUser(c *gin.Context) {
+	user, err := h.UseCase.Get(c.Request.Context(), middleware.GetUserID(c))
 	if err != nil {
 		response.NewError(c.Writer, err)
 		return
@@ -218,6 +353,7 @@ func (h *UserHandler) GetInterestedPosts(c *gin.Context) {
 		OrderBy:       orderBy,
 		SortDirection: sortDirection,
 		UserID:        userId,
+		ViewerID:      middleware.GetUserID(c),
 	})
 	if err != nil {
 		response.NewError(c.Writer, err)
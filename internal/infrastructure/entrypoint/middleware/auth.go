@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"cpi-hub-api/pkg/apperror"
+	"cpi-hub-api/pkg/helpers"
+	response "cpi-hub-api/pkg/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	userIDKey    = "auth_user_id"
+	sessionIDKey = "auth_session_id"
+)
+
+// PublicRoute identifies a route that can be called without a bearer token
+type PublicRoute struct {
+	Method string
+	Path   string
+}
+
+// SessionValidator rejects access tokens whose session was logged out
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, userID int, sessionID string) error
+}
+
+// Authenticate verifies the bearer token of every request and stores the
+// authenticated user ID in the request context. Routes listed in publicRoutes
+// are let through without a token.
+func Authenticate(sessions SessionValidator, publicRoutes ...PublicRoute) gin.HandlerFunc {
+	public := make(map[PublicRoute]bool, len(publicRoutes))
+	for _, route := range publicRoutes {
+		public[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method == "OPTIONS" || public[PublicRoute{Method: c.Request.Method, Path: c.FullPath()}] {
+			c.Next()
+			return
+		}
+
+		token := BearerToken(c.Request.Header.Get("Authorization"))
+		if token == "" {
+			abort(c, apperror.NewUnauthorized("Missing authorization token", nil, "auth_middleware.go:Authenticate"))
+			return
+		}
+
+		userID, sessionID, err := ParseAccessToken(c.Request.Context(), sessions, token)
+		if err != nil {
+			abort(c, err)
+			return
+		}
+
+		c.Set(userIDKey, userID)
+		c.Set(sessionIDKey, sessionID)
+		c.Next()
+	}
+}
+
+// ParseAccessToken returns the user and the session of an access token, as long
+// as the session was not logged out
+func ParseAccessToken(ctx context.Context, sessions SessionValidator, token string) (int, string, error) {
+	userID, err := helpers.GetUserIdFromToken(token)
+	if err != nil {
+		return 0, "", apperror.NewUnauthorized("Invalid token", err, "auth_middleware.go:ParseAccessToken")
+	}
+
+	// Tokens issued before sessions existed carry no sid, the validator rejects them
+	sessionID, _ := helpers.GetSessionIdFromToken(token)
+	if err := sessions.ValidateSession(ctx, userID, sessionID); err != nil {
+		return 0, "", err
+	}
+
+	return userID, sessionID, nil
+}
+
+// RequireSameUser rejects the request when the given path param does not
+// match the authenticated user
+func RequireSameUser(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		paramUserID, err := strconv.Atoi(c.Param(param))
+		if err != nil {
+			abort(c, apperror.NewInvalidData("Invalid "+param+" (must be integer)", err, "auth_middleware.go:RequireSameUser"))
+			return
+		}
+
+		if paramUserID != GetUserID(c) {
+			abort(c, apperror.NewForbidden("You can only access your own resources", nil, "auth_middleware.go:RequireSameUser"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetUserID returns the ID of the authenticated user, or 0 on public routes
+func GetUserID(c *gin.Context) int {
+	return c.GetInt(userIDKey)
+}
+
+// GetSessionID returns the refresh token family the access token was issued with
+func GetSessionID(c *gin.Context) string {
+	return c.GetString(sessionIDKey)
+}
+
+// BearerToken strips the optional "Bearer " prefix of an Authorization header
+func BearerToken(header string) string {
+	header = strings.TrimSpace(header)
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return header
+}
+
+func abort(c *gin.Context, err error) {
+	response.NewError(c.Writer, err)
+	c.Abort()
+}
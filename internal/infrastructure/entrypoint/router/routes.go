@@ -2,42 +2,102 @@ package router
 
 import (
 	"cpi-hub-api/internal/app/dependencies"
+	"cpi-hub-api/internal/infrastructure/entrypoint/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
+// publicRoutes can be called without a bearer token
+var publicRoutes = []middleware.PublicRoute{
+	{Method: "POST", Path: "/v1/auth/register"},
+	{Method: "POST", Path: "/v1/auth/login"},
+	{Method: "POST", Path: "/v1/auth/refresh"},
+	{Method: "POST", Path: "/v1/auth/verify-email"},
+	{Method: "POST", Path: "/v1/auth/forgot-password"},
+	{Method: "POST", Path: "/v1/auth/reset-password"},
+	{Method: "POST", Path: "/v1/auth/confirm-email"},
+
+	// WebSocket handshakes cannot carry an Authorization header from the browser,
+	// EventsHandler authenticates them with a ticket or the Sec-WebSocket-Protocol header
+	{Method: "GET", Path: "/v1/ws/spaces/:space_id"},
+	{Method: "GET", Path: "/v1/ws/user-connection"},
+	{Method: "GET", Path: "/v1/ws/notifications"},
+}
+
 func LoadRoutes(app *gin.Engine, handlers *dependencies.Handlers) {
 	v1 := app.Group("/v1")
+	v1.Use(middleware.Authenticate(handlers.AuthHandler.AuthUseCase, publicRoutes...))
+
+	sameUser := middleware.RequireSameUser("user_id")
 
 	// users
 	v1.GET("/users/current", handlers.UserHandler.GetCurrentUser)
+	v1.PUT("/users/current/password", handlers.UserHandler.ChangePassword)
+	v1.PUT("/users/current/email", handlers.UserHandler.ChangeEmail)
 	v1.GET("/users", handlers.UserHandler.Search)
 
 	// notifications
-	v1.GET("/users/:user_id/notifications", handlers.NotificationHandler.GetNotifications)
-	v1.GET("/users/:user_id/notifications/unread-count", handlers.NotificationHandler.GetUnreadCount)
-	v1.PUT("/users/:user_id/notifications/:notification_id/read", handlers.NotificationHandler.MarkAsRead)
-	v1.PUT("/users/:user_id/notifications/read-all", handlers.NotificationHandler.MarkAllAsRead)
+	v1.GET("/users/:user_id/notifications", sameUser, handlers.NotificationHandler.GetNotifications)
+	v1.GET("/users/:user_id/notifications/unread-count", sameUser, handlers.NotificationHandler.GetUnreadCount)
+	v1.PUT("/users/:user_id/notifications/:notification_id/read", sameUser, handlers.NotificationHandler.MarkAsRead)
+	v1.PUT("/users/:user_id/notifications/read-all", sameUser, handlers.NotificationHandler.MarkAllAsRead)
+	v1.GET("/users/:user_id/notification-preferences", sameUser, handlers.NotificationHandler.GetPreferences)
+	v1.PUT("/users/:user_id/notification-preferences", sameUser, handlers.NotificationHandler.UpdatePreferences)
+	v1.DELETE("/users/:user_id/notification-preferences", sameUser, handlers.NotificationHandler.ResetPreferences)
+	v1.POST("/users/:user_id/notification-preferences/mutes", sameUser, handlers.NotificationHandler.Mute)
+	v1.DELETE("/users/:user_id/notification-preferences/mutes/:entity_type/:entity_id", sameUser, handlers.NotificationHandler.Unmute)
 
 	// user spaces
-	v1.PUT("/users/:user_id/spaces/:space_id/add", handlers.UserHandler.AddSpaceToUser)
-	v1.PUT("/users/:user_id/spaces/:space_id/remove", handlers.UserHandler.RemoveSpaceFromUser)
-	v1.GET("/users/:user_id/interested-posts", handlers.UserHandler.GetInterestedPosts)
-	v1.POST("/users/:user_id/likes", handlers.ReactionHandler.GetUserLikes)
+	v1.PUT("/users/:user_id/spaces/:space_id/add", sameUser, handlers.UserHandler.AddSpaceToUser)
+	v1.PUT("/users/:user_id/spaces/:space_id/remove", sameUser, handlers.UserHandler.RemoveSpaceFromUser)
+	v1.GET("/users/:user_id/interested-posts", sameUser, handlers.UserHandler.GetInterestedPosts)
+	v1.POST("/users/:user_id/likes", sameUser, handlers.ReactionHandler.GetUserLikes)
 
 	// users
 	v1.GET("/users/:user_id", handlers.UserHandler.Get)
-	v1.PUT("/users/:user_id", handlers.UserHandler.UpdateUser)
+	v1.PUT("/users/:user_id", sameUser, handlers.UserHandler.UpdateUser)
 
 	//auth
 	v1.POST("/auth/register", handlers.UserHandler.Register)
 	v1.POST("/auth/login", handlers.UserHandler.Login)
+	v1.POST("/auth/refresh", handlers.AuthHandler.Refresh)
+	v1.POST("/auth/logout", handlers.AuthHandler.Logout)
+	v1.POST("/auth/logout-all", handlers.AuthHandler.LogoutAll)
+	v1.POST("/auth/verify-email", handlers.UserHandler.VerifyEmail)
+	v1.POST("/auth/verify-email/resend", handlers.UserHandler.ResendEmailVerification)
+	v1.POST("/auth/forgot-password", handlers.UserHandler.ForgotPassword)
+	v1.POST("/auth/reset-password", handlers.UserHandler.ResetPassword)
+	v1.POST("/auth/confirm-email", handlers.UserHandler.ConfirmEmailChange)
+
+	// admin
+	v1.POST("/admin/users/:user_id/unlock", handlers.UserHandler.UnlockUser)
 
 	// spaces
 	v1.POST("/spaces", handlers.SpaceHandler.Create)
 	v1.GET("/spaces/:space_id", handlers.SpaceHandler.Get)
 	v1.GET("/spaces", handlers.SpaceHandler.Search)
+	v1.PUT("/spaces/:space_id", handlers.SpaceHandler.Update)
+	v1.DELETE("/spaces/:space_id", handlers.SpaceHandler.Delete)
+	v1.POST("/spaces/:space_id/archive", handlers.SpaceHandler.Archive)
+	v1.DELETE("/spaces/:space_id/archive", handlers.SpaceHandler.Unarchive)
 	v1.GET("/spaces/:space_id/users", handlers.SpaceHandler.GetUsersBySpace)
+	v1.GET("/spaces/:space_id/presence", handlers.EventsHandler.GetPresence)
+	v1.PUT("/spaces/:space_id/members/:user_id/role", handlers.SpaceHandler.UpdateMemberRole)
+	v1.DELETE("/spaces/:space_id/members/:user_id", handlers.SpaceHandler.RemoveMember)
+	v1.PUT("/spaces/:space_id/owner", handlers.SpaceHandler.TransferOwnership)
+
+	// invitations and join requests
+	v1.POST("/spaces/:space_id/invitations", handlers.InvitationHandler.Create)
+	v1.GET("/spaces/:space_id/invitations", handlers.InvitationHandler.ListBySpace)
+	v1.DELETE("/invitations/:invitation_id", handlers.InvitationHandler.Revoke)
+	v1.GET("/users/current/invitations", handlers.InvitationHandler.ListCurrentUser)
+	v1.POST("/invitations/accept", handlers.InvitationHandler.AcceptLink)
+	v1.POST("/invitations/:invitation_id/accept", handlers.InvitationHandler.Accept)
+	v1.POST("/invitations/:invitation_id/decline", handlers.InvitationHandler.Decline)
+	v1.POST("/spaces/:space_id/join-requests", handlers.InvitationHandler.RequestToJoin)
+	v1.GET("/spaces/:space_id/join-requests", handlers.InvitationHandler.ListJoinRequests)
+	v1.PUT("/join-requests/:request_id/approve", handlers.InvitationHandler.ApproveJoinRequest)
+	v1.PUT("/join-requests/:request_id/reject", handlers.InvitationHandler.RejectJoinRequest)
 
 	// posts
 	v1.POST("/posts", handlers.PostHandler.Create)
@@ -46,6 +106,8 @@ func LoadRoutes(app *gin.Engine, handlers *dependencies.Handlers) {
 	v1.GET("/posts", handlers.PostHandler.Search)
 	v1.POST("/posts/:post_id/comments", handlers.PostHandler.AddComment)
 	v1.DELETE("/posts/:post_id", handlers.PostHandler.Delete)
+	v1.POST("/posts/:post_id/pin", handlers.PostHandler.Pin)
+	v1.DELETE("/posts/:post_id/pin", handlers.PostHandler.Unpin)
 
 	//comments
 	v1.GET("/comments", handlers.CommentHandler.Search)
@@ -53,6 +115,7 @@ func LoadRoutes(app *gin.Engine, handlers *dependencies.Handlers) {
 	v1.DELETE("/comments/:comment_id", handlers.CommentHandler.Delete)
 
 	// events
+	v1.POST("/ws/tickets", handlers.EventsHandler.IssueTicket)
 	v1.GET("/ws/spaces/:space_id", handlers.EventsHandler.Connect)
 	v1.POST("/ws/spaces/:space_id/broadcast", handlers.EventsHandler.Broadcast)
 	v1.POST("/ws/spaces/:space_id/chat", handlers.EventsHandler.ChatMessage)
@@ -61,6 +124,9 @@ func LoadRoutes(app *gin.Engine, handlers *dependencies.Handlers) {
 
 	// messages
 	v1.GET("/messages", handlers.MessageHandler.Search)
+	v1.GET("/users/:user_id/messages/unread-counts", sameUser, handlers.MessageHandler.UnreadCounts)
+	v1.PUT("/messages/:message_id", handlers.EventsHandler.EditMessage)
+	v1.DELETE("/messages/:message_id", handlers.EventsHandler.DeleteMessage)
 
 	// reactions
 	v1.POST("/reactions", handlers.ReactionHandler.AddReaction)
@@ -27,6 +27,7 @@ func NewNotFound(message string, error interface{}, thrownAt string) error {
 	)
 }
 
+// NewUnauthorized corresponds to a request without valid credentials
 func NewUnauthorized(message string, error interface{}, thrownAt string) error {
 	return NewError(
 		Unauthorized,
@@ -147,6 +148,7 @@ func StatusCode(e error) int {
 
 	errorTypeToStatus := map[ErrorType]int{
 		NotFound:                http.StatusNotFound,
+		Unauthorized:            http.StatusUnauthorized,
 		UnexpectedDatabaseError: defaultStatus,
 		Gone:                    http.StatusGone,
 		InternalServer:          defaultStatus,
@@ -11,12 +11,15 @@ import (
 
 var secretKey = []byte(os.Getenv("JWT_SECRET_KEY"))
 
-func CreateToken(email string, userId int) (string, error) {
+// CreateToken signs an access token for the user. sessionID ties the token to
+// the refresh token family it was issued with.
+func CreateToken(email string, userId int, sessionID string, ttl time.Duration) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
 		jwt.MapClaims{
 			"email":   email,
 			"user_id": userId,
-			"exp":     GetTime().Add(time.Hour * 24).Unix(),
+			"sid":     sessionID,
+			"exp":     GetTime().Add(ttl).Unix(),
 			"iat":     GetTime().Unix(),
 		})
 
@@ -45,6 +48,30 @@ func VerifyToken(tokenString string) error {
 }
 
 func GetUserIdFromToken(tokenString string) (int, error) {
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		return 0, err
+	}
+
+	if userIdFloat, exists := claims["user_id"].(float64); exists {
+		return int(userIdFloat), nil
+	}
+	return 0, fmt.Errorf("user_id claim not found")
+}
+
+func GetSessionIdFromToken(tokenString string) (string, error) {
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	if sessionID, exists := claims["sid"].(string); exists {
+		return sessionID, nil
+	}
+	return "", fmt.Errorf("sid claim not found")
+}
+
+func parseClaims(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -54,16 +81,13 @@ func GetUserIdFromToken(tokenString string) (int, error) {
 	})
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if userIdFloat, exists := claims["user_id"].(float64); exists {
-			return int(userIdFloat), nil
-		}
-		return 0, fmt.Errorf("user_id claim not found")
+		return claims, nil
 	}
-	return 0, fmt.Errorf("invalid token claims")
+	return nil, fmt.Errorf("invalid token claims")
 }
 
 func IsTokenExpired(tokenString string) bool {
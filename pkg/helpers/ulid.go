@@ -1,15 +1,31 @@
 package helpers
 
 import (
-	"math/rand"
+	"crypto/rand"
+	"sync"
 
 	"github.com/oklog/ulid/v2"
 )
 
+var (
+	// ulidEntropy is shared so the IDs made in the same millisecond keep increasing,
+	// ulid.Monotonic is not safe for concurrent use
+	ulidEntropy      = ulid.Monotonic(rand.Reader, 0)
+	ulidEntropyMutex sync.Mutex
+)
+
 func NewULID() string {
-	now := GetTime()
-	entropy := rand.New(rand.NewSource(now.UnixNano()))
-	ms := ulid.Timestamp(now)
-	result, _ := ulid.New(ms, entropy)
+	ms := ulid.Timestamp(GetTime())
+
+	ulidEntropyMutex.Lock()
+	defer ulidEntropyMutex.Unlock()
+
+	result := ulid.MustNew(ms, ulidEntropy)
 	return result.String()
 }
+
+// IsULID reports whether s is a well formed ULID
+func IsULID(s string) bool {
+	_, err := ulid.ParseStrict(s)
+	return err == nil
+}